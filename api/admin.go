@@ -0,0 +1,86 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type KeyRotationStatus string
+
+const (
+	KeyRotationStatusRunning   KeyRotationStatus = "running"
+	KeyRotationStatusCompleted KeyRotationStatus = "completed"
+	KeyRotationStatusFailed    KeyRotationStatus = "failed"
+)
+
+// Progress of a re-encryption of all stored platform tokens
+// under the cloud provider's current encryption key
+type KeyRotation struct {
+	Status    KeyRotationStatus `json:"status"`
+	BatchSize int               `json:"batch_size"`
+
+	TotalTokens       int64 `json:"total_tokens"`
+	ProcessedTokens   int64 `json:"processed_tokens"`
+	ReEncryptedTokens int64 `json:"re_encrypted_tokens"`
+	SkippedTokens     int64 `json:"skipped_tokens"` // Replaced while the rotation was running
+	FailedTokens      int64 `json:"failed_tokens"`
+	UpdatedJobs       int64 `json:"updated_jobs"`
+	FailedJobs        int64 `json:"failed_jobs"`
+
+	Failures []KeyRotationFailure `json:"failures"`
+	Error    *string              `json:"error,omitempty"`
+
+	StartedAt   time.Time  `json:"started_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+// A token or job that could not be updated during a key rotation
+// JobID is only set if the failure occurred while updating a scheduled job
+type KeyRotationFailure struct {
+	TokenID uuid.UUID  `json:"token_id"`
+	JobID   *uuid.UUID `json:"job_id,omitempty"`
+	Error   string     `json:"error"`
+}
+
+// Request type to start a key rotation
+// If the batch size is zero, the server default is used
+type KeyRotationRequest struct {
+	BatchSize int `json:"batch_size"`
+}
+
+// Starts a key rotation re-encrypting all stored platform tokens
+// Returns the initial progress of the rotation
+func (c *Client) StartKeyRotation(keyRotationReq KeyRotationRequest) (KeyRotation, error) {
+	reqUrl := c.host + "/api/admin/key-rotation"
+	req, err := c.NewJsonRequest(http.MethodPost, reqUrl, keyRotationReq)
+	if err != nil {
+		return KeyRotation{}, err
+	}
+
+	var keyRotation KeyRotation
+	err = c.Do(req, &keyRotation)
+	if err != nil {
+		return KeyRotation{}, err
+	}
+
+	return keyRotation, nil
+}
+
+// Retrieves the progress of the current or most recent key rotation
+func (c *Client) GetKeyRotation() (KeyRotation, error) {
+	reqUrl := c.host + "/api/admin/key-rotation"
+	req, err := http.NewRequest(http.MethodGet, reqUrl, nil)
+	if err != nil {
+		return KeyRotation{}, err
+	}
+
+	var keyRotation KeyRotation
+	err = c.Do(req, &keyRotation)
+	if err != nil {
+		return KeyRotation{}, err
+	}
+
+	return keyRotation, nil
+}
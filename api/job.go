@@ -1,18 +1,49 @@
 package api
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+var (
+	ErrInvalidTimeWindow = errors.New("invalid time window")
+	ErrInvalidStrategy   = errors.New("invalid booking strategy")
+)
+
+const (
+	// Maximum tolerance of a time window in minutes
+	MaxTimeWindowTolerance = 180
+	// Maximum number of alternatives of a job as each
+	// is queried concurrently at the drop
+	MaxJobAlternatives = 5
+	// Maximum stagger of the stagger strategy in milliseconds
+	MaxJobStrategyStagger = 5000
+)
+
+type JobType string
+
+const (
+	// Job booking a slot at the drop time
+	JobTypeDrop JobType = "drop"
+	// Job polling for slots from its start until its deadline, such as for cancellations
+	JobTypeWatch JobType = "watch"
+	// Job polling for slots more preferred than those of a reservation booked by a job,
+	// booking them and cancelling the reservation, created by the server
+	JobTypeUpgrade JobType = "upgrade"
+)
+
 type JobStatus string
 
 const (
 	JobStatusCreated   JobStatus = "created"
 	JobStatusScheduled JobStatus = "scheduled"
+	JobStatusRunning   JobStatus = "running"
 	JobStatusSuccess   JobStatus = "success"
 	JobStatusFailed    JobStatus = "failed"
 	JobStatusCancelled JobStatus = "cancelled"
@@ -23,10 +54,24 @@ type Job struct {
 	UserID       uuid.UUID `json:"user_id"`
 	RestaurantID uuid.UUID `json:"restaurant_id"`
 	Platform     string    `json:"platform"`
+	Type         JobType   `json:"type"`
 
 	ReservationDate string   `json:"reservation_date"` // YYYY-MM-DD
 	PartySize       int16    `json:"party_size"`
 	PreferredTimes  []string `json:"preferred_times"` // HH:mm
+	// Preferred time windows, matched after the preferred times
+	PreferredWindows []TimeWindow `json:"preferred_windows,omitempty"`
+	// Alternative reservation dates and party sizes, in order of preference
+	Alternatives []JobAlternative `json:"alternatives,omitempty"`
+	Watch        *JobWatch        `json:"watch,omitempty"`
+	// Seating types to restrict the booked slots to and to prefer, in order of preference
+	AllowedSeatingTypes   []string          `json:"allowed_seating_types,omitempty"`
+	PreferredSeatingTypes []string          `json:"preferred_seating_types,omitempty"`
+	PaymentPolicy         *JobPaymentPolicy `json:"payment_policy,omitempty"`
+	Strategy              *JobStrategy      `json:"strategy,omitempty"`
+	Upgrade               *JobUpgrade       `json:"upgrade,omitempty"`
+	// Reservation upgraded by an upgrade job
+	UpgradeReservationID *uuid.UUID `json:"upgrade_reservation_id,omitempty"`
 
 	ScheduledAt  time.Time `json:"scheduled_at"`
 	DropConfigID uuid.UUID `json:"drop_config_id"`
@@ -37,21 +82,224 @@ type Job struct {
 	CompletedAt *time.Time `json:"completed_at,omitempty"`
 
 	ReservedTime *time.Time `json:"reserved_time,omitempty"`
-	Confirmation *string    `json:"confirmation,omitempty"`
-	ErrorMessage *string    `json:"error_message,omitempty"`
-	Logs         *string    `json:"logs,omitempty"`
+	// Reservation date and party size of the booked alternative
+	ReservedDate        *string `json:"reserved_date,omitempty"` // YYYY-MM-DD
+	ReservedPartySize   *int16  `json:"reserved_party_size,omitempty"`
+	ReservedSeatingType *string `json:"reserved_seating_type,omitempty"`
+	// Deposit required by the booked slot and the time until which it can be cancelled without a fee
+	ReservedDeposit            *float32   `json:"reserved_deposit,omitempty"`
+	ReservedCancellationCutOff *time.Time `json:"reserved_cancellation_cut_off,omitempty"`
+	Confirmation               *string    `json:"confirmation,omitempty"`
+	ErrorMessage               *string    `json:"error_message,omitempty"`
+	Logs                       *string    `json:"logs,omitempty"`
 
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
 // Request type for a new job
+// NOTE: Type defaults to a drop job, watch jobs require the watch
+// configuration and do not use a drop configuration
 type JobCreationRequest struct {
+	Type            JobType   `json:"type,omitempty"`
 	RestaurantID    uuid.UUID `json:"restaurant_id"`
 	ReservationDate string    `json:"reservation_date"` // YYYY-MM-DD
 	PartySize       int16     `json:"party_size"`
 	PreferredTimes  []string  `json:"preferred_times"` // HH:mm
-	DropConfigID    uuid.UUID `json:"drop_config_id"`
+	// Preferred time windows, matched after the preferred times
+	PreferredWindows []TimeWindow `json:"preferred_windows,omitempty"`
+	// Alternative reservation dates and party sizes, in order of preference
+	// They are booked if no slot of the reservation date and party size could
+	// be booked and must be released at the same drop as the reservation date
+	Alternatives []JobAlternative `json:"alternatives,omitempty"`
+	Watch        *JobWatch        `json:"watch,omitempty"`
+	// Seating types to restrict the booked slots to, all seating types being allowed if empty
+	AllowedSeatingTypes []string `json:"allowed_seating_types,omitempty"`
+	// Seating types to prefer, in order of preference
+	PreferredSeatingTypes []string          `json:"preferred_seating_types,omitempty"`
+	PaymentPolicy         *JobPaymentPolicy `json:"payment_policy,omitempty"`
+	// Booking strategy of the slots, slots being attempted with a stagger of a second if not set
+	Strategy *JobStrategy `json:"strategy,omitempty"`
+	// Upgrade of the booked reservation to a more preferred slot, disabled if not set
+	Upgrade      *JobUpgrade `json:"upgrade,omitempty"`
+	DropConfigID uuid.UUID   `json:"drop_config_id"`
+}
+
+// Request type for a dry run of a job
+type JobTestRequest struct {
+	// Reservation date to retrieve the slots of instead of the reservation date
+	// of the job, such as a date whose slots are already released
+	ReservationDate string `json:"reservation_date,omitempty"` // YYYY-MM-DD
+}
+
+// Result of a dry run of a job
+// The slot that would have been booked is set if the dry run was successful
+type JobTestResult struct {
+	Success         bool          `json:"success"`
+	Message         string        `json:"message"`
+	Error           string        `json:"error,omitempty"`
+	SlotTime        *time.Time    `json:"slot_time,omitempty"`
+	ReservationDate string        `json:"reservation_date,omitempty"` // YYYY-MM-DD
+	PartySize       int16         `json:"party_size,omitempty"`
+	Deposit         *float32      `json:"deposit,omitempty"`
+	Attempts        int           `json:"attempts"` // Slots checked before finding a bookable slot
+	Duration        time.Duration `json:"duration"`
+}
+
+// Watch configuration of a job
+type JobWatch struct {
+	StartAt  *time.Time `json:"start_at,omitempty"` // Starts shortly after creation if not set
+	Until    time.Time  `json:"until"`
+	Interval int        `json:"interval"` // Seconds between polls
+}
+
+// Automatic upgrade of the reservation booked by a job
+// Once a job books a slot that is not its most preferred slot, an upgrade job polls
+// for more preferred slots until the deadline, books one, and cancels the booked reservation
+// Reservations are only upgraded while they can be cancelled without a fee,
+// so upgrade jobs end at the cancellation cut off of reservations with a deposit
+type JobUpgrade struct {
+	Until    time.Time `json:"until"`
+	Interval int       `json:"interval"` // Seconds between polls
+}
+
+// Payment policy of a job
+// Slots requiring a deposit above the maximum deposit are not booked, a maximum
+// deposit of 0 never booking slots requiring a deposit, and any deposit is allowed
+// if it is not set
+// The payment method is required for booking if set, otherwise the default
+// payment method of the platform account is used
+type JobPaymentPolicy struct {
+	MaxDeposit      *float32 `json:"max_deposit,omitempty"`
+	PaymentMethodID *int     `json:"payment_method_id,omitempty"`
+}
+
+type JobStrategyName string
+
+const (
+	// Attempts each slot sequentially in preference order
+	JobStrategySequential JobStrategyName = "sequential"
+	// Attempts the slots concurrently, launching each slot after the stagger
+	JobStrategyStagger JobStrategyName = "stagger"
+	// Attempts all of the slots concurrently
+	JobStrategyParallel JobStrategyName = "parallel"
+	// Attempts the slots concurrently in batches of the race size in preference order
+	JobStrategyRace JobStrategyName = "race"
+	// Attempts all of the slots concurrently, upgrading the booked slot to more
+	// preferred slots booked afterwards and cancelling it if it has no deposit
+	JobStrategyUpgrade JobStrategyName = "upgrade"
+)
+
+// Booking strategy of a job
+// Stagger is only used by the stagger strategy and race size by the race strategy,
+// defaulting to a second and 3 slots respectively
+type JobStrategy struct {
+	Name     JobStrategyName `json:"name"`
+	Stagger  int             `json:"stagger,omitempty"` // Milliseconds
+	RaceSize int             `json:"race_size,omitempty"`
+}
+
+// Validates that the strategy is known and that its parameters are within bounds
+func (s JobStrategy) Validate() error {
+	switch s.Name {
+	case JobStrategySequential, JobStrategyParallel, JobStrategyRace, JobStrategyStagger, JobStrategyUpgrade:
+	default:
+		return fmt.Errorf("%w: unknown strategy %q", ErrInvalidStrategy, s.Name)
+	}
+	if s.Stagger < 0 || s.Stagger > MaxJobStrategyStagger {
+		return fmt.Errorf("%w: stagger must be between 0 and %d milliseconds", ErrInvalidStrategy, MaxJobStrategyStagger)
+	}
+	if s.RaceSize < 0 {
+		return fmt.Errorf("%w: race size cannot be negative", ErrInvalidStrategy)
+	}
+	return nil
+}
+
+// Alternative reservation date and party size of a job
+// Alternative dates of drop jobs must drop at the same time as the reservation date
+// of the job, which with a drop config releasing a fixed number of days in advance
+// restricts them to the reservation date of the job with a different party size
+type JobAlternative struct {
+	ReservationDate string `json:"reservation_date"` // YYYY-MM-DD
+	PartySize       int16  `json:"party_size"`
+}
+
+// Preferred time window of a reservation
+// Slots between the start and end of the window, extended on both sides
+// by the tolerance, match the window and are ranked by their distance
+// to the ideal time of the window
+type TimeWindow struct {
+	Start     string `json:"start"`               // HH:mm
+	End       string `json:"end,omitempty"`       // HH:mm, start if empty
+	Ideal     string `json:"ideal,omitempty"`     // HH:mm, start if empty
+	Tolerance int16  `json:"tolerance,omitempty"` // Minutes
+}
+
+// Parses a time window from its string representation
+// Format: HH:mm[-HH:mm][@HH:mm][~minutes]
+// e.g. 18:30-20:00, 19:00~15, or 18:30-20:00@19:00~15
+func ParseTimeWindow(value string) (TimeWindow, error) {
+	var window TimeWindow
+
+	value, tolerance, hasTolerance := strings.Cut(value, "~")
+	if hasTolerance {
+		minutes, err := strconv.ParseInt(tolerance, 10, 16)
+		if err != nil {
+			return window, fmt.Errorf("%w: invalid tolerance %q", ErrInvalidTimeWindow, tolerance)
+		}
+		window.Tolerance = int16(minutes)
+	}
+	value, window.Ideal, _ = strings.Cut(value, "@")
+	window.Start, window.End, _ = strings.Cut(value, "-")
+
+	return window, window.Validate()
+}
+
+// Validates that the times of the window are valid and ordered
+// and that the tolerance is within bounds
+func (w TimeWindow) Validate() error {
+	start, err := time.Parse("15:04", w.Start)
+	if err != nil {
+		return fmt.Errorf("%w: invalid start %q", ErrInvalidTimeWindow, w.Start)
+	}
+	end := start
+	if w.End != "" {
+		if end, err = time.Parse("15:04", w.End); err != nil {
+			return fmt.Errorf("%w: invalid end %q", ErrInvalidTimeWindow, w.End)
+		}
+		if end.Before(start) {
+			return fmt.Errorf("%w: end is before start", ErrInvalidTimeWindow)
+		}
+	}
+	if w.Ideal != "" {
+		ideal, err := time.Parse("15:04", w.Ideal)
+		if err != nil {
+			return fmt.Errorf("%w: invalid ideal time %q", ErrInvalidTimeWindow, w.Ideal)
+		}
+		if ideal.Before(start) || ideal.After(end) {
+			return fmt.Errorf("%w: ideal time is outside of the window", ErrInvalidTimeWindow)
+		}
+	}
+	if w.Tolerance < 0 || w.Tolerance > MaxTimeWindowTolerance {
+		return fmt.Errorf("%w: tolerance must be between 0 and %d minutes", ErrInvalidTimeWindow, MaxTimeWindowTolerance)
+	}
+	return nil
+}
+
+// Returns the string representation of the window
+// in the format accepted by ParseTimeWindow
+func (w TimeWindow) String() string {
+	value := w.Start
+	if w.End != "" && w.End != w.Start {
+		value += "-" + w.End
+	}
+	if w.Ideal != "" && w.Ideal != w.Start {
+		value += "@" + w.Ideal
+	}
+	if w.Tolerance > 0 {
+		value += "~" + strconv.Itoa(int(w.Tolerance))
+	}
+	return value
 }
 
 // Retrieve a given job
@@ -122,3 +370,22 @@ func (c *Client) CancelJob(jobId uuid.UUID) error {
 
 	return nil
 }
+
+// Performs a dry run of a job, checking the job end to end without booking
+// A reservation date can be specified to retrieve the slots of instead of
+// the reservation date of the job, otherwise it must be empty
+func (c *Client) TestJob(jobId uuid.UUID, reservationDate string) (JobTestResult, error) {
+	reqUrl := c.host + "/api/job/" + jobId.String() + "/test"
+	req, err := c.NewJsonRequest(http.MethodPost, reqUrl, JobTestRequest{ReservationDate: reservationDate})
+	if err != nil {
+		return JobTestResult{}, err
+	}
+
+	var result JobTestResult
+	err = c.Do(req, &result)
+	if err != nil {
+		return JobTestResult{}, err
+	}
+
+	return result, nil
+}
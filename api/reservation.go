@@ -8,6 +8,39 @@ import (
 	"github.com/google/uuid"
 )
 
+type ReservationStatus string
+
+const (
+	ReservationStatusBooked    ReservationStatus = "booked"
+	ReservationStatusCancelled ReservationStatus = "cancelled"
+)
+
+// Actions recorded in the audit of a reservation
+const (
+	// Reservation was booked by a job
+	ReservationAuditBooked = "booked"
+	// Upgrade job was scheduled to upgrade the reservation
+	ReservationAuditUpgradeScheduled = "upgrade_scheduled"
+	// Reservation could not be upgraded
+	ReservationAuditUpgradeSkipped = "upgrade_skipped"
+	// Upgrade job ended without booking a more preferred slot
+	ReservationAuditUpgradeEnded = "upgrade_ended"
+	// Reservation was cancelled after a more preferred slot was booked
+	ReservationAuditUpgraded = "upgraded"
+	// Reservation could not be cancelled after a more preferred slot was booked
+	ReservationAuditCancelFailed = "cancel_failed"
+)
+
+// Entry of the audit of a reservation
+// Related reservation is the other reservation of an upgrade
+type ReservationAuditEntry struct {
+	Time                 time.Time  `json:"time"`
+	Action               string     `json:"action"`
+	Message              string     `json:"message"`
+	JobID                *uuid.UUID `json:"job_id,omitempty"`
+	RelatedReservationID *uuid.UUID `json:"related_reservation_id,omitempty"`
+}
+
 type Reservation struct {
 	ID           uuid.UUID  `json:"id"`
 	UserID       uuid.UUID  `json:"user_id"`
@@ -19,6 +52,9 @@ type Reservation struct {
 	ReservationAt time.Time `json:"reservation_at"`
 	PartySize     int16     `json:"party_size"`
 
+	Status ReservationStatus       `json:"status"`
+	Audit  []ReservationAuditEntry `json:"audit,omitempty"`
+
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
@@ -8,17 +8,18 @@ import (
 )
 
 type Restaurant struct {
-	ID         uuid.UUID `json:"id"`
-	Platform   string    `json:"platform"`
-	PlatformID string    `json:"platform_id"`
-	Name       string    `json:"name"`
-	Address    *string   `json:"address,omitempty"`
-	City       *string   `json:"city,omitempty"`
-	State      *string   `json:"state,omitempty"`
-	Timezone   string    `json:"timezone,omitempty"`
-	Rating     *float32  `json:"rating,omitempty"`
-	CreatedAt  time.Time `json:"created_at"`
-	UpdatedAt  time.Time `json:"updated_at"`
+	ID           uuid.UUID `json:"id"`
+	Platform     string    `json:"platform"`
+	PlatformID   string    `json:"platform_id"`
+	Name         string    `json:"name"`
+	Address      *string   `json:"address,omitempty"`
+	City         *string   `json:"city,omitempty"`
+	State        *string   `json:"state,omitempty"`
+	Timezone     string    `json:"timezone,omitempty"`
+	Rating       *float32  `json:"rating,omitempty"`
+	SeatingTypes []string  `json:"seating_types,omitempty"` // Seating types seen in the slots of the restaurant
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
 }
 
 // Retrieves a restaurant by its ID
@@ -54,3 +55,22 @@ func (c *Client) GetRestaurantByPlatform(platform string, platformId string) (Re
 
 	return restaurant, nil
 }
+
+// Retrieves the known seating types of a restaurant
+// Seating types are discovered from the upcoming available slots of the restaurant
+// and are accumulated, so seating types that are not currently available are included
+func (c *Client) GetRestaurantSeatingTypes(id uuid.UUID) ([]string, error) {
+	reqUrl := c.host + "/api/restaurant/" + id.String() + "/seating-types"
+	req, err := http.NewRequest(http.MethodGet, reqUrl, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var seatingTypes []string
+	err = c.Do(req, &seatingTypes)
+	if err != nil {
+		return nil, err
+	}
+
+	return seatingTypes, nil
+}
@@ -8,7 +8,8 @@ require (
 	github.com/charmbracelet/huh v1.0.0
 	github.com/charmbracelet/lipgloss v1.1.0
 	github.com/daylamtayari/cierge/api v0.8.0
-	github.com/daylamtayari/cierge/resy v0.8.6
+	github.com/daylamtayari/cierge/reservation v0.0.0-00010101000000-000000000000
+	github.com/daylamtayari/cierge/resy v0.8.9
 	github.com/fatih/color v1.19.0
 	github.com/google/uuid v1.6.0
 	github.com/jedib0t/go-pretty/v6 v6.8.2
@@ -30,6 +31,8 @@ require (
 	github.com/charmbracelet/x/term v0.2.2 // indirect
 	github.com/clipperhouse/displaywidth v0.10.0 // indirect
 	github.com/clipperhouse/uax29/v2 v2.7.0 // indirect
+	github.com/daylamtayari/cierge/opentable v0.0.0-00010101000000-000000000000 // indirect
+	github.com/daylamtayari/cierge/tock v0.0.0-00010101000000-000000000000 // indirect
 	github.com/dustin/go-humanize v1.0.1 // indirect
 	github.com/erikgeiser/coninput v0.0.0-20211004153227-1c3628e74d0f // indirect
 	github.com/fsnotify/fsnotify v1.9.0 // indirect
@@ -59,3 +62,9 @@ require (
 	golang.org/x/sys v0.46.0 // indirect
 	gopkg.in/check.v1 v1.0.0-20201130134442-10cb98267c6c // indirect
 )
+
+replace (
+	github.com/daylamtayari/cierge/opentable => ../opentable
+	github.com/daylamtayari/cierge/reservation => ../reservation
+	github.com/daylamtayari/cierge/tock => ../tock
+)
@@ -1,9 +1,15 @@
 package main
 
 import (
+	"fmt"
+	"strings"
+	"time"
+
 	"github.com/daylamtayari/cierge/api"
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
+	"golang.org/x/text/cases"
+	"golang.org/x/text/language"
 )
 
 var jobCmd = &cobra.Command{
@@ -16,6 +22,7 @@ func initJobCmd() *cobra.Command {
 	jobCmd.AddCommand(initJobCreateCmd())
 	jobCmd.AddCommand(initJobGetCmd())
 	jobCmd.AddCommand(initJobListCmd())
+	jobCmd.AddCommand(initJobTestCmd())
 	return jobCmd
 }
 
@@ -26,6 +33,8 @@ func formatJobStatus(jobStatus api.JobStatus) string {
 		return "Created"
 	case api.JobStatusScheduled:
 		return color.BlueString("Scheduled")
+	case api.JobStatusRunning:
+		return color.CyanString("Running")
 	case api.JobStatusSuccess:
 		return color.GreenString("Succeeded")
 	case api.JobStatusFailed:
@@ -36,3 +45,51 @@ func formatJobStatus(jobStatus api.JobStatus) string {
 		return string(jobStatus)
 	}
 }
+
+// Returns a comma separated list of job alternatives
+func formatJobAlternatives(alternatives []api.JobAlternative) string {
+	formatted := make([]string, 0, len(alternatives))
+	for _, alternative := range alternatives {
+		date, _ := time.Parse("2006-01-02", alternative.ReservationDate)
+		formatted = append(formatted, fmt.Sprintf("%s for %d", date.Format("02 Jan"), alternative.PartySize))
+	}
+	return strings.Join(formatted, ", ")
+}
+
+// Returns a description of a job payment policy
+func formatJobPaymentPolicy(policy api.JobPaymentPolicy) string {
+	parts := make([]string, 0, 2)
+	switch {
+	case policy.MaxDeposit == nil:
+		parts = append(parts, "Any deposit")
+	case *policy.MaxDeposit <= 0:
+		parts = append(parts, "No deposit")
+	default:
+		parts = append(parts, fmt.Sprintf("Deposit up to %.2f", *policy.MaxDeposit))
+	}
+	if policy.PaymentMethodID != nil {
+		parts = append(parts, fmt.Sprintf("payment method %d", *policy.PaymentMethodID))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Returns a description of a job booking strategy
+func formatJobStrategy(strategy api.JobStrategy) string {
+	switch {
+	case strategy.Name == api.JobStrategyStagger && strategy.Stagger > 0:
+		return fmt.Sprintf("Stagger of %s", time.Duration(strategy.Stagger)*time.Millisecond)
+	case strategy.Name == api.JobStrategyRace && strategy.RaceSize > 0:
+		return fmt.Sprintf("Race of %d slots", strategy.RaceSize)
+	default:
+		return cases.Title(language.Und).String(string(strategy.Name))
+	}
+}
+
+// Returns a comma separated list of time windows
+func formatTimeWindows(windows []api.TimeWindow) string {
+	formatted := make([]string, 0, len(windows))
+	for _, window := range windows {
+		formatted = append(formatted, window.String())
+	}
+	return strings.Join(formatted, ", ")
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"strconv"
@@ -11,7 +12,7 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/huh"
 	"github.com/daylamtayari/cierge/api"
-	"github.com/daylamtayari/cierge/resy"
+	"github.com/daylamtayari/cierge/reservation"
 	"github.com/google/uuid"
 	"github.com/jedib0t/go-pretty/v6/table"
 	"github.com/spf13/cobra"
@@ -25,33 +26,37 @@ var (
 	jobPlatform             string
 	jobTimeSlotsInput       []string
 	jobTimeSlots            []string
+	jobTimeWindowsInput     []string
+	jobTimeWindows          []api.TimeWindow
+	jobAlternativesInput    []string
+	jobAlternatives         []api.JobAlternative
 	jobDropConfigId         string
+	jobWatchUntilInput      string
+	jobWatchInterval        time.Duration
+	jobAllowedSeatingTypes  []string
+	jobPreferredSeating     []string
+	jobNoDeposit            bool
+	jobMaxDeposit           float32
+	jobPaymentMethodId      int
+	jobStrategy             string
+	jobStrategyStagger      time.Duration
+	jobStrategyRaceSize     int
+	jobUpgradeUntilInput    string
+	jobUpgradeInterval      time.Duration
 
 	jobCreateCmd = &cobra.Command{
 		Use:   "create",
 		Short: "Create a new reservation job",
 		Run: func(cmd *cobra.Command, args []string) {
 			client := newClient()
-			resyClient := resy.NewClient(nil, resy.Tokens{ApiKey: resy.DefaultApiKey}, "")
 
 			// Platform selection
 			if cmd.Flags().Changed("platform") {
 				jobPlatform = strings.ToLower(jobPlatform)
-				if jobPlatform != "resy" && jobPlatform != "opentable" {
-					logger.Fatal().Msgf("Invalid platform %q specified - only 'resy' and 'opentable' are supported platforms", jobPlatform)
-				}
 			} else {
-				err := runHuh(huh.NewSelect[string]().
-					Title("Select reservation platform:").
-					Options(
-						huh.NewOption("Resy", "resy"),
-						huh.NewOption("OpenTable", "opentable"),
-					).
-					Value(&jobPlatform))
-				if err != nil {
-					logger.Fatal().Err(err).Msg("Failed to prompt user for reservation platform")
-				}
+				jobPlatform = promptPlatform()
 			}
+			platform := getPlatform(jobPlatform)
 			platformTokens, err := client.GetPlatformTokens(&jobPlatform)
 			if err != nil {
 				logger.Error().Err(err).Msg("Failed to retrieve platform tokens")
@@ -62,42 +67,24 @@ var (
 			// Restaurant selection
 			var restaurant *api.Restaurant
 			if cmd.Flags().Changed("restaurant") {
-				switch jobPlatform {
-				case "resy":
-					resyPlatformId, err := strconv.Atoi(restaurantPlatformId)
+				_, err = platform.GetVenue(context.Background(), restaurantPlatformId)
+				if err != nil && errors.Is(err, reservation.ErrVenueNotFound) {
+					logger.Error().Err(err).Msgf("Invalid %s restaurant ID", platform.DisplayName())
+				} else if err != nil {
+					logger.Error().Err(err).Msgf("Failed to fetch %s restaurant", platform.DisplayName())
+				} else {
+					res, err := client.GetRestaurantByPlatform(jobPlatform, restaurantPlatformId)
 					if err != nil {
-						logger.Error().Err(err).Msg("Resy restaurant platform ID must be numerical")
-					}
-
-					_, err = resyClient.GetVenue(resyPlatformId)
-					if err != nil && errors.Is(err, resy.ErrNotFound) {
-						logger.Error().Err(err).Msg("Invalid Resy restaurant ID")
-					} else if err != nil {
-						logger.Error().Err(err).Msg("Failed to fetch Resy restaurant")
+						logger.Error().Err(err).Msg("Failed to get restaurant")
 					} else {
-						res, err := client.GetRestaurantByPlatform(jobPlatform, restaurantPlatformId)
-						if err != nil {
-							logger.Error().Err(err).Msg("Failed to get restaurant")
-						} else {
-							restaurant = &res
-						}
+						restaurant = &res
 					}
-
-				case "opentable":
-					// TODO: Implement opentable
 				}
 			}
 			if restaurant == nil {
-				switch jobPlatform {
-				case "resy":
-					venueId, err := runResyVenueSearch(resyClient)
-					if err != nil {
-						logger.Fatal().Err(err).Msg("Failed to search for venue")
-					}
-					restaurantPlatformId = strconv.Itoa(venueId)
-				case "opentable":
-					// TODO: Implement opentable search
-					logger.Fatal().Msg("OpenTable search not yet implemented")
+				restaurantPlatformId, err = runVenueSearch(platform)
+				if err != nil {
+					logger.Fatal().Err(err).Msg("Failed to search for venue")
 				}
 
 				res, err := client.GetRestaurantByPlatform(jobPlatform, restaurantPlatformId)
@@ -173,6 +160,15 @@ var (
 				jobReservationDate = &parsedDateFormatted
 			}
 
+			// Alternatives default to the reservation date and party size of the job
+			for _, altInput := range jobAlternativesInput {
+				if alternative, err := parseJobAlternative(altInput, *jobReservationDate, jobPartySize); err == nil {
+					jobAlternatives = append(jobAlternatives, alternative)
+				} else {
+					logger.Error().Err(err).Msgf("Alternative %q is in invalid format", altInput)
+				}
+			}
+
 			// Timeslot selection
 			if len(jobTimeSlotsInput) > 0 {
 				for _, tsInput := range jobTimeSlotsInput {
@@ -183,7 +179,14 @@ var (
 					}
 				}
 			}
-			if len(jobTimeSlots) == 0 {
+			for _, twInput := range jobTimeWindowsInput {
+				if window, err := api.ParseTimeWindow(twInput); err == nil {
+					jobTimeWindows = append(jobTimeWindows, window)
+				} else {
+					logger.Error().Err(err).Msg("time window is in invalid format")
+				}
+			}
+			if len(jobTimeSlots) == 0 && len(jobTimeWindows) == 0 {
 				var err error
 				jobTimeSlots, err = runTimeSlotPicker()
 				if err != nil {
@@ -191,140 +194,232 @@ var (
 				}
 			}
 
-			// Drop config selection
-			var dropConfig *uuid.UUID
-			dropConfigs, err := client.GetDropConfigs(restaurant.ID)
-			if err != nil {
-				logger.Error().Err(err).Msg("Failed to retrieve drop configurations")
-			}
-			if cmd.Flags().Changed("drop-config") {
-				parsedId, err := uuid.Parse(jobDropConfigId)
+			// Seating type selection, offering the known seating types of the restaurant
+			if !cmd.Flags().Changed("seating") && !cmd.Flags().Changed("preferred-seating") {
+				seatingTypes, err := client.GetRestaurantSeatingTypes(restaurant.ID)
 				if err != nil {
-					logger.Error().Err(err).Msgf("Invalid drop config ID %q - must be a valid UUID", jobDropConfigId)
-				} else {
-					for _, dc := range dropConfigs {
-						if dc.ID == parsedId {
-							dropConfig = &parsedId
-							break
-						}
+					logger.Error().Err(err).Msg("Failed to get restaurant seating types")
+				} else if len(seatingTypes) > 0 {
+					options := make([]huh.Option[string], 0, len(seatingTypes))
+					for _, seatingType := range seatingTypes {
+						options = append(options, huh.NewOption(seatingType, seatingType))
 					}
-					if dropConfig == nil {
-						logger.Error().Msgf("Drop config ID %q was not found for the restaurant", jobDropConfigId)
+					err := runHuh(huh.NewMultiSelect[string]().
+						Title("Select allowed seating types (none for any):").Options(options...).Value(&jobAllowedSeatingTypes))
+					if err != nil {
+						logger.Fatal().Err(err).Msg("Failed to prompt user for seating types")
 					}
 				}
 			}
-			if len(dropConfigs) > 0 {
-				options := make([]huh.Option[string], 0, len(dropConfigs)+1)
-				tzAbbr := restaurant.Timezone
-				if loc, err := time.LoadLocation(restaurant.Timezone); err == nil {
-					tzAbbr = time.Now().In(loc).Format("MST")
+
+			// Payment policy
+			var jobPaymentPolicy *api.JobPaymentPolicy
+			if jobNoDeposit || cmd.Flags().Changed("max-deposit") || cmd.Flags().Changed("payment-method") {
+				jobPaymentPolicy = &api.JobPaymentPolicy{}
+				if jobNoDeposit {
+					noDeposit := float32(0)
+					jobPaymentPolicy.MaxDeposit = &noDeposit
+				} else if cmd.Flags().Changed("max-deposit") {
+					jobPaymentPolicy.MaxDeposit = &jobMaxDeposit
 				}
-				maxConf, maxDays := 0, 0
-				for _, dc := range dropConfigs {
-					if c := int(dc.Confidence); c > maxConf {
-						maxConf = c
-					}
-					if d := int(dc.DaysInAdvance); d > maxDays {
-						maxDays = d
-					}
+				if cmd.Flags().Changed("payment-method") {
+					jobPaymentPolicy.PaymentMethodID = &jobPaymentMethodId
+				}
+			}
+
+			// Booking strategy
+			var strategy *api.JobStrategy
+			if cmd.Flags().Changed("strategy") {
+				strategy = &api.JobStrategy{
+					Name:     api.JobStrategyName(jobStrategy),
+					Stagger:  int(jobStrategyStagger.Milliseconds()),
+					RaceSize: jobStrategyRaceSize,
 				}
-				confWidth := len(fmt.Sprintf("%d", maxConf))
-				daysWidth := len(fmt.Sprintf("%d", maxDays))
-				reservationDate, _ := time.Parse("2006-01-02", *jobReservationDate)
-				for _, dc := range dropConfigs {
-					dropDate := reservationDate.Add(-time.Duration(dc.DaysInAdvance) * 24 * time.Hour)
-					label := fmt.Sprintf("%*d %s  %*d days in advance (%s) at %s %s", confWidth, dc.Confidence, upArrow, daysWidth, dc.DaysInAdvance, dropDate.Format("02 Jan"), dc.DropTime, tzAbbr)
-					options = append(options, huh.NewOption(label, dc.ID.String()))
+				if err := strategy.Validate(); err != nil {
+					logger.Fatal().Err(err).Msg("Invalid booking strategy")
 				}
-				options = append(options, huh.NewOption("Create new drop configuration", "new"))
+			}
 
-				var selectedDropConfig string
-				err := runHuh(huh.NewSelect[string]().
-					Title("Select drop configuration:").
-					Options(options...).
-					Value(&selectedDropConfig))
+			// Watch configuration
+			var jobWatch *api.JobWatch
+			if cmd.Flags().Changed("watch-until") {
+				// Watch deadlines are in the timezone of the restaurant, like its reservation times
+				loc, err := time.LoadLocation(restaurant.Timezone)
+				if err != nil {
+					loc = time.UTC
+				}
+				watchUntil, err := time.ParseInLocation("02-01-2006 15:04", jobWatchUntilInput, loc)
 				if err != nil {
-					logger.Fatal().Err(err).Msg("Failed to prompt user for drop configuration")
+					logger.Fatal().Err(err).Msg("Failed to parse watch deadline - format: DD-MM-YYYY HH:mm")
 				}
-				if selectedDropConfig != "new" {
-					parsedId, _ := uuid.Parse(selectedDropConfig)
-					dropConfig = &parsedId
+				jobWatch = &api.JobWatch{
+					Until:    watchUntil,
+					Interval: int(jobWatchInterval.Seconds()),
 				}
 			}
-			if dropConfig == nil {
-				var daysInAdvanceInput, dropTimeInput string
-				for {
-					err := runHuh(huh.NewInput().
-						Title("Days in advance:").
-						Description("How many days before the reservation date should the drop be attempted?").
-						Value(&daysInAdvanceInput).
-						Validate(func(s string) error {
-							val, err := strconv.ParseInt(s, 10, 16)
-							if err != nil {
-								return errors.New("days in advance must be a valid number")
-							}
-							if val <= 0 {
-								return errors.New("days in advance must be greater than 0")
-							}
-							return nil
-						}))
-					if err != nil {
-						logger.Fatal().Err(err).Msg("Failed to prompt user for days in advance")
-					}
 
-					err = runHuh(huh.NewInput().
-						Title("Drop time (HH:mm):").
-						Placeholder("09:00").
-						Value(&dropTimeInput).
-						Validate(func(s string) error {
-							if _, err := time.Parse("15:04", s); err != nil {
-								return errors.New("invalid time format - use HH:mm")
-							}
-							return nil
-						}))
+			// Upgrade configuration
+			var jobUpgrade *api.JobUpgrade
+			if cmd.Flags().Changed("upgrade-until") {
+				upgradeUntil, err := time.ParseInLocation("02-01-2006 15:04", jobUpgradeUntilInput, time.Local)
+				if err != nil {
+					logger.Fatal().Err(err).Msg("Failed to parse upgrade deadline - format: DD-MM-YYYY HH:mm")
+				}
+				jobUpgrade = &api.JobUpgrade{
+					Until:    upgradeUntil,
+					Interval: int(jobUpgradeInterval.Seconds()),
+				}
+			}
+
+			// Drop config selection, only used by drop jobs
+			var dropConfig *uuid.UUID
+			if jobWatch == nil {
+				dropConfigs, err := client.GetDropConfigs(restaurant.ID)
+				if err != nil {
+					logger.Error().Err(err).Msg("Failed to retrieve drop configurations")
+				}
+				if cmd.Flags().Changed("drop-config") {
+					parsedId, err := uuid.Parse(jobDropConfigId)
 					if err != nil {
-						logger.Fatal().Err(err).Msg("Failed to prompt user for drop time")
+						logger.Error().Err(err).Msgf("Invalid drop config ID %q - must be a valid UUID", jobDropConfigId)
+					} else {
+						for _, dc := range dropConfigs {
+							if dc.ID == parsedId {
+								dropConfig = &parsedId
+								break
+							}
+						}
+						if dropConfig == nil {
+							logger.Error().Msgf("Drop config ID %q was not found for the restaurant", jobDropConfigId)
+						}
 					}
-
+				}
+				if len(dropConfigs) > 0 {
+					options := make([]huh.Option[string], 0, len(dropConfigs)+1)
+					tzAbbr := restaurant.Timezone
+					if loc, err := time.LoadLocation(restaurant.Timezone); err == nil {
+						tzAbbr = time.Now().In(loc).Format("MST")
+					}
+					maxConf, maxDays := 0, 0
+					for _, dc := range dropConfigs {
+						if c := int(dc.Confidence); c > maxConf {
+							maxConf = c
+						}
+						if d := int(dc.DaysInAdvance); d > maxDays {
+							maxDays = d
+						}
+					}
+					confWidth := len(fmt.Sprintf("%d", maxConf))
+					daysWidth := len(fmt.Sprintf("%d", maxDays))
 					reservationDate, _ := time.Parse("2006-01-02", *jobReservationDate)
-					daysInAdvance, _ := strconv.ParseInt(daysInAdvanceInput, 10, 16)
-					dropTimeParsed, _ := time.Parse("15:04", dropTimeInput)
-					dropDate := reservationDate.Add(-time.Duration(daysInAdvance) * 24 * time.Hour)
-					loc, err := time.LoadLocation(restaurant.Timezone)
-					if err != nil {
-						loc = time.UTC
+					for _, dc := range dropConfigs {
+						dropDate := reservationDate.Add(-time.Duration(dc.DaysInAdvance) * 24 * time.Hour)
+						label := fmt.Sprintf("%*d %s  %*d days in advance (%s) at %s %s", confWidth, dc.Confidence, upArrow, daysWidth, dc.DaysInAdvance, dropDate.Format("02 Jan"), dc.DropTime, tzAbbr)
+						options = append(options, huh.NewOption(label, dc.ID.String()))
 					}
-					expectedDrop := time.Date(dropDate.Year(), dropDate.Month(), dropDate.Day(), dropTimeParsed.Hour(), dropTimeParsed.Minute(), 0, 0, loc)
+					options = append(options, huh.NewOption("Create new drop configuration", "new"))
 
-					var confirmed bool
-					err = runHuh(huh.NewConfirm().
-						Title("Confirm drop configuration").
-						Description(fmt.Sprintf("%d days in advance at %s — expected drop: %s", daysInAdvance, dropTimeInput, expectedDrop.Format("02 Jan at 15:04 MST"))).
-						Value(&confirmed))
+					var selectedDropConfig string
+					err := runHuh(huh.NewSelect[string]().
+						Title("Select drop configuration:").
+						Options(options...).
+						Value(&selectedDropConfig))
 					if err != nil {
-						logger.Fatal().Err(err).Msg("Failed to confirm drop configuration")
+						logger.Fatal().Err(err).Msg("Failed to prompt user for drop configuration")
 					}
-					if confirmed {
-						break
+					if selectedDropConfig != "new" {
+						parsedId, _ := uuid.Parse(selectedDropConfig)
+						dropConfig = &parsedId
 					}
 				}
+				if dropConfig == nil {
+					var daysInAdvanceInput, dropTimeInput string
+					for {
+						err := runHuh(huh.NewInput().
+							Title("Days in advance:").
+							Description("How many days before the reservation date should the drop be attempted?").
+							Value(&daysInAdvanceInput).
+							Validate(func(s string) error {
+								val, err := strconv.ParseInt(s, 10, 16)
+								if err != nil {
+									return errors.New("days in advance must be a valid number")
+								}
+								if val <= 0 {
+									return errors.New("days in advance must be greater than 0")
+								}
+								return nil
+							}))
+						if err != nil {
+							logger.Fatal().Err(err).Msg("Failed to prompt user for days in advance")
+						}
 
-				daysInAdvance, _ := strconv.ParseInt(daysInAdvanceInput, 10, 16)
-				newDropConfig, err := client.CreateDropConfig(restaurant.ID, int16(daysInAdvance), dropTimeInput)
-				if err != nil {
-					logger.Fatal().Err(err).Msg("Failed to create drop configuration")
+						err = runHuh(huh.NewInput().
+							Title("Drop time (HH:mm):").
+							Placeholder("09:00").
+							Value(&dropTimeInput).
+							Validate(func(s string) error {
+								if _, err := time.Parse("15:04", s); err != nil {
+									return errors.New("invalid time format - use HH:mm")
+								}
+								return nil
+							}))
+						if err != nil {
+							logger.Fatal().Err(err).Msg("Failed to prompt user for drop time")
+						}
+
+						reservationDate, _ := time.Parse("2006-01-02", *jobReservationDate)
+						daysInAdvance, _ := strconv.ParseInt(daysInAdvanceInput, 10, 16)
+						dropTimeParsed, _ := time.Parse("15:04", dropTimeInput)
+						dropDate := reservationDate.Add(-time.Duration(daysInAdvance) * 24 * time.Hour)
+						loc, err := time.LoadLocation(restaurant.Timezone)
+						if err != nil {
+							loc = time.UTC
+						}
+						expectedDrop := time.Date(dropDate.Year(), dropDate.Month(), dropDate.Day(), dropTimeParsed.Hour(), dropTimeParsed.Minute(), 0, 0, loc)
+
+						var confirmed bool
+						err = runHuh(huh.NewConfirm().
+							Title("Confirm drop configuration").
+							Description(fmt.Sprintf("%d days in advance at %s — expected drop: %s", daysInAdvance, dropTimeInput, expectedDrop.Format("02 Jan at 15:04 MST"))).
+							Value(&confirmed))
+						if err != nil {
+							logger.Fatal().Err(err).Msg("Failed to confirm drop configuration")
+						}
+						if confirmed {
+							break
+						}
+					}
+
+					daysInAdvance, _ := strconv.ParseInt(daysInAdvanceInput, 10, 16)
+					newDropConfig, err := client.CreateDropConfig(restaurant.ID, int16(daysInAdvance), dropTimeInput)
+					if err != nil {
+						logger.Fatal().Err(err).Msg("Failed to create drop configuration")
+					}
+					dropConfig = &newDropConfig.ID
 				}
-				dropConfig = &newDropConfig.ID
 			}
 
 			// Job creation
-			job, err := client.CreateJob(api.JobCreationRequest{
-				RestaurantID:    restaurant.ID,
-				ReservationDate: *jobReservationDate,
-				PartySize:       jobPartySize,
-				PreferredTimes:  jobTimeSlots,
-				DropConfigID:    *dropConfig,
-			})
+			jobCreationReq := api.JobCreationRequest{
+				RestaurantID:          restaurant.ID,
+				ReservationDate:       *jobReservationDate,
+				PartySize:             jobPartySize,
+				PreferredTimes:        jobTimeSlots,
+				PreferredWindows:      jobTimeWindows,
+				Alternatives:          jobAlternatives,
+				AllowedSeatingTypes:   jobAllowedSeatingTypes,
+				PreferredSeatingTypes: jobPreferredSeating,
+				PaymentPolicy:         jobPaymentPolicy,
+				Strategy:              strategy,
+				Upgrade:               jobUpgrade,
+			}
+			if jobWatch != nil {
+				jobCreationReq.Type = api.JobTypeWatch
+				jobCreationReq.Watch = jobWatch
+			} else {
+				jobCreationReq.DropConfigID = *dropConfig
+			}
+			job, err := client.CreateJob(jobCreationReq)
 			if err != nil {
 				logger.Fatal().Err(err).Msg("Failed to create job")
 			}
@@ -343,6 +438,30 @@ var (
 				{"Party Size", job.PartySize},
 				{"Preferred Times", job.PreferredTimes},
 			})
+			if len(job.PreferredWindows) > 0 {
+				jt.AppendRow(table.Row{"Preferred Windows", formatTimeWindows(job.PreferredWindows)})
+			}
+			if job.Watch != nil {
+				jt.AppendRow(table.Row{"Watch", fmt.Sprintf("Until %s every %s", job.Watch.Until.Local().Format("02 Jan 2006 15:04"), time.Duration(job.Watch.Interval)*time.Second)})
+			}
+			if len(job.Alternatives) > 0 {
+				jt.AppendRow(table.Row{"Alternatives", formatJobAlternatives(job.Alternatives)})
+			}
+			if len(job.AllowedSeatingTypes) > 0 {
+				jt.AppendRow(table.Row{"Allowed Seating", strings.Join(job.AllowedSeatingTypes, ", ")})
+			}
+			if len(job.PreferredSeatingTypes) > 0 {
+				jt.AppendRow(table.Row{"Preferred Seating", strings.Join(job.PreferredSeatingTypes, ", ")})
+			}
+			if job.PaymentPolicy != nil {
+				jt.AppendRow(table.Row{"Payment Policy", formatJobPaymentPolicy(*job.PaymentPolicy)})
+			}
+			if job.Strategy != nil {
+				jt.AppendRow(table.Row{"Strategy", formatJobStrategy(*job.Strategy)})
+			}
+			if job.Upgrade != nil {
+				jt.AppendRow(table.Row{"Upgrade", fmt.Sprintf("Until %s every %s", job.Upgrade.Until.Local().Format("02 Jan 2006 15:04"), time.Duration(job.Upgrade.Interval)*time.Second)})
+			}
 			fmt.Print(jt.Render() + "\n")
 		},
 	}
@@ -354,17 +473,32 @@ func initJobCreateCmd() *cobra.Command {
 	jobCreateCmd.Flags().StringVar(&jobReservationDateInput, "date", "", "Date for the reservation - format: DD-MM-YYYY")
 	jobCreateCmd.Flags().StringVar(&restaurantPlatformId, "restaurant", "", "ID of the restaurant for the respective platform")
 	jobCreateCmd.Flags().StringSliceVar(&jobTimeSlotsInput, "slots", nil, "Time slots for the reservation - format: HH:mm")
+	jobCreateCmd.Flags().StringSliceVar(&jobTimeWindowsInput, "windows", nil, "Time windows for the reservation, matched after the time slots - format: HH:mm[-HH:mm][@ideal HH:mm][~tolerance minutes]")
+	jobCreateCmd.Flags().StringSliceVar(&jobAlternativesInput, "alternatives", nil, "Alternative dates and party sizes in order of preference, booked if none of the slots could be, with dates of drop jobs dropping at the same time as the reservation date - format: DD-MM-YYYY[:size] or size")
+	jobCreateCmd.Flags().StringSliceVar(&jobAllowedSeatingTypes, "seating", nil, "Seating types the reservation is restricted to, such as \"Dining Room\" or \"Bar\" - all seating types if not set")
+	jobCreateCmd.Flags().StringSliceVar(&jobPreferredSeating, "preferred-seating", nil, "Seating types to prefer in order of preference")
+	jobCreateCmd.Flags().BoolVar(&jobNoDeposit, "no-deposit", false, "Never book slots that require a deposit")
+	jobCreateCmd.Flags().Float32Var(&jobMaxDeposit, "max-deposit", 0, "Maximum deposit of the booked slot")
+	jobCreateCmd.Flags().IntVar(&jobPaymentMethodId, "payment-method", 0, "ID of the payment method to book with instead of the default payment method")
+	jobCreateCmd.MarkFlagsMutuallyExclusive("no-deposit", "max-deposit")
+	jobCreateCmd.Flags().StringVar(&jobStrategy, "strategy", "", "Booking strategy of the slots - sequential, stagger, parallel, race, or upgrade")
+	jobCreateCmd.Flags().DurationVar(&jobStrategyStagger, "stagger", 0, "Stagger between the slots of the stagger strategy (default 1s)")
+	jobCreateCmd.Flags().IntVar(&jobStrategyRaceSize, "race-size", 0, "Number of slots raced at once by the race strategy (default 3)")
+	jobCreateCmd.Flags().StringVar(&jobWatchUntilInput, "watch-until", "", "Create a watch job polling for slots, such as from cancellations, until the specified time in the timezone of the restaurant - format: DD-MM-YYYY HH:mm")
+	jobCreateCmd.Flags().DurationVar(&jobWatchInterval, "watch-interval", time.Minute, "Interval between polls of a watch job")
+	jobCreateCmd.Flags().StringVar(&jobUpgradeUntilInput, "upgrade-until", "", "Upgrade the booked reservation to more preferred slots, cancelling it if it has no cancellation fee, until the specified time - format: DD-MM-YYYY HH:mm")
+	jobCreateCmd.Flags().DurationVar(&jobUpgradeInterval, "upgrade-interval", time.Minute, "Interval between polls of the upgrade of a reservation")
 	jobCreateCmd.Flags().StringVar(&jobDropConfigId, "drop-config", "", "ID of the drop configuration to use")
 	return jobCreateCmd
 }
 
-// resySearchModel implements a real-time venue search with debouncing
-type resySearchModel struct {
-	client        *resy.Client
+// venueSearchModel implements a real-time venue search of a platform with debouncing
+type venueSearchModel struct {
+	platform      reservation.Platform
 	textInput     textinput.Model
-	venues        []resy.Venue
+	venues        []reservation.Venue
 	cursor        int
-	selectedVenue *resy.Venue
+	selectedVenue *reservation.Venue
 	lastQuery     string
 	searchPending bool // Waiting for debounce timer
 	searching     bool // Actively performing API call
@@ -373,7 +507,7 @@ type resySearchModel struct {
 }
 
 type searchResultMsg struct {
-	venues []resy.Venue
+	venues []reservation.Venue
 	err    error
 }
 
@@ -381,11 +515,11 @@ type triggerSearchMsg struct{}
 
 const searchDebounceMs = 300
 
-func (m resySearchModel) Init() tea.Cmd {
+func (m venueSearchModel) Init() tea.Cmd {
 	return textinput.Blink
 }
 
-func (m resySearchModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+func (m venueSearchModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
 
 	switch msg := msg.(type) {
@@ -430,7 +564,7 @@ func (m resySearchModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if currentQuery == "" {
 				// Clear results when query is empty
 				m.lastQuery = ""
-				m.venues = []resy.Venue{}
+				m.venues = []reservation.Venue{}
 				m.cursor = 0
 				m.err = nil
 			} else {
@@ -458,7 +592,7 @@ func (m resySearchModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, cmd
 }
 
-func (m resySearchModel) View() string {
+func (m venueSearchModel) View() string {
 	if m.quitting {
 		return ""
 	}
@@ -483,10 +617,10 @@ func (m resySearchModel) View() string {
 				cursor = ">"
 			}
 			if m.cursor == i {
-				line := fmt.Sprintf("%s %s - %s, %s", cursor, venue.Name, venue.Locality, venue.Region)
+				line := fmt.Sprintf("%s %s - %s, %s", cursor, venue.Name, venue.City, venue.Region)
 				b.WriteString(selectedStyle.Render(line))
 			} else {
-				fmt.Fprintf(&b, "%s %s - %s, %s", cursor, venue.Name, venue.Locality, venue.Region)
+				fmt.Fprintf(&b, "%s %s - %s, %s", cursor, venue.Name, venue.City, venue.Region)
 			}
 			b.WriteString("\n")
 		}
@@ -500,9 +634,9 @@ func (m resySearchModel) View() string {
 	return b.String()
 }
 
-func (m resySearchModel) performSearch(query string) tea.Cmd {
+func (m venueSearchModel) performSearch(query string) tea.Cmd {
 	return func() tea.Msg {
-		venues, err := m.client.SearchVenue(query, nil)
+		venues, err := m.platform.SearchVenues(context.Background(), query)
 		return searchResultMsg{
 			venues: venues,
 			err:    err,
@@ -510,33 +644,33 @@ func (m resySearchModel) performSearch(query string) tea.Cmd {
 	}
 }
 
-// runResyVenueSearch provides an interactive real-time search interface for Resy venues.
-// It returns the venue ID of the selected restaurant or an error if cancelled or failed.
-func runResyVenueSearch(client *resy.Client) (int, error) {
+// runVenueSearch provides an interactive real-time search interface for the venues of a platform.
+// It returns the platform ID of the selected restaurant or an error if cancelled or failed.
+func runVenueSearch(platform reservation.Platform) (string, error) {
 	ti := styledTextInput()
 	ti.Placeholder = "Type to search..."
 	ti.Focus()
 	ti.CharLimit = 100
 	ti.Width = 50
 
-	m := resySearchModel{
-		client:    client,
+	m := venueSearchModel{
+		platform:  platform,
 		textInput: ti,
-		venues:    []resy.Venue{},
+		venues:    []reservation.Venue{},
 	}
 
 	p := tea.NewProgram(m)
 	finalModel, err := p.Run()
 	if err != nil {
-		return 0, fmt.Errorf("error running search: %w", err)
+		return "", fmt.Errorf("error running search: %w", err)
 	}
 
-	result := finalModel.(resySearchModel)
+	result := finalModel.(venueSearchModel)
 	if result.selectedVenue == nil {
-		return 0, fmt.Errorf("no venue selected")
+		return "", fmt.Errorf("no venue selected")
 	}
 
-	return result.selectedVenue.Id.Resy, nil
+	return result.selectedVenue.Id, nil
 }
 
 const timeSlotViewHeight = 10
@@ -815,3 +949,35 @@ func runTimeSlotPicker() ([]string, error) {
 
 	return result.prioritySlots, nil
 }
+
+// Parses an alternative of a job in the format DD-MM-YYYY[:size] or size
+// The reservation date and party size of the job are used if not specified
+func parseJobAlternative(value string, reservationDate string, partySize int16) (api.JobAlternative, error) {
+	alternative := api.JobAlternative{
+		ReservationDate: reservationDate,
+		PartySize:       partySize,
+	}
+
+	dateInput, sizeInput, hasSize := strings.Cut(value, ":")
+	if !hasSize && !strings.Contains(dateInput, "-") {
+		dateInput, sizeInput, hasSize = "", dateInput, true
+	}
+	if dateInput != "" {
+		date, err := time.Parse("02-01-2006", dateInput)
+		if err != nil {
+			return alternative, err
+		}
+		alternative.ReservationDate = date.Format("2006-01-02")
+	}
+	if hasSize {
+		size, err := strconv.ParseInt(sizeInput, 10, 16)
+		if err != nil {
+			return alternative, err
+		}
+		if size <= 0 {
+			return alternative, errors.New("party size must be greater than 0")
+		}
+		alternative.PartySize = int16(size)
+	}
+	return alternative, nil
+}
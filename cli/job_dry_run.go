@@ -0,0 +1,110 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/huh"
+	"github.com/fatih/color"
+	"github.com/google/uuid"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+)
+
+var (
+	jobTestId        string
+	jobTestDateInput string
+
+	jobTestCmd = &cobra.Command{
+		Use:   "test",
+		Short: "Perform a dry run of a job without booking",
+		Long: "Perform a dry run of a job, decrypting its token, performing the pre-booking checks,\n" +
+			"and retrieving and matching the slots, stopping before booking and reporting\n" +
+			"the slot that would have been booked",
+		Run: func(cmd *cobra.Command, args []string) {
+			client := newClient()
+			var selectedJobId uuid.UUID
+
+			if cmd.Flags().Changed("job") {
+				uid, err := uuid.Parse(jobTestId)
+				if err != nil {
+					logger.Fatal().Err(err).Msgf("%q is not a valid UUID", jobTestId)
+				}
+				selectedJobId = uid
+			} else {
+				jobs, err := client.GetJobs(true)
+				if err != nil {
+					logger.Fatal().Err(err).Msg("Failed to retrieve jobs")
+				}
+				if len(jobs) == 0 {
+					logger.Fatal().Msg("No upcoming jobs to test")
+				}
+
+				options := make([]huh.Option[uuid.UUID], 0, len(jobs))
+				for _, job := range jobs {
+					date, _ := time.Parse("2006-01-02", job.ReservationDate)
+					label := fmt.Sprintf("On %s for %s, party of %d", job.ScheduledAt.Format("02 Jan"), date.Format("02 Jan"), job.PartySize)
+					options = append(options, huh.NewOption(label, job.ID))
+				}
+				err = runHuh(huh.NewSelect[uuid.UUID]().
+					Title("Select job to test:").Options(options...).Value(&selectedJobId))
+				if err != nil {
+					logger.Fatal().Err(err).Msg("Failed to prompt user for job")
+				}
+			}
+
+			// Slots of the reservation date of a job are usually not released
+			// before the drop so a different date can be tested
+			var reservationDate string
+			if cmd.Flags().Changed("date") {
+				date, err := time.Parse("02-01-2006", jobTestDateInput)
+				if err != nil {
+					logger.Fatal().Err(err).Msg("Failed to parse date - format: DD-MM-YYYY")
+				}
+				reservationDate = date.Format("2006-01-02")
+			}
+
+			logger.Info().Msg("Performing dry run of job, this may take a while")
+			result, err := client.TestJob(selectedJobId, reservationDate)
+			if err != nil {
+				logger.Fatal().Err(err).Msg("Failed to perform dry run of job")
+			}
+
+			jt := table.NewWriter()
+			jt.SetStyle(table.StyleLight)
+			jt.Style().Options.DrawBorder = false
+			jt.Style().Options.SeparateColumns = false
+			jt.SetColumnConfigs([]table.ColumnConfig{
+				{Number: 2, WidthMax: 80},
+			})
+			if result.Success {
+				jt.AppendRow(table.Row{"Result", color.GreenString("Slot found")})
+			} else {
+				jt.AppendRow(table.Row{"Result", color.RedString("Failed")})
+			}
+			jt.AppendRow(table.Row{"Message", result.Message})
+			if result.Error != "" {
+				jt.AppendRow(table.Row{"Error", result.Error})
+			}
+			if result.SlotTime != nil {
+				jt.AppendRow(table.Row{"Slot", result.SlotTime.Format("02 Jan 2006 15:04")})
+				jt.AppendRow(table.Row{"Party Size", result.PartySize})
+			}
+			if result.Deposit != nil {
+				jt.AppendRow(table.Row{"Deposit", fmt.Sprintf("%.2f", *result.Deposit)})
+			}
+			jt.AppendRows([]table.Row{
+				{"Slots Checked", result.Attempts},
+				{"Duration", result.Duration.Round(time.Millisecond)},
+			})
+
+			fmt.Print(jt.Render() + "\n")
+		},
+	}
+)
+
+func initJobTestCmd() *cobra.Command {
+	jobTestCmd.Flags().StringVar(&jobTestId, "job", "", "UUID of the job to test")
+	jobTestCmd.Flags().StringVar(&jobTestDateInput, "date", "", "Date to retrieve the slots of instead of the reservation date of the job, such as a date whose slots are already released - format: DD-MM-YYYY")
+	return jobTestCmd
+}
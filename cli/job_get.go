@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/charmbracelet/huh"
@@ -86,18 +87,66 @@ var (
 				{"Preferred Times", selectedJob.PreferredTimes},
 				{"Scheduled At", selectedJob.ScheduledAt.Local().Format("02 Jan 2006")},
 			})
+			if len(selectedJob.PreferredWindows) > 0 {
+				jt.AppendRow(table.Row{"Preferred Windows", formatTimeWindows(selectedJob.PreferredWindows)})
+			}
+			if selectedJob.Watch != nil {
+				jt.AppendRow(table.Row{"Watch", fmt.Sprintf("Until %s every %s", selectedJob.Watch.Until.Local().Format("02 Jan 2006 15:04"), time.Duration(selectedJob.Watch.Interval)*time.Second)})
+			}
+			if len(selectedJob.Alternatives) > 0 {
+				jt.AppendRow(table.Row{"Alternatives", formatJobAlternatives(selectedJob.Alternatives)})
+			}
+			if len(selectedJob.AllowedSeatingTypes) > 0 {
+				jt.AppendRow(table.Row{"Allowed Seating", strings.Join(selectedJob.AllowedSeatingTypes, ", ")})
+			}
+			if len(selectedJob.PreferredSeatingTypes) > 0 {
+				jt.AppendRow(table.Row{"Preferred Seating", strings.Join(selectedJob.PreferredSeatingTypes, ", ")})
+			}
+			if selectedJob.PaymentPolicy != nil {
+				jt.AppendRow(table.Row{"Payment Policy", formatJobPaymentPolicy(*selectedJob.PaymentPolicy)})
+			}
+			if selectedJob.Strategy != nil {
+				jt.AppendRow(table.Row{"Strategy", formatJobStrategy(*selectedJob.Strategy)})
+			}
+			if selectedJob.Upgrade != nil {
+				jt.AppendRow(table.Row{"Upgrade", fmt.Sprintf("Until %s every %s", selectedJob.Upgrade.Until.Local().Format("02 Jan 2006 15:04"), time.Duration(selectedJob.Upgrade.Interval)*time.Second)})
+			}
+			if selectedJob.UpgradeReservationID != nil {
+				jt.AppendRow(table.Row{"Upgrading", selectedJob.UpgradeReservationID.String()})
+			}
 
-			if selectedJob.Status == api.JobStatusSuccess || selectedJob.Status == api.JobStatusFailed {
-				jt.AppendRows([]table.Row{
-					{"Started At", selectedJob.StartedAt.Local().Format("2006-01-02 15:04:05")},
-					{"Completed At", selectedJob.CompletedAt.Local().Format("2006-01-02 15:04:05")},
-				})
+			// Running jobs only have a start time and jobs that never
+			// reported their outcome only have a completion time
+			if selectedJob.StartedAt != nil {
+				jt.AppendRow(table.Row{"Started At", selectedJob.StartedAt.Local().Format("2006-01-02 15:04:05")})
+			}
+			if selectedJob.CompletedAt != nil {
+				jt.AppendRow(table.Row{"Completed At", selectedJob.CompletedAt.Local().Format("2006-01-02 15:04:05")})
+			}
+			if selectedJob.Status == api.JobStatusFailed && selectedJob.ErrorMessage != nil && *selectedJob.ErrorMessage != "" {
+				jt.AppendRow(table.Row{"Error", *selectedJob.ErrorMessage})
 			}
 			if selectedJob.Status == api.JobStatusSuccess {
 				jt.AppendRows([]table.Row{
 					{"Reserved Time", selectedJob.ReservedTime.Format("02 Jan 2006 15:04:05")},
 					{"Confirmation", *selectedJob.Confirmation},
 				})
+				// Shown if an alternative was booked
+				if selectedJob.ReservedDate != nil && *selectedJob.ReservedDate != selectedJob.ReservationDate {
+					jt.AppendRow(table.Row{"Reserved Date", *selectedJob.ReservedDate})
+				}
+				if selectedJob.ReservedPartySize != nil && *selectedJob.ReservedPartySize != selectedJob.PartySize {
+					jt.AppendRow(table.Row{"Reserved Party Size", *selectedJob.ReservedPartySize})
+				}
+				if selectedJob.ReservedDeposit != nil {
+					jt.AppendRow(table.Row{"Deposit", fmt.Sprintf("%.2f", *selectedJob.ReservedDeposit)})
+				}
+				if selectedJob.ReservedCancellationCutOff != nil {
+					jt.AppendRow(table.Row{"Cancellation Cut Off", selectedJob.ReservedCancellationCutOff.Local().Format("02 Jan 2006 15:04")})
+				}
+				if selectedJob.ReservedSeatingType != nil {
+					jt.AppendRow(table.Row{"Seating", *selectedJob.ReservedSeatingType})
+				}
 			}
 			if selectedJob.Logs != nil {
 				jt.AppendRow(table.Row{"Logs", *selectedJob.Logs})
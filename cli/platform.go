@@ -0,0 +1,45 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/huh"
+	"github.com/daylamtayari/cierge/reservation"
+)
+
+// Returns the adapter of a platform specified by the user
+// and exits if the platform is not supported
+func getPlatform(name string) reservation.Platform {
+	platform, err := reservation.GetPlatform(name)
+	if err != nil {
+		logger.Fatal().Msgf("Invalid platform %q specified - supported platforms are: %s", name, strings.Join(reservation.AvailablePlatforms(), ", "))
+	}
+	return platform
+}
+
+// Prompts the user to select one of the supported platforms and returns its name
+func promptPlatform() string {
+	var name string
+	options := make([]huh.Option[string], 0)
+	for _, platformName := range reservation.AvailablePlatforms() {
+		options = append(options, huh.NewOption(getPlatform(platformName).DisplayName(), platformName))
+	}
+
+	err := runHuh(huh.NewSelect[string]().
+		Title("Select reservation platform:").
+		Options(options...).
+		Value(&name))
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Failed to prompt user for reservation platform")
+	}
+	return name
+}
+
+// Returns the display name of a platform, or the platform name if it is not supported
+func platformDisplayName(name string) string {
+	platform, err := reservation.GetPlatform(name)
+	if err != nil {
+		return name
+	}
+	return platform.DisplayName()
+}
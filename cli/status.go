@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/daylamtayari/cierge/api"
+	"github.com/daylamtayari/cierge/reservation"
 	"github.com/fatih/color"
 	"github.com/jedib0t/go-pretty/v6/table"
 	"github.com/spf13/cobra"
@@ -49,36 +50,31 @@ var statusCmd = &cobra.Command{
 		}
 		st.AppendRow(table.Row{"User", userStatus})
 
-		resyStatus := "Unknown"
-		openTableStatus := "Unknown"
+		platforms := reservation.AvailablePlatforms()
+		platformStatuses := make(map[string]string)
+		for _, platform := range platforms {
+			platformStatuses[platform] = "Unknown"
+		}
 		if user != nil {
 			platformTokens, err := client.GetPlatformTokens(nil)
 			if err != nil {
 				logger.Error().Err(err).Msg("Failed to fetch platform tokens")
 			} else {
-				resyStatus = color.RedString(crossmark + " Not connected")
-				openTableStatus = color.RedString(crossmark + " Not connected")
+				for _, platform := range platforms {
+					platformStatuses[platform] = color.RedString(crossmark + " Not connected")
+				}
 				for _, token := range platformTokens {
-					var tokenStatus string
 					if token.ExpiresAt != nil && time.Now().After(*token.ExpiresAt) {
-						tokenStatus = color.YellowString(warnsign + " Token is expired")
+						platformStatuses[token.Platform] = color.YellowString(warnsign + " Token is expired")
 					} else {
-						tokenStatus = color.GreenString(checkmark + " Connected")
-					}
-
-					switch token.Platform {
-					case "resy":
-						resyStatus = tokenStatus
-					case "opentable":
-						openTableStatus = tokenStatus
+						platformStatuses[token.Platform] = color.GreenString(checkmark + " Connected")
 					}
 				}
 			}
 		}
-		st.AppendRows([]table.Row{
-			{"Resy", resyStatus},
-			{"OpenTable", openTableStatus},
-		})
+		for _, platform := range platforms {
+			st.AppendRow(table.Row{platformDisplayName(platform), platformStatuses[platform]})
+		}
 
 		versionStatus := getVersion()
 		if latestTag, updateAvailable := checkForUpdate(); updateAvailable {
@@ -1,11 +1,12 @@
 package main
 
 import (
+	"context"
 	"errors"
 	"strings"
 
 	"github.com/charmbracelet/huh"
-	"github.com/daylamtayari/cierge/resy"
+	"github.com/daylamtayari/cierge/reservation"
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 )
@@ -16,69 +17,58 @@ var (
 		Short: "Connect a reservation platform",
 		Run: func(cmd *cobra.Command, args []string) {
 			client := newClient()
+			ctx := context.Background()
 
 			if cmd.Flags().Changed("platform") {
 				platform = strings.ToLower(platform)
-				if platform != "resy" && platform != "opentable" {
-					logger.Fatal().Msgf("Invalid platform %q specified - only 'resy' and 'opentable' are supported platforms", platform)
-				}
 			} else {
-				err := runHuh(huh.NewSelect[string]().
-					Title("Select reservation platform:").
-					Options(
-						huh.NewOption("Resy", "resy"),
-						huh.NewOption("OpenTable", "opentable"),
-					).
-					Value(&platform))
-				if err != nil {
-					logger.Fatal().Err(err).Msg("Failed to prompt user for reservation platform")
-				}
+				platform = promptPlatform()
 			}
+			adapter := getPlatform(platform)
 
-			var token any
-			switch platform {
-			case "resy":
-				var username string
-				var password string
-
-				err := runHuh(huh.NewInput().Title("Enter your email:").
-					Description(color.YellowString(warnsign + " By connecting your credentials, you assume trust in the server owner")).
-					Value(&username))
-				if err != nil {
-					logger.Fatal().Err(err).Msg("Failed to prompt user for username")
-				}
+			credentials := reservation.Credentials{}
+			err := runHuh(huh.NewInput().Title("Enter your email:").
+				Description(color.YellowString(warnsign + " By connecting your credentials, you assume trust in the server owner")).
+				Value(&credentials.Email))
+			if err != nil {
+				logger.Fatal().Err(err).Msg("Failed to prompt user for email")
+			}
 
+			switch adapter.LoginMethod() {
+			case reservation.LoginMethodPassword:
 				err = runHuh(huh.NewInput().Title("Enter your password:").
 					Description(color.YellowString(warnsign + " By connecting your credentials, you assume trust in the server owner")).
-					EchoMode(huh.EchoModePassword).Value(&password))
+					EchoMode(huh.EchoModePassword).Value(&credentials.Password))
 				if err != nil {
 					logger.Fatal().Err(err).Msg("Failed to prompt user for password")
 				}
 
-				resyClient := resy.NewClient(nil, resy.Tokens{ApiKey: resy.DefaultApiKey}, "")
-				resyToken, err := resyClient.Login(username, password)
-				if err != nil && errors.Is(err, resy.ErrUnauthorized) {
-					logger.Fatal().Msg("Invalid Resy credentials provided")
-				} else if err != nil {
-					logger.Fatal().Err(err).Msg("Failed to login")
+			case reservation.LoginMethodCode:
+				if err := adapter.SendLoginCode(ctx, credentials.Email); err != nil {
+					logger.Fatal().Err(err).Msgf("Failed to send %s login code", adapter.DisplayName())
 				}
 
-				authResyClient := resy.NewClient(nil, resyToken, "")
-				user, err := authResyClient.GetUser()
+				err = runHuh(huh.NewInput().Title("Enter the login code sent to your email:").Value(&credentials.Code))
 				if err != nil {
-					logger.Error().Err(err).Msg("Failed to get current Resy user profile")
-				}
-				if len(user.PaymentMethods) == 0 {
-					logger.Warn().Msg("No payment methods are saved on Resy - Reservations that require deposits will fail\nTo prevet, go to your Resy account and save a payment method")
+					logger.Fatal().Err(err).Msg("Failed to prompt user for login code")
 				}
+			}
 
-				token = resyToken
+			token, err := adapter.Login(ctx, credentials)
+			if err != nil && errors.Is(err, reservation.ErrInvalidCredentials) {
+				logger.Fatal().Msgf("Invalid %s credentials provided", adapter.DisplayName())
+			} else if err != nil {
+				logger.Fatal().Err(err).Msg("Failed to login")
+			}
 
-			case "opentable":
-				// TODO: Complete opentable implementation
+			hasPaymentMethod, err := adapter.HasPaymentMethod(ctx, token)
+			if err != nil {
+				logger.Error().Err(err).Msgf("Failed to get current %s user profile", adapter.DisplayName())
+			} else if !hasPaymentMethod {
+				logger.Warn().Msgf("No payment methods are saved on %[1]s - Reservations that require deposits will fail\nTo prevent, go to your %[1]s account and save a payment method", adapter.DisplayName())
 			}
 
-			_, err := client.CreatePlatformToken(platform, token)
+			_, err = client.CreatePlatformToken(platform, token)
 			if err != nil {
 				logger.Fatal().Err(err).Msg("Failed to create platform token")
 			}
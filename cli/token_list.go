@@ -6,8 +6,6 @@ import (
 
 	"github.com/jedib0t/go-pretty/v6/table"
 	"github.com/spf13/cobra"
-	"golang.org/x/text/cases"
-	"golang.org/x/text/language"
 )
 
 var (
@@ -19,9 +17,7 @@ var (
 
 			if cmd.Flags().Changed("platform") {
 				platform = strings.ToLower(platform)
-				if platform != "resy" && platform != "opentable" {
-					logger.Fatal().Msg("Invalid platform %q specified - only 'resy' and 'opentable' are valid platforms")
-				}
+				getPlatform(platform)
 			}
 
 			tokens, err := client.GetPlatformTokens(&platform)
@@ -36,7 +32,7 @@ var (
 			for _, token := range tokens {
 				tt.AppendRow(table.Row{
 					token.ID,
-					cases.Title(language.Und).String(token.Platform),
+					platformDisplayName(token.Platform),
 					token.HasRefresh,
 					token.ExpiresAt.Local().Format("2006-01-02 15:04:05"),
 					token.RefreshExpiresAt.Local().Format("2006-01-02 15:04:05"),
@@ -0,0 +1,15 @@
+module github.com/daylamtayari/cierge/cloudrun
+
+go 1.25.5
+
+require (
+	github.com/daylamtayari/cierge/reservation v0.8.6
+	golang.org/x/oauth2 v0.27.0
+)
+
+require (
+	cloud.google.com/go/compute/metadata v0.3.0 // indirect
+	github.com/daylamtayari/cierge/resy v0.8.9 // indirect
+	github.com/golang-jwt/jwt/v5 v5.3.1 // indirect
+	github.com/google/uuid v1.6.0 // indirect
+)
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+
+	"golang.org/x/oauth2/google"
+
+	"github.com/daylamtayari/cierge/reservation"
+	"github.com/daylamtayari/cierge/reservation/gcpkms"
+)
+
+const cloudPlatformScope = "https://www.googleapis.com/auth/cloud-platform"
+
+var decrypter *gcpkms.Client
+
+func init() {
+	keyName := os.Getenv("CIERGE_KMS_KEY_NAME")
+	if keyName == "" {
+		panic("CIERGE_KMS_KEY_NAME is required")
+	}
+
+	client, err := google.DefaultClient(context.Background(), cloudPlatformScope)
+	if err != nil {
+		panic("failed to load google credentials: " + err.Error())
+	}
+
+	decrypter = gcpkms.NewClient(client, "", keyName)
+}
+
+func main() {
+	port := os.Getenv("PORT")
+	if port == "" {
+		port = "8080"
+	}
+
+	http.HandleFunc("POST /", handle)
+	if err := http.ListenAndServe(":"+port, nil); err != nil {
+		panic("failed to start server: " + err.Error())
+	}
+}
+
+// Runs the reservation handler for the event in the request body
+// A successful status is always returned once the event has been
+// handled as Cloud Tasks retries tasks that return an error status
+func handle(w http.ResponseWriter, r *http.Request) {
+	var event reservation.Event
+	if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+		http.Error(w, "invalid event: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	output := reservation.Handle(r.Context(), event, decrypter)
+
+	marshalledOutput, _ := json.Marshal(output)
+	fmt.Println(string(marshalledOutput))
+
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(marshalledOutput)
+}
@@ -236,6 +236,23 @@ resource "aws_iam_user_policy" "cierge_server" {
         # Scoped to only cierge-job-* schedules in the cierge group
         Resource = "arn:aws:scheduler:${var.aws_region}:${data.aws_caller_identity.current.account_id}:schedule/${var.schedule_group_name}/cierge-job-*"
       },
+      {
+        Sid    = "EventBridgeSchedulerList"
+        Effect = "Allow"
+        Action = "scheduler:ListSchedules"
+        # ListSchedules does not support resource-level permissions, used to reconcile scheduled jobs
+        Resource = "*"
+      },
+      {
+        Sid    = "CloudWatchLogsRead"
+        Effect = "Allow"
+        Action = "logs:FilterLogEvents"
+        # Scoped to only the Lambda log group, used to retrieve the output of jobs without a callback
+        Resource = [
+          aws_cloudwatch_log_group.lambda.arn,
+          "${aws_cloudwatch_log_group.lambda.arn}:*"
+        ]
+      },
       {
         Sid    = "PassSchedulerRole"
         Effect = "Allow"
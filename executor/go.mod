@@ -0,0 +1,11 @@
+module github.com/daylamtayari/cierge/executor
+
+go 1.25.5
+
+require github.com/daylamtayari/cierge/reservation v0.8.6
+
+require (
+	github.com/daylamtayari/cierge/resy v0.8.9 // indirect
+	github.com/golang-jwt/jwt/v5 v5.3.1 // indirect
+	github.com/google/uuid v1.6.0 // indirect
+)
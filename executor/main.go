@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/daylamtayari/cierge/reservation"
+	"github.com/daylamtayari/cierge/reservation/envelope"
+)
+
+// Environment variables containing the keys used to decrypt the event
+const (
+	masterKeyEnv   = "CIERGE_EXECUTOR_MASTER_KEY"
+	masterKeyIDEnv = "CIERGE_EXECUTOR_MASTER_KEY_ID"
+	keyFileEnv     = "CIERGE_EXECUTOR_KEY_FILE"
+
+	// Path of a file containing the event, read instead of stdin if set
+	eventFileEnv = "CIERGE_EXECUTOR_EVENT_FILE"
+
+	// Directory in which outputs that could not be delivered to the server are stored
+	outboxDirEnv = "CIERGE_EXECUTOR_OUTBOX_DIR"
+
+	// NTP server against which the clock offset is measured before the drop
+	ntpServerEnv = "CIERGE_EXECUTOR_NTP_SERVER"
+)
+
+// Standalone executor of a reservation job
+// Reads a reservation event from stdin or the event file, runs the reservation handler,
+// and writes the output of the job to stdout as a single JSON line
+// Exits with a non-zero status code if the handler could not be ran
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	var event reservation.Event
+	eventReader := os.Stdin
+	if eventFile := os.Getenv(eventFileEnv); eventFile != "" {
+		file, err := os.Open(eventFile)
+		if err != nil {
+			fail(event, "failed to open event file", err)
+		}
+		defer file.Close() //nolint:errcheck
+		eventReader = file
+	}
+	if err := json.NewDecoder(eventReader).Decode(&event); err != nil {
+		fail(event, "failed to decode event", err)
+	}
+
+	keyring, err := envelope.LoadKeyring(os.Getenv(masterKeyEnv), os.Getenv(masterKeyIDEnv), os.Getenv(keyFileEnv))
+	if err != nil {
+		fail(event, "failed to load keys", err)
+	}
+
+	var opts []reservation.Option
+	if outboxDir := os.Getenv(outboxDirEnv); outboxDir != "" {
+		outbox, err := reservation.NewFileOutbox(outboxDir)
+		if err != nil {
+			fail(event, "failed to create outbox", err)
+		}
+		opts = append(opts, reservation.WithOutbox(outbox))
+	}
+	if ntpServer := os.Getenv(ntpServerEnv); ntpServer != "" {
+		opts = append(opts, reservation.WithNTPServer(ntpServer))
+	}
+
+	output := reservation.Handle(ctx, event, keyring, opts...)
+
+	marshalledOutput, _ := json.Marshal(output)
+	fmt.Println(string(marshalledOutput))
+}
+
+// Writes a failed output to stdout and exits
+func fail(event reservation.Event, message string, err error) {
+	output := reservation.Output{
+		JobId:     event.JobID,
+		Success:   false,
+		Message:   message,
+		Error:     err.Error(),
+		Level:     "error",
+		StartTime: time.Now().UTC(),
+	}
+
+	marshalledOutput, _ := json.Marshal(output)
+	fmt.Println(string(marshalledOutput))
+	os.Exit(1)
+}
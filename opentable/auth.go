@@ -0,0 +1,107 @@
+package opentable
+
+import (
+	"errors"
+	"net/http"
+	"time"
+)
+
+var (
+	ErrNoAuthToken = errors.New("no auth token was included in the response")
+)
+
+type Tokens struct {
+	// Bearer token of the user used to authenticate requests
+	Token string
+	// Refresh token that can be used to get an updated bearer token
+	Refresh string
+	// Expiration time of the bearer token
+	Expiry time.Time
+}
+
+// Sends a one-time login code to the email of a user that is
+// then used to login, returning an error that is nil if successful
+// NOTE: OpenTable accounts have no password, users login with a code
+func (c *Client) SendLoginCode(email string) error {
+	reqUrl := Host + "/api/v1/auth/otp"
+
+	req, err := c.NewJsonRequest(http.MethodPost, reqUrl, map[string]string{
+		"email": email,
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.Do(req, nil)
+}
+
+// Performs authentication using the login code sent to the email of a user,
+// returning the user's tokens and an error that is nil if successful
+// Returns an ErrUnauthorized if the code is incorrect or expired
+func (c *Client) Login(email string, code string) (Tokens, error) {
+	reqUrl := Host + "/api/v1/auth/otp/verify"
+
+	req, err := c.NewJsonRequest(http.MethodPost, reqUrl, map[string]string{
+		"email": email,
+		"code":  code,
+	})
+	if err != nil {
+		return Tokens{}, err
+	}
+
+	return c.makeAuthRequest(req)
+}
+
+// Uses a provided refresh token to retrieve a new bearer token
+// If no new refresh token is returned, the provided one remains valid and is kept
+// Returns an error that is nil if successful
+func (c *Client) RefreshToken(refreshToken string) (Tokens, error) {
+	reqUrl := Host + "/api/v1/auth/token"
+
+	req, err := c.NewJsonRequest(http.MethodPost, reqUrl, map[string]string{
+		"grant_type":    "refresh_token",
+		"refresh_token": refreshToken,
+	})
+	if err != nil {
+		return Tokens{}, err
+	}
+
+	tokens, err := c.makeAuthRequest(req)
+	if err != nil {
+		return Tokens{}, err
+	}
+	if tokens.Refresh == "" {
+		tokens.Refresh = refreshToken
+	}
+	return tokens, nil
+}
+
+// Handles an authentication request and retrieves the bearer and refresh tokens
+func (c *Client) makeAuthRequest(req *http.Request) (Tokens, error) {
+	type authResponse struct {
+		AccessToken  string `json:"access_token"`
+		RefreshToken string `json:"refresh_token"`
+		// Number of seconds until the access token expires
+		ExpiresIn int `json:"expires_in"`
+	}
+	var authRes authResponse
+
+	requestTime := time.Now().UTC()
+	err := c.Do(req, &authRes)
+	if err != nil {
+		return Tokens{}, err
+	}
+
+	if authRes.AccessToken == "" {
+		return Tokens{}, ErrNoAuthToken
+	}
+
+	tokens := Tokens{
+		Token:   authRes.AccessToken,
+		Refresh: authRes.RefreshToken,
+	}
+	if authRes.ExpiresIn > 0 {
+		tokens.Expiry = requestTime.Add(time.Duration(authRes.ExpiresIn) * time.Second)
+	}
+	return tokens, nil
+}
@@ -0,0 +1,82 @@
+package opentable
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// Login code sent to the email of the user of the fake OpenTable API
+const testLoginCode = "123456"
+
+// Fake OpenTable authentication API issuing the test token for the login code
+// Refreshing with the refresh token returns a new token without a new refresh token
+func authHandler(sentCodes *[]string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		switch r.URL.Path {
+		case "/api/v1/auth/otp":
+			*sentCodes = append(*sentCodes, req["email"])
+			w.WriteHeader(http.StatusNoContent)
+		case "/api/v1/auth/otp/verify":
+			if req["code"] != testLoginCode {
+				http.Error(w, `{"message":"invalid code"}`, http.StatusUnauthorized)
+				return
+			}
+			w.Write([]byte(`{"access_token":"` + testToken + `","refresh_token":"refresh","expires_in":3600}`)) //nolint:errcheck
+		case "/api/v1/auth/token":
+			if req["grant_type"] != "refresh_token" || req["refresh_token"] != "refresh" {
+				http.Error(w, `{"message":"invalid refresh token"}`, http.StatusUnauthorized)
+				return
+			}
+			w.Write([]byte(`{"access_token":"renewed","expires_in":3600}`)) //nolint:errcheck
+		default:
+			http.NotFound(w, r)
+		}
+	})
+}
+
+func TestLogin(t *testing.T) {
+	var sentCodes []string
+	client := newFakeClient(t, "", authHandler(&sentCodes))
+
+	requireNoError(t, client.SendLoginCode("test@example.com"), "SendLoginCode failed")
+	if len(sentCodes) != 1 || sentCodes[0] != "test@example.com" {
+		t.Errorf("login codes sent to %v, want test@example.com", sentCodes)
+	}
+
+	tokens, err := client.Login("test@example.com", testLoginCode)
+	requireNoError(t, err, "Login failed")
+	if tokens.Token != testToken || tokens.Refresh != "refresh" {
+		t.Errorf("unexpected tokens %+v", tokens)
+	}
+	if expiresIn := time.Until(tokens.Expiry); expiresIn <= 59*time.Minute || expiresIn > time.Hour {
+		t.Errorf("token expires in %s, want an hour", expiresIn)
+	}
+
+	if _, err := client.Login("test@example.com", "000000"); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("incorrect code: got %v, want %v", err, ErrUnauthorized)
+	}
+}
+
+func TestRefreshToken(t *testing.T) {
+	var sentCodes []string
+	client := newFakeClient(t, "", authHandler(&sentCodes))
+
+	tokens, err := client.RefreshToken("refresh")
+	requireNoError(t, err, "RefreshToken failed")
+	if tokens.Token != "renewed" || tokens.Refresh != "refresh" || tokens.Expiry.IsZero() {
+		t.Errorf("got %+v, want the renewed token keeping the refresh token", tokens)
+	}
+
+	if _, err := client.RefreshToken("revoked"); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("invalid refresh token: got %v, want %v", err, ErrUnauthorized)
+	}
+}
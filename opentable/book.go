@@ -0,0 +1,149 @@
+package opentable
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Represents the deposit of a locked slot
+// The amount is in the major unit of the currency and the
+// cancellation cut off is the RFC 3339 time until which the
+// reservation can be cancelled without forfeiting the deposit
+type Deposit struct {
+	Amount             float32 `json:"amount"`
+	CurrencyCode       string  `json:"currencyCode"`
+	CancellationCutOff string  `json:"cancellationCutoffDate"`
+}
+
+// Represents the lock of a slot, which holds the slot for the user
+// until it is booked or the lock expires
+// A credit card is required to book the slot if CreditCardRequired
+// is true, and the deposit is nil if the slot has no deposit
+// NOTE: The slot fields are those of the locked slot and are not returned by the API
+type SlotLock struct {
+	Id                 int      `json:"id"`
+	CreditCardRequired bool     `json:"creditCardRequired"`
+	Deposit            *Deposit `json:"deposit"`
+
+	DateTime          time.Time `json:"-"`
+	PartySize         int       `json:"-"`
+	SlotHash          string    `json:"-"`
+	AvailabilityToken string    `json:"-"`
+}
+
+// Represents a booked reservation
+// The confirmation number and security token are required to cancel the reservation
+type BookingConfirmation struct {
+	ReservationId      int    `json:"id"`
+	ConfirmationNumber int    `json:"confirmationNumber"`
+	SecurityToken      string `json:"securityToken"`
+	RestaurantId       int    `json:"restaurantId"`
+	DateTime           string `json:"dateTime"`
+	PartySize          int    `json:"partySize"`
+}
+
+type lockRequest struct {
+	PartySize         int    `json:"partySize"`
+	DateTime          string `json:"dateTime"`
+	SlotHash          string `json:"hash"`
+	AvailabilityToken string `json:"slotAvailabilityToken"`
+}
+
+type bookingRequest struct {
+	LockId            int     `json:"slotLockId"`
+	PartySize         int     `json:"partySize"`
+	DateTime          string  `json:"dateTime"`
+	SlotHash          string  `json:"hash"`
+	AvailabilityToken string  `json:"slotAvailabilityToken"`
+	CreditCardId      *string `json:"creditCardId,omitempty"`
+}
+
+// Locks a slot of a restaurant at a given time for a party size
+// The slot time is the time of the slot obtained from Availability.SlotTime
+// If the slot is no longer available, an ErrConflict is returned
+func (c *Client) LockSlot(restaurantId int, slotTime time.Time, partySize int, slot Slot) (*SlotLock, error) {
+	reqUrl := Host + "/api/v1/reservation/" + strconv.Itoa(restaurantId) + "/lock"
+
+	req, err := c.NewJsonRequest(http.MethodPost, reqUrl, lockRequest{
+		PartySize:         partySize,
+		DateTime:          slotTime.Format(DateTimeFormat),
+		SlotHash:          slot.SlotHash,
+		AvailabilityToken: slot.AvailabilityToken,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var lock SlotLock
+	err = c.Do(req, &lock)
+	if err != nil {
+		return nil, err
+	}
+
+	lock.DateTime = slotTime
+	lock.PartySize = partySize
+	lock.SlotHash = slot.SlotHash
+	lock.AvailabilityToken = slot.AvailabilityToken
+	return &lock, nil
+}
+
+// Books the locked slot of a restaurant
+// The ID of one of the user's credit cards should be passed if the lock
+// requires a credit card, otherwise a 402 Payment Required will be returned
+// If the lock expired or the slot was taken, an ErrConflict is returned
+func (c *Client) BookReservation(restaurantId int, lock *SlotLock, creditCardId *string) (*BookingConfirmation, error) {
+	reqUrl := Host + "/api/v1/reservation/" + strconv.Itoa(restaurantId)
+
+	req, err := c.NewJsonRequest(http.MethodPost, reqUrl, bookingRequest{
+		LockId:            lock.Id,
+		PartySize:         lock.PartySize,
+		DateTime:          lock.DateTime.Format(DateTimeFormat),
+		SlotHash:          lock.SlotHash,
+		AvailabilityToken: lock.AvailabilityToken,
+		CreditCardId:      creditCardId,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var confirmation BookingConfirmation
+	err = c.Do(req, &confirmation)
+	if err != nil {
+		return nil, err
+	}
+
+	return &confirmation, nil
+}
+
+// Releases the lock of a slot of a restaurant without booking it, making the slot available again
+// Unreleased locks expire, so releasing a lock is not required but prevents
+// the slot from being held until the lock expires
+// If the lock expired or was already booked or released, an ErrNotFound is returned
+func (c *Client) ReleaseLock(restaurantId int, lock *SlotLock) error {
+	reqUrl := Host + "/api/v1/reservation/" + strconv.Itoa(restaurantId) + "/lock/" + strconv.Itoa(lock.Id)
+
+	req, err := http.NewRequest(http.MethodDelete, reqUrl, nil)
+	if err != nil {
+		return err
+	}
+
+	return c.Do(req, nil)
+}
+
+// Cancels a reservation of a restaurant from its confirmation
+// number and security token and returns an error that is nil if successful
+// If the reservation does not exist or was already cancelled, an ErrNotFound is returned
+func (c *Client) CancelBooking(restaurantId int, confirmationNumber int, securityToken string) error {
+	reqUrl := Host + "/api/v3/reservation/" + strconv.Itoa(restaurantId) + "/" + strconv.Itoa(confirmationNumber) + "?" + url.Values{
+		"securityToken": []string{securityToken},
+	}.Encode()
+
+	req, err := http.NewRequest(http.MethodDelete, reqUrl, nil)
+	if err != nil {
+		return err
+	}
+
+	return c.Do(req, nil)
+}
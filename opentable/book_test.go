@@ -0,0 +1,214 @@
+package opentable
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Fake OpenTable reservation API holding slot locks and booked reservations
+// Each slot can only be locked once and a slot with a deposit requires a credit card
+type fakeReservations struct {
+	mu       sync.Mutex
+	locks    map[int]lockRequest
+	locked   map[string]bool
+	booked   map[int]bookingRequest
+	deposits map[string]bool
+}
+
+func newFakeReservations() *fakeReservations {
+	return &fakeReservations{
+		locks:    map[int]lockRequest{},
+		locked:   map[string]bool{},
+		booked:   map[int]bookingRequest{},
+		deposits: map[string]bool{},
+	}
+}
+
+func (f *fakeReservations) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !requireAuth(w, r) {
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == "/api/v1/reservation/1234/lock":
+		var req lockRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if f.locked[req.SlotHash] {
+			http.Error(w, `{"message":"slot no longer available"}`, http.StatusConflict)
+			return
+		}
+		f.locked[req.SlotHash] = true
+		lockId := len(f.locks) + 1
+		f.locks[lockId] = req
+
+		lock := SlotLock{Id: lockId}
+		if f.deposits[req.SlotHash] {
+			lock.CreditCardRequired = true
+			lock.Deposit = &Deposit{Amount: 25, CurrencyCode: "USD", CancellationCutOff: "2026-11-19T19:00:00Z"}
+		}
+		json.NewEncoder(w).Encode(lock) //nolint:errcheck
+
+	case r.Method == http.MethodPost && r.URL.Path == "/api/v1/reservation/1234":
+		var req bookingRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		lock, ok := f.locks[req.LockId]
+		if !ok || lock.SlotHash != req.SlotHash {
+			http.Error(w, `{"message":"lock expired"}`, http.StatusConflict)
+			return
+		}
+		if f.deposits[req.SlotHash] && req.CreditCardId == nil {
+			http.Error(w, `{"message":"credit card required"}`, http.StatusPaymentRequired)
+			return
+		}
+		delete(f.locks, req.LockId)
+		confirmationNumber := 1000 + len(f.booked)
+		f.booked[confirmationNumber] = req
+		json.NewEncoder(w).Encode(BookingConfirmation{ //nolint:errcheck
+			ReservationId:      confirmationNumber * 10,
+			ConfirmationNumber: confirmationNumber,
+			SecurityToken:      "security-" + strconv.Itoa(confirmationNumber),
+			RestaurantId:       testRestaurantId,
+			DateTime:           req.DateTime,
+			PartySize:          req.PartySize,
+		})
+
+	case r.Method == http.MethodDelete && strings.HasPrefix(r.URL.Path, "/api/v1/reservation/1234/lock/"):
+		lockId, _ := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/api/v1/reservation/1234/lock/"))
+		lock, ok := f.locks[lockId]
+		if !ok {
+			http.Error(w, `{"message":"lock not found"}`, http.StatusNotFound)
+			return
+		}
+		delete(f.locks, lockId)
+		delete(f.locked, lock.SlotHash)
+		w.WriteHeader(http.StatusNoContent)
+
+	case r.Method == http.MethodDelete:
+		confirmationNumber, err := cancelledConfirmationNumber(r.URL.Path)
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		if _, ok := f.booked[confirmationNumber]; !ok || r.URL.Query().Get("securityToken") != "security-"+strconv.Itoa(confirmationNumber) {
+			http.Error(w, `{"message":"reservation not found"}`, http.StatusNotFound)
+			return
+		}
+		delete(f.booked, confirmationNumber)
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// Parses the confirmation number of the path of a reservation cancellation
+func cancelledConfirmationNumber(path string) (int, error) {
+	confirmationNumber, ok := strings.CutPrefix(path, "/api/v3/reservation/1234/")
+	if !ok {
+		return 0, errors.New("unexpected path")
+	}
+	return strconv.Atoi(confirmationNumber)
+}
+
+func TestBooking_LockBookCancel(t *testing.T) {
+	fake := newFakeReservations()
+	client := newFakeClient(t, testToken, fake)
+	slotTime := time.Date(2026, 11, 20, 19, 45, 0, 0, time.UTC)
+	slot := Slot{IsAvailable: true, SlotHash: "hash-1945", AvailabilityToken: "token-1945"}
+
+	lock, err := client.LockSlot(testRestaurantId, slotTime, 2, slot)
+	requireNoError(t, err, "LockSlot failed")
+	if lock.CreditCardRequired || lock.Deposit != nil {
+		t.Errorf("got lock %+v, want no credit card or deposit", lock)
+	}
+
+	// A locked slot cannot be locked again
+	if _, err := client.LockSlot(testRestaurantId, slotTime, 2, slot); !errors.Is(err, ErrConflict) {
+		t.Errorf("second lock: got %v, want %v", err, ErrConflict)
+	}
+
+	confirmation, err := client.BookReservation(testRestaurantId, lock, nil)
+	requireNoError(t, err, "BookReservation failed")
+	if confirmation.DateTime != "2026-11-20T19:45" || confirmation.PartySize != 2 || confirmation.SecurityToken == "" {
+		t.Errorf("unexpected confirmation %+v", confirmation)
+	}
+	if booked := fake.booked[confirmation.ConfirmationNumber]; booked.AvailabilityToken != "token-1945" {
+		t.Errorf("booked with availability token %q, want token-1945", booked.AvailabilityToken)
+	}
+
+	// The lock is consumed by the booking
+	if _, err := client.BookReservation(testRestaurantId, lock, nil); !errors.Is(err, ErrConflict) {
+		t.Errorf("second booking: got %v, want %v", err, ErrConflict)
+	}
+
+	if err := client.CancelBooking(testRestaurantId, confirmation.ConfirmationNumber, "invalid"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("cancel with invalid security token: got %v, want %v", err, ErrNotFound)
+	}
+	requireNoError(t, client.CancelBooking(testRestaurantId, confirmation.ConfirmationNumber, confirmation.SecurityToken), "CancelBooking failed")
+	if len(fake.booked) != 0 {
+		t.Errorf("reservation was not cancelled")
+	}
+}
+
+func TestBooking_CreditCardRequired(t *testing.T) {
+	fake := newFakeReservations()
+	fake.deposits["hash-deposit"] = true
+	client := newFakeClient(t, testToken, fake)
+	slot := Slot{IsAvailable: true, SlotHash: "hash-deposit", AvailabilityToken: "token-deposit"}
+
+	lock, err := client.LockSlot(testRestaurantId, time.Date(2026, 11, 20, 20, 0, 0, 0, time.UTC), 4, slot)
+	requireNoError(t, err, "LockSlot failed")
+	if !lock.CreditCardRequired || lock.Deposit == nil || lock.Deposit.Amount != 25 {
+		t.Fatalf("got lock %+v, want a deposit of 25", lock)
+	}
+
+	if _, err := client.BookReservation(testRestaurantId, lock, nil); !errors.Is(err, ErrPaymentRequired) {
+		t.Errorf("booking without credit card: got %v, want %v", err, ErrPaymentRequired)
+	}
+	creditCardId := "card-1"
+	_, err = client.BookReservation(testRestaurantId, lock, &creditCardId)
+	requireNoError(t, err, "BookReservation with credit card failed")
+}
+
+func TestBooking_ReleaseLock(t *testing.T) {
+	fake := newFakeReservations()
+	client := newFakeClient(t, testToken, fake)
+	slotTime := time.Date(2026, 11, 20, 19, 45, 0, 0, time.UTC)
+	slot := Slot{IsAvailable: true, SlotHash: "hash-1945", AvailabilityToken: "token-1945"}
+
+	lock, err := client.LockSlot(testRestaurantId, slotTime, 2, slot)
+	requireNoError(t, err, "LockSlot failed")
+	requireNoError(t, client.ReleaseLock(testRestaurantId, lock), "ReleaseLock failed")
+
+	// A released lock cannot be booked or released again and its slot can be locked again
+	if _, err := client.BookReservation(testRestaurantId, lock, nil); !errors.Is(err, ErrConflict) {
+		t.Errorf("booking released lock: got %v, want %v", err, ErrConflict)
+	}
+	if err := client.ReleaseLock(testRestaurantId, lock); !errors.Is(err, ErrNotFound) {
+		t.Errorf("second release: got %v, want %v", err, ErrNotFound)
+	}
+	_, err = client.LockSlot(testRestaurantId, slotTime, 2, slot)
+	requireNoError(t, err, "LockSlot of released slot failed")
+}
+
+func TestBooking_Unauthorized(t *testing.T) {
+	client := newFakeClient(t, "", newFakeReservations())
+	_, err := client.LockSlot(testRestaurantId, time.Now(), 2, Slot{SlotHash: "hash"})
+	if !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("got %v, want %v", err, ErrUnauthorized)
+	}
+}
@@ -0,0 +1,178 @@
+package opentable
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// OpenTable mobile API host
+const Host = "https://mobile-api.opentable.com"
+
+// Generic popular user agent to use as default
+// if not specified by a user
+const defaultUserAgent = "Mozilla/5.0 (iPhone; CPU iPhone OS 18_2 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Mobile/15E148"
+
+var (
+	ErrBadRequest      = errors.New("bad or malformed request")
+	ErrConflict        = errors.New("conflict - slot is no longer available")
+	ErrForbidden       = errors.New("forbidden")
+	ErrNotFound        = errors.New("not found")
+	ErrPaymentRequired = errors.New("payment required")
+	ErrTooManyRequests = errors.New("too many requests")
+	ErrUnauthorized    = errors.New("unauthorized")
+	ErrUnhandledStatus = errors.New("unhandled status code returned")
+)
+
+type Client struct {
+	client *http.Client
+}
+
+type transport struct {
+	base    http.RoundTripper
+	headers map[string]string
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// Add headers to the request
+	for key, value := range t.headers {
+		req.Header.Set(key, value)
+	}
+
+	return t.base.RoundTrip(req)
+}
+
+// Creates a new OpenTable API client. It accepts an `http.Client` value
+// that will be used as the base HTTP client and will have the
+// authentication added to. If nil is provided, a new `http.Client` is used.
+// The token is the bearer token of the user.
+// A user agent value to be added to requests is also accepted and if
+// an empty string is provided, a popular generic user agent is used.
+// NOTE: A client without a token can only be used for requests that
+// do not require authentication, such as searching restaurants
+func NewClient(httpClient *http.Client, token string, userAgent string) *Client {
+	trans := http.DefaultTransport
+	if httpClient == nil {
+		httpClient = &http.Client{}
+	} else if t := httpClient.Transport; t != nil {
+		trans = httpClient.Transport
+	}
+
+	if userAgent == "" {
+		userAgent = defaultUserAgent
+	}
+
+	headers := map[string]string{
+		"Accept":     "application/json",
+		"User-Agent": userAgent,
+	}
+
+	if token != "" {
+		headers["Authorization"] = "Bearer " + token
+	}
+
+	httpClient.Transport = &transport{
+		base:    trans,
+		headers: headers,
+	}
+
+	return &Client{
+		client: httpClient,
+	}
+}
+
+// Creates a new http request for a JSON payload
+// Marshals the provided jsonValue value, creates
+// a new request, and sets the content type to JSON
+// Error is only returned if the specified value
+// fails to be marshalled or the new request fails to be created
+func (c *Client) NewJsonRequest(method string, url string, jsonValue any) (*http.Request, error) {
+	reqBody, err := json.Marshal(jsonValue)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(method, url, bytes.NewBuffer(reqBody))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	return req, nil
+}
+
+// Performs an API request, handles the response,
+// and unmarshals the response into a given interface.
+// The value to unmarshal must be a pointer to an interface.
+// If a pointer to a byte array is provided, the returned value
+// will be the value of the body.
+// Returns an error that is nil if successful
+func (c *Client) Do(req *http.Request, v any) error {
+	res, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close() //nolint: errcheck
+
+	var body []byte
+	if res.ContentLength != 0 {
+		// Handle gzip-compressed responses
+		reader := res.Body
+		if res.Header.Get("Content-Encoding") == "gzip" {
+			gzReader, err := gzip.NewReader(res.Body)
+			if err != nil {
+				return err
+			}
+			defer gzReader.Close() //nolint: errcheck
+			reader = gzReader
+		}
+
+		body, err = io.ReadAll(reader)
+		if err != nil {
+			return err
+		}
+
+		// Only attempt to unmarshall in the provided type
+		// if the status code is successful
+		if res.StatusCode < 300 && len(body) > 0 {
+			if _, ok := v.(*[]byte); ok {
+				// If a byte array is provided, the body value
+				// is returned directly and not unmarshalled
+				*v.(*[]byte) = body
+			} else if v != nil {
+				err = json.Unmarshal(body, &v)
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	switch res.StatusCode {
+	case 200, 201, 204:
+		return nil
+	case 400:
+		return fmt.Errorf("%w: %v", ErrBadRequest, string(body))
+	case 401:
+		return fmt.Errorf("%w: %v", ErrUnauthorized, string(body))
+	case 402:
+		return fmt.Errorf("%w: %v", ErrPaymentRequired, string(body))
+	case 403:
+		// OpenTable returns a 403 when the request was blocked by its bot protection
+		return fmt.Errorf("%w: %v", ErrForbidden, string(body))
+	case 404:
+		return fmt.Errorf("%w: %v", ErrNotFound, string(body))
+	case 409:
+		// Locking or booking a slot that was taken returns a 409
+		return fmt.Errorf("%w: %v", ErrConflict, string(body))
+	case 429:
+		return fmt.Errorf("%w: %v", ErrTooManyRequests, string(body))
+	default:
+		return fmt.Errorf("%w: %d: %v", ErrUnhandledStatus, res.StatusCode, string(body))
+	}
+}
@@ -0,0 +1,69 @@
+package opentable
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestClient_Headers(t *testing.T) {
+	var userAgent, authorization string
+	client := newFakeClient(t, testToken, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgent = r.Header.Get("User-Agent")
+		authorization = r.Header.Get("Authorization")
+		w.Write([]byte(`{"restaurantId":1234}`)) //nolint:errcheck
+	}))
+
+	_, err := client.GetRestaurant(testRestaurantId)
+	requireNoError(t, err, "GetRestaurant failed")
+
+	if userAgent != defaultUserAgent {
+		t.Errorf("user agent: got %q, want the default user agent", userAgent)
+	}
+	if authorization != "Bearer "+testToken {
+		t.Errorf("authorization: got %q, want bearer token", authorization)
+	}
+}
+
+func TestClient_UnauthenticatedHeaders(t *testing.T) {
+	var authorization string
+	client := newFakeClient(t, "", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		w.Write([]byte(`{"autocompleteResults":[]}`)) //nolint:errcheck
+	}))
+
+	_, err := client.Search("test", 0, 0, false)
+	requireNoError(t, err, "Search failed")
+	if authorization != "" {
+		t.Errorf("authorization: got %q, want none without a token", authorization)
+	}
+}
+
+func TestClient_StatusErrors(t *testing.T) {
+	tests := []struct {
+		status int
+		want   error
+	}{
+		{status: http.StatusBadRequest, want: ErrBadRequest},
+		{status: http.StatusUnauthorized, want: ErrUnauthorized},
+		{status: http.StatusPaymentRequired, want: ErrPaymentRequired},
+		{status: http.StatusForbidden, want: ErrForbidden},
+		{status: http.StatusNotFound, want: ErrNotFound},
+		{status: http.StatusConflict, want: ErrConflict},
+		{status: http.StatusTooManyRequests, want: ErrTooManyRequests},
+		{status: http.StatusInternalServerError, want: ErrUnhandledStatus},
+	}
+
+	for _, test := range tests {
+		t.Run(http.StatusText(test.status), func(t *testing.T) {
+			client := newFakeClient(t, testToken, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				http.Error(w, `{"message":"error"}`, test.status)
+			}))
+
+			_, err := client.GetRestaurant(testRestaurantId)
+			if !errors.Is(err, test.want) {
+				t.Errorf("got %v, want %v", err, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package opentable
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+// Test restaurant ID of the fake OpenTable API
+const testRestaurantId = 1234
+
+// Token of the user of the fake OpenTable API
+const testToken = "test-token"
+
+// Transport sending the requests to the OpenTable API to a test server
+type rewriteTransport struct {
+	target *url.URL
+}
+
+func (t rewriteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.URL.Scheme = t.target.Scheme
+	req.URL.Host = t.target.Host
+	req.Host = t.target.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// newFakeClient starts a test server with the provided handler and returns
+// a client authenticated with the token whose requests are sent to it
+func newFakeClient(t *testing.T, token string, handler http.Handler) *Client {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	target, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatalf("invalid test server URL: %v", err)
+	}
+	return NewClient(&http.Client{Transport: rewriteTransport{target: target}}, token, "")
+}
+
+// requireAuth responds with a 401 and returns false if the request
+// is not authenticated with the test token
+func requireAuth(w http.ResponseWriter, r *http.Request) bool {
+	if r.Header.Get("Authorization") != "Bearer "+testToken {
+		http.Error(w, `{"message":"unauthorized"}`, http.StatusUnauthorized)
+		return false
+	}
+	return true
+}
+
+// requireNoError fails the test if err is not nil
+func requireNoError(t *testing.T, err error, msg string) {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("%s: %v", msg, err)
+	}
+}
@@ -0,0 +1,6 @@
+// Go library for the OpenTable API
+//
+// The library uses the API of the OpenTable mobile application, which
+// authenticates users with a bearer token. Only the fields used to search
+// restaurants, retrieve their availability, and book reservations are handled.
+package opentable
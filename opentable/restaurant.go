@@ -1,5 +1,12 @@
 package opentable
 
+import (
+	"net/http"
+	"strconv"
+)
+
+// Represents a restaurant
+// NOTE: Not all fields are populated by every endpoint
 type Restaurant struct {
 	Id             int                  `json:"restaurantId"`
 	Name           string               `json:"name"`
@@ -23,8 +30,10 @@ type Restaurant struct {
 		PostCode string  `json:"postCode"`
 		Country  *string `json:"country"`
 	} `json:"address"`
-	Website *string `json:"website"`
-	Urls    struct {
+	// IANA name of the timezone of the restaurant
+	TimeZone string  `json:"timeZone"`
+	Website  *string `json:"website"`
+	Urls     struct {
 		ProfileLink PageLink `json:"profileLink"`
 	} `json:"urls"`
 	ContactInformation struct {
@@ -82,3 +91,21 @@ type Award struct {
 	Year   string  `json:"year"`
 	Rating *string `json:"rating"`
 }
+
+// Retrieves the details of a restaurant from its ID
+func (c *Client) GetRestaurant(restaurantId int) (*Restaurant, error) {
+	reqUrl := Host + "/api/v3/restaurant/" + strconv.Itoa(restaurantId)
+
+	req, err := http.NewRequest(http.MethodGet, reqUrl, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var restaurant Restaurant
+	err = c.Do(req, &restaurant)
+	if err != nil {
+		return nil, err
+	}
+
+	return &restaurant, nil
+}
@@ -0,0 +1,38 @@
+package opentable
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestGetRestaurant(t *testing.T) {
+	client := newFakeClient(t, testToken, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v3/restaurant/1234" {
+			http.Error(w, `{"message":"restaurant not found"}`, http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{
+			"restaurantId":1234,
+			"name":"Test Bistro",
+			"diningStyle":"Casual Elegant",
+			"maxAdvanceDays":30,
+			"address":{"line1":"1 Main St","city":"New York","state":"NY","postCode":"10001"},
+			"timeZone":"America/New_York",
+			"priceBand":{"priceBandId":3,"currencySymbol":"$","name":"$31 to $50"}
+		}`)) //nolint:errcheck
+	}))
+
+	restaurant, err := client.GetRestaurant(testRestaurantId)
+	requireNoError(t, err, "GetRestaurant failed")
+	if restaurant.Id != testRestaurantId || restaurant.Name != "Test Bistro" || restaurant.Address.City != "New York" || restaurant.TimeZone != "America/New_York" {
+		t.Errorf("unexpected restaurant %+v", restaurant)
+	}
+	if restaurant.MaxAdvanceDays == nil || *restaurant.MaxAdvanceDays != 30 {
+		t.Errorf("max advance days: got %v, want 30", restaurant.MaxAdvanceDays)
+	}
+
+	if _, err := client.GetRestaurant(1); !errors.Is(err, ErrNotFound) {
+		t.Errorf("unknown restaurant: got %v, want %v", err, ErrNotFound)
+	}
+}
@@ -1,5 +1,19 @@
 package opentable
 
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// Result types of a search
+const (
+	SearchResultRestaurant = "Restaurant"
+	SearchResultLocation   = "Location"
+)
+
+// Represents a search result, which is either a restaurant,
+// whose ID is the restaurant ID, or a location
 type SearchResult struct {
 	Id               int     `json:"id"`
 	Type             string  `json:"type"`
@@ -16,3 +30,39 @@ type SearchResult struct {
 	Latitude         float64 `json:"latitude"`
 	Longitude        float64 `json:"longitude"`
 }
+
+type searchResponse struct {
+	Results []SearchResult `json:"autocompleteResults"`
+}
+
+// Searches for restaurants and locations matching a term near given coordinates
+// Only the results that are restaurants are returned if restaurantsOnly is true
+func (c *Client) Search(term string, latitude float64, longitude float64, restaurantsOnly bool) ([]SearchResult, error) {
+	reqUrl := Host + "/api/v3/autocomplete?" + url.Values{
+		"term":      []string{term},
+		"latitude":  []string{strconv.FormatFloat(latitude, 'f', -1, 64)},
+		"longitude": []string{strconv.FormatFloat(longitude, 'f', -1, 64)},
+	}.Encode()
+
+	req, err := http.NewRequest(http.MethodGet, reqUrl, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var res searchResponse
+	err = c.Do(req, &res)
+	if err != nil {
+		return nil, err
+	}
+
+	if !restaurantsOnly {
+		return res.Results, nil
+	}
+	restaurants := make([]SearchResult, 0, len(res.Results))
+	for _, result := range res.Results {
+		if result.Type == SearchResultRestaurant {
+			restaurants = append(restaurants, result)
+		}
+	}
+	return restaurants, nil
+}
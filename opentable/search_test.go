@@ -0,0 +1,42 @@
+package opentable
+
+import (
+	"net/http"
+	"testing"
+)
+
+const searchResponseBody = `{"autocompleteResults":[
+	{"id":1234,"type":"Restaurant","name":"Test Bistro","country":"United States","metroId":8,"metroName":"New York","latitude":40.7,"longitude":-74.0},
+	{"id":8,"type":"Location","name":"New York","country":"United States","metroId":8,"metroName":"New York","latitude":40.7,"longitude":-74.0}
+]}`
+
+func TestSearch(t *testing.T) {
+	var query map[string]string
+	client := newFakeClient(t, "", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/api/v3/autocomplete" {
+			http.NotFound(w, r)
+			return
+		}
+		query = map[string]string{
+			"term":      r.URL.Query().Get("term"),
+			"latitude":  r.URL.Query().Get("latitude"),
+			"longitude": r.URL.Query().Get("longitude"),
+		}
+		w.Write([]byte(searchResponseBody)) //nolint:errcheck
+	}))
+
+	results, err := client.Search("test bistro", 40.7, -74, false)
+	requireNoError(t, err, "Search failed")
+	if len(results) != 2 {
+		t.Fatalf("results: got %d, want 2", len(results))
+	}
+	if query["term"] != "test bistro" || query["latitude"] != "40.7" || query["longitude"] != "-74" {
+		t.Errorf("unexpected query %v", query)
+	}
+
+	restaurants, err := client.Search("test bistro", 40.7, -74, true)
+	requireNoError(t, err, "Search of restaurants failed")
+	if len(restaurants) != 1 || restaurants[0].Id != testRestaurantId || restaurants[0].Name != "Test Bistro" {
+		t.Errorf("got restaurants %+v, want only Test Bistro", restaurants)
+	}
+}
@@ -1,15 +1,113 @@
 package opentable
 
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Date time format of the OpenTable API
+// Date times are in the local time of the restaurant
+const DateTimeFormat = "2006-01-02T15:04"
+
+// Represents a time slot of the availability of a restaurant
+// The time of the slot is an offset from the date time of the availability
+// The slot hash and availability token are required to lock the slot
+// Attributes are the seating types of the slot, such as default, bar, or outdoor
 type Slot struct {
-	IsAvailable                  bool   `json:"isAvailable"`
-	TimeOffsetMinutes            int    `json:"timeOffsetMinutes"`
-	SlotHash                     string `json:"slotHash"`
-	PointsType                   string `json:"pointsType"`
-	PointsValue                  int    `json:"pointsValue"`
-	HasPrivateDiningAvailability bool   `json:"hasPrivateDiningAvailability"`
-	AvailableSpaceIds            []int  `json:"AvailableSpaceIds"`
-	ExperienceIds                []int  `json:"experienceIds"`
-	AvailabilityToken            string `json:"slotAvailabilityToken"`
-	RedemptionTier               string `json:"redemptionTier"`
-	Type                         string `json:"type"`
+	IsAvailable                  bool     `json:"isAvailable"`
+	TimeOffsetMinutes            int      `json:"timeOffsetMinutes"`
+	SlotHash                     string   `json:"slotHash"`
+	PointsType                   string   `json:"pointsType"`
+	PointsValue                  int      `json:"pointsValue"`
+	HasPrivateDiningAvailability bool     `json:"hasPrivateDiningAvailability"`
+	AvailableSpaceIds            []int    `json:"AvailableSpaceIds"`
+	ExperienceIds                []int    `json:"experienceIds"`
+	AvailabilityToken            string   `json:"slotAvailabilityToken"`
+	RedemptionTier               string   `json:"redemptionTier"`
+	Type                         string   `json:"type"`
+	Attributes                   []string `json:"attributes"`
+}
+
+// Represents the availability of a restaurant for a date time and party size
+// NOTE: The timezone value of the date time is UTC but
+// the time is in the local time of the restaurant
+// e.g. 19:00 local time -> 19:00:00 +0000 UTC
+type Availability struct {
+	DateTime  time.Time
+	PartySize int
+	Slots     []Slot
+}
+
+type availabilityRequest struct {
+	RestaurantIds          []string `json:"rids"`
+	DateTime               string   `json:"dateTime"`
+	PartySize              int      `json:"partySize"`
+	ForceNextAvailable     bool     `json:"forceNextAvailable"`
+	IncludeNextAvailable   bool     `json:"includeNextAvailable"`
+	RequestAttributeTables bool     `json:"requestAttributeTables"`
+}
+
+type availabilityResponse struct {
+	Availability []struct {
+		RestaurantId     int `json:"restaurantId"`
+		AvailabilityDays []struct {
+			DayOffset int    `json:"dayOffset"`
+			Slots     []Slot `json:"slots"`
+		} `json:"availabilityDays"`
+	} `json:"availability"`
+}
+
+// Returns the time of a slot of the availability, in the same
+// representation as the date time of the availability
+func (a Availability) SlotTime(slot Slot) time.Time {
+	return a.DateTime.Add(time.Duration(slot.TimeOffsetMinutes) * time.Minute)
+}
+
+// Retrieves the available slots of a restaurant for a party size around a date time
+// OpenTable only returns slots within a few hours of the date time, so the date time
+// should be the time around which slots are desired
+// Slots that are not available are not included
+func (c *Client) GetAvailability(restaurantId int, dateTime time.Time, partySize int) (*Availability, error) {
+	reqUrl := Host + "/api/v3/restaurant/availability"
+
+	req, err := c.NewJsonRequest(http.MethodPut, reqUrl, availabilityRequest{
+		RestaurantIds:          []string{strconv.Itoa(restaurantId)},
+		DateTime:               dateTime.Format(DateTimeFormat),
+		PartySize:              partySize,
+		ForceNextAvailable:     true,
+		RequestAttributeTables: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var res availabilityResponse
+	err = c.Do(req, &res)
+	if err != nil {
+		return nil, err
+	}
+
+	availability := Availability{
+		DateTime:  time.Date(dateTime.Year(), dateTime.Month(), dateTime.Day(), dateTime.Hour(), dateTime.Minute(), 0, 0, time.UTC),
+		PartySize: partySize,
+		Slots:     []Slot{},
+	}
+	for _, restaurant := range res.Availability {
+		if restaurant.RestaurantId != restaurantId {
+			continue
+		}
+		for _, day := range restaurant.AvailabilityDays {
+			// Only the slots of the day of the date time are returned
+			if day.DayOffset != 0 {
+				continue
+			}
+			for _, slot := range day.Slots {
+				if slot.IsAvailable {
+					availability.Slots = append(availability.Slots, slot)
+				}
+			}
+		}
+	}
+	return &availability, nil
 }
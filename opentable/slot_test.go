@@ -0,0 +1,56 @@
+package opentable
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+)
+
+const availabilityResponseBody = `{"availability":[{"restaurantId":1234,"availabilityDays":[
+	{"dayOffset":0,"slots":[
+		{"isAvailable":true,"timeOffsetMinutes":-30,"slotHash":"hash-1830","slotAvailabilityToken":"token-1830","type":"Standard","attributes":["default"]},
+		{"isAvailable":false,"timeOffsetMinutes":0},
+		{"isAvailable":true,"timeOffsetMinutes":45,"slotHash":"hash-1945","slotAvailabilityToken":"token-1945","type":"Standard","attributes":["bar"]}
+	]},
+	{"dayOffset":1,"slots":[
+		{"isAvailable":true,"timeOffsetMinutes":0,"slotHash":"hash-next","slotAvailabilityToken":"token-next"}
+	]}
+]}]}`
+
+func TestGetAvailability(t *testing.T) {
+	var request availabilityRequest
+	client := newFakeClient(t, testToken, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut || r.URL.Path != "/api/v3/restaurant/availability" {
+			http.NotFound(w, r)
+			return
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte(availabilityResponseBody)) //nolint:errcheck
+	}))
+
+	dateTime := time.Date(2026, 11, 20, 19, 0, 0, 0, time.UTC)
+	availability, err := client.GetAvailability(testRestaurantId, dateTime, 2)
+	requireNoError(t, err, "GetAvailability failed")
+
+	if len(request.RestaurantIds) != 1 || request.RestaurantIds[0] != "1234" || request.DateTime != "2026-11-20T19:00" || request.PartySize != 2 {
+		t.Errorf("unexpected request %+v", request)
+	}
+	// Unavailable slots and slots of the following day are not included
+	if len(availability.Slots) != 2 {
+		t.Fatalf("slots: got %d, want 2", len(availability.Slots))
+	}
+
+	wantTimes := []string{"2026-11-20T18:30", "2026-11-20T19:45"}
+	for i, slot := range availability.Slots {
+		if got := availability.SlotTime(slot).Format(DateTimeFormat); got != wantTimes[i] {
+			t.Errorf("slot %d: got time %s, want %s", i, got, wantTimes[i])
+		}
+	}
+	if availability.Slots[1].Attributes[0] != "bar" {
+		t.Errorf("attributes: got %v, want [bar]", availability.Slots[1].Attributes)
+	}
+}
@@ -0,0 +1,58 @@
+package opentable
+
+import (
+	"net/http"
+)
+
+// Represents the authenticated user
+// The global person ID identifies the user across OpenTable
+type User struct {
+	GlobalPersonId string       `json:"gpid"`
+	FirstName      string       `json:"firstName"`
+	LastName       string       `json:"lastName"`
+	Email          string       `json:"email"`
+	PhoneNumber    string       `json:"phoneNumber"`
+	CreditCards    []CreditCard `json:"creditCards"`
+}
+
+// Represents a credit card of the user used to book
+// slots that require a credit card or a deposit
+type CreditCard struct {
+	Id        string `json:"id"`
+	Type      string `json:"type"`
+	Last4     string `json:"last4"`
+	IsDefault bool   `json:"isDefault"`
+}
+
+// Retrieves the authenticated user
+// Returns an ErrUnauthorized if the token is invalid or expired
+func (c *Client) GetUser() (*User, error) {
+	reqUrl := Host + "/api/v1/users/me"
+
+	req, err := http.NewRequest(http.MethodGet, reqUrl, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var user User
+	err = c.Do(req, &user)
+	if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// Returns the default credit card of a user, or the first
+// credit card if none is the default, and false if the user has none
+func GetDefaultCreditCard(user *User) (CreditCard, bool) {
+	for _, creditCard := range user.CreditCards {
+		if creditCard.IsDefault {
+			return creditCard, true
+		}
+	}
+	if len(user.CreditCards) > 0 {
+		return user.CreditCards[0], true
+	}
+	return CreditCard{}, false
+}
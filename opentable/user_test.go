@@ -0,0 +1,32 @@
+package opentable
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+const userResponseBody = `{"gpid":"100200300","firstName":"Test","lastName":"User","email":"test@example.com",
+	"creditCards":[{"id":"11","type":"Visa","last4":"4242"},{"id":"12","type":"Amex","last4":"0005","isDefault":true}]}`
+
+func TestGetUser(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !requireAuth(w, r) {
+			return
+		}
+		w.Write([]byte(userResponseBody)) //nolint:errcheck
+	})
+
+	user, err := newFakeClient(t, testToken, handler).GetUser()
+	requireNoError(t, err, "GetUser failed")
+	if user.GlobalPersonId != "100200300" || len(user.CreditCards) != 2 {
+		t.Errorf("unexpected user %+v", user)
+	}
+	if creditCard, ok := GetDefaultCreditCard(user); !ok || creditCard.Id != "12" {
+		t.Errorf("default credit card: got %+v, want card 12", creditCard)
+	}
+
+	if _, err := newFakeClient(t, "expired", handler).GetUser(); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("invalid token: got %v, want %v", err, ErrUnauthorized)
+	}
+}
@@ -3,10 +3,16 @@ package reservation
 import (
 	"context"
 	"errors"
-	"sync"
 	"time"
 )
 
+const (
+	// Duration slots are retrieved for after the drop time until slots are found
+	slotDeadline = 30 * time.Second
+	// Duration other targets of an event are waited for once slots of a target are found
+	targetGracePeriod = 500 * time.Millisecond
+)
+
 var (
 	ErrUnsupportedPlatform = errors.New("unsupported platform")
 	ErrFailedToBookSlots   = errors.New("failed to book any of the slots")
@@ -16,11 +22,20 @@ type BookingClient interface {
 	// Handles any pre-booking checks such as token validity
 	PreBookingCheck(ctx context.Context, event Event) error
 
+	// Opens up to the specified number of connections to the platform and
+	// keeps them alive so that the requests at the drop time do not start cold
+	WarmUp(ctx context.Context, event Event, connections int) error
+
 	// Retrieve matching slots
 	// The slice of any represents slot types and should be
 	// type asserted in the respective methods
 	FetchSlots(ctx context.Context, event Event) (any, error)
 
+	// Retrieve matching slots once without waiting for slots to be released
+	// Used by watch jobs to poll for slots, returns ErrNoMatchingSlotsFound
+	// if no slots match and ErrRateLimited if rate limited by the platform
+	PollSlots(ctx context.Context, event Event) (any, error)
+
 	// Attempts to perform a booking for a single slot
 	Book(ctx context.Context, event Event, slot any) (Attempt, error)
 
@@ -29,18 +44,16 @@ type BookingClient interface {
 }
 
 // Returns a new booking client for the specified platform
-// NOTE: Add OpenTable client when created
 func NewBookingClient(platform string, token string) (BookingClient, error) {
-	switch platform {
-	case "resy":
-		return NewResyClient(token)
-	default:
-		return nil, ErrUnsupportedPlatform
+	adapter, err := GetPlatform(platform)
+	if err != nil {
+		return nil, err
 	}
+	return adapter.NewBookingClient(token)
 }
 
 // Generic booking handler that handles the core booking logic
-// - Implements soft and strict preference
+// - Books slots using the booking strategy of the event
 // - Generics to handle any slot type
 // The slots slice is to be in preference order
 // Returns the result if successful, a slice of all attempts, and an error
@@ -50,107 +63,92 @@ func bookingHandler[T any](
 	event Event,
 	slots []T,
 ) (*BookingResult, []Attempt, error) {
-	var attempts []Attempt
-
-	// Strict preference: attempt each reservation sequentially
-	if event.StrictPreference {
-		for _, slot := range slots {
-			attempt, err := client.Book(ctx, event, slot)
-			attempts = append(attempts, attempt)
-			if err == nil {
-				return attempt.Result, attempts, nil
-			}
-		}
-		return nil, attempts, ErrFailedToBookSlots
+	anySlots := make([]any, len(slots))
+	for i, slot := range slots {
+		anySlots[i] = slot
 	}
+	return event.bookingStrategy(client).book(ctx, client, event, anySlots)
+}
 
-	// Soft preference: concurrent booking with 1-second stagger
-	// Create cancellable context (inherits parent cancellation)
+// Retrieves the slots of each target concurrently using a fetch function
+// Once slots of a target are found, the other targets are given a grace period
+// to return their slots so that the booking is not delayed by targets without slots
+// Returns the slots of each target in the order of the targets and an error if
+// no slots were found for any target
+func getTargetSlots[T any](ctx context.Context, targets []Alternative, fetch func(ctx context.Context, target Alternative) ([]T, error)) ([][]T, error) {
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
-	resultCh := make(chan Attempt, len(slots))
-	doneCh := make(chan struct{})
-	var wg sync.WaitGroup
-
-	var finalResult *BookingResult
-	var finalError error
-
-	// Process results concurrently as they arrive
-	go func() {
-		defer close(doneCh)
-		for attempt := range resultCh {
-			attempts = append(attempts, attempt)
-			if attempt.Error == "" && finalResult == nil {
-				// Cancel all other goroutines on success
-				cancel()
-				finalResult = attempt.Result
-			}
-		}
+	type targetResult struct {
+		index int
+		slots []T
+		err   error
+	}
+	resultCh := make(chan targetResult, len(targets))
+	for i, target := range targets {
+		go func() {
+			slots, err := fetch(ctx, target)
+			resultCh <- targetResult{index: i, slots: slots, err: err}
+		}()
+	}
 
-		if finalResult == nil {
-			// All attempts failed
-			finalError = ErrFailedToBookSlots
-		}
-	}()
+	targetSlots := make([][]T, len(targets))
+	var firstErr error
+	var grace <-chan time.Time
+	found := false
 
-	// Launch goroutines with 1-second stagger to maintain soft preference
-launchLoop:
-	for i, slot := range slots {
-		// Stagger launches (except first one)
-		if i > 0 {
-			select {
-			case <-time.After(1 * time.Second):
-				// Continue to launch next goroutine
-			case <-ctx.Done():
-				// Context cancelled
-				break launchLoop
+collectLoop:
+	for range targets {
+		select {
+		case result := <-resultCh:
+			if result.err != nil && firstErr == nil {
+				firstErr = result.err
 			}
+			targetSlots[result.index] = result.slots
+			if len(result.slots) > 0 && !found {
+				found = true
+				grace = time.After(targetGracePeriod)
+			}
+		case <-grace:
+			break collectLoop
 		}
+	}
 
-		// Check if context was cancelled
-		select {
-		case <-ctx.Done():
-			break launchLoop
-		default:
-			wg.Add(1)
-			go bookSlotConcurrent(ctx, &wg, resultCh, client, event, slot)
+	if !found {
+		if firstErr == nil {
+			firstErr = ErrNoSlotsFound
 		}
+		return nil, firstErr
 	}
+	return targetSlots, nil
+}
 
-	// Close channel after all attempts complete
-	wg.Wait()
-	close(resultCh)
+// Retrieves slots with a 0.05s pause between requests until either slots are found or the deadline is expired
+// This is to handle if there is a slight delay in the API in marking slots as available after the drop time and ensuring this does
+// not cause the lambda to fail
+func getSlotsUntilDeadline[T any](ctx context.Context, deadline time.Time, fetch func() ([]T, error)) ([]T, error) {
+	pauseDuration := 50 * time.Millisecond // 0.05s
 
-	// Wait for result processing to complete
-	<-doneCh
-	return finalResult, attempts, finalError
-}
+	for {
+		if time.Now().UTC().After(deadline) {
+			return nil, ErrNoSlotsFound
+		}
 
-// Wraps Book call for goroutine execution with context cancellation support
-func bookSlotConcurrent[T any](
-	ctx context.Context,
-	wg *sync.WaitGroup,
-	resultCh chan<- Attempt,
-	client BookingClient,
-	event Event,
-	slot T,
-) {
-	defer wg.Done()
-
-	// Check if context already cancelled, skip if cancelled
-	select {
-	case <-ctx.Done():
-		return
-	default:
-	}
+		// Handle context cancellation
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		slots, err := fetch()
+		if err != nil {
+			// Exit if an error is returned in the request
+			return nil, err
+		}
 
-	attempt, err := client.Book(ctx, event, slot)
+		if len(slots) > 0 {
+			return slots, nil
+		}
 
-	// Send result back
-	if err != nil {
-		attempt.Error = err.Error()
+		time.Sleep(pauseDuration)
 	}
-
-	resultCh <- attempt
 }
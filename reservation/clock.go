@@ -0,0 +1,190 @@
+package reservation
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+)
+
+var (
+	ErrInvalidNTPResponse = errors.New("invalid NTP response")
+	ErrNoDateHeader       = errors.New("response has no date header")
+)
+
+const (
+	// Timeout of a single clock offset measurement
+	clockMeasurementTimeout = 2 * time.Second
+
+	// Resolution of the Date header of HTTP responses
+	dateHeaderResolution = time.Second
+
+	ntpPacketSize = 48
+	// Seconds between the NTP epoch (1900) and the Unix epoch (1970)
+	ntpEpochOffset = 2208988800
+)
+
+// Sources of the clock offset of a job
+const (
+	ClockSourceNTP        = "ntp"
+	ClockSourceDateHeader = "date_header"
+)
+
+// Implemented by booking clients whose platform can be used as a reference
+// clock through the Date header of its HTTP responses
+type dateHeaderSource interface {
+	DateHeaderURL() string
+}
+
+// Measures the offset of the local clock against the NTP server, falling back
+// to the Date header of the platform of the booking client if the NTP server
+// is not set or could not be queried
+// The offset is the duration to add to the local clock to obtain the reference time
+// Returns the offset and its source, which is empty if it could not be measured
+func measureClockOffset(ctx context.Context, ntpServer string, client BookingClient) (time.Duration, string, error) {
+	var ntpErr error
+	if ntpServer != "" {
+		offset, err := ntpOffset(ctx, ntpServer)
+		if err == nil {
+			return offset, ClockSourceNTP, nil
+		}
+		ntpErr = err
+	}
+
+	source, ok := client.(dateHeaderSource)
+	if !ok {
+		return 0, "", ntpErr
+	}
+	offset, err := dateHeaderOffset(ctx, source.DateHeaderURL())
+	if err != nil {
+		return 0, "", errors.Join(ntpErr, err)
+	}
+	return offset, ClockSourceDateHeader, nil
+}
+
+// Converts the drop time to the local clock using a measured clock offset
+// NTP offsets are always applied, whereas Date header offsets are only applied if
+// they exceed the resolution of the header as a smaller offset is within its error
+// and the local clock is usually already synchronised
+// Returns the drop time on the local clock and whether the offset was applied
+func correctDropTime(dropTime time.Time, offset time.Duration, source string) (time.Time, bool) {
+	switch source {
+	case ClockSourceNTP:
+	case ClockSourceDateHeader:
+		if offset.Abs() <= dateHeaderResolution {
+			return dropTime, false
+		}
+	default:
+		return dropTime, false
+	}
+	return dropTime.Add(-offset), true
+}
+
+// Measures the offset of the local clock against an NTP server using SNTP
+// The server address defaults to port 123 if no port is specified
+func ntpOffset(ctx context.Context, server string) (time.Duration, error) {
+	if _, _, err := net.SplitHostPort(server); err != nil {
+		server = net.JoinHostPort(server, "123")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, clockMeasurementTimeout)
+	defer cancel()
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "udp", server)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close() //nolint:errcheck
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			return 0, err
+		}
+	}
+
+	// Leap indicator 0, version 4, and client mode
+	request := make([]byte, ntpPacketSize)
+	request[0] = 0<<6 | 4<<3 | 3
+	originate := time.Now()
+	binary.BigEndian.PutUint64(request[40:], toNTPTime(originate))
+	if _, err := conn.Write(request); err != nil {
+		return 0, err
+	}
+
+	response := make([]byte, ntpPacketSize)
+	n, err := conn.Read(response)
+	received := time.Now()
+	if err != nil {
+		return 0, err
+	}
+	if n < ntpPacketSize {
+		return 0, fmt.Errorf("%w: packet of %d bytes", ErrInvalidNTPResponse, n)
+	}
+
+	mode := response[0] & 0x7
+	stratum := response[1]
+	switch {
+	case mode != 4:
+		return 0, fmt.Errorf("%w: mode %d", ErrInvalidNTPResponse, mode)
+	case stratum == 0:
+		return 0, fmt.Errorf("%w: kiss of death", ErrInvalidNTPResponse)
+	case binary.BigEndian.Uint64(response[24:]) != binary.BigEndian.Uint64(request[40:]):
+		return 0, fmt.Errorf("%w: originate timestamp mismatch", ErrInvalidNTPResponse)
+	}
+
+	serverReceive := fromNTPTime(binary.BigEndian.Uint64(response[32:]))
+	serverTransmit := fromNTPTime(binary.BigEndian.Uint64(response[40:]))
+	if serverTransmit.IsZero() {
+		return 0, fmt.Errorf("%w: no transmit timestamp", ErrInvalidNTPResponse)
+	}
+
+	return (serverReceive.Sub(originate) + serverTransmit.Sub(received)) / 2, nil
+}
+
+// Measures the offset of the local clock against the Date header of the response to a request
+// The Date header only has a precision of a second, so the reference time is
+// assumed to be halfway through the second and compared to the midpoint of the request
+func dateHeaderOffset(ctx context.Context, url string) (time.Duration, error) {
+	ctx, cancel := context.WithTimeout(ctx, clockMeasurementTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
+	if err != nil {
+		return 0, err
+	}
+	sent := time.Now()
+	res, err := http.DefaultClient.Do(req)
+	received := time.Now()
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close() //nolint:errcheck
+
+	date, err := http.ParseTime(res.Header.Get("Date"))
+	if err != nil {
+		return 0, fmt.Errorf("%w: %w", ErrNoDateHeader, err)
+	}
+
+	midpoint := sent.Add(received.Sub(sent) / 2)
+	return date.Add(500 * time.Millisecond).Sub(midpoint), nil
+}
+
+// Converts a time to an NTP timestamp
+func toNTPTime(t time.Time) uint64 {
+	seconds := uint64(t.Unix() + ntpEpochOffset)
+	fraction := uint64(t.Nanosecond()) << 32 / uint64(time.Second)
+	return seconds<<32 | fraction
+}
+
+// Converts an NTP timestamp to a time, a zero timestamp being the zero time
+func fromNTPTime(timestamp uint64) time.Time {
+	if timestamp == 0 {
+		return time.Time{}
+	}
+	seconds := int64(timestamp>>32) - ntpEpochOffset
+	nanoseconds := int64((timestamp & 0xffffffff) * uint64(time.Second) >> 32)
+	return time.Unix(seconds, nanoseconds)
+}
@@ -0,0 +1,163 @@
+package reservation
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// Starts a local stand-in NTP server whose clock is ahead of the local clock by
+// the given offset and returns its address
+func startNTPServer(t *testing.T, offset time.Duration, stratum byte) string {
+	t.Helper()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() }) //nolint:errcheck
+
+	go func() {
+		request := make([]byte, ntpPacketSize)
+		for {
+			n, addr, err := conn.ReadFrom(request)
+			if err != nil {
+				return
+			}
+			if n < ntpPacketSize {
+				continue
+			}
+			received := time.Now().Add(offset)
+
+			response := make([]byte, ntpPacketSize)
+			response[0] = 0<<6 | 4<<3 | 4
+			response[1] = stratum
+			copy(response[24:32], request[40:48])
+			binary.BigEndian.PutUint64(response[32:], toNTPTime(received))
+			binary.BigEndian.PutUint64(response[40:], toNTPTime(time.Now().Add(offset)))
+			conn.WriteTo(response, addr) //nolint:errcheck
+		}
+	}()
+
+	return conn.LocalAddr().String()
+}
+
+// Booking client whose Date header source is a test server
+type dateHeaderClient struct {
+	watchClient
+	url string
+}
+
+func (c *dateHeaderClient) DateHeaderURL() string {
+	return c.url
+}
+
+func TestNTPTimeConversion(t *testing.T) {
+	now := time.Now()
+	if diff := fromNTPTime(toNTPTime(now)).Sub(now); diff < -time.Microsecond || diff > time.Microsecond {
+		t.Errorf("round trip differs by %s", diff)
+	}
+}
+
+func TestNTPOffset(t *testing.T) {
+	for _, offset := range []time.Duration{2 * time.Second, -1500 * time.Millisecond} {
+		server := startNTPServer(t, offset, 1)
+		measured, err := ntpOffset(context.Background(), server)
+		if err != nil {
+			t.Fatalf("ntpOffset failed: %v", err)
+		}
+		if diff := measured - offset; diff < -50*time.Millisecond || diff > 50*time.Millisecond {
+			t.Errorf("offset: got %s, want %s", measured, offset)
+		}
+	}
+}
+
+func TestNTPOffset_KissOfDeath(t *testing.T) {
+	server := startNTPServer(t, 0, 0)
+	if _, err := ntpOffset(context.Background(), server); !errors.Is(err, ErrInvalidNTPResponse) {
+		t.Errorf("got %v, want %v", err, ErrInvalidNTPResponse)
+	}
+}
+
+func TestMeasureClockOffset_DateHeaderFallback(t *testing.T) {
+	offset := -10 * time.Second
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Date", time.Now().Add(offset).UTC().Format(http.TimeFormat))
+	}))
+	defer server.Close()
+
+	// Nothing listens on the NTP server so the Date header is used
+	unreachable, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	ntpServer := unreachable.LocalAddr().String()
+	unreachable.Close() //nolint:errcheck
+
+	measured, source, err := measureClockOffset(context.Background(), ntpServer, &dateHeaderClient{url: server.URL})
+	if err != nil {
+		t.Fatalf("measureClockOffset failed: %v", err)
+	}
+	if source != ClockSourceDateHeader {
+		t.Errorf("source: got %q, want %q", source, ClockSourceDateHeader)
+	}
+	if diff := measured - offset; diff < -time.Second || diff > time.Second {
+		t.Errorf("offset: got %s, want %s within a second", measured, offset)
+	}
+}
+
+func TestMeasureClockOffset_PrefersNTP(t *testing.T) {
+	server := startNTPServer(t, time.Second, 2)
+	measured, source, err := measureClockOffset(context.Background(), server, &watchClient{})
+	if err != nil {
+		t.Fatalf("measureClockOffset failed: %v", err)
+	}
+	if source != ClockSourceNTP || measured < 950*time.Millisecond || measured > 1050*time.Millisecond {
+		t.Errorf("got %s from %q, want 1s from %q", measured, source, ClockSourceNTP)
+	}
+}
+
+func TestCorrectDropTime(t *testing.T) {
+	dropTime := time.Date(2026, 11, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		offset  time.Duration
+		source  string
+		want    time.Time
+		applied bool
+	}{
+		{"ntp", 200 * time.Millisecond, ClockSourceNTP, dropTime.Add(-200 * time.Millisecond), true},
+		{"small date header", -400 * time.Millisecond, ClockSourceDateHeader, dropTime, false},
+		{"date header resolution", time.Second, ClockSourceDateHeader, dropTime, false},
+		{"large date header", -3 * time.Second, ClockSourceDateHeader, dropTime.Add(3 * time.Second), true},
+		{"unmeasured", 0, "", dropTime, false},
+	}
+
+	for _, test := range tests {
+		got, applied := correctDropTime(dropTime, test.offset, test.source)
+		if !got.Equal(test.want) || applied != test.applied {
+			t.Errorf("%s: got %s applied %t, want %s applied %t", test.name, got, applied, test.want, test.applied)
+		}
+	}
+}
+
+func TestMeasureClockOffset_SmallDateHeaderOffsetNotApplied(t *testing.T) {
+	// A synchronised platform clock only differs by the resolution of the header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Date", time.Now().UTC().Format(http.TimeFormat))
+	}))
+	defer server.Close()
+
+	offset, source, err := measureClockOffset(context.Background(), "", &dateHeaderClient{url: server.URL})
+	if err != nil {
+		t.Fatalf("measureClockOffset failed: %v", err)
+	}
+	dropTime := time.Now().Add(time.Minute)
+	if got, applied := correctDropTime(dropTime, offset, source); applied || !got.Equal(dropTime) {
+		t.Errorf("offset of %s from %q moved the drop time by %s", offset, source, dropTime.Sub(got))
+	}
+}
@@ -0,0 +1,36 @@
+package reservation
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+// Transport sending the requests to a platform API to a test server
+type rewriteTransport struct {
+	target *url.URL
+}
+
+func (t rewriteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.URL.Scheme = t.target.Scheme
+	req.URL.Host = t.target.Host
+	req.Host = t.target.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// newFakeHTTPClient starts a test server with the provided handler and
+// returns an HTTP client whose requests are sent to it
+func newFakeHTTPClient(t *testing.T, handler http.Handler) *http.Client {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	target, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatalf("invalid test server URL: %v", err)
+	}
+	return &http.Client{Transport: rewriteTransport{target: target}}
+}
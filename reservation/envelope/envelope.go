@@ -0,0 +1,293 @@
+// Package envelope implements AES-256-GCM envelope encryption using a keyring
+// of master keys. Every message is encrypted with a random data key which is
+// in turn wrapped with a master key. The ID of the master key is embedded in
+// the ciphertext so that master keys can be rotated without losing the ability
+// to decrypt existing ciphertexts.
+//
+// A Keyring implements the reservation.Decrypter interface so that the same
+// ciphertext produced by the server can be decrypted by an executor.
+package envelope
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+const (
+	// Version of the ciphertext format
+	version byte = 1
+
+	// Size of master keys and data keys (AES-256)
+	KeySize = 32
+
+	// Key ID used for a single master key when no key ID is specified
+	DefaultKeyID = "default"
+
+	// Maximum length of a key ID as it is stored in a single byte
+	maxKeyIDLength = 255
+
+	nonceSize      = 12
+	tagSize        = 16
+	wrappedKeySize = nonceSize + KeySize + tagSize
+)
+
+var (
+	ErrCiphertextTooShort  = errors.New("ciphertext is too short")
+	ErrDuplicateKeyID      = errors.New("key ID already exists in keyring")
+	ErrInvalidKeyID        = errors.New("key ID must be between 1 and 255 characters")
+	ErrInvalidKeySize      = errors.New("master key must be 32 bytes")
+	ErrNoPrimaryKey        = errors.New("primary key ID does not exist in keyring")
+	ErrUnknownKeyID        = errors.New("ciphertext was encrypted with an unknown key ID")
+	ErrUnsupportedVersion  = errors.New("unsupported ciphertext version")
+	ErrFailedToUnwrapKey   = errors.New("failed to unwrap data key")
+	ErrFailedToDecrypt     = errors.New("failed to decrypt ciphertext")
+	ErrFailedToReadKeyFile = errors.New("failed to read key file")
+	ErrMissingMasterKey    = errors.New("either a master key or a key file is required")
+	ErrConflictingKeys     = errors.New("master key and key file cannot both be specified")
+	ErrInvalidMasterKey    = errors.New("master key must be a base64 encoded 32 byte value")
+)
+
+// A set of master keys indexed by their ID
+// New ciphertexts are always encrypted using the primary key
+type Keyring struct {
+	primaryID string
+	keys      map[string][]byte
+}
+
+// Format of a key file
+// Keys are base64 encoded 32 byte values
+type KeyFile struct {
+	PrimaryKeyID string            `json:"primary_key_id"`
+	Keys         map[string]string `json:"keys"`
+}
+
+// Creates a new keyring from a map of key IDs to 32 byte master keys
+// The primary key ID must be present in the keys map
+func NewKeyring(primaryID string, keys map[string][]byte) (*Keyring, error) {
+	keyring := &Keyring{
+		keys: make(map[string][]byte, len(keys)),
+	}
+	for id, key := range keys {
+		if err := keyring.add(id, key); err != nil {
+			return nil, err
+		}
+	}
+	if _, exists := keyring.keys[primaryID]; !exists {
+		return nil, ErrNoPrimaryKey
+	}
+	keyring.primaryID = primaryID
+
+	return keyring, nil
+}
+
+// Loads a keyring from either a base64 encoded master key or a key file
+// Exactly one of the master key and key file must be provided
+// If no master key ID is provided, DefaultKeyID is used
+func LoadKeyring(masterKey string, masterKeyID string, keyFile string) (*Keyring, error) {
+	switch {
+	case masterKey == "" && keyFile == "":
+		return nil, ErrMissingMasterKey
+	case masterKey != "" && keyFile != "":
+		return nil, ErrConflictingKeys
+	case keyFile != "":
+		return LoadKeyFile(keyFile)
+	}
+
+	key, err := DecodeKey(masterKey)
+	if err != nil {
+		return nil, ErrInvalidMasterKey
+	}
+	if masterKeyID == "" {
+		masterKeyID = DefaultKeyID
+	}
+	return NewKeyring(masterKeyID, map[string][]byte{masterKeyID: key})
+}
+
+// Loads a keyring from a JSON key file
+func LoadKeyFile(path string) (*Keyring, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrFailedToReadKeyFile, err)
+	}
+
+	var keyFile KeyFile
+	if err := json.Unmarshal(content, &keyFile); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrFailedToReadKeyFile, err)
+	}
+
+	keys := make(map[string][]byte, len(keyFile.Keys))
+	for id, encodedKey := range keyFile.Keys {
+		key, err := DecodeKey(encodedKey)
+		if err != nil {
+			return nil, fmt.Errorf("%w: key %q: %w", ErrFailedToReadKeyFile, id, err)
+		}
+		keys[id] = key
+	}
+
+	return NewKeyring(keyFile.PrimaryKeyID, keys)
+}
+
+// Decodes a base64 encoded master key and validates its size
+func DecodeKey(encodedKey string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(encodedKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(key) != KeySize {
+		return nil, ErrInvalidKeySize
+	}
+	return key, nil
+}
+
+// Generates a new random master key
+func GenerateKey() ([]byte, error) {
+	key := make([]byte, KeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// Returns the ID of the primary key
+func (k *Keyring) PrimaryKeyID() string {
+	return k.primaryID
+}
+
+// Returns whether the keyring contains a key with a given ID
+func (k *Keyring) HasKey(id string) bool {
+	_, exists := k.keys[id]
+	return exists
+}
+
+// Encrypts a plaintext using a random data key wrapped by the primary key
+// Format: version | key ID length | key ID | wrapped data key | nonce | ciphertext
+func (k *Keyring) Encrypt(plaintext []byte) ([]byte, error) {
+	dataKey, err := GenerateKey()
+	if err != nil {
+		return nil, err
+	}
+
+	header := make([]byte, 0, 2+len(k.primaryID))
+	header = append(header, version, byte(len(k.primaryID)))
+	header = append(header, k.primaryID...)
+
+	wrappedKey, err := seal(k.keys[k.primaryID], dataKey, []byte(k.primaryID))
+	if err != nil {
+		return nil, err
+	}
+	ciphertext, err := seal(dataKey, plaintext, header)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, 0, len(header)+len(wrappedKey)+len(ciphertext))
+	out = append(out, header...)
+	out = append(out, wrappedKey...)
+	return append(out, ciphertext...), nil
+}
+
+// Decrypts a ciphertext produced by Encrypt using the key
+// whose ID is embedded in the ciphertext
+// Implements the reservation.Decrypter interface
+func (k *Keyring) Decrypt(ctx context.Context, encrypted []byte) (string, error) {
+	keyID, header, err := parseHeader(encrypted)
+	if err != nil {
+		return "", err
+	}
+
+	masterKey, exists := k.keys[keyID]
+	if !exists {
+		return "", fmt.Errorf("%w: %s", ErrUnknownKeyID, keyID)
+	}
+
+	body := encrypted[len(header):]
+	if len(body) < wrappedKeySize+nonceSize+tagSize {
+		return "", ErrCiphertextTooShort
+	}
+
+	dataKey, err := open(masterKey, body[:wrappedKeySize], []byte(keyID))
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrFailedToUnwrapKey, err)
+	}
+	plaintext, err := open(dataKey, body[wrappedKeySize:], header)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrFailedToDecrypt, err)
+	}
+
+	return string(plaintext), nil
+}
+
+// Returns the ID of the master key that was used to encrypt a ciphertext
+func KeyID(encrypted []byte) (string, error) {
+	keyID, _, err := parseHeader(encrypted)
+	return keyID, err
+}
+
+func (k *Keyring) add(id string, key []byte) error {
+	if len(id) == 0 || len(id) > maxKeyIDLength {
+		return ErrInvalidKeyID
+	}
+	if len(key) != KeySize {
+		return fmt.Errorf("%w: key %q", ErrInvalidKeySize, id)
+	}
+	if _, exists := k.keys[id]; exists {
+		return fmt.Errorf("%w: %s", ErrDuplicateKeyID, id)
+	}
+	k.keys[id] = key
+	return nil
+}
+
+// Parses the header of a ciphertext, returning the key ID and the raw header
+func parseHeader(encrypted []byte) (string, []byte, error) {
+	if len(encrypted) < 2 {
+		return "", nil, ErrCiphertextTooShort
+	}
+	if encrypted[0] != version {
+		return "", nil, fmt.Errorf("%w: %d", ErrUnsupportedVersion, encrypted[0])
+	}
+
+	headerLength := 2 + int(encrypted[1])
+	if len(encrypted) < headerLength {
+		return "", nil, ErrCiphertextTooShort
+	}
+
+	return string(encrypted[2:headerLength]), encrypted[:headerLength], nil
+}
+
+// Encrypts a plaintext with AES-GCM and returns the nonce followed by the ciphertext
+func seal(key []byte, plaintext []byte, additionalData []byte) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, nonceSize, nonceSize+len(plaintext)+tagSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+// Decrypts a value produced by seal
+func open(key []byte, sealed []byte, additionalData []byte) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	return aead.Open(nil, sealed[:nonceSize], sealed[nonceSize:], additionalData)
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,148 @@
+package envelope
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// newTestKey generates a random master key and fails the test on error
+func newTestKey(t *testing.T) []byte {
+	t.Helper()
+	key, err := GenerateKey()
+	if err != nil {
+		t.Fatalf("GenerateKey failed: %v", err)
+	}
+	return key
+}
+
+func TestKeyring_RoundTrip(t *testing.T) {
+	keyring, err := NewKeyring("k1", map[string][]byte{"k1": newTestKey(t)})
+	if err != nil {
+		t.Fatalf("NewKeyring failed: %v", err)
+	}
+
+	plaintext := `{"ApiKey":"key","Token":"token","Refresh":"refresh"}`
+	ciphertext, err := keyring.Encrypt([]byte(plaintext))
+	if err != nil {
+		t.Fatalf("Encrypt failed: %v", err)
+	}
+
+	keyID, err := KeyID(ciphertext)
+	if err != nil {
+		t.Fatalf("KeyID failed: %v", err)
+	}
+	if keyID != "k1" {
+		t.Errorf("KeyID: got %q, want %q", keyID, "k1")
+	}
+
+	decrypted, err := keyring.Decrypt(context.Background(), ciphertext)
+	if err != nil {
+		t.Fatalf("Decrypt failed: %v", err)
+	}
+	if decrypted != plaintext {
+		t.Errorf("Decrypt: got %q, want %q", decrypted, plaintext)
+	}
+}
+
+func TestKeyring_Rotation(t *testing.T) {
+	oldKey := newTestKey(t)
+	oldKeyring, err := NewKeyring("old", map[string][]byte{"old": oldKey})
+	if err != nil {
+		t.Fatalf("NewKeyring failed: %v", err)
+	}
+	oldCiphertext, err := oldKeyring.Encrypt([]byte("secret"))
+	if err != nil {
+		t.Fatalf("Encrypt failed: %v", err)
+	}
+
+	rotatedKeyring, err := NewKeyring("new", map[string][]byte{"old": oldKey, "new": newTestKey(t)})
+	if err != nil {
+		t.Fatalf("NewKeyring failed: %v", err)
+	}
+
+	// Ciphertexts encrypted with the old key must remain decryptable
+	decrypted, err := rotatedKeyring.Decrypt(context.Background(), oldCiphertext)
+	if err != nil {
+		t.Fatalf("Decrypt of old ciphertext failed: %v", err)
+	}
+	if decrypted != "secret" {
+		t.Errorf("Decrypt: got %q, want %q", decrypted, "secret")
+	}
+
+	// New ciphertexts must use the new primary key
+	newCiphertext, err := rotatedKeyring.Encrypt([]byte("secret"))
+	if err != nil {
+		t.Fatalf("Encrypt failed: %v", err)
+	}
+	if keyID, _ := KeyID(newCiphertext); keyID != "new" {
+		t.Errorf("KeyID: got %q, want %q", keyID, "new")
+	}
+
+	// The old keyring does not know the new key
+	if _, err := oldKeyring.Decrypt(context.Background(), newCiphertext); !errors.Is(err, ErrUnknownKeyID) {
+		t.Errorf("expected ErrUnknownKeyID, got: %v", err)
+	}
+}
+
+func TestKeyring_Tampered(t *testing.T) {
+	keyring, err := NewKeyring("k1", map[string][]byte{"k1": newTestKey(t)})
+	if err != nil {
+		t.Fatalf("NewKeyring failed: %v", err)
+	}
+	ciphertext, err := keyring.Encrypt([]byte("secret"))
+	if err != nil {
+		t.Fatalf("Encrypt failed: %v", err)
+	}
+
+	ciphertext[len(ciphertext)-1] ^= 0xff
+	if _, err := keyring.Decrypt(context.Background(), ciphertext); !errors.Is(err, ErrFailedToDecrypt) {
+		t.Errorf("expected ErrFailedToDecrypt, got: %v", err)
+	}
+
+	if _, err := keyring.Decrypt(context.Background(), ciphertext[:10]); !errors.Is(err, ErrCiphertextTooShort) {
+		t.Errorf("expected ErrCiphertextTooShort, got: %v", err)
+	}
+}
+
+func TestNewKeyring_Invalid(t *testing.T) {
+	if _, err := NewKeyring("missing", map[string][]byte{"k1": newTestKey(t)}); !errors.Is(err, ErrNoPrimaryKey) {
+		t.Errorf("expected ErrNoPrimaryKey, got: %v", err)
+	}
+	if _, err := NewKeyring("k1", map[string][]byte{"k1": []byte("short")}); !errors.Is(err, ErrInvalidKeySize) {
+		t.Errorf("expected ErrInvalidKeySize, got: %v", err)
+	}
+}
+
+func TestLoadKeyFile(t *testing.T) {
+	keyFile := KeyFile{
+		PrimaryKeyID: "2026-01",
+		Keys: map[string]string{
+			"2025-06": base64.StdEncoding.EncodeToString(newTestKey(t)),
+			"2026-01": base64.StdEncoding.EncodeToString(newTestKey(t)),
+		},
+	}
+	content, err := json.Marshal(keyFile)
+	if err != nil {
+		t.Fatalf("failed to marshal key file: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "keys.json")
+	if err := os.WriteFile(path, content, 0o600); err != nil {
+		t.Fatalf("failed to write key file: %v", err)
+	}
+
+	keyring, err := LoadKeyFile(path)
+	if err != nil {
+		t.Fatalf("LoadKeyFile failed: %v", err)
+	}
+	if keyring.PrimaryKeyID() != "2026-01" {
+		t.Errorf("PrimaryKeyID: got %q, want %q", keyring.PrimaryKeyID(), "2026-01")
+	}
+	if !keyring.HasKey("2025-06") {
+		t.Error("keyring should contain key 2025-06")
+	}
+}
@@ -0,0 +1,123 @@
+// Package gcpkms implements a minimal client of the Cloud KMS REST API used to
+// encrypt and decrypt data with a Cloud KMS key.
+//
+// A Client implements the reservation.Decrypter interface so that the same
+// ciphertext produced by the server can be decrypted by an executor.
+package gcpkms
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Cloud KMS API endpoint
+const DefaultEndpoint = "https://cloudkms.googleapis.com"
+
+// Error returned by the Cloud KMS API
+type APIError struct {
+	StatusCode int
+	Status     string `json:"status"`
+	Message    string `json:"message"`
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("cloud KMS API error %d %s: %s", e.StatusCode, e.Status, e.Message)
+}
+
+// Client of the Cloud KMS REST API using a key
+// The HTTP client is expected to add the authentication to requests
+type Client struct {
+	httpClient *http.Client
+	endpoint   string
+	keyName    string
+}
+
+// Creates a new Cloud KMS client using the key with the given resource name
+// (projects/*/locations/*/keyRings/*/cryptoKeys/*)
+// If the endpoint is empty, the default Cloud KMS endpoint is used
+func NewClient(httpClient *http.Client, endpoint string, keyName string) *Client {
+	if endpoint == "" {
+		endpoint = DefaultEndpoint
+	}
+	return &Client{
+		httpClient: httpClient,
+		endpoint:   strings.TrimSuffix(endpoint, "/"),
+		keyName:    keyName,
+	}
+}
+
+// Encrypts a plaintext using the key and returns the ciphertext
+func (c *Client) Encrypt(ctx context.Context, plaintext []byte) ([]byte, error) {
+	reqBody := struct {
+		Plaintext []byte `json:"plaintext"`
+	}{plaintext}
+
+	var res struct {
+		Ciphertext []byte `json:"ciphertext"`
+	}
+	if err := c.do(ctx, "encrypt", reqBody, &res); err != nil {
+		return nil, err
+	}
+	return res.Ciphertext, nil
+}
+
+// Decrypts a ciphertext using the key and returns the plaintext
+func (c *Client) Decrypt(ctx context.Context, ciphertext []byte) (string, error) {
+	reqBody := struct {
+		Ciphertext []byte `json:"ciphertext"`
+	}{ciphertext}
+
+	var res struct {
+		Plaintext []byte `json:"plaintext"`
+	}
+	if err := c.do(ctx, "decrypt", reqBody, &res); err != nil {
+		return "", err
+	}
+	return string(res.Plaintext), nil
+}
+
+// Performs a method of the key, marshalling the request body
+// and unmarshalling the response into a given value
+// Returns an *APIError if a non-200 status code is returned
+func (c *Client) do(ctx context.Context, method string, reqBody any, v any) error {
+	payload, err := json.Marshal(reqBody)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint+"/v1/"+c.keyName+":"+method, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close() //nolint:errcheck
+
+	resBody, err := io.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+
+	if res.StatusCode != http.StatusOK {
+		apiErr := struct {
+			Error APIError `json:"error"`
+		}{}
+		_ = json.Unmarshal(resBody, &apiErr)
+		apiErr.Error.StatusCode = res.StatusCode
+		if apiErr.Error.Message == "" {
+			apiErr.Error.Message = strings.TrimSpace(string(resBody))
+		}
+		return &apiErr.Error
+	}
+
+	return json.Unmarshal(resBody, v)
+}
@@ -0,0 +1,77 @@
+package gcpkms
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/daylamtayari/cierge/reservation"
+)
+
+const testKeyName = "projects/test-project/locations/us-central1/keyRings/cierge/cryptoKeys/tokens"
+
+// Ensure that a client can be used as the decrypter of an executor
+var _ reservation.Decrypter = (*Client)(nil)
+
+// newFakeKMS starts an HTTP fake of the Cloud KMS API of the test key whose
+// encryption prefixes the plaintext so that ciphertexts can be inspected
+func newFakeKMS(t *testing.T) *Client {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Plaintext  []byte `json:"plaintext"`
+			Ciphertext []byte `json:"ciphertext"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/v1/" + testKeyName + ":encrypt":
+			_ = json.NewEncoder(w).Encode(map[string]any{"ciphertext": append([]byte("kms:"), req.Plaintext...)})
+		case "/v1/" + testKeyName + ":decrypt":
+			if !bytes.HasPrefix(req.Ciphertext, []byte("kms:")) {
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(`{"error":{"code":400,"status":"INVALID_ARGUMENT","message":"Decryption failed"}}`))
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"plaintext": bytes.TrimPrefix(req.Ciphertext, []byte("kms:"))})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return NewClient(server.Client(), server.URL+"/", testKeyName)
+}
+
+func TestClient_RoundTrip(t *testing.T) {
+	client := newFakeKMS(t)
+
+	ciphertext, err := client.Encrypt(context.Background(), []byte("secret-token"))
+	if err != nil {
+		t.Fatalf("Encrypt failed: %v", err)
+	}
+	plaintext, err := client.Decrypt(context.Background(), ciphertext)
+	if err != nil {
+		t.Fatalf("Decrypt failed: %v", err)
+	}
+	if plaintext != "secret-token" {
+		t.Errorf("plaintext: got %q, want %q", plaintext, "secret-token")
+	}
+}
+
+func TestClient_APIError(t *testing.T) {
+	client := newFakeKMS(t)
+
+	_, err := client.Decrypt(context.Background(), []byte("invalid"))
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadRequest || apiErr.Status != "INVALID_ARGUMENT" {
+		t.Errorf("expected bad request API error, got %v", err)
+	}
+}
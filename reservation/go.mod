@@ -3,8 +3,15 @@ module github.com/daylamtayari/cierge/reservation
 go 1.25.5
 
 require (
+	github.com/daylamtayari/cierge/opentable v0.0.0-00010101000000-000000000000
 	github.com/daylamtayari/cierge/resy v0.8.9
+	github.com/daylamtayari/cierge/tock v0.0.0-00010101000000-000000000000
 	github.com/google/uuid v1.6.0
 )
 
 require github.com/golang-jwt/jwt/v5 v5.3.1 // indirect
+
+replace (
+	github.com/daylamtayari/cierge/opentable => ../opentable
+	github.com/daylamtayari/cierge/tock => ../tock
+)
@@ -0,0 +1,163 @@
+package reservation
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Range of slot times matching a preference
+// Times are in seconds since midnight
+type preferenceRange struct {
+	start int
+	end   int
+	ideal int
+}
+
+// Returns the ranges of the preferred times and windows of an event in order of preference
+// Preferred times are exact ranges and are followed by the preferred windows
+// NOTE: Times are assumed to be valid, any invalid time or window is ignored
+func preferenceRanges(event Event) []preferenceRange {
+	ranges := make([]preferenceRange, 0, len(event.PreferredTimes)+len(event.PreferredWindows))
+
+	for _, preferredTime := range event.PreferredTimes {
+		t, err := parseClock(preferredTime)
+		if err != nil {
+			continue
+		}
+		ranges = append(ranges, preferenceRange{start: t, end: t, ideal: t})
+	}
+
+	for _, window := range event.PreferredWindows {
+		start, err := parseClock(window.Start)
+		if err != nil {
+			continue
+		}
+		end, ideal := start, start
+		if window.End != "" {
+			if end, err = parseClock(window.End); err != nil || end < start {
+				continue
+			}
+		}
+		if window.Ideal != "" {
+			if ideal, err = parseClock(window.Ideal); err != nil {
+				continue
+			}
+		}
+
+		tolerance := int(window.Tolerance) * 60
+		ranges = append(ranges, preferenceRange{
+			start: start - tolerance,
+			end:   end + tolerance,
+			ideal: ideal,
+		})
+	}
+
+	return ranges
+}
+
+// Returns the slots matching the preferences of an event in order of preference
+// Slots of seating types that are not allowed are excluded and slots matching a
+// preference are ranked by their seating type preference, then by their distance
+// to its ideal time, earlier slots first if equally distant
+// Slots matching multiple preferences are only included for the first preference they match
+// The slot time function returns the start time of a slot in the local time of the restaurant
+// and the slot type function returns its seating type
+func rankSlots[T any](slots []T, slotTime func(T) time.Time, slotType func(T) string, event Event) []T {
+	times := make([]int, len(slots))
+	seatingRanks := make([]int, len(slots))
+	allowed := make([]bool, len(slots))
+	for i, slot := range slots {
+		times[i] = secondOfDay(slotTime(slot))
+		seatingType := slotType(slot)
+		allowed[i] = isSeatingTypeAllowed(seatingType, event.AllowedSeatingTypes)
+		seatingRanks[i] = seatingTypeRank(seatingType, event.PreferredSeatingTypes)
+	}
+
+	matched := make([]bool, len(slots))
+	ranked := make([]T, 0)
+
+	for _, preference := range preferenceRanges(event) {
+		candidates := make([]int, 0)
+		for i := range slots {
+			if allowed[i] && !matched[i] && times[i] >= preference.start && times[i] <= preference.end {
+				candidates = append(candidates, i)
+			}
+		}
+
+		slices.SortStableFunc(candidates, func(a, b int) int {
+			if c := cmp.Compare(seatingRanks[a], seatingRanks[b]); c != 0 {
+				return c
+			}
+			distanceA := max(times[a]-preference.ideal, preference.ideal-times[a])
+			distanceB := max(times[b]-preference.ideal, preference.ideal-times[b])
+			if c := cmp.Compare(distanceA, distanceB); c != 0 {
+				return c
+			}
+			return cmp.Compare(times[a], times[b])
+		})
+
+		for _, i := range candidates {
+			matched[i] = true
+			ranked = append(ranked, slots[i])
+		}
+	}
+
+	return ranked
+}
+
+// Returns whether a seating type is allowed, all seating types being allowed if none are specified
+func isSeatingTypeAllowed(seatingType string, allowedTypes []string) bool {
+	if len(allowedTypes) == 0 {
+		return true
+	}
+	return seatingTypeRank(seatingType, allowedTypes) < len(allowedTypes)
+}
+
+// Returns the rank of a seating type in the preferred seating types
+// Seating types that are not preferred rank after all preferred seating types
+func seatingTypeRank(seatingType string, preferredTypes []string) int {
+	seatingType = strings.TrimSpace(seatingType)
+	for i, preferredType := range preferredTypes {
+		if strings.EqualFold(seatingType, strings.TrimSpace(preferredType)) {
+			return i
+		}
+	}
+	return len(preferredTypes)
+}
+
+// Parses a HH:mm time to seconds since midnight
+func parseClock(value string) (int, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, err
+	}
+	return secondOfDay(t), nil
+}
+
+// Returns the seconds since midnight of the clock of a time
+func secondOfDay(t time.Time) int {
+	h, m, s := t.Clock()
+	return h*3600 + m*60 + s
+}
+
+// Slot of a reservation date and party size of an event
+type candidate[T any] struct {
+	slot   T
+	target Alternative
+}
+
+// Combines the ranked slots of each target of an event into candidates in
+// order of preference, the slots of a target all being ranked before those
+// of the following targets
+// Targets and their ranked slots are in the same order
+func combineCandidates[T any](targets []Alternative, rankedSlots [][]T) []candidate[T] {
+	candidates := make([]candidate[T], 0)
+	for i, target := range targets {
+		for _, slot := range rankedSlots[i] {
+			candidates = append(candidates, candidate[T]{slot: slot, target: target})
+		}
+	}
+	return candidates
+}
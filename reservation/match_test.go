@@ -0,0 +1,194 @@
+package reservation
+
+import (
+	"testing"
+	"time"
+)
+
+// Returns slots at the given HH:mm times
+func clockSlots(t *testing.T, values ...string) []time.Time {
+	t.Helper()
+	slots := make([]time.Time, 0, len(values))
+	for _, value := range values {
+		slot, err := time.Parse("15:04", value)
+		if err != nil {
+			t.Fatalf("invalid slot time %q: %v", value, err)
+		}
+		slots = append(slots, slot)
+	}
+	return slots
+}
+
+func slotClock(slot time.Time) time.Time {
+	return slot
+}
+
+func slotNoType(slot time.Time) string {
+	return ""
+}
+
+func TestRankSlots(t *testing.T) {
+	slots := clockSlots(t, "17:45", "18:30", "19:00", "19:15", "19:45", "20:15", "21:00")
+
+	tests := []struct {
+		name  string
+		event Event
+		want  []string
+	}{
+		{
+			name:  "exact preferred times",
+			event: Event{PreferredTimes: []string{"19:15", "18:30", "20:00"}},
+			want:  []string{"19:15", "18:30"},
+		},
+		{
+			name:  "window ranked by distance to ideal time",
+			event: Event{PreferredWindows: []TimeWindow{{Start: "18:30", End: "20:00", Ideal: "19:30"}}},
+			want:  []string{"19:15", "19:45", "19:00", "18:30"},
+		},
+		{
+			name:  "window ideal defaults to start",
+			event: Event{PreferredWindows: []TimeWindow{{Start: "18:30", End: "19:15"}}},
+			want:  []string{"18:30", "19:00", "19:15"},
+		},
+		{
+			name:  "tolerance around a single time",
+			event: Event{PreferredWindows: []TimeWindow{{Start: "21:15", Tolerance: 15}}},
+			want:  []string{"21:00"},
+		},
+		{
+			name:  "equally distant slots ranked earlier first",
+			event: Event{PreferredWindows: []TimeWindow{{Start: "19:30", Tolerance: 15}}},
+			want:  []string{"19:15", "19:45"},
+		},
+		{
+			name: "preferred times before windows without duplicates",
+			event: Event{
+				PreferredTimes: []string{"21:00"},
+				PreferredWindows: []TimeWindow{
+					{Start: "19:00", End: "19:15"},
+					{Start: "18:00", End: "21:00", Ideal: "19:00", Tolerance: 15},
+				},
+			},
+			want: []string{"21:00", "19:00", "19:15", "18:30", "19:45", "17:45", "20:15"},
+		},
+		{
+			name:  "invalid windows are ignored",
+			event: Event{PreferredWindows: []TimeWindow{{Start: "20:00", End: "19:00"}, {Start: "7pm"}}},
+			want:  []string{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ranked := rankSlots(slots, slotClock, slotNoType, test.event)
+			if len(ranked) != len(test.want) {
+				t.Fatalf("got %d slots, want %d", len(ranked), len(test.want))
+			}
+			for i, slot := range ranked {
+				if got := slot.Format("15:04"); got != test.want[i] {
+					t.Errorf("slot %d: got %s, want %s", i, got, test.want[i])
+				}
+			}
+		})
+	}
+}
+
+// Slot at a HH:mm time with a seating type
+type seatingSlot struct {
+	time        string
+	seatingType string
+}
+
+func TestRankSlots_SeatingTypes(t *testing.T) {
+	slots := []seatingSlot{
+		{"19:00", "Bar"},
+		{"19:00", "Dining Room"},
+		{"19:15", "Patio"},
+		{"19:30", "Dining Room"},
+		{"19:30", "Bar"},
+	}
+	slotTime := func(slot seatingSlot) time.Time {
+		parsed, _ := time.Parse("15:04", slot.time)
+		return parsed
+	}
+	slotType := func(slot seatingSlot) string {
+		return slot.seatingType
+	}
+	window := []TimeWindow{{Start: "19:00", End: "19:30"}}
+
+	tests := []struct {
+		name  string
+		event Event
+		want  []seatingSlot
+	}{
+		{
+			name:  "all seating types allowed by default",
+			event: Event{PreferredWindows: window},
+			want:  slots,
+		},
+		{
+			name:  "seating types not allowed are excluded",
+			event: Event{PreferredWindows: window, AllowedSeatingTypes: []string{"dining room", "patio"}},
+			want:  []seatingSlot{{"19:00", "Dining Room"}, {"19:15", "Patio"}, {"19:30", "Dining Room"}},
+		},
+		{
+			name:  "preferred seating types ranked first in order",
+			event: Event{PreferredWindows: window, PreferredSeatingTypes: []string{"Patio", "Dining Room"}},
+			want:  []seatingSlot{{"19:15", "Patio"}, {"19:00", "Dining Room"}, {"19:30", "Dining Room"}, {"19:00", "Bar"}, {"19:30", "Bar"}},
+		},
+		{
+			name:  "preferred seating type within a preferred time",
+			event: Event{PreferredTimes: []string{"19:30", "19:00"}, PreferredSeatingTypes: []string{"Bar"}},
+			want:  []seatingSlot{{"19:30", "Bar"}, {"19:30", "Dining Room"}, {"19:00", "Bar"}, {"19:00", "Dining Room"}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ranked := rankSlots(slots, slotTime, slotType, test.event)
+			if len(ranked) != len(test.want) {
+				t.Fatalf("got %d slots, want %d", len(ranked), len(test.want))
+			}
+			for i, slot := range ranked {
+				if slot != test.want[i] {
+					t.Errorf("slot %d: got %+v, want %+v", i, slot, test.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestCombineCandidates(t *testing.T) {
+	event := Event{
+		ReservationDate: "2026-03-13",
+		PartySize:       4,
+		Alternatives: []Alternative{
+			{ReservationDate: "2026-03-14", PartySize: 4},
+			{ReservationDate: "2026-03-13", PartySize: 5},
+		},
+	}
+	targets := event.targets()
+	rankedSlots := [][]time.Time{
+		clockSlots(t, "19:00"),
+		nil,
+		clockSlots(t, "19:30", "18:30"),
+	}
+
+	candidates := combineCandidates(targets, rankedSlots)
+	want := []struct {
+		slot   string
+		target Alternative
+	}{
+		{"19:00", Alternative{ReservationDate: "2026-03-13", PartySize: 4}},
+		{"19:30", Alternative{ReservationDate: "2026-03-13", PartySize: 5}},
+		{"18:30", Alternative{ReservationDate: "2026-03-13", PartySize: 5}},
+	}
+	if len(candidates) != len(want) {
+		t.Fatalf("got %d candidates, want %d", len(candidates), len(want))
+	}
+	for i, candidate := range candidates {
+		if candidate.slot.Format("15:04") != want[i].slot || candidate.target != want[i].target {
+			t.Errorf("candidate %d: got %s %+v, want %s %+v", i, candidate.slot.Format("15:04"), candidate.target, want[i].slot, want[i].target)
+		}
+	}
+}
@@ -3,8 +3,10 @@ package reservation
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"math/rand/v2"
 	"net/http"
 	"strings"
 	"time"
@@ -14,15 +16,141 @@ var (
 	ErrUnsuccessfulStatusCode = errors.New("non-200 HTTP code returned")
 )
 
-// Notifies the server about the status of a job
-func notifyServer(ctx context.Context, serverEndpoint string, callbackSecret string, output []byte) error {
+const (
+	statusPath  = "internal/job/status"
+	startedPath = "internal/job/started"
+
+	// Maximum duration of the started notification so
+	// that it does not delay the booking
+	startedTimeout = 5 * time.Second
+)
+
+// Retry policy of the completion callback
+// Retries span a few minutes so that a successful booking is
+// still recorded if the server is briefly unavailable
+var callbackRetry = retryPolicy{
+	attempts:       6,
+	initialBackoff: time.Second,
+	maxBackoff:     time.Minute,
+}
+
+// Exponential backoff with jitter between the attempts of a request
+type retryPolicy struct {
+	attempts       int
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+}
+
+// Returns the backoff before a given retry, starting at 0
+// Half of the backoff is randomised so that retries are spread out
+func (p retryPolicy) backoff(retry int) time.Duration {
+	backoff := p.initialBackoff << retry
+	if backoff > p.maxBackoff || backoff <= 0 {
+		backoff = p.maxBackoff
+	}
+	half := backoff / 2
+	return half + rand.N(half+1)
+}
+
+// Error of a request to which the server responded with a non-200 status code
+type statusCodeError struct {
+	statusCode int
+}
+
+func (e *statusCodeError) Error() string {
+	return fmt.Sprintf("%s: %d", ErrUnsuccessfulStatusCode, e.statusCode)
+}
+
+func (e *statusCodeError) Unwrap() error {
+	return ErrUnsuccessfulStatusCode
+}
+
+// Returns whether a failed request to the server can be retried
+// Requests rejected by the server are not retried unless they
+// were rate limited or caused by a server error
+func isRetryable(err error) bool {
+	var statusErr *statusCodeError
+	if errors.As(err, &statusErr) {
+		return statusErr.statusCode == http.StatusTooManyRequests || statusErr.statusCode >= 500
+	}
+	return true
+}
+
+// Notifies the server of the output of a job using the event's callback secret
+// Failed requests are retried with exponential backoff following the callback retry policy
+// Allows the output of a job to be delivered by a process other than the
+// handler, such as when the handler was unable to notify the server itself
+func NotifyServer(ctx context.Context, event Event, output Output, decrypter Decrypter) error {
+	callbackSecret, err := decryptToken(ctx, event.EncryptedCallbackSecret, decrypter)
+	if err != nil {
+		return err
+	}
+
+	marshalledOutput, err := json.Marshal(output)
+	if err != nil {
+		return err
+	}
+	return notifyServerWithRetry(ctx, event.ServerEndpoint, statusPath, callbackSecret, marshalledOutput, callbackRetry)
+}
+
+// Notifies the server that a job has started
+// The notification is bounded by the drop time of the event
+func notifyStarted(ctx context.Context, event Event, startTime time.Time, decrypter Decrypter) error {
+	deadline := time.Now().Add(startedTimeout)
+	if event.DropTime.Before(deadline) {
+		deadline = event.DropTime
+	}
+	ctx, cancel := context.WithDeadline(ctx, deadline)
+	defer cancel()
+
+	callbackSecret, err := decryptToken(ctx, event.EncryptedCallbackSecret, decrypter)
+	if err != nil {
+		return err
+	}
+
+	marshalledStarted, err := json.Marshal(Started{
+		JobId:     event.JobID,
+		StartTime: startTime,
+	})
+	if err != nil {
+		return err
+	}
+	return notifyServer(ctx, event.ServerEndpoint, startedPath, callbackSecret, marshalledStarted)
+}
+
+// Notifies the server about a job at a given callback path, retrying
+// retryable failures until the attempts of the policy are exhausted
+// or the context is done
+func notifyServerWithRetry(ctx context.Context, serverEndpoint string, path string, callbackSecret string, payload []byte, policy retryPolicy) error {
+	var err error
+	for attempt := range policy.attempts {
+		if attempt > 0 {
+			timer := time.NewTimer(policy.backoff(attempt - 1))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return err
+			case <-timer.C:
+			}
+		}
+
+		err = notifyServer(ctx, serverEndpoint, path, callbackSecret, payload)
+		if err == nil || !isRetryable(err) || ctx.Err() != nil {
+			return err
+		}
+	}
+	return err
+}
+
+// Notifies the server about a job at a given callback path
+func notifyServer(ctx context.Context, serverEndpoint string, path string, callbackSecret string, payload []byte) error {
 	if !strings.HasSuffix(serverEndpoint, "/") {
 		serverEndpoint += "/"
 	}
 
-	reqUrl := serverEndpoint + "internal/job/status"
+	reqUrl := serverEndpoint + path
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, reqUrl, bytes.NewReader(output))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, reqUrl, bytes.NewReader(payload))
 	if err != nil {
 		return err
 	}
@@ -41,7 +169,7 @@ func notifyServer(ctx context.Context, serverEndpoint string, callbackSecret str
 	defer res.Body.Close() //nolint:errcheck
 
 	if res.StatusCode != 200 {
-		return fmt.Errorf("%w: %d", ErrUnsuccessfulStatusCode, res.StatusCode)
+		return &statusCodeError{statusCode: res.StatusCode}
 	}
 	return nil
 }
@@ -0,0 +1,144 @@
+package reservation
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// plaintextDecrypter returns the ciphertext as the plaintext
+type plaintextDecrypter struct{}
+
+func (plaintextDecrypter) Decrypt(ctx context.Context, encrypted []byte) (string, error) {
+	return string(encrypted), nil
+}
+
+func TestNotifyStarted(t *testing.T) {
+	jobID := uuid.New()
+	startTime := time.Now().UTC().Truncate(time.Second)
+
+	var received Started
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/"+startedPath {
+			t.Errorf("path: got %q, want %q", r.URL.Path, "/"+startedPath)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer secret" {
+			t.Errorf("authorization: got %q", got)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			t.Errorf("failed to decode body: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	event := Event{
+		JobID:                   jobID,
+		EncryptedCallbackSecret: base64.StdEncoding.EncodeToString([]byte("secret")),
+		ServerEndpoint:          server.URL,
+		DropTime:                time.Now().Add(time.Minute),
+	}
+	if err := notifyStarted(context.Background(), event, startTime, plaintextDecrypter{}); err != nil {
+		t.Fatalf("notifyStarted failed: %v", err)
+	}
+
+	if received.JobId != jobID || !received.StartTime.Equal(startTime) {
+		t.Errorf("unexpected started notification %+v", received)
+	}
+}
+
+func TestNotifyStarted_BoundedByDropTime(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(time.Second)
+	}))
+	defer server.Close()
+
+	event := Event{
+		JobID:                   uuid.New(),
+		EncryptedCallbackSecret: base64.StdEncoding.EncodeToString([]byte("secret")),
+		ServerEndpoint:          server.URL,
+		DropTime:                time.Now().Add(100 * time.Millisecond),
+	}
+
+	start := time.Now()
+	if err := notifyStarted(context.Background(), event, start, plaintextDecrypter{}); err == nil {
+		t.Error("expected notification to time out at the drop time")
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("notification was not bounded by the drop time, took %s", elapsed)
+	}
+}
+
+// Retry policy without meaningful backoff for tests
+var testRetry = retryPolicy{
+	attempts:       3,
+	initialBackoff: time.Millisecond,
+	maxBackoff:     time.Millisecond,
+}
+
+func TestNotifyServerWithRetry_RetriesServerErrors(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	if err := notifyServerWithRetry(context.Background(), server.URL, statusPath, "secret", []byte("{}"), testRetry); err != nil {
+		t.Fatalf("notification failed: %v", err)
+	}
+	if requests != 3 {
+		t.Errorf("requests: got %d, want 3", requests)
+	}
+}
+
+func TestNotifyServerWithRetry_DoesNotRetryRejections(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	err := notifyServerWithRetry(context.Background(), server.URL, statusPath, "secret", []byte("{}"), testRetry)
+	if !errors.Is(err, ErrUnsuccessfulStatusCode) {
+		t.Fatalf("expected unsuccessful status code error, got %v", err)
+	}
+	if requests != 1 {
+		t.Errorf("requests: got %d, want 1", requests)
+	}
+}
+
+func TestNotifyServerWithRetry_ExhaustsAttempts(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	if err := notifyServerWithRetry(context.Background(), server.URL, statusPath, "secret", []byte("{}"), testRetry); err == nil {
+		t.Fatal("expected notification to fail")
+	}
+	if requests != testRetry.attempts {
+		t.Errorf("requests: got %d, want %d", requests, testRetry.attempts)
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := retryPolicy{attempts: 10, initialBackoff: time.Second, maxBackoff: 8 * time.Second}
+	for retry, want := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 8 * time.Second} {
+		backoff := policy.backoff(retry)
+		if backoff < want/2 || backoff > want {
+			t.Errorf("retry %d: backoff %s not within [%s, %s]", retry, backoff, want/2, want)
+		}
+	}
+}
@@ -0,0 +1,439 @@
+package reservation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/daylamtayari/cierge/opentable"
+)
+
+const (
+	// OpenTable only returns the slots within a few hours of the requested time,
+	// so the availability is retrieved around times covering the preferences
+	// of the event, each covering this span before and after it
+	openTableAvailabilitySpan = 2 * time.Hour
+)
+
+var (
+	ErrNoConfirmation = errors.New("booking result has no confirmation")
+)
+
+type OpenTableClient struct {
+	client     *opentable.Client
+	httpClient *http.Client
+	tokens     opentable.Tokens
+
+	// User retrieved by the pre-booking check, whose credit cards are used for booking
+	userMu sync.Mutex
+	user   *opentable.User
+}
+
+// OpenTable slot with its time, computed from the offset of the slot
+// from the time its availability was retrieved around
+// NOTE: The slot time is in the local time of the restaurant with a UTC timezone
+type openTableSlot struct {
+	slot opentable.Slot
+	time time.Time
+}
+
+// Returns an OpenTable booking client
+func NewOpenTableClient(token string) (*OpenTableClient, error) {
+	openTableClient := OpenTableClient{}
+	// Unmarshal token string into opentable.Tokens
+	err := json.Unmarshal([]byte(token), &openTableClient.tokens)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrUnmarshalToken, err)
+	}
+
+	// Idle connections are kept so that connections opened by the warm up are reused
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConnsPerHost = maxWarmUpConnections
+	transport.IdleConnTimeout = 2 * warmUpKeepAlive
+	openTableClient.httpClient = &http.Client{
+		Timeout:   15 * time.Second,
+		Transport: transport,
+	}
+
+	openTableClient.client = opentable.NewClient(openTableClient.httpClient, openTableClient.tokens.Token, "")
+
+	return &openTableClient, nil
+}
+
+// Performs pre-booking checks for the OpenTable client
+// - Test if the token is valid
+// - Test if the payment method of the payment policy exists
+func (c *OpenTableClient) PreBookingCheck(ctx context.Context, event Event) error {
+	// Test token validity by retrieving the current user
+	user, err := c.getUser()
+	if err != nil {
+		return err
+	}
+	_, _, err = openTableCreditCard(user, event.PaymentPolicy)
+	return err
+}
+
+// Opens connections to the OpenTable API concurrently so that they are kept
+// idle and reused by the requests at the drop time
+func (c *OpenTableClient) WarmUp(ctx context.Context, event Event, connections int) error {
+	return warmUpConnections(ctx, c.httpClient, opentable.Host, connections)
+}
+
+// Returns the URL of the OpenTable API whose Date header is used as a reference clock
+func (c *OpenTableClient) DateHeaderURL() string {
+	return opentable.Host
+}
+
+// Returns a slice of matching OpenTable slot candidates of the reservation date and party
+// size of the event and its alternatives in order of preference, and an error that is nil if successful
+func (c *OpenTableClient) FetchSlots(ctx context.Context, event Event) (any, error) {
+	restaurantId, err := strconv.Atoi(event.PlatformVenueId)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get slots of each target until slots are found or the deadline after the drop time
+	deadline := event.DropTime.Add(slotDeadline)
+	targets := event.targets()
+	anchors := openTableAnchors(event)
+	targetSlots, err := getTargetSlots(ctx, targets, func(ctx context.Context, target Alternative) ([]openTableSlot, error) {
+		return getSlotsUntilDeadline(ctx, deadline, func() ([]openTableSlot, error) {
+			return c.getSlots(restaurantId, target, anchors)
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Find matching slots of each target and sort them in order of preference
+	candidates := rankOpenTableCandidates(targetSlots, targets, event)
+	if len(candidates) == 0 {
+		return nil, ErrNoMatchingSlotsFound
+	}
+
+	return candidates, nil
+}
+
+// Retrieves the slots of the reservation date and party size of the event and its alternatives
+// once, concurrently, and returns a slice of matching OpenTable slot candidates in order of preference
+// An error retrieving the slots of a target is only returned if no matching slots were found
+func (c *OpenTableClient) PollSlots(ctx context.Context, event Event) (any, error) {
+	restaurantId, err := strconv.Atoi(event.PlatformVenueId)
+	if err != nil {
+		return nil, err
+	}
+
+	targets := event.targets()
+	anchors := openTableAnchors(event)
+	targetSlots := make([][]openTableSlot, len(targets))
+	errs := make([]error, len(targets))
+	var wg sync.WaitGroup
+	for i, target := range targets {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			slots, err := c.getSlots(restaurantId, target, anchors)
+			if errors.Is(err, opentable.ErrTooManyRequests) {
+				err = fmt.Errorf("%w: %w", ErrRateLimited, err)
+			}
+			targetSlots[i] = slots
+			errs[i] = err
+		}()
+	}
+	wg.Wait()
+
+	candidates := rankOpenTableCandidates(targetSlots, targets, event)
+	if len(candidates) > 0 {
+		return candidates, nil
+	}
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return nil, ErrNoMatchingSlotsFound
+}
+
+// Books a single slot candidate and returns an Attempt
+// This method is called by the generic bookingHandler for each slot
+func (c *OpenTableClient) Book(ctx context.Context, event Event, slot any) (Attempt, error) {
+	startTime := time.Now().UTC()
+	openTableCandidate := slot.(candidate[openTableSlot])
+
+	bookingResult, err := c.bookSlot(ctx, event, openTableCandidate.slot, openTableCandidate.target)
+
+	attempt := Attempt{
+		Result:          bookingResult,
+		SlotTime:        openTableCandidate.slot.time,
+		ReservationDate: openTableCandidate.target.ReservationDate,
+		PartySize:       openTableCandidate.target.PartySize,
+		StartTime:       startTime,
+		Duration:        time.Now().UTC().Sub(startTime),
+	}
+
+	if err != nil {
+		attempt.Error = err.Error()
+		return attempt, err
+	}
+
+	return attempt, nil
+}
+
+// Cancels a booked slot using the confirmation number and security token of its confirmation
+func (c *OpenTableClient) Cancel(ctx context.Context, result BookingResult) error {
+	restaurantId, ok := confirmationInt(result.PlatformConfirmation["restaurant_id"])
+	if !ok {
+		return fmt.Errorf("%w: no restaurant ID", ErrNoConfirmation)
+	}
+	confirmationNumber, ok := confirmationInt(result.PlatformConfirmation["confirmation_number"])
+	if !ok {
+		return fmt.Errorf("%w: no confirmation number", ErrNoConfirmation)
+	}
+	securityToken, ok := result.PlatformConfirmation["security_token"].(string)
+	if !ok || securityToken == "" {
+		return fmt.Errorf("%w: no security token", ErrNoConfirmation)
+	}
+	return c.client.CancelBooking(restaurantId, confirmationNumber, securityToken)
+}
+
+// Book slots calls the generic booking handler after type asserting slots
+func (c *OpenTableClient) BookSlots(ctx context.Context, event Event, slots any) (*BookingResult, []Attempt, error) {
+	return bookingHandler(ctx, c, event, slots.([]candidate[openTableSlot]))
+}
+
+// Returns the user of the token, retrieving it if it was not retrieved yet
+func (c *OpenTableClient) getUser() (*opentable.User, error) {
+	c.userMu.Lock()
+	defer c.userMu.Unlock()
+
+	if c.user != nil {
+		return c.user, nil
+	}
+	user, err := c.client.GetUser()
+	if err != nil {
+		return nil, err
+	}
+	c.user = user
+	return user, nil
+}
+
+// Retrieves the available slots of a target around each of the anchor times concurrently
+// Slots returned around multiple anchors are only included once
+// An error is only returned if no slots were retrieved
+func (c *OpenTableClient) getSlots(restaurantId int, target Alternative, anchors []time.Duration) ([]openTableSlot, error) {
+	reservationDate, err := time.Parse("2006-01-02", target.ReservationDate)
+	if err != nil {
+		return nil, err
+	}
+
+	anchorSlots := make([][]openTableSlot, len(anchors))
+	errs := make([]error, len(anchors))
+	var wg sync.WaitGroup
+	for i, anchor := range anchors {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			availability, err := c.client.GetAvailability(restaurantId, reservationDate.Add(anchor), int(target.PartySize))
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			for _, slot := range availability.Slots {
+				anchorSlots[i] = append(anchorSlots[i], openTableSlot{slot: slot, time: availability.SlotTime(slot)})
+			}
+		}()
+	}
+	wg.Wait()
+
+	seen := make(map[string]bool)
+	slots := make([]openTableSlot, 0)
+	for _, slot := range slices.Concat(anchorSlots...) {
+		if seen[slot.slot.SlotHash] {
+			continue
+		}
+		seen[slot.slot.SlotHash] = true
+		slots = append(slots, slot)
+	}
+	if len(slots) == 0 {
+		return nil, errors.Join(errs...)
+	}
+	return slots, nil
+}
+
+// Books a given slot for the party size of a given target
+// The slot is locked and its deposit checked against the payment policy of the event before booking,
+// with the lock being released if the slot is not booked
+// Returns a BookingResult if successful or an error if not
+// Dry runs return the result of the slot without locking or booking it, so have no deposit
+func (c *OpenTableClient) bookSlot(ctx context.Context, event Event, slot openTableSlot, target Alternative) (*BookingResult, error) {
+	restaurantId, err := strconv.Atoi(event.PlatformVenueId)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &BookingResult{
+		ReservationTime: slot.time,
+		ReservationDate: target.ReservationDate,
+		PartySize:       target.PartySize,
+		SeatingType:     openTableSeatingType(slot),
+	}
+	if event.DryRun {
+		return result, nil
+	}
+
+	lock, err := c.client.LockSlot(restaurantId, slot.time, int(target.PartySize), slot.slot)
+	if err != nil {
+		return nil, err
+	}
+	confirmation, err := c.bookLock(ctx, event, restaurantId, lock, result)
+	if err != nil {
+		// The lock expires if it fails to be released, so the release error is ignored
+		c.client.ReleaseLock(restaurantId, lock) //nolint:errcheck
+		return nil, err
+	}
+
+	result.PlatformConfirmation = map[string]any{
+		"confirmation_number": confirmation.ConfirmationNumber,
+		"security_token":      confirmation.SecurityToken,
+		"reservation_id":      confirmation.ReservationId,
+		"restaurant_id":       restaurantId,
+	}
+	return result, nil
+}
+
+// Books a lock, adding its deposit to the result
+func (c *OpenTableClient) bookLock(ctx context.Context, event Event, restaurantId int, lock *opentable.SlotLock, result *BookingResult) (*opentable.BookingConfirmation, error) {
+	if lock.Deposit != nil {
+		if err := event.PaymentPolicy.checkDeposit(lock.Deposit.Amount, true); err != nil {
+			return nil, err
+		}
+		result.Deposit = &Deposit{
+			Fee:                lock.Deposit.Amount,
+			CancellationCutOff: lock.Deposit.CancellationCutOff,
+		}
+	}
+
+	// The credit card of the payment policy or the default credit card is used
+	// if the user has one, which will work fine if the slot does not require
+	// a credit card but if it does, an opentable.ErrPaymentRequired error will be returned
+	user, err := c.getUser()
+	if err != nil {
+		return nil, err
+	}
+	creditCard, found, err := openTableCreditCard(user, event.PaymentPolicy)
+	if err != nil {
+		return nil, err
+	}
+	var creditCardId *string
+	if found {
+		creditCardId = &creditCard.Id
+	}
+
+	// Check for context cancellation prior to executing booking
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	return c.client.BookReservation(restaurantId, lock, creditCardId)
+}
+
+// Returns the credit card of the payment policy if set, otherwise the default
+// credit card of the user, and whether the user has a credit card
+// Returns ErrPaymentMethodNotFound if the payment method of the policy is not one of the user's
+func openTableCreditCard(user *opentable.User, policy *PaymentPolicy) (opentable.CreditCard, bool, error) {
+	if policy == nil || policy.PaymentMethodId == nil {
+		creditCard, found := opentable.GetDefaultCreditCard(user)
+		return creditCard, found, nil
+	}
+	for _, creditCard := range user.CreditCards {
+		if creditCard.Id == strconv.Itoa(*policy.PaymentMethodId) {
+			return creditCard, true, nil
+		}
+	}
+	return opentable.CreditCard{}, false, fmt.Errorf("%w: %d", ErrPaymentMethodNotFound, *policy.PaymentMethodId)
+}
+
+// Returns the times of day around which the availability is retrieved so that the
+// preferred times and windows of the event are covered, in ascending order
+// Each time covers the availability span before and after it
+func openTableAnchors(event Event) []time.Duration {
+	ranges := preferenceRanges(event)
+	slices.SortFunc(ranges, func(a, b preferenceRange) int {
+		return a.start - b.start
+	})
+
+	span := int(openTableAvailabilitySpan.Seconds())
+	anchors := make([]time.Duration, 0)
+	covered := -1
+	for _, preference := range ranges {
+		for start := max(preference.start, covered+1); start <= preference.end; start = covered + 1 {
+			anchor := min(max(start+span, 0), 24*60*60-60)
+			anchors = append(anchors, time.Duration(anchor)*time.Second)
+			covered = anchor + span
+		}
+	}
+	return anchors
+}
+
+// Returns the matching slots of each target as candidates in order of preference
+// For upgrades, the booked slot is ranked with the slots of its target and only
+// the candidates ranked before it are returned
+func rankOpenTableCandidates(targetSlots [][]openTableSlot, targets []Alternative, event Event) []candidate[openTableSlot] {
+	var bookedSlot *openTableSlot
+	if event.Upgrade != nil {
+		slotTime, err := event.Upgrade.slotTime()
+		if err != nil {
+			return nil
+		}
+		bookedSlot = &openTableSlot{
+			slot: opentable.Slot{SlotHash: upgradeBookedSlotToken, Attributes: []string{event.Upgrade.SeatingType}},
+			time: slotTime,
+		}
+	}
+
+	rankedSlots := make([][]openTableSlot, len(targets))
+	for i, slots := range targetSlots {
+		// The booked slot is ranked before the slots ranked equally to it
+		if bookedSlot != nil && event.Upgrade.isTarget(targets[i]) {
+			slots = append([]openTableSlot{*bookedSlot}, slots...)
+		}
+		rankedSlots[i] = rankSlots(slots, func(slot openTableSlot) time.Time {
+			return slot.time
+		}, openTableSeatingType, event)
+	}
+	candidates := combineCandidates(targets, rankedSlots)
+
+	if bookedSlot == nil {
+		return candidates
+	}
+	return upgradeCandidates(candidates, func(c candidate[openTableSlot]) bool {
+		return c.slot.slot.SlotHash == upgradeBookedSlotToken
+	})
+}
+
+// Returns the seating type of an OpenTable slot, being its first attribute such as default, bar, or outdoor
+func openTableSeatingType(slot openTableSlot) string {
+	if len(slot.slot.Attributes) == 0 {
+		return ""
+	}
+	return slot.slot.Attributes[0]
+}
+
+// Returns the integer value of a platform confirmation field, which is a float if the
+// confirmation was unmarshalled from JSON, and whether the field is an integer
+func confirmationInt(value any) (int, bool) {
+	switch v := value.(type) {
+	case int:
+		return v, true
+	case float64:
+		return int(v), v == float64(int(v))
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,199 @@
+package reservation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/daylamtayari/cierge/opentable"
+)
+
+const (
+	// Time of day around which the availability of a date is retrieved to discover seating types
+	openTableSeatingTypeTime = 19 * time.Hour
+)
+
+// OpenTable platform adapter
+type openTablePlatform struct {
+	// Unauthenticated client used for requests not requiring a user
+	client *opentable.Client
+}
+
+func newOpenTablePlatform() *openTablePlatform {
+	return &openTablePlatform{
+		client: opentable.NewClient(nil, "", ""),
+	}
+}
+
+func (p *openTablePlatform) DisplayName() string {
+	return "OpenTable"
+}
+
+// OpenTable accounts have no password and users login with a code sent to their email
+func (p *openTablePlatform) LoginMethod() LoginMethod {
+	return LoginMethodCode
+}
+
+func (p *openTablePlatform) SendLoginCode(ctx context.Context, email string) error {
+	return p.client.SendLoginCode(email)
+}
+
+func (p *openTablePlatform) Login(ctx context.Context, credentials Credentials) (any, error) {
+	tokens, err := p.client.Login(credentials.Email, strings.TrimSpace(credentials.Code))
+	if errors.Is(err, opentable.ErrUnauthorized) {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidCredentials, err)
+	} else if err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+func (p *openTablePlatform) ParseToken(token []byte) (any, error) {
+	var tokens opentable.Tokens
+	if err := json.Unmarshal(token, &tokens); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrUnmarshalToken, err)
+	}
+	if tokens.Token == "" {
+		return nil, fmt.Errorf("%w: no token", ErrUnmarshalToken)
+	}
+	return tokens, nil
+}
+
+// Returns the expiry of OpenTable tokens
+// OpenTable tokens are not JWTs so their expiry is the one returned
+// when they were issued, and the expiry of refresh tokens is unknown
+func (p *openTablePlatform) TokenExpiry(token any) (TokenExpiry, error) {
+	tokens, ok := token.(opentable.Tokens)
+	if !ok {
+		return TokenExpiry{}, ErrIncorrectToken
+	}
+
+	expiry := TokenExpiry{
+		HasRefresh: tokens.Refresh != "",
+	}
+	if !tokens.Expiry.IsZero() {
+		expiresAt := tokens.Expiry.UTC()
+		expiry.ExpiresAt = &expiresAt
+	}
+	return expiry, nil
+}
+
+func (p *openTablePlatform) RefreshToken(ctx context.Context, token any) (any, error) {
+	tokens, ok := token.(opentable.Tokens)
+	if !ok {
+		return nil, ErrIncorrectToken
+	}
+	return p.client.RefreshToken(tokens.Refresh)
+}
+
+func (p *openTablePlatform) HasPaymentMethod(ctx context.Context, token any) (bool, error) {
+	tokens, ok := token.(opentable.Tokens)
+	if !ok {
+		return false, ErrIncorrectToken
+	}
+
+	user, err := opentable.NewClient(nil, tokens.Token, "").GetUser()
+	if err != nil {
+		return false, err
+	}
+	return len(user.CreditCards) > 0, nil
+}
+
+// Searches OpenTable restaurants matching a query
+// NOTE: OpenTable ranks results by their distance to coordinates, and as
+// venues are searched without a location, results are not ranked by distance
+func (p *openTablePlatform) SearchVenues(ctx context.Context, query string) ([]Venue, error) {
+	results, err := p.client.Search(query, 0, 0, true)
+	if err != nil {
+		return nil, err
+	}
+
+	venues := make([]Venue, 0, len(results))
+	for _, result := range results {
+		venues = append(venues, Venue{
+			Id:           strconv.Itoa(result.Id),
+			Name:         result.Name,
+			Neighborhood: result.Neighborhood,
+			City:         result.MetroName,
+			Region:       result.MacroName,
+		})
+	}
+	return venues, nil
+}
+
+func (p *openTablePlatform) GetVenue(ctx context.Context, venueId string) (*Venue, error) {
+	restaurantId, err := strconv.Atoi(venueId)
+	if err != nil {
+		return nil, err
+	}
+	restaurant, err := p.client.GetRestaurant(restaurantId)
+	if errors.Is(err, opentable.ErrNotFound) {
+		return nil, fmt.Errorf("%w: %w", ErrVenueNotFound, err)
+	} else if err != nil {
+		return nil, err
+	}
+
+	venue := &Venue{
+		Id:           venueId,
+		Name:         restaurant.Name,
+		Neighborhood: restaurant.Neighborhood.Name,
+		City:         restaurant.Address.City,
+		Region:       restaurant.Address.State,
+	}
+
+	address := strings.TrimSpace(restaurant.Address.Line1 + " " + restaurant.Address.Line2)
+	if address != "" {
+		venue.Address = &address
+	}
+
+	if restaurant.TimeZone != "" {
+		location, err := time.LoadLocation(restaurant.TimeZone)
+		if err != nil {
+			return nil, err
+		}
+		venue.Timezone = location
+	}
+
+	return venue, nil
+}
+
+// Retrieves the seating types of the slots of the upcoming dates of an OpenTable restaurant
+// The availability of each date is only retrieved around the evening, so seating
+// types only available at other times of the day are not discovered
+func (p *openTablePlatform) GetSeatingTypes(ctx context.Context, venueId string, partySize int) ([]string, error) {
+	restaurantId, err := strconv.Atoi(venueId)
+	if err != nil {
+		return nil, err
+	}
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	seatingTypes := make([]string, 0)
+	sampled := 0
+	for day := 0; day < seatingTypeSearchDays && sampled < seatingTypeSampleDates; day++ {
+		availability, err := p.client.GetAvailability(restaurantId, today.AddDate(0, 0, day).Add(openTableSeatingTypeTime), partySize)
+		if err != nil {
+			return nil, err
+		}
+		if len(availability.Slots) == 0 {
+			continue
+		}
+		sampled++
+
+		for _, slot := range availability.Slots {
+			seatingType := strings.TrimSpace(openTableSeatingType(openTableSlot{slot: slot}))
+			if seatingType != "" && !slices.Contains(seatingTypes, seatingType) {
+				seatingTypes = append(seatingTypes, seatingType)
+			}
+		}
+	}
+	return seatingTypes, nil
+}
+
+func (p *openTablePlatform) NewBookingClient(token string) (BookingClient, error) {
+	return NewOpenTableClient(token)
+}
@@ -0,0 +1,290 @@
+package reservation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/daylamtayari/cierge/opentable"
+)
+
+// Fake OpenTable API of a restaurant with slots at given times of a date
+// Slots listed in deposits require a deposit of the given amount
+type fakeOpenTable struct {
+	date     string
+	times    []string
+	deposits map[string]float32
+
+	mu        sync.Mutex
+	locks     map[int]string
+	released  []string
+	booked    []string
+	cancelled []string
+}
+
+// Returns an OpenTable booking client whose requests are sent to the fake
+func newFakeOpenTableClient(t *testing.T, fake *fakeOpenTable) *OpenTableClient {
+	t.Helper()
+	fake.locks = map[int]string{}
+
+	httpClient := newFakeHTTPClient(t, fake)
+	return &OpenTableClient{
+		client:     opentable.NewClient(httpClient, "token", ""),
+		httpClient: httpClient,
+	}
+}
+
+func (f *fakeOpenTable) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch {
+	case r.URL.Path == "/api/v1/users/me":
+		w.Write([]byte(`{"gpid":"1","creditCards":[{"id":"7","isDefault":true}]}`)) //nolint:errcheck
+
+	case r.URL.Path == "/api/v3/restaurant/availability":
+		var req struct {
+			DateTime string `json:"dateTime"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		requested, _ := time.Parse(opentable.DateTimeFormat, req.DateTime)
+		var slots []map[string]any
+		for _, slotTime := range f.times {
+			start, _ := time.Parse(opentable.DateTimeFormat, f.date+"T"+slotTime)
+			offset := start.Sub(requested)
+			if requested.Format("2006-01-02") != f.date || offset.Abs() > 150*time.Minute {
+				continue
+			}
+			slots = append(slots, map[string]any{
+				"isAvailable":           true,
+				"timeOffsetMinutes":     int(offset.Minutes()),
+				"slotHash":              slotTime,
+				"slotAvailabilityToken": "token-" + slotTime,
+				"attributes":            []string{"default"},
+			})
+		}
+		json.NewEncoder(w).Encode(map[string]any{ //nolint:errcheck
+			"availability": []map[string]any{{
+				"restaurantId":     1234,
+				"availabilityDays": []map[string]any{{"dayOffset": 0, "slots": slots}},
+			}},
+		})
+
+	case r.URL.Path == "/api/v1/reservation/1234/lock":
+		var req struct {
+			SlotHash string `json:"hash"`
+		}
+		json.NewDecoder(r.Body).Decode(&req) //nolint:errcheck
+		lockId := len(f.locks) + 1
+		f.locks[lockId] = req.SlotHash
+		lock := map[string]any{"id": lockId}
+		if amount, ok := f.deposits[req.SlotHash]; ok {
+			lock["creditCardRequired"] = true
+			lock["deposit"] = map[string]any{"amount": amount, "cancellationCutoffDate": "2026-11-19T19:00:00Z"}
+		}
+		json.NewEncoder(w).Encode(lock) //nolint:errcheck
+
+	case r.URL.Path == "/api/v1/reservation/1234":
+		var req struct {
+			LockId   int     `json:"slotLockId"`
+			DateTime string  `json:"dateTime"`
+			CardId   *string `json:"creditCardId"`
+		}
+		json.NewDecoder(r.Body).Decode(&req) //nolint:errcheck
+		if _, ok := f.locks[req.LockId]; !ok {
+			http.Error(w, `{"message":"lock expired"}`, http.StatusConflict)
+			return
+		}
+		f.booked = append(f.booked, req.DateTime)
+		json.NewEncoder(w).Encode(map[string]any{ //nolint:errcheck
+			"id":                 99,
+			"confirmationNumber": 5000 + len(f.booked),
+			"securityToken":      "security",
+			"dateTime":           req.DateTime,
+		})
+
+	case r.Method == http.MethodDelete && strings.HasPrefix(r.URL.Path, "/api/v1/reservation/1234/lock/"):
+		lockId, _ := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/api/v1/reservation/1234/lock/"))
+		slotHash, ok := f.locks[lockId]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		delete(f.locks, lockId)
+		f.released = append(f.released, slotHash)
+		w.WriteHeader(http.StatusNoContent)
+
+	case r.Method == http.MethodDelete && strings.HasPrefix(r.URL.Path, "/api/v3/reservation/1234/"):
+		if r.URL.Query().Get("securityToken") != "security" {
+			http.NotFound(w, r)
+			return
+		}
+		f.cancelled = append(f.cancelled, strings.TrimPrefix(r.URL.Path, "/api/v3/reservation/1234/"))
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// Returns the reservation date and HH:mm time of OpenTable candidates
+func openTableCandidateTimes(candidates []candidate[openTableSlot]) []string {
+	times := make([]string, 0, len(candidates))
+	for _, c := range candidates {
+		times = append(times, c.slot.time.Format("2006-01-02 15:04"))
+	}
+	return times
+}
+
+func TestOpenTableAnchors(t *testing.T) {
+	tests := []struct {
+		name  string
+		event Event
+		want  []time.Duration
+	}{
+		{
+			name:  "preferences within a span",
+			event: Event{PreferredTimes: []string{"20:00", "19:00", "21:30"}},
+			want:  []time.Duration{21 * time.Hour},
+		},
+		{
+			name:  "preferences across the day",
+			event: Event{PreferredTimes: []string{"12:00", "19:00"}},
+			want:  []time.Duration{14 * time.Hour, 21 * time.Hour},
+		},
+		{
+			name:  "window longer than a span",
+			event: Event{PreferredWindows: []TimeWindow{{Start: "12:00", End: "20:00"}}},
+			want:  []time.Duration{14 * time.Hour, 18*time.Hour + time.Second},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := openTableAnchors(test.event); !slices.Equal(got, test.want) {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestOpenTableClient_FetchAndBook(t *testing.T) {
+	fake := &fakeOpenTable{date: "2026-11-20", times: []string{"17:30", "18:00", "19:15", "21:00"}}
+	client := newFakeOpenTableClient(t, fake)
+	event := Event{
+		PlatformVenueId: "1234",
+		ReservationDate: "2026-11-20",
+		PartySize:       2,
+		PreferredTimes:  []string{"21:00", "18:00"},
+		DropTime:        time.Now(),
+	}
+
+	if err := client.PreBookingCheck(context.Background(), event); err != nil {
+		t.Fatalf("pre-booking check failed: %v", err)
+	}
+	slots, err := client.FetchSlots(context.Background(), event)
+	if err != nil {
+		t.Fatalf("FetchSlots failed: %v", err)
+	}
+	candidates := slots.([]candidate[openTableSlot])
+	if got, want := openTableCandidateTimes(candidates), []string{"2026-11-20 21:00", "2026-11-20 18:00"}; !slices.Equal(got, want) {
+		t.Fatalf("candidates: got %v, want %v", got, want)
+	}
+
+	result, attempts, err := client.BookSlots(context.Background(), event, slots)
+	if err != nil {
+		t.Fatalf("BookSlots failed: %v", err)
+	}
+	if len(attempts) != 1 || !slices.Equal(fake.booked, []string{"2026-11-20T21:00"}) {
+		t.Errorf("booked %v with %d attempts, want 21:00 with a single attempt", fake.booked, len(attempts))
+	}
+	if result.PlatformConfirmation["confirmation_number"] != 5001 || result.PlatformConfirmation["security_token"] != "security" {
+		t.Errorf("unexpected confirmation %v", result.PlatformConfirmation)
+	}
+
+	// Confirmations are cancelled after being stored as JSON by the server
+	stored, _ := json.Marshal(result.PlatformConfirmation)
+	var confirmation map[string]any
+	json.Unmarshal(stored, &confirmation) //nolint:errcheck
+	if err := client.Cancel(context.Background(), BookingResult{PlatformConfirmation: confirmation}); err != nil {
+		t.Errorf("Cancel failed: %v", err)
+	}
+	if !slices.Equal(fake.cancelled, []string{"5001"}) {
+		t.Errorf("cancelled %v, want confirmation 5001", fake.cancelled)
+	}
+	if err := client.Cancel(context.Background(), BookingResult{}); !errors.Is(err, ErrNoConfirmation) {
+		t.Errorf("cancel without confirmation: got %v, want %v", err, ErrNoConfirmation)
+	}
+}
+
+func TestOpenTableClient_DepositPolicy(t *testing.T) {
+	fake := &fakeOpenTable{date: "2026-11-20", times: []string{"19:00", "20:00"}, deposits: map[string]float32{"19:00": 50}}
+	client := newFakeOpenTableClient(t, fake)
+	maxDeposit := float32(20)
+	event := Event{
+		PlatformVenueId: "1234",
+		ReservationDate: "2026-11-20",
+		PartySize:       2,
+		PreferredTimes:  []string{"19:00", "20:00"},
+		PaymentPolicy:   &PaymentPolicy{MaxDeposit: &maxDeposit},
+		Strategy:        &Strategy{Name: StrategySequential},
+		DropTime:        time.Now(),
+	}
+
+	slots, err := client.PollSlots(context.Background(), event)
+	if err != nil {
+		t.Fatalf("PollSlots failed: %v", err)
+	}
+	result, attempts, err := client.BookSlots(context.Background(), event, slots)
+	if err != nil {
+		t.Fatalf("BookSlots failed: %v", err)
+	}
+	if len(attempts) != 2 || !strings.Contains(attempts[0].Error, ErrDepositExceedsLimit.Error()) {
+		t.Errorf("got %d attempts, want the first failing as its deposit exceeds the limit", len(attempts))
+	}
+	if result.ReservationTime.Format("15:04") != "20:00" || result.Deposit != nil {
+		t.Errorf("booked %s with deposit %v, want 20:00 without deposit", result.ReservationTime.Format("15:04"), result.Deposit)
+	}
+	// The lock of the slot whose deposit exceeds the limit is released
+	if !slices.Equal(fake.released, []string{"19:00"}) {
+		t.Errorf("released locks of %v, want 19:00", fake.released)
+	}
+}
+
+func TestOpenTableClient_PreBookingCheckPaymentMethod(t *testing.T) {
+	client := newFakeOpenTableClient(t, &fakeOpenTable{})
+	paymentMethodId := 8
+	event := Event{PaymentPolicy: &PaymentPolicy{PaymentMethodId: &paymentMethodId}}
+
+	if err := client.PreBookingCheck(context.Background(), event); !errors.Is(err, ErrPaymentMethodNotFound) {
+		t.Errorf("got %v, want %v", err, ErrPaymentMethodNotFound)
+	}
+}
+
+func TestRankOpenTableCandidates_Upgrade(t *testing.T) {
+	slotAt := func(value string) openTableSlot {
+		slotTime, _ := time.Parse("2006-01-02 15:04", "2026-11-20 "+value)
+		return openTableSlot{slot: opentable.Slot{SlotHash: value, Attributes: []string{"default"}}, time: slotTime}
+	}
+	targets := []Alternative{{ReservationDate: "2026-11-20", PartySize: 2}}
+	targetSlots := [][]openTableSlot{{slotAt("18:00"), slotAt("19:00"), slotAt("20:00")}}
+	event := Event{
+		PreferredTimes: []string{"20:00", "19:00", "18:00"},
+		Upgrade:        &Upgrade{ReservationDate: "2026-11-20", PartySize: 2, SlotTime: "19:00", SeatingType: "default"},
+	}
+
+	got := openTableCandidateTimes(rankOpenTableCandidates(targetSlots, targets, event))
+	if !slices.Equal(got, []string{"2026-11-20 20:00"}) {
+		t.Errorf("got %v, want only the more preferred 20:00 slot", got)
+	}
+}
@@ -0,0 +1,93 @@
+package reservation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+const outboxFileSuffix = ".output.json"
+
+// Secondary sink of the outputs of jobs that could not be delivered
+// to the server, allowing the server to ingest them at a later time
+type Outbox interface {
+	Store(ctx context.Context, output Output) error
+}
+
+// Outbox storing the output of each job as a JSON file in a directory
+// The directory must be shared with the server for it to ingest the outputs
+type FileOutbox struct {
+	dir string
+}
+
+// Creates a file outbox, creating its directory if it does not exist
+func NewFileOutbox(dir string) (*FileOutbox, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	return &FileOutbox{dir: dir}, nil
+}
+
+// Stores the output of a job, replacing any previously stored output of the job
+func (o *FileOutbox) Store(ctx context.Context, output Output) error {
+	payload, err := json.Marshal(output)
+	if err != nil {
+		return err
+	}
+
+	// Written to a temporary file first so that a partially
+	// written output is never read by the server
+	outputFile := o.path(output.JobId)
+	tmpFile := outputFile + ".tmp"
+	if err := os.WriteFile(tmpFile, payload, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmpFile, outputFile)
+}
+
+// Returns all outputs stored in the outbox
+// Files that are not valid outputs are ignored
+func (o *FileOutbox) List() ([]Output, error) {
+	entries, err := os.ReadDir(o.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	outputs := make([]Output, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), outboxFileSuffix) {
+			continue
+		}
+
+		payload, err := os.ReadFile(filepath.Join(o.dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		var output Output
+		if err := json.Unmarshal(payload, &output); err != nil || output.JobId == uuid.Nil {
+			continue
+		}
+		outputs = append(outputs, output)
+	}
+	return outputs, nil
+}
+
+// Removes the stored output of a job
+// Removing an output that is not stored is not an error
+func (o *FileOutbox) Remove(jobID uuid.UUID) error {
+	err := os.Remove(o.path(jobID))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// Returns the path of the file containing the output of a job
+func (o *FileOutbox) path(jobID uuid.UUID) string {
+	return filepath.Join(o.dir, jobID.String()+outboxFileSuffix)
+}
@@ -0,0 +1,87 @@
+package reservation
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestFileOutbox(t *testing.T) {
+	outbox, err := NewFileOutbox(filepath.Join(t.TempDir(), "outbox"))
+	if err != nil {
+		t.Fatalf("failed to create outbox: %v", err)
+	}
+
+	output := Output{JobId: uuid.New(), Success: true, Message: "reservation completed successfully"}
+	if err := outbox.Store(context.Background(), output); err != nil {
+		t.Fatalf("failed to store output: %v", err)
+	}
+	// Files that are not outputs are ignored
+	if err := os.WriteFile(filepath.Join(outbox.dir, "other.json"), []byte("{}"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	outputs, err := outbox.List()
+	if err != nil {
+		t.Fatalf("failed to list outputs: %v", err)
+	}
+	if len(outputs) != 1 || outputs[0].JobId != output.JobId || !outputs[0].Success {
+		t.Fatalf("unexpected outputs %+v", outputs)
+	}
+
+	if err := outbox.Remove(output.JobId); err != nil {
+		t.Fatalf("failed to remove output: %v", err)
+	}
+	if err := outbox.Remove(output.JobId); err != nil {
+		t.Errorf("removing a missing output failed: %v", err)
+	}
+	if outputs, _ := outbox.List(); len(outputs) != 0 {
+		t.Errorf("expected empty outbox, got %d outputs", len(outputs))
+	}
+}
+
+func TestHandle_StoresUndeliveredOutputInOutbox(t *testing.T) {
+	defaultRetry := callbackRetry
+	callbackRetry = testRetry
+	t.Cleanup(func() { callbackRetry = defaultRetry })
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	outbox, err := NewFileOutbox(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create outbox: %v", err)
+	}
+
+	// The unknown platform fails the job before any request to a platform is made
+	event := Event{
+		JobID:                   uuid.New(),
+		Platform:                "unknown",
+		EncryptedToken:          base64.StdEncoding.EncodeToString([]byte("token")),
+		EncryptedCallbackSecret: base64.StdEncoding.EncodeToString([]byte("secret")),
+		ServerEndpoint:          server.URL,
+		DropTime:                time.Now(),
+		Callback:                true,
+	}
+	output := Handle(context.Background(), event, plaintextDecrypter{}, WithOutbox(outbox))
+	if output.Notified {
+		t.Fatal("expected the server to not be notified")
+	}
+
+	outputs, err := outbox.List()
+	if err != nil {
+		t.Fatalf("failed to list outputs: %v", err)
+	}
+	if len(outputs) != 1 || outputs[0].JobId != event.JobID {
+		t.Fatalf("unexpected outputs %+v", outputs)
+	}
+}
@@ -0,0 +1,53 @@
+package reservation
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrDepositRequired       = errors.New("slot requires a deposit")
+	ErrDepositExceedsLimit   = errors.New("slot deposit exceeds the maximum deposit")
+	ErrDepositUnknown        = errors.New("slot deposit fee is unknown")
+	ErrPaymentMethodNotFound = errors.New("payment method not found")
+)
+
+// Payment policy of an event
+// Slots requiring a deposit above the maximum deposit are not booked, a maximum
+// deposit of 0 never booking slots requiring a deposit, and any deposit is
+// allowed if no maximum deposit is set
+// Slots requiring a deposit of unknown fee are only booked if no maximum deposit is set
+// The payment method is required for booking if set, otherwise the default
+// payment method of the user is used if they have one
+type PaymentPolicy struct {
+	MaxDeposit      *float32 `json:"max_deposit,omitempty"`
+	PaymentMethodId *int     `json:"payment_method_id,omitempty"`
+}
+
+// Deposit required by a booked slot
+// NOTE: Cancellation cut off is the time until which the reservation can be
+// cancelled without forfeiting the deposit, as reported by the platform
+type Deposit struct {
+	Fee                float32 `json:"fee"`
+	CancellationCutOff string  `json:"cancellation_cut_off,omitempty"`
+}
+
+// Returns an error if a slot of a given deposit fee cannot be booked
+// The fee of a slot requiring a deposit is unknown if it is not positive, such
+// slots only being booked if the policy allows any deposit
+// A nil policy allows any deposit
+func (p *PaymentPolicy) checkDeposit(fee float32, required bool) error {
+	if !required || p == nil || p.MaxDeposit == nil {
+		return nil
+	}
+	if *p.MaxDeposit <= 0 {
+		return fmt.Errorf("%w: deposit of %.2f", ErrDepositRequired, fee)
+	}
+	if fee <= 0 {
+		return fmt.Errorf("%w: maximum deposit of %.2f cannot be enforced", ErrDepositUnknown, *p.MaxDeposit)
+	}
+	if fee > *p.MaxDeposit {
+		return fmt.Errorf("%w: deposit of %.2f exceeds %.2f", ErrDepositExceedsLimit, fee, *p.MaxDeposit)
+	}
+	return nil
+}
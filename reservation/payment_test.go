@@ -0,0 +1,78 @@
+package reservation
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/daylamtayari/cierge/resy"
+)
+
+func TestPaymentPolicyCheckDeposit(t *testing.T) {
+	zero := float32(0)
+	limit := float32(50)
+
+	tests := []struct {
+		name    string
+		policy   *PaymentPolicy
+		fee      float32
+		required bool
+		wantErr  error
+	}{
+		{name: "no policy allows any deposit", policy: nil, fee: 200, required: true},
+		{name: "no maximum deposit allows any deposit", policy: &PaymentPolicy{}, fee: 200, required: true},
+		{name: "no maximum deposit allows unknown deposits", policy: &PaymentPolicy{}, required: true},
+		{name: "zero maximum deposit allows slots without deposit", policy: &PaymentPolicy{MaxDeposit: &zero}},
+		{name: "zero maximum deposit rejects deposits", policy: &PaymentPolicy{MaxDeposit: &zero}, fee: 10, required: true, wantErr: ErrDepositRequired},
+		{name: "zero maximum deposit rejects unknown deposits", policy: &PaymentPolicy{MaxDeposit: &zero}, required: true, wantErr: ErrDepositRequired},
+		{name: "deposit within maximum", policy: &PaymentPolicy{MaxDeposit: &limit}, fee: 50, required: true},
+		{name: "deposit above maximum", policy: &PaymentPolicy{MaxDeposit: &limit}, fee: 75, required: true, wantErr: ErrDepositExceedsLimit},
+		{name: "unknown deposit with maximum", policy: &PaymentPolicy{MaxDeposit: &limit}, required: true, wantErr: ErrDepositUnknown},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.policy.checkDeposit(test.fee, test.required)
+			if test.wantErr == nil && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			} else if !errors.Is(err, test.wantErr) {
+				t.Errorf("got error %v, want %v", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestPaymentPolicyCheckDeposit_PaidSlotWithoutFee(t *testing.T) {
+	zero := float32(0)
+	payment := resy.SlotPayment{IsPaid: true}
+
+	fee, required := payment.Deposit()
+	err := (&PaymentPolicy{MaxDeposit: &zero}).checkDeposit(fee, required)
+	if !errors.Is(err, ErrDepositRequired) {
+		t.Errorf("paid slot without fee: got %v, want %v", err, ErrDepositRequired)
+	}
+}
+
+func TestResyPaymentMethod(t *testing.T) {
+	user := &resy.User{
+		PaymentMethods: []resy.PaymentMethod{
+			{Id: 1, IsDefault: false},
+			{Id: 2, IsDefault: true},
+		},
+	}
+
+	paymentMethod, err := resyPaymentMethod(user, nil)
+	if err != nil || paymentMethod.Id != 2 {
+		t.Errorf("default payment method: got %d, %v, want 2", paymentMethod.Id, err)
+	}
+
+	id := 1
+	paymentMethod, err = resyPaymentMethod(user, &PaymentPolicy{PaymentMethodId: &id})
+	if err != nil || paymentMethod.Id != 1 {
+		t.Errorf("policy payment method: got %d, %v, want 1", paymentMethod.Id, err)
+	}
+
+	missing := 3
+	if _, err := resyPaymentMethod(user, &PaymentPolicy{PaymentMethodId: &missing}); !errors.Is(err, ErrPaymentMethodNotFound) {
+		t.Errorf("missing payment method: got %v, want %v", err, ErrPaymentMethodNotFound)
+	}
+}
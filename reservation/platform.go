@@ -0,0 +1,148 @@
+package reservation
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+)
+
+var (
+	ErrDuplicatePlatform      = errors.New("platform register called twice for the same platform")
+	ErrNilPlatform            = errors.New("platform register adapter is nil")
+	ErrIncorrectToken         = errors.New("token is not a token of the platform")
+	ErrInvalidCredentials     = errors.New("incorrect platform credentials")
+	ErrLoginMethodUnsupported = errors.New("login method is not supported by the platform")
+	ErrRefreshUnsupported     = errors.New("platform does not issue refresh tokens")
+	ErrVenueNotFound          = errors.New("venue does not exist on the platform")
+)
+
+// Method used by users to login to a platform
+type LoginMethod string
+
+const (
+	// Users login with their email and password
+	LoginMethodPassword LoginMethod = "password"
+	// Users login with their email and a one-time code sent to it
+	LoginMethodCode LoginMethod = "code"
+)
+
+// Platform defines the interface that all reservation platform adapters must implement
+// Tokens are values of the token type of the platform, such as resy.Tokens, that
+// are stored as JSON and are the token booking clients are created with
+type Platform interface {
+	// Returns the name of the platform displayed to users
+	DisplayName() string
+
+	// Returns the method used by users to login to the platform
+	LoginMethod() LoginMethod
+
+	// Sends a one-time login code to the email of a user
+	// Returns ErrLoginMethodUnsupported if the platform does not login with codes
+	SendLoginCode(ctx context.Context, email string) error
+
+	// Logs in to the platform and returns the tokens of the user
+	// Returns an error wrapping ErrInvalidCredentials if the credentials are incorrect
+	Login(ctx context.Context, credentials Credentials) (any, error)
+
+	// Unmarshals tokens stored or provided as JSON
+	ParseToken(token []byte) (any, error)
+
+	// Returns the expiry and refresh metadata of tokens
+	// Returns ErrIncorrectToken if the tokens are not tokens of the platform
+	TokenExpiry(token any) (TokenExpiry, error)
+
+	// Uses the refresh token of tokens to retrieve new tokens
+	// Returns ErrRefreshUnsupported if the platform does not issue refresh tokens
+	RefreshToken(ctx context.Context, token any) (any, error)
+
+	// Returns whether the user of tokens has a payment method saved on the platform,
+	// without which slots requiring a deposit cannot be booked
+	HasPaymentMethod(ctx context.Context, token any) (bool, error)
+
+	// Searches the venues of the platform matching a query
+	SearchVenues(ctx context.Context, query string) ([]Venue, error)
+
+	// Retrieves the details of a venue, including its timezone, from its platform ID
+	// Returns an error wrapping ErrVenueNotFound if the venue does not exist
+	GetVenue(ctx context.Context, venueId string) (*Venue, error)
+
+	// Retrieves the seating types of the slots of the upcoming available dates of a venue
+	GetSeatingTypes(ctx context.Context, venueId string, partySize int) ([]string, error)
+
+	// Returns a booking client authenticated with tokens marshalled as JSON
+	NewBookingClient(token string) (BookingClient, error)
+}
+
+// Credentials used to login to a platform
+// The password is used by platforms logging in with passwords
+// and the code by platforms logging in with one-time codes
+type Credentials struct {
+	Email    string `json:"email"`
+	Password string `json:"password,omitempty"`
+	Code     string `json:"code,omitempty"`
+}
+
+// Expiry and refresh metadata of platform tokens
+// Expiry times are nil if unknown
+type TokenExpiry struct {
+	ExpiresAt        *time.Time
+	HasRefresh       bool
+	RefreshExpiresAt *time.Time
+}
+
+// Venue of a platform
+// Search results only contain the details returned by the search of the platform
+type Venue struct {
+	Id           string  `json:"id"`
+	Name         string  `json:"name"`
+	Address      *string `json:"address,omitempty"`
+	Neighborhood string  `json:"neighborhood,omitempty"`
+	City         string  `json:"city,omitempty"`
+	Region       string  `json:"region,omitempty"`
+	// Timezone of the venue, nil if unknown
+	Timezone *time.Location `json:"-"`
+}
+
+// A map of platforms
+// The key value is always lower case
+var platformRegistry = make(map[string]Platform)
+
+// The platforms supported by the reservation engine register themselves
+func init() {
+	Register("resy", newResyPlatform())           //nolint:errcheck
+	Register("opentable", newOpenTablePlatform()) //nolint:errcheck
+	Register("tock", newTockPlatform())           //nolint:errcheck
+}
+
+// Registers a platform adapter
+func Register(name string, platform Platform) error {
+	if platform == nil {
+		return ErrNilPlatform
+	}
+	name = strings.ToLower(name)
+	if _, exists := platformRegistry[name]; exists {
+		return ErrDuplicatePlatform
+	}
+
+	platformRegistry[name] = platform
+
+	return nil
+}
+
+// Returns the adapter of a platform
+// Platform names are case insensitive
+func GetPlatform(name string) (Platform, error) {
+	platform, exists := platformRegistry[strings.ToLower(name)]
+	if !exists {
+		return nil, ErrUnsupportedPlatform
+	}
+	return platform, nil
+}
+
+// Returns a sorted slice of all available platforms
+func AvailablePlatforms() []string {
+	return slices.Sorted(maps.Keys(platformRegistry))
+}
@@ -0,0 +1,156 @@
+package reservation
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/daylamtayari/cierge/opentable"
+	"github.com/daylamtayari/cierge/resy"
+	"github.com/daylamtayari/cierge/tock"
+)
+
+func TestRegister(t *testing.T) {
+	if err := Register("resy", newResyPlatform()); !errors.Is(err, ErrDuplicatePlatform) {
+		t.Errorf("duplicate platform: got %v, want %v", err, ErrDuplicatePlatform)
+	}
+	if err := Register("test", nil); !errors.Is(err, ErrNilPlatform) {
+		t.Errorf("nil platform: got %v, want %v", err, ErrNilPlatform)
+	}
+
+	platform := newOpenTablePlatform()
+	if err := Register("Test", platform); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	t.Cleanup(func() { delete(platformRegistry, "test") })
+
+	for _, name := range []string{"test", "TEST"} {
+		if got, err := GetPlatform(name); err != nil || got != platform {
+			t.Errorf("%s: got %v with error %v, want the registered platform", name, got, err)
+		}
+	}
+	if !slices.Equal(AvailablePlatforms(), []string{"opentable", "resy", "test", "tock"}) {
+		t.Errorf("available platforms: got %v", AvailablePlatforms())
+	}
+}
+
+func TestNewBookingClient_UnsupportedPlatform(t *testing.T) {
+	if _, err := NewBookingClient("unknown", "{}"); !errors.Is(err, ErrUnsupportedPlatform) {
+		t.Errorf("got %v, want %v", err, ErrUnsupportedPlatform)
+	}
+}
+
+func TestResyPlatform_Token(t *testing.T) {
+	platform := newResyPlatform()
+
+	token, err := platform.ParseToken([]byte(`{"Token":"token","Refresh":"refresh"}`))
+	if err != nil {
+		t.Fatalf("ParseToken failed: %v", err)
+	}
+	if tokens := token.(resy.Tokens); tokens.ApiKey != resy.DefaultApiKey {
+		t.Errorf("API key: got %q, want the default API key", tokens.ApiKey)
+	}
+	if _, err := platform.ParseToken([]byte(`[]`)); !errors.Is(err, ErrUnmarshalToken) {
+		t.Errorf("invalid token: got %v, want %v", err, ErrUnmarshalToken)
+	}
+	if _, err := platform.TokenExpiry(opentable.Tokens{}); !errors.Is(err, ErrIncorrectToken) {
+		t.Errorf("token of another platform: got %v, want %v", err, ErrIncorrectToken)
+	}
+}
+
+func TestOpenTablePlatform_TokenExpiry(t *testing.T) {
+	platform := newOpenTablePlatform()
+	expiry := time.Date(2026, 11, 20, 19, 0, 0, 0, time.UTC)
+
+	got, err := platform.TokenExpiry(opentable.Tokens{Token: "token", Refresh: "refresh", Expiry: expiry})
+	if err != nil {
+		t.Fatalf("TokenExpiry failed: %v", err)
+	}
+	if got.ExpiresAt == nil || !got.ExpiresAt.Equal(expiry) || !got.HasRefresh || got.RefreshExpiresAt != nil {
+		t.Errorf("got %+v, want expiring at %s with a refresh token of unknown expiry", got, expiry)
+	}
+
+	got, err = platform.TokenExpiry(opentable.Tokens{Token: "token"})
+	if err != nil || got.ExpiresAt != nil || got.HasRefresh {
+		t.Errorf("token without expiry or refresh: got %+v with error %v", got, err)
+	}
+	if _, err := platform.TokenExpiry(resy.Tokens{}); !errors.Is(err, ErrIncorrectToken) {
+		t.Errorf("token of another platform: got %v, want %v", err, ErrIncorrectToken)
+	}
+}
+
+func TestOpenTablePlatform_GetVenue(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v3/restaurant/1234" {
+			http.Error(w, `{"message":"restaurant not found"}`, http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{"restaurantId":1234,"name":"Test Bistro","neighborhood":{"name":"Chelsea"},` + //nolint:errcheck
+			`"address":{"line1":"1 Main St","city":"New York","state":"NY"},"timeZone":"America/New_York"}`))
+	})
+	platform := &openTablePlatform{
+		client: opentable.NewClient(newFakeHTTPClient(t, handler), "", ""),
+	}
+
+	venue, err := platform.GetVenue(context.Background(), "1234")
+	if err != nil {
+		t.Fatalf("GetVenue failed: %v", err)
+	}
+	if venue.Name != "Test Bistro" || venue.Neighborhood != "Chelsea" || venue.Region != "NY" || venue.Address == nil || *venue.Address != "1 Main St" {
+		t.Errorf("unexpected venue %+v", venue)
+	}
+	if venue.Timezone == nil || venue.Timezone.String() != "America/New_York" {
+		t.Errorf("timezone: got %v, want America/New_York", venue.Timezone)
+	}
+
+	if _, err := platform.GetVenue(context.Background(), "1"); !errors.Is(err, ErrVenueNotFound) {
+		t.Errorf("unknown venue: got %v, want %v", err, ErrVenueNotFound)
+	}
+}
+
+func TestTockPlatform_Token(t *testing.T) {
+	platform := newTockPlatform()
+	expiry := time.Date(2026, 12, 20, 19, 0, 0, 0, time.UTC)
+
+	token, err := platform.ParseToken([]byte(`{"Token":"token","Expiry":"2026-12-20T19:00:00Z"}`))
+	if err != nil {
+		t.Fatalf("ParseToken failed: %v", err)
+	}
+	got, err := platform.TokenExpiry(token)
+	if err != nil {
+		t.Fatalf("TokenExpiry failed: %v", err)
+	}
+	if got.ExpiresAt == nil || !got.ExpiresAt.Equal(expiry) || got.HasRefresh {
+		t.Errorf("got %+v, want expiring at %s without a refresh token", got, expiry)
+	}
+
+	if _, err := platform.ParseToken([]byte(`{}`)); !errors.Is(err, ErrUnmarshalToken) {
+		t.Errorf("token without session: got %v, want %v", err, ErrUnmarshalToken)
+	}
+	if _, err := platform.RefreshToken(context.Background(), token); !errors.Is(err, ErrRefreshUnsupported) {
+		t.Errorf("refresh: got %v, want %v", err, ErrRefreshUnsupported)
+	}
+}
+
+func TestTockPlatform_Venues(t *testing.T) {
+	platform := &tockPlatform{
+		client: tock.NewClient(newFakeHTTPClient(t, &fakeTock{}), "", ""),
+	}
+
+	venue, err := platform.GetVenue(context.Background(), "test-kitchen")
+	if err != nil {
+		t.Fatalf("GetVenue failed: %v", err)
+	}
+	if venue.Name != "Test Kitchen" || venue.Neighborhood != "West Loop" || venue.Region != "IL" || venue.Address == nil || *venue.Address != "1 Randolph St" {
+		t.Errorf("unexpected venue %+v", venue)
+	}
+	if venue.Timezone == nil || venue.Timezone.String() != "America/Chicago" {
+		t.Errorf("timezone: got %v, want America/Chicago", venue.Timezone)
+	}
+	if _, err := platform.GetVenue(context.Background(), "unknown"); !errors.Is(err, ErrVenueNotFound) {
+		t.Errorf("unknown venue: got %v, want %v", err, ErrVenueNotFound)
+	}
+}
@@ -3,7 +3,6 @@ package reservation
 import (
 	"context"
 	"encoding/base64"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"time"
@@ -20,29 +19,64 @@ var (
 	ErrDecrypt      = errors.New("failed to decrypt token")
 )
 
+// Option configuring the reservation handler
+type Option func(*options)
+
+type options struct {
+	outbox    Outbox
+	ntpServer string
+}
+
+// Stores the output of the job in an outbox if the server could not be notified
+func WithOutbox(outbox Outbox) Option {
+	return func(o *options) {
+		o.outbox = outbox
+	}
+}
+
+// Measures the offset of the local clock against an NTP server, instead of the
+// Date header of the platform, to correct the wait until the drop time
+func WithNTPServer(server string) Option {
+	return func(o *options) {
+		o.ntpServer = server
+	}
+}
+
 // Main handler of the reservation job and handles the core logic
 // - Decrypts token
 // - Creates booking client
 // - Performs pre-booking checks
-// - Performs booking
+// - Notifies the server that the job has started
+// - Measures the offset of the local clock to correct the drop time
+// - Warms up the booking client and performs the early polls before the drop
+// - Performs booking, or polls for slots until one is booked for watch jobs
 // Returns an Output type representing the output of the reservation job
-func Handle(ctx context.Context, event Event, decrypter Decrypter) Output {
+func Handle(ctx context.Context, event Event, decrypter Decrypter, opts ...Option) Output {
 	startTime := time.Now().UTC()
 
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
 	output := Output{
-		JobId:     event.JobID,
-		StartTime: startTime,
+		JobId:         event.JobID,
+		StartTime:     startTime,
+		PaymentPolicy: event.PaymentPolicy,
+		DryRun:        event.DryRun,
 	}
 
 	ctx = context.WithValue(ctx, startTimeKey, startTime)
 
+	phaseStart := time.Now()
 	token, err := decryptToken(ctx, event.EncryptedToken, decrypter)
+	output.Timings.Decrypt = time.Since(phaseStart)
 	if err != nil {
 		output.Message = "failed to decrypt token"
 		output.Success = false
 		output.Error = err.Error()
 		output.Level = "error"
-		return complete(ctx, event, output, decrypter)
+		return complete(ctx, event, output, decrypter, o)
 	}
 
 	bookingClient, err := NewBookingClient(event.Platform, token)
@@ -51,49 +85,130 @@ func Handle(ctx context.Context, event Event, decrypter Decrypter) Output {
 		output.Success = false
 		output.Error = err.Error()
 		output.Level = "error"
-		return complete(ctx, event, output, decrypter)
+		return complete(ctx, event, output, decrypter, o)
 	}
 
+	phaseStart = time.Now()
 	err = bookingClient.PreBookingCheck(ctx, event)
+	output.Timings.PreBookingCheck = time.Since(phaseStart)
 	if err != nil {
 		output.Message = "failed to perform pre-booking checks"
 		output.Success = false
 		output.Error = err.Error()
 		output.Level = "error"
-		return complete(ctx, event, output, decrypter)
+		return complete(ctx, event, output, decrypter, o)
+	}
+
+	// Upgrades book a slot before cancelling the booked slot so
+	// are only performed if it can be cancelled without a fee
+	if event.Upgrade != nil {
+		if err := event.Upgrade.check(bookingClient); err != nil {
+			output.Message = "booked slot cannot be upgraded"
+			output.Success = false
+			output.Error = err.Error()
+			output.Level = "error"
+			return complete(ctx, event, output, decrypter, o)
+		}
+		event.Watch = event.Upgrade.limitWatch(event.Watch)
+	}
+
+	if event.Callback {
+		// The completion callback is authoritative so failing to
+		// notify the server that the job started is not fatal
+		output.StartNotified = notifyStarted(ctx, event, startTime, decrypter) == nil
+	}
+
+	// The drop time is converted to the local clock so that waiting for it does not
+	// depend on the accuracy of the local clock, failing to measure the offset
+	// falling back to the local clock
+	offset, source, err := measureClockOffset(ctx, o.ntpServer, bookingClient)
+	output.ClockOffsetNs = offset.Nanoseconds()
+	output.ClockSource = source
+	if err != nil {
+		output.ClockError = err.Error()
 	}
+	event.DropTime, output.ClockCorrected = correctDropTime(event.DropTime, offset, source)
 
-	waitUntil(ctx, event.DropTime)
+	// Slots found by an early poll are booked without waiting for the drop time
+	var slots any
+	var earlySlots bool
+	if !event.DryRun {
+		slots, earlySlots = warmUp(ctx, bookingClient, event, &output)
+	}
+	if !earlySlots {
+		waitUntil(ctx, event.DropTime)
+	}
 	output.BookingStart = time.Now().UTC()
 	output.DriftNs = time.Since(event.DropTime).Nanoseconds()
 
-	slots, err := bookingClient.FetchSlots(ctx, event)
+	if event.Watch != nil && !event.DryRun {
+		bookingResult, err := watch(ctx, bookingClient, event, &output)
+		if err != nil {
+			output.Message = "failed to book a slot while watching"
+			output.Success = false
+			output.Error = err.Error()
+			output.Level = "error"
+			return complete(ctx, event, output, decrypter, o)
+		}
+		return completeBooking(ctx, bookingClient, event, output, *bookingResult, decrypter, o)
+	}
+
+	// Dry runs retrieve the slots once as they are usually run before the drop
+	phaseStart = time.Now()
+	switch {
+	case earlySlots:
+		// Already retrieved by an early poll
+	case event.DryRun:
+		slots, err = bookingClient.PollSlots(ctx, event)
+	default:
+		slots, err = bookingClient.FetchSlots(ctx, event)
+	}
+	output.Timings.FetchSlots = time.Since(phaseStart)
 	if err != nil {
 		output.Message = "failed to retrieve slots"
 		output.Success = false
 		output.Error = err.Error()
 		output.Level = "error"
-		return complete(ctx, event, output, decrypter)
+		return complete(ctx, event, output, decrypter, o)
 	}
 
+	phaseStart = time.Now()
 	bookingResult, attempts, err := bookingClient.BookSlots(ctx, event, slots)
 	output.BookingAttempts = attempts
+	output.Timings.Booking = time.Since(phaseStart)
 
 	if err != nil {
 		output.Message = "failed to perform booking"
 		output.Success = false
 		output.Error = err.Error()
 		output.Level = "error"
-		return complete(ctx, event, output, decrypter)
+		return complete(ctx, event, output, decrypter, o)
 	}
 
-	output.ReservationTime = bookingResult.ReservationTime
-	output.PlatformConfirmation = bookingResult.PlatformConfirmation
+	return completeBooking(ctx, bookingClient, event, output, *bookingResult, decrypter, o)
+}
+
+// Exit handler of a successful booking
+// The booked slot of an upgrade is cancelled once the more preferred slot is booked
+func completeBooking(ctx context.Context, client BookingClient, event Event, output Output, bookingResult BookingResult, decrypter Decrypter, o options) Output {
+	output.BookingResult = bookingResult
 	output.Success = true
 	output.Message = "reservation completed successfully"
 	output.Level = "info"
+	switch {
+	case event.DryRun:
+		output.Message = "dry run found a slot that would have been booked"
+	case event.Upgrade != nil:
+		output.Upgrade = cancelUpgraded(ctx, client, event)
+		if output.Upgrade.Cancelled {
+			output.Message = "upgraded reservation and cancelled the booked slot"
+		} else {
+			output.Message = "upgraded reservation but failed to cancel the booked slot"
+			output.Level = "warn"
+		}
+	}
 
-	return complete(ctx, event, output, decrypter)
+	return complete(ctx, event, output, decrypter, o)
 }
 
 // Decrypts the users token using the Decrypter interface
@@ -113,7 +228,8 @@ func decryptToken(ctx context.Context, encryptedToken string, decrypter Decrypte
 
 // Exit handler of the Lambda
 // Calculates duration, notifies server of output, and outputs job output to stdout
-func complete(ctx context.Context, event Event, output Output, decrypter Decrypter) Output {
+// If the server could not be notified, the output is stored in the outbox if one is configured
+func complete(ctx context.Context, event Event, output Output, decrypter Decrypter, o options) Output {
 	if startTime, ok := ctx.Value(startTimeKey).(time.Time); ok {
 		output.Duration = time.Now().UTC().Sub(startTime)
 	} else {
@@ -124,24 +240,27 @@ func complete(ctx context.Context, event Event, output Output, decrypter Decrypt
 		return output
 	}
 
-	callbackSecret, err := decryptToken(ctx, event.EncryptedCallbackSecret, decrypter)
-	if err != nil {
-		// Keep success as true if the reservation completed
-		// as that is the core goal of this lambda and the
-		// output will still be sent to stdout
+	// Keep success as true if the reservation completed
+	// as that is the core goal of this lambda and the
+	// output will still be sent to stdout
+	err := NotifyServer(ctx, event, output, decrypter)
+	if errors.Is(err, ErrDecrypt) || errors.Is(err, ErrBase64Decode) {
 		output.Message += " - error: failed to decrypt token"
 		output.Error = err.Error()
 		output.Level = "error"
+	} else if err != nil {
+		output.Message += " - error: failed to notify server"
+		output.Error = err.Error()
+		output.Level = "error"
 	} else {
-		marshalledOutput, _ := json.Marshal(output)
-		err = notifyServer(ctx, event.ServerEndpoint, callbackSecret, marshalledOutput)
-		if err != nil {
-			// Keep success as true if the reservation completed
-			// as that is the core goal of this lambda and the
-			// output will still be sent to stdout
-			output.Message += " - error: failed to notify server"
-			output.Error = err.Error()
-			output.Level = "error"
+		output.Notified = true
+	}
+
+	if !output.Notified && o.outbox != nil {
+		if err := o.outbox.Store(ctx, output); err != nil {
+			output.Message += " - error: failed to store output in outbox"
+		} else {
+			output.Message += " - output stored in outbox"
 		}
 	}
 
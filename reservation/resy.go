@@ -7,20 +7,41 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/daylamtayari/cierge/resy"
 )
 
+const (
+	// Number of the most preferred slots whose details are retrieved concurrently before booking
+	prefetchedSlotDetails = 3
+	// Token of the booked slot of an upgrade when ranked with the slots of its target
+	upgradeBookedSlotToken = "upgrade-booked-slot"
+)
+
 var (
 	ErrNoMatchingSlotsFound = errors.New("no slots matching the preferred times found")
 	ErrNoSlotsFound         = errors.New("no reservation slots found")
 	ErrUnmarshalToken       = errors.New("failed to unmarshal token")
+	ErrNoReservationToken   = errors.New("booking result has no reservation token")
 )
 
 type ResyClient struct {
-	client *resy.Client
-	tokens resy.Tokens
+	client     *resy.Client
+	httpClient *http.Client
+	tokens     resy.Tokens
+
+	// Slot details being retrieved ahead of the booking of their slot
+	prefetchMu sync.Mutex
+	prefetched map[string]*slotDetailsResult
+}
+
+// Result of the retrieval of the details of a slot, available once done is closed
+type slotDetailsResult struct {
+	done    chan struct{}
+	details *resy.SlotDetails
+	err     error
 }
 
 // Returns a Resy booking client
@@ -32,62 +53,129 @@ func NewResyClient(token string) (*ResyClient, error) {
 		return nil, fmt.Errorf("%w: %w", ErrUnmarshalToken, err)
 	}
 
-	httpClient := &http.Client{
-		Timeout: 15 * time.Second,
+	// Idle connections are kept so that connections opened by the warm up are reused
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConnsPerHost = maxWarmUpConnections
+	transport.IdleConnTimeout = 2 * warmUpKeepAlive
+	resyClient.httpClient = &http.Client{
+		Timeout:   15 * time.Second,
+		Transport: transport,
 	}
 
-	resyClient.client = resy.NewClient(httpClient, resyClient.tokens, "")
+	resyClient.client = resy.NewClient(resyClient.httpClient, resyClient.tokens, "")
 
 	return &resyClient, nil
 }
 
 // Performs pre-booking checks for the Resy client
 // - Test if the tokens are valid
+// - Test if the payment method of the payment policy exists
 func (c *ResyClient) PreBookingCheck(ctx context.Context, event Event) error {
 	// Test token validity by retrieving the current user
-	_, err := c.client.GetUser()
+	user, err := c.client.GetUser()
 	if err != nil {
 		return err
 	}
-	return nil
+	_, err = resyPaymentMethod(user, event.PaymentPolicy)
+	return err
+}
+
+// Opens connections to the Resy API concurrently so that they are kept
+// idle and reused by the requests at the drop time
+// Warming up again before the connections expire keeps them alive
+func (c *ResyClient) WarmUp(ctx context.Context, event Event, connections int) error {
+	return warmUpConnections(ctx, c.httpClient, resy.Host, connections)
+}
+
+// Returns the URL of the Resy API whose Date header is used as a reference clock
+func (c *ResyClient) DateHeaderURL() string {
+	return resy.Host
 }
 
-// Returns a slice of matching resy.Slot and an error that is nil if successful
+// Returns a slice of matching Resy slot candidates of the reservation date and party size
+// of the event and its alternatives in order of preference, and an error that is nil if successful
 func (c *ResyClient) FetchSlots(ctx context.Context, event Event) (any, error) {
 	venueId, err := strconv.Atoi(event.PlatformVenueId)
 	if err != nil {
 		return nil, err
 	}
 
-	// Get slots
-	slotDeadline := 30 * time.Second
-	slots, err := c.getSlotsUntilDeadline(ctx, event, venueId, slotDeadline)
+	// Get slots of each target until slots are found or the deadline after the drop time
+	deadline := event.DropTime.Add(slotDeadline)
+	targets := event.targets()
+	targetSlots, err := getTargetSlots(ctx, targets, func(ctx context.Context, target Alternative) ([]resy.Slot, error) {
+		return getSlotsUntilDeadline(ctx, deadline, func() ([]resy.Slot, error) {
+			slots, _, err := c.client.GetSlots(venueId, target.ReservationDate, int(target.PartySize))
+			return slots, err
+		})
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	// Find matching slots and sort them in order of preference
-	matchingSlots := matchSlots(slots, event.PreferredTimes)
-	if len(matchingSlots) == 0 {
+	// Find matching slots of each target and sort them in order of preference
+	candidates := rankResyCandidates(targetSlots, targets, event)
+	if len(candidates) == 0 {
 		return nil, ErrNoMatchingSlotsFound
 	}
 
-	return matchingSlots, nil
+	return candidates, nil
+}
+
+// Retrieves the slots of the reservation date and party size of the event and its alternatives
+// once, concurrently, and returns a slice of matching Resy slot candidates in order of preference
+// An error retrieving the slots of a target is only returned if no matching slots were found
+func (c *ResyClient) PollSlots(ctx context.Context, event Event) (any, error) {
+	venueId, err := strconv.Atoi(event.PlatformVenueId)
+	if err != nil {
+		return nil, err
+	}
+
+	targets := event.targets()
+	targetSlots := make([][]resy.Slot, len(targets))
+	errs := make([]error, len(targets))
+	var wg sync.WaitGroup
+	for i, target := range targets {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			slots, _, err := c.client.GetSlots(venueId, target.ReservationDate, int(target.PartySize))
+			if errors.Is(err, resy.ErrTooManyRequests) {
+				err = fmt.Errorf("%w: %w", ErrRateLimited, err)
+			}
+			targetSlots[i] = slots
+			errs[i] = err
+		}()
+	}
+	wg.Wait()
+
+	candidates := rankResyCandidates(targetSlots, targets, event)
+	if len(candidates) > 0 {
+		return candidates, nil
+	}
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return nil, ErrNoMatchingSlotsFound
 }
 
-// Books a single slot and returns an Attempt
+// Books a single slot candidate and returns an Attempt
 // This method is called by the generic bookingHandler for each slot
 func (c *ResyClient) Book(ctx context.Context, event Event, slot any) (Attempt, error) {
 	startTime := time.Now().UTC()
-	resySlot := slot.(resy.Slot)
+	resyCandidate := slot.(candidate[resy.Slot])
 
-	bookingResult, err := c.bookSlot(ctx, resySlot, int(event.PartySize))
+	bookingResult, err := c.bookSlot(ctx, event, resyCandidate.slot, resyCandidate.target)
 
 	attempt := Attempt{
-		Result:    bookingResult,
-		SlotTime:  resySlot.Date.Start.Time,
-		StartTime: startTime,
-		Duration:  time.Now().UTC().Sub(startTime),
+		Result:          bookingResult,
+		SlotTime:        resyCandidate.slot.Date.Start.Time,
+		ReservationDate: resyCandidate.target.ReservationDate,
+		PartySize:       resyCandidate.target.PartySize,
+		StartTime:       startTime,
+		Duration:        time.Now().UTC().Sub(startTime),
 	}
 
 	if err != nil {
@@ -99,35 +187,114 @@ func (c *ResyClient) Book(ctx context.Context, event Event, slot any) (Attempt,
 	return attempt, nil
 }
 
+// Cancels a booked slot using the reservation token of its confirmation
+func (c *ResyClient) Cancel(ctx context.Context, result BookingResult) error {
+	reservationToken, ok := result.PlatformConfirmation["resy_token"].(string)
+	if !ok || reservationToken == "" {
+		return ErrNoReservationToken
+	}
+	return c.client.CancelBooking(reservationToken, nil)
+}
+
 // Book slots calls the generic booking handler after type asserting slots
+// The details of the most preferred slots are retrieved concurrently beforehand
+// so that their booking does not wait for their details
 func (c *ResyClient) BookSlots(ctx context.Context, event Event, slots any) (*BookingResult, []Attempt, error) {
-	resySlots := slots.([]resy.Slot)
-	return bookingHandler(ctx, c, event, resySlots)
+	resyCandidates := slots.([]candidate[resy.Slot])
+	c.prefetchSlotDetails(resyCandidates[:min(prefetchedSlotDetails, len(resyCandidates))])
+	return bookingHandler(ctx, c, event, resyCandidates)
+}
+
+// Retrieves the details of slot candidates concurrently, replacing any previously retrieved details
+func (c *ResyClient) prefetchSlotDetails(candidates []candidate[resy.Slot]) {
+	c.prefetchMu.Lock()
+	defer c.prefetchMu.Unlock()
+
+	c.prefetched = make(map[string]*slotDetailsResult, len(candidates))
+	for _, resyCandidate := range candidates {
+		result := &slotDetailsResult{done: make(chan struct{})}
+		c.prefetched[slotDetailsKey(resyCandidate.slot, resyCandidate.target)] = result
+		go func() {
+			defer close(result.done)
+			result.details, result.err = c.client.GetSlotDetails(resyCandidate.slot.Config.Token, resyCandidate.slot.Date.Start.UTC().Format("2006-01-02"), int(resyCandidate.target.PartySize))
+		}()
+	}
 }
 
-// Books a given slot for a given party size
+// Returns the details of a slot for the party size of a target, waiting for
+// prefetched details if they are being retrieved and retrieving them otherwise
+// Prefetched details are only used once as their booking token is consumed by the booking
+func (c *ResyClient) slotDetails(ctx context.Context, slot resy.Slot, target Alternative) (*resy.SlotDetails, error) {
+	key := slotDetailsKey(slot, target)
+	c.prefetchMu.Lock()
+	result, ok := c.prefetched[key]
+	delete(c.prefetched, key)
+	c.prefetchMu.Unlock()
+
+	if ok {
+		select {
+		case <-result.done:
+			return result.details, result.err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	return c.client.GetSlotDetails(slot.Config.Token, slot.Date.Start.UTC().Format("2006-01-02"), int(target.PartySize))
+}
+
+// Returns the key of the details of a slot for the party size of a target
+func slotDetailsKey(slot resy.Slot, target Alternative) string {
+	return slot.Config.Token + "|" + strconv.Itoa(int(target.PartySize))
+}
+
+// Books a given slot for the party size of a given target
+// The deposit of the slot is checked against the payment policy of the event before booking
 // Returns a BookingResult if successful or an error if not
-// If an ErrNotFound is returned, that is due to the slot no longer being available
-func (c *ResyClient) bookSlot(ctx context.Context, slot resy.Slot, partySize int) (*BookingResult, error) {
-	// Get the slot details to get the booking token
-	slotDetails, err := c.client.GetSlotDetails(slot.Config.Token, slot.Date.Start.UTC().Format("2006-01-02"), partySize)
+// Dry runs return the result of the slot without booking it
+func (c *ResyClient) bookSlot(ctx context.Context, event Event, slot resy.Slot, target Alternative) (*BookingResult, error) {
+	// Get the slot details to get the booking token and payment
+	slotDetails, err := c.slotDetails(ctx, slot, target)
 	if err != nil {
 		return nil, err
 	}
 
+	var deposit *Deposit
+	if fee, required := slotDetails.Payment.Deposit(); required {
+		if err := event.PaymentPolicy.checkDeposit(fee, required); err != nil {
+			return nil, err
+		}
+		deposit = &Deposit{Fee: fee}
+		if slotDetails.Payment.TimeCancelCutOff != nil {
+			deposit.CancellationCutOff = *slotDetails.Payment.TimeCancelCutOff
+		}
+	}
+
 	var bookingConfirmation *resy.BookingConfirmation
 
-	// If no payment methods are configured, book without, otherwise get
-	// default payment method and use that for booking
+	// If no payment methods are configured, book without, otherwise use the
+	// payment method of the policy or the default payment method for booking
 	// This will work fine if the restaurant does not require a deposit
 	// but if it does, a resy.ErrPaymentRequired error will be returned
-	paymentMethod := resy.GetDefaultPaymentMethod(&slotDetails.User)
+	paymentMethod, err := resyPaymentMethod(&slotDetails.User, event.PaymentPolicy)
+	if err != nil {
+		return nil, err
+	}
 
 	// Check for context cancellation prior to executing booking
 	if ctx.Err() != nil {
 		return nil, ctx.Err()
 	}
 
+	if event.DryRun {
+		return &BookingResult{
+			ReservationTime: slot.Date.Start.Time,
+			ReservationDate: target.ReservationDate,
+			PartySize:       target.PartySize,
+			SeatingType:     slot.Config.Type,
+			Deposit:         deposit,
+		}, nil
+	}
+
 	if (paymentMethod == resy.PaymentMethod{}) {
 		bookingConfirmation, err = c.client.BookReservation(slotDetails.BookingToken.Value, nil)
 	} else {
@@ -140,6 +307,10 @@ func (c *ResyClient) bookSlot(ctx context.Context, slot resy.Slot, partySize int
 
 	return &BookingResult{
 		ReservationTime: slot.Date.Start.Time,
+		ReservationDate: target.ReservationDate,
+		PartySize:       target.PartySize,
+		SeatingType:     slot.Config.Type,
+		Deposit:         deposit,
 		PlatformConfirmation: map[string]any{
 			"resy_token":     bookingConfirmation.ReservationToken,
 			"reservation_id": bookingConfirmation.ReservationId,
@@ -148,62 +319,62 @@ func (c *ResyClient) bookSlot(ctx context.Context, slot resy.Slot, partySize int
 	}, nil
 }
 
-// Retrieves slots with a 0.05s pause between requests until either slots are found or the deadline after the drop time is expired
-// This is to handle if there is a slight delay in the API in marking slots as available after the drop time and ensuring this does
-// not cause the lambda to fail
-func (c *ResyClient) getSlotsUntilDeadline(ctx context.Context, event Event, venueId int, deadline time.Duration) ([]resy.Slot, error) {
-	deadlineTime := event.DropTime.Add(deadline)
-	pauseDuration := 50 * time.Millisecond // 0.05s
-
-	for {
-		if time.Now().UTC().After(deadlineTime) {
-			return nil, ErrNoSlotsFound
-		}
-
-		// Handle context cancellation
-		if ctx.Err() != nil {
-			return nil, ctx.Err()
+// Returns the payment method of the payment policy if set, otherwise the default
+// payment method of the user which is empty if the user has none
+// Returns ErrPaymentMethodNotFound if the payment method of the policy is not one of the user's
+func resyPaymentMethod(user *resy.User, policy *PaymentPolicy) (resy.PaymentMethod, error) {
+	if policy == nil || policy.PaymentMethodId == nil {
+		return resy.GetDefaultPaymentMethod(user), nil
+	}
+	for _, paymentMethod := range user.PaymentMethods {
+		if paymentMethod.Id == *policy.PaymentMethodId {
+			return paymentMethod, nil
 		}
+	}
+	return resy.PaymentMethod{}, fmt.Errorf("%w: %d", ErrPaymentMethodNotFound, *policy.PaymentMethodId)
+}
 
-		slots, _, err := c.client.GetSlots(venueId, event.ReservationDate, int(event.PartySize))
+// Returns the matching slots of each target as candidates in order of preference
+// For upgrades, the booked slot is ranked with the slots of its target and only
+// the candidates ranked before it are returned
+func rankResyCandidates(targetSlots [][]resy.Slot, targets []Alternative, event Event) []candidate[resy.Slot] {
+	var bookedSlot *resy.Slot
+	if event.Upgrade != nil {
+		slotTime, err := event.Upgrade.slotTime()
 		if err != nil {
-			// Exit if an error is returned in the request
-			return nil, err
+			return nil
 		}
-
-		if len(slots) > 0 {
-			return slots, nil
+		bookedSlot = &resy.Slot{
+			Config: resy.SlotConfig{Token: upgradeBookedSlotToken, Type: event.Upgrade.SeatingType},
+			Date:   resy.SlotDate{Start: resy.ResyDatetime{Time: slotTime}},
 		}
-
-		time.Sleep(pauseDuration)
 	}
-}
-
-// Accepts a slice of slots representing valid reservation slots, and an ordered slice of
-// time objects that represent the preferred slot times in order of preference.
-// Returns a slice of matching slots in order of preference
-// NOTE: Preferred times string values must be in format "HH:mm" and are
-// assumed to be accurate when parsed
-func matchSlots(slots []resy.Slot, preferredTimes []string) []resy.Slot {
 
-	slotsByTime := make(map[[3]int]resy.Slot)
-	for _, slot := range slots {
-		h, m, s := slot.Date.Start.Clock()
-		key := [3]int{h, m, s}
-		slotsByTime[key] = slot
+	rankedSlots := make([][]resy.Slot, len(targets))
+	for i, slots := range targetSlots {
+		// The booked slot is ranked before the slots ranked equally to it, such as
+		// other tables of the same time, as they are not more preferred
+		if bookedSlot != nil && event.Upgrade.isTarget(targets[i]) {
+			slots = append([]resy.Slot{*bookedSlot}, slots...)
+		}
+		rankedSlots[i] = matchSlots(slots, event)
 	}
+	candidates := combineCandidates(targets, rankedSlots)
 
-	matchingSlots := make([]resy.Slot, 0)
-
-	for _, preferredTime := range preferredTimes {
-		preferredT, _ := time.Parse("15:04", preferredTime)
-		h, m, _ := preferredT.Clock()
-		key := [3]int{h, m, 0}
-
-		if matchingSlot, exists := slotsByTime[key]; exists {
-			matchingSlots = append(matchingSlots, matchingSlot)
-		}
+	if bookedSlot == nil {
+		return candidates
 	}
+	return upgradeCandidates(candidates, func(c candidate[resy.Slot]) bool {
+		return c.slot.Config.Token == upgradeBookedSlotToken
+	})
+}
 
-	return matchingSlots
+// Returns the slots matching the preferred times, windows, and seating types of the event in order of preference
+// NOTE: Resy slot times are in the local time of the restaurant
+func matchSlots(slots []resy.Slot, event Event) []resy.Slot {
+	return rankSlots(slots, func(slot resy.Slot) time.Time {
+		return slot.Date.Start.Time
+	}, func(slot resy.Slot) string {
+		return slot.Config.Type
+	}, event)
 }
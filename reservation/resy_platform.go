@@ -0,0 +1,219 @@
+package reservation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/daylamtayari/cierge/resy"
+)
+
+const (
+	// Number of days ahead searched for available dates to discover seating types from
+	seatingTypeSearchDays = 30
+	// Maximum number of available dates whose slots are retrieved to discover seating types
+	seatingTypeSampleDates = 3
+)
+
+// Resy platform adapter
+type resyPlatform struct {
+	// Client authenticated with only the API key used for requests not requiring a user
+	client *resy.Client
+}
+
+func newResyPlatform() *resyPlatform {
+	return &resyPlatform{
+		client: resy.NewClient(nil, resy.Tokens{ApiKey: resy.DefaultApiKey}, ""),
+	}
+}
+
+func (p *resyPlatform) DisplayName() string {
+	return "Resy"
+}
+
+func (p *resyPlatform) LoginMethod() LoginMethod {
+	return LoginMethodPassword
+}
+
+func (p *resyPlatform) SendLoginCode(ctx context.Context, email string) error {
+	return ErrLoginMethodUnsupported
+}
+
+func (p *resyPlatform) Login(ctx context.Context, credentials Credentials) (any, error) {
+	tokens, err := p.client.Login(credentials.Email, credentials.Password)
+	if errors.Is(err, resy.ErrUnauthorized) {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidCredentials, err)
+	} else if err != nil {
+		return nil, err
+	}
+	tokens.ApiKey = resy.DefaultApiKey
+	return tokens, nil
+}
+
+// Unmarshals Resy tokens, using the default API key if none is specified
+func (p *resyPlatform) ParseToken(token []byte) (any, error) {
+	var tokens resy.Tokens
+	if err := json.Unmarshal(token, &tokens); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrUnmarshalToken, err)
+	}
+	if tokens.ApiKey == "" {
+		tokens.ApiKey = resy.DefaultApiKey
+	}
+	return tokens, nil
+}
+
+// Returns the expiry of Resy tokens, which are JWTs containing their expiry
+func (p *resyPlatform) TokenExpiry(token any) (TokenExpiry, error) {
+	tokens, ok := token.(resy.Tokens)
+	if !ok {
+		return TokenExpiry{}, ErrIncorrectToken
+	}
+
+	expiresAt, err := resy.GetTokenExpiry(tokens.Token)
+	if err != nil {
+		return TokenExpiry{}, err
+	}
+	refreshExpiresAt, err := resy.GetTokenExpiry(tokens.Refresh)
+	if err != nil {
+		return TokenExpiry{}, err
+	}
+
+	return TokenExpiry{
+		ExpiresAt:        &expiresAt,
+		HasRefresh:       true,
+		RefreshExpiresAt: &refreshExpiresAt,
+	}, nil
+}
+
+func (p *resyPlatform) RefreshToken(ctx context.Context, token any) (any, error) {
+	tokens, ok := token.(resy.Tokens)
+	if !ok {
+		return nil, ErrIncorrectToken
+	}
+
+	newTokens, err := resy.NewClient(nil, resy.Tokens{}, "").RefreshToken(tokens.Refresh)
+	if err != nil {
+		return nil, err
+	}
+	newTokens.ApiKey = tokens.ApiKey
+	return newTokens, nil
+}
+
+func (p *resyPlatform) HasPaymentMethod(ctx context.Context, token any) (bool, error) {
+	tokens, ok := token.(resy.Tokens)
+	if !ok {
+		return false, ErrIncorrectToken
+	}
+
+	user, err := resy.NewClient(nil, tokens, "").GetUser()
+	if err != nil {
+		return false, err
+	}
+	return len(user.PaymentMethods) > 0, nil
+}
+
+func (p *resyPlatform) SearchVenues(ctx context.Context, query string) ([]Venue, error) {
+	// Use the default page limit of 10
+	resyVenues, err := p.client.SearchVenue(query, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	venues := make([]Venue, 0, len(resyVenues))
+	for _, venue := range resyVenues {
+		venues = append(venues, Venue{
+			Id:           strconv.Itoa(venue.Id.Resy),
+			Name:         venue.Name,
+			Neighborhood: venue.Neighborhood,
+			City:         venue.Locality,
+			Region:       venue.Region,
+		})
+	}
+	return venues, nil
+}
+
+func (p *resyPlatform) GetVenue(ctx context.Context, venueId string) (*Venue, error) {
+	resyVenueId, err := strconv.Atoi(venueId)
+	if err != nil {
+		return nil, err
+	}
+	resyVenue, err := p.client.GetVenue(resyVenueId)
+	if errors.Is(err, resy.ErrNotFound) {
+		return nil, fmt.Errorf("%w: %w", ErrVenueNotFound, err)
+	} else if err != nil {
+		return nil, err
+	}
+
+	venue := &Venue{
+		Id:           venueId,
+		Name:         resyVenue.Name,
+		Neighborhood: resyVenue.Location.Neighborhood,
+		City:         resyVenue.Locality,
+		Region:       resyVenue.Location.Region,
+		Timezone:     resyVenue.Locale.Timezone.Location,
+	}
+
+	var address string
+	if resyVenue.Location.Address1 != nil {
+		address = *resyVenue.Location.Address1
+	}
+	if resyVenue.Location.Address2 != nil {
+		if address != "" {
+			address += " " + *resyVenue.Location.Address2
+		} else {
+			address = *resyVenue.Location.Address2
+		}
+	}
+	if address != "" {
+		venue.Address = &address
+	}
+
+	return venue, nil
+}
+
+// Retrieves the seating types of the slots of the first available dates of a Resy venue
+func (p *resyPlatform) GetSeatingTypes(ctx context.Context, venueId string, partySize int) ([]string, error) {
+	resyVenueId, err := strconv.Atoi(venueId)
+	if err != nil {
+		return nil, err
+	}
+
+	today := time.Now().UTC()
+	calendar, err := p.client.GetVenueCalendar(resyVenueId, partySize, resy.ResyDate{Time: today}, resy.ResyDate{Time: today.AddDate(0, 0, seatingTypeSearchDays)})
+	if err != nil {
+		return nil, err
+	}
+
+	seatingTypes := make([]string, 0)
+	sampled := 0
+	for _, day := range calendar {
+		if sampled >= seatingTypeSampleDates {
+			break
+		}
+		if day.Inventory.Reservation != "available" {
+			continue
+		}
+		sampled++
+
+		slots, _, err := p.client.GetSlots(resyVenueId, day.Date.Format(resy.ResyDateFormat), partySize)
+		if err != nil {
+			return nil, err
+		}
+		for _, slot := range slots {
+			seatingType := strings.TrimSpace(slot.Config.Type)
+			if seatingType != "" && !slices.Contains(seatingTypes, seatingType) {
+				seatingTypes = append(seatingTypes, seatingType)
+			}
+		}
+	}
+	return seatingTypes, nil
+}
+
+func (p *resyPlatform) NewBookingClient(token string) (BookingClient, error) {
+	return NewResyClient(token)
+}
@@ -0,0 +1,400 @@
+package reservation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var (
+	ErrInvalidStrategy = errors.New("invalid booking strategy")
+)
+
+// Names of the booking strategies
+const (
+	// Attempts each slot sequentially in preference order
+	StrategySequential = "sequential"
+	// Attempts the slots concurrently, launching each slot after a stagger
+	StrategyStagger = "stagger"
+	// Attempts all of the slots concurrently
+	StrategyParallel = "parallel"
+	// Attempts the slots concurrently in batches of the race size in preference order
+	StrategyRace = "race"
+	// Attempts all of the slots concurrently, upgrading the booked slot to more preferred slots
+	StrategyUpgrade = "upgrade"
+)
+
+const (
+	// Stagger of the stagger strategy if none is set
+	defaultStagger = 1 * time.Second
+	// Race size of the race strategy if none is set
+	defaultRaceSize = 3
+)
+
+// Booking strategy of an event
+// Stagger is only used by the stagger strategy and race size by the race strategy
+// If no strategy is set, strict preference uses the sequential strategy and
+// soft preference the stagger strategy
+type Strategy struct {
+	Name     string        `json:"name"`
+	Stagger  time.Duration `json:"stagger,omitempty"`
+	RaceSize int           `json:"race_size,omitempty"`
+}
+
+// Decision of the booking strategy that led to an attempt
+// NOTE: Rank is the preference rank of the slot, 0 being the most preferred slot
+// NOTE: Delay is the duration between the start of the booking and the launch of the attempt
+type StrategyDecision struct {
+	Strategy string        `json:"strategy"`
+	Rank     int           `json:"rank"`
+	Delay    time.Duration `json:"delay"`
+	Reason   string        `json:"reason,omitempty"`
+}
+
+// Implemented by booking clients that can cancel a booked slot
+// Used by the upgrade strategy to cancel the booked slot once a more preferred slot is booked
+type bookingCanceller interface {
+	Cancel(ctx context.Context, result BookingResult) error
+}
+
+// Strategy used to book the slots of an event
+type bookingStrategy interface {
+	// Books the slots, in preference order, and returns the result if successful,
+	// a slice of all attempts, and an error
+	book(ctx context.Context, client BookingClient, event Event, slots []any) (*BookingResult, []Attempt, error)
+}
+
+// Returns an error if the strategy is not a valid booking strategy
+func (s Strategy) Validate() error {
+	switch s.Name {
+	case StrategySequential, StrategyParallel, StrategyUpgrade:
+	case StrategyStagger:
+		if s.Stagger < 0 {
+			return fmt.Errorf("%w: negative stagger", ErrInvalidStrategy)
+		}
+	case StrategyRace:
+		if s.RaceSize < 0 {
+			return fmt.Errorf("%w: negative race size", ErrInvalidStrategy)
+		}
+	default:
+		return fmt.Errorf("%w: unknown strategy %q", ErrInvalidStrategy, s.Name)
+	}
+	return nil
+}
+
+// Returns the booking strategy of the event for a booking client
+// Invalid strategies fall back to the strategy of the preference of the event
+// Concurrent strategies can book several slots before the other attempts are
+// cancelled, so clients that cannot cancel the extra bookings fall back to the
+// sequential strategy
+func (e Event) bookingStrategy(client BookingClient) bookingStrategy {
+	strategy := Strategy{Name: StrategyStagger}
+	if e.StrictPreference {
+		strategy.Name = StrategySequential
+	}
+	if e.Strategy != nil && e.Strategy.Validate() == nil {
+		strategy = *e.Strategy
+	}
+
+	if _, ok := client.(bookingCanceller); !ok && strategy.Name != StrategySequential {
+		return raceStrategy{
+			name:   StrategySequential,
+			size:   1,
+			reason: fmt.Sprintf("attempted in preference order instead of the %s strategy as the platform cannot cancel bookings", strategy.Name),
+		}
+	}
+
+	switch strategy.Name {
+	case StrategySequential:
+		return raceStrategy{name: StrategySequential, size: 1}
+	case StrategyParallel:
+		return concurrentStrategy{name: StrategyParallel}
+	case StrategyUpgrade:
+		return concurrentStrategy{name: StrategyUpgrade, upgrade: true}
+	case StrategyRace:
+		if strategy.RaceSize == 0 {
+			strategy.RaceSize = defaultRaceSize
+		}
+		return raceStrategy{name: StrategyRace, size: strategy.RaceSize}
+	default:
+		if strategy.Stagger == 0 {
+			strategy.Stagger = defaultStagger
+		}
+		return concurrentStrategy{name: StrategyStagger, stagger: strategy.Stagger}
+	}
+}
+
+// Strategy attempting the slots in batches of a size, the slots of a batch being
+// attempted concurrently and the next batch only being attempted if all of the
+// attempts of the batch failed
+// A size of 1 attempts each slot sequentially
+// Reason replaces the reason of the decisions of the attempts if set
+type raceStrategy struct {
+	name   string
+	size   int
+	reason string
+}
+
+func (s raceStrategy) book(ctx context.Context, client BookingClient, event Event, slots []any) (*BookingResult, []Attempt, error) {
+	var attempts []Attempt
+	start := time.Now()
+	for batchStart := 0; batchStart < len(slots); batchStart += s.size {
+		batch := slots[batchStart:min(batchStart+s.size, len(slots))]
+		reason := fmt.Sprintf("raced among slots %d to %d", batchStart, batchStart+len(batch)-1)
+		if s.size == 1 {
+			reason = "attempted in preference order"
+		}
+		if s.reason != "" {
+			reason = s.reason
+		}
+
+		run := concurrentRun{
+			name:    s.name,
+			start:   start,
+			offset:  batchStart,
+			reasons: func(int) string { return reason },
+		}
+		result, batchAttempts, err := run.book(ctx, client, event, batch)
+		attempts = append(attempts, batchAttempts...)
+		if err == nil {
+			return result, attempts, nil
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return nil, attempts, ErrFailedToBookSlots
+}
+
+// Strategy attempting all of the slots concurrently, launching each slot after
+// the stagger following the launch of the previous slot
+// The first booked slot cancels the other attempts, unless upgrading in which
+// case only the attempts of less preferred slots are cancelled and a more
+// preferred slot that is then booked replaces the booked slot, which is cancelled
+type concurrentStrategy struct {
+	name    string
+	stagger time.Duration
+	upgrade bool
+}
+
+func (s concurrentStrategy) book(ctx context.Context, client BookingClient, event Event, slots []any) (*BookingResult, []Attempt, error) {
+	run := concurrentRun{
+		name:    s.name,
+		start:   time.Now(),
+		stagger: s.stagger,
+		upgrade: s.upgrade,
+		reasons: func(rank int) string {
+			switch {
+			case s.upgrade:
+				return "launched with all slots, upgrading to more preferred slots"
+			case s.stagger == 0:
+				return "launched with all slots"
+			case rank == 0:
+				return "launched first"
+			default:
+				return fmt.Sprintf("launched %s after the previous slot", s.stagger)
+			}
+		},
+	}
+	return run.book(ctx, client, event, slots)
+}
+
+// Concurrent booking of slots shared by the strategies
+// Offset is the rank of the first slot and is used to record the decisions of the attempts
+type concurrentRun struct {
+	name    string
+	start   time.Time
+	offset  int
+	stagger time.Duration
+	upgrade bool
+	reasons func(rank int) string
+}
+
+// Result of an attempt of the slot of a given index
+type indexedAttempt struct {
+	index   int
+	attempt Attempt
+}
+
+func (r concurrentRun) book(ctx context.Context, client BookingClient, event Event, slots []any) (*BookingResult, []Attempt, error) {
+	// Create cancellable context (inherits parent cancellation)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	resultCh := make(chan indexedAttempt, len(slots))
+	doneCh := make(chan struct{})
+	var wg sync.WaitGroup
+
+	// Cancellation of the attempt of each slot and index of the booked slot, -1 if none
+	var mu sync.Mutex
+	cancels := make([]context.CancelFunc, len(slots))
+	booked := -1
+
+	var attempts []Attempt
+	var finalResult *BookingResult
+	var finalError error
+
+	// Process results concurrently as they arrive
+	go func() {
+		defer close(doneCh)
+		bookedAttempt := -1
+		for result := range resultCh {
+			attempts = append(attempts, result.attempt)
+			if result.attempt.Error != "" {
+				continue
+			}
+
+			mu.Lock()
+			previous := booked
+			mu.Unlock()
+
+			switch {
+			case previous == -1:
+				finalResult = result.attempt.Result
+				bookedAttempt = len(attempts) - 1
+			case r.upgrade && result.index < previous && canUpgrade(client, event, *finalResult):
+				// Cancel the booked slot only once the more preferred slot has been booked
+				canceller := client.(bookingCanceller)
+				err := canceller.Cancel(context.WithoutCancel(ctx), *finalResult)
+				decision := &attempts[bookedAttempt].Decision
+				if err != nil {
+					decision.Reason += fmt.Sprintf(", failed to cancel after upgrading to slot %d: %v", r.offset+result.index, err)
+				} else {
+					decision.Reason += fmt.Sprintf(", cancelled after upgrading to slot %d", r.offset+result.index)
+				}
+				finalResult = result.attempt.Result
+				bookedAttempt = len(attempts) - 1
+			default:
+				// Attempts already sent to the platform when the booked slot was
+				// adopted can still succeed, so their extra bookings are cancelled
+				if !event.DryRun {
+					attempts[len(attempts)-1].Decision.Reason += cancelExtraBooking(ctx, client, *result.attempt.Result, r.offset+previous)
+				}
+				continue
+			}
+
+			mu.Lock()
+			booked = result.index
+			mu.Unlock()
+
+			if !r.upgrade || !canUpgrade(client, event, *finalResult) {
+				// Cancel all other goroutines on success
+				cancel()
+				continue
+			}
+			mu.Lock()
+			for _, cancelAttempt := range cancels[result.index+1:] {
+				if cancelAttempt != nil {
+					cancelAttempt()
+				}
+			}
+			mu.Unlock()
+		}
+
+		if finalResult == nil {
+			// All attempts failed
+			finalError = ErrFailedToBookSlots
+		}
+	}()
+
+	// Launch goroutines with the stagger between launches
+launchLoop:
+	for i, slot := range slots {
+		// Stagger launches (except first one)
+		if i > 0 && r.stagger > 0 {
+			select {
+			case <-time.After(r.stagger):
+				// Continue to launch next goroutine
+			case <-ctx.Done():
+				// Context cancelled
+				break launchLoop
+			}
+		}
+
+		// Slots launched after a slot was booked are less preferred and are not attempted
+		mu.Lock()
+		if ctx.Err() != nil || booked != -1 {
+			mu.Unlock()
+			break launchLoop
+		}
+		attemptCtx, cancelAttempt := context.WithCancel(ctx)
+		cancels[i] = cancelAttempt
+		mu.Unlock()
+
+		decision := StrategyDecision{
+			Strategy: r.name,
+			Rank:     r.offset + i,
+			Delay:    time.Since(r.start),
+			Reason:   r.reasons(r.offset + i),
+		}
+		wg.Add(1)
+		go bookSlotConcurrent(attemptCtx, &wg, resultCh, client, event, i, slot, decision)
+	}
+
+	// Close channel after all attempts complete
+	wg.Wait()
+	close(resultCh)
+
+	// Wait for result processing to complete
+	<-doneCh
+	for _, cancelAttempt := range cancels {
+		if cancelAttempt != nil {
+			cancelAttempt()
+		}
+	}
+	return finalResult, attempts, finalError
+}
+
+// Cancels a booking made after the slot of a rank was booked
+// Returns the outcome of the cancellation to record in the decision of the attempt
+func cancelExtraBooking(ctx context.Context, client BookingClient, result BookingResult, bookedRank int) string {
+	canceller, ok := client.(bookingCanceller)
+	if !ok {
+		return fmt.Sprintf(", booked after slot %d and cannot be cancelled", bookedRank)
+	}
+	if err := canceller.Cancel(context.WithoutCancel(ctx), result); err != nil {
+		return fmt.Sprintf(", failed to cancel after slot %d was booked: %v", bookedRank, err)
+	}
+	return fmt.Sprintf(", cancelled as slot %d was booked", bookedRank)
+}
+
+// Returns whether the booked result can be upgraded to a more preferred slot
+// Upgrades require cancelling the booked slot, so are only performed if the client can
+// cancel bookings and the booked slot has no deposit that could be forfeited
+func canUpgrade(client BookingClient, event Event, result BookingResult) bool {
+	_, ok := client.(bookingCanceller)
+	return ok && !event.DryRun && result.Deposit == nil
+}
+
+// Wraps Book call for goroutine execution with context cancellation support
+func bookSlotConcurrent(
+	ctx context.Context,
+	wg *sync.WaitGroup,
+	resultCh chan<- indexedAttempt,
+	client BookingClient,
+	event Event,
+	index int,
+	slot any,
+	decision StrategyDecision,
+) {
+	defer wg.Done()
+
+	// Check if context already cancelled, skip if cancelled
+	select {
+	case <-ctx.Done():
+		return
+	default:
+	}
+
+	attempt, err := client.Book(ctx, event, slot)
+	attempt.Decision = decision
+
+	// Send result back
+	if err != nil {
+		attempt.Error = err.Error()
+	}
+
+	resultCh <- indexedAttempt{index: index, attempt: attempt}
+}
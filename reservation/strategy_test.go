@@ -0,0 +1,237 @@
+package reservation
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Booking client whose slots are booked after a delay, failing the slots marked
+// as taken, and recording the launched and cancelled bookings
+// Bookings of in flight slots complete even if their attempt is cancelled, as
+// a request already received by the platform would
+type strategyClient struct {
+	watchClient
+	delays   map[int]time.Duration
+	taken    map[int]bool
+	inFlight map[int]bool
+
+	mu        sync.Mutex
+	launched  []int
+	cancelled []int
+}
+
+func (c *strategyClient) Book(ctx context.Context, event Event, slot any) (Attempt, error) {
+	c.mu.Lock()
+	c.launched = append(c.launched, slot.(int))
+	c.mu.Unlock()
+
+	select {
+	case <-time.After(c.delays[slot.(int)]):
+	case <-ctx.Done():
+		if !c.inFlight[slot.(int)] {
+			return Attempt{}, ctx.Err()
+		}
+		<-time.After(c.delays[slot.(int)])
+	}
+	if c.taken[slot.(int)] {
+		return Attempt{}, errors.New("taken")
+	}
+	return Attempt{Result: &BookingResult{PlatformConfirmation: map[string]any{"slot": slot}}}, nil
+}
+
+func (c *strategyClient) Cancel(ctx context.Context, result BookingResult) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.cancelled = append(c.cancelled, result.PlatformConfirmation["slot"].(int))
+	return nil
+}
+
+func TestBookingStrategy_Default(t *testing.T) {
+	tests := []struct {
+		name  string
+		event Event
+		want  bookingStrategy
+	}{
+		{name: "soft preference", event: Event{}, want: concurrentStrategy{name: StrategyStagger, stagger: defaultStagger}},
+		{name: "strict preference", event: Event{StrictPreference: true}, want: raceStrategy{name: StrategySequential, size: 1}},
+		{name: "race without size", event: Event{Strategy: &Strategy{Name: StrategyRace}}, want: raceStrategy{name: StrategyRace, size: defaultRaceSize}},
+		{name: "invalid strategy", event: Event{StrictPreference: true, Strategy: &Strategy{Name: "fastest"}}, want: raceStrategy{name: StrategySequential, size: 1}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.event.bookingStrategy(&strategyClient{}); got != test.want {
+				t.Errorf("got %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestBookingStrategy_Uncancellable(t *testing.T) {
+	for _, name := range []string{StrategyStagger, StrategyParallel, StrategyRace, StrategyUpgrade} {
+		event := Event{Strategy: &Strategy{Name: name}}
+		got, ok := event.bookingStrategy(&watchClient{}).(raceStrategy)
+		if !ok || got.name != StrategySequential || got.size != 1 || got.reason == "" {
+			t.Errorf("%s: got %+v, want the sequential strategy", name, got)
+		}
+	}
+
+	event := Event{Strategy: &Strategy{Name: StrategySequential}}
+	if got := event.bookingStrategy(&watchClient{}); got != (raceStrategy{name: StrategySequential, size: 1}) {
+		t.Errorf("sequential: got %+v, want the sequential strategy", got)
+	}
+}
+
+func TestBookingHandler_Sequential(t *testing.T) {
+	client := &strategyClient{taken: map[int]bool{0: true, 1: true}}
+	event := Event{Strategy: &Strategy{Name: StrategySequential}}
+
+	result, attempts, err := bookingHandler(context.Background(), client, event, []int{0, 1, 2, 3})
+	if err != nil {
+		t.Fatalf("booking failed: %v", err)
+	}
+	if result.PlatformConfirmation["slot"] != 2 {
+		t.Errorf("booked slot %v, want 2", result.PlatformConfirmation["slot"])
+	}
+	if len(attempts) != 3 {
+		t.Fatalf("attempts: got %d, want 3", len(attempts))
+	}
+	for i, attempt := range attempts {
+		if attempt.Decision.Strategy != StrategySequential || attempt.Decision.Rank != i {
+			t.Errorf("attempt %d: got decision %+v", i, attempt.Decision)
+		}
+	}
+}
+
+func TestBookingHandler_Parallel(t *testing.T) {
+	client := &strategyClient{
+		delays: map[int]time.Duration{0: 50 * time.Millisecond, 1: 10 * time.Millisecond, 2: 50 * time.Millisecond},
+	}
+	event := Event{Strategy: &Strategy{Name: StrategyParallel}}
+
+	result, attempts, err := bookingHandler(context.Background(), client, event, []int{0, 1, 2})
+	if err != nil {
+		t.Fatalf("booking failed: %v", err)
+	}
+	if result.PlatformConfirmation["slot"] != 1 {
+		t.Errorf("booked slot %v, want the fastest slot 1", result.PlatformConfirmation["slot"])
+	}
+	if len(client.launched) != 3 {
+		t.Errorf("launched %d slots, want 3", len(client.launched))
+	}
+	// The other attempts are cancelled once a slot is booked
+	for _, attempt := range attempts {
+		if attempt.Decision.Rank != 1 && attempt.Error != context.Canceled.Error() {
+			t.Errorf("attempt of slot %d: got error %q, want cancellation", attempt.Decision.Rank, attempt.Error)
+		}
+	}
+}
+
+func TestBookingHandler_ParallelCancelsExtraBookings(t *testing.T) {
+	// The less preferred slot is booked by the platform after the first slot was adopted
+	client := &strategyClient{
+		delays:   map[int]time.Duration{0: 10 * time.Millisecond, 1: 40 * time.Millisecond},
+		inFlight: map[int]bool{1: true},
+	}
+	event := Event{Strategy: &Strategy{Name: StrategyParallel}}
+
+	result, attempts, err := bookingHandler(context.Background(), client, event, []int{0, 1})
+	if err != nil {
+		t.Fatalf("booking failed: %v", err)
+	}
+	if result.PlatformConfirmation["slot"] != 0 {
+		t.Errorf("booked slot %v, want 0", result.PlatformConfirmation["slot"])
+	}
+	if len(client.cancelled) != 1 || client.cancelled[0] != 1 {
+		t.Errorf("cancelled slots %v, want [1]", client.cancelled)
+	}
+	for _, attempt := range attempts {
+		if attempt.Decision.Rank == 1 && !strings.HasSuffix(attempt.Decision.Reason, "cancelled as slot 0 was booked") {
+			t.Errorf("attempt of slot 1: got reason %q, want the cancellation", attempt.Decision.Reason)
+		}
+	}
+}
+
+func TestBookingHandler_Stagger(t *testing.T) {
+	client := &strategyClient{taken: map[int]bool{0: true}}
+	event := Event{Strategy: &Strategy{Name: StrategyStagger, Stagger: 20 * time.Millisecond}}
+
+	result, attempts, err := bookingHandler(context.Background(), client, event, []int{0, 1, 2})
+	if err != nil {
+		t.Fatalf("booking failed: %v", err)
+	}
+	if result.PlatformConfirmation["slot"] != 1 {
+		t.Errorf("booked slot %v, want 1", result.PlatformConfirmation["slot"])
+	}
+	if len(attempts) != 2 {
+		t.Fatalf("attempts: got %d, want 2", len(attempts))
+	}
+	if delay := attempts[1].Decision.Delay; delay < 20*time.Millisecond {
+		t.Errorf("second attempt launched after %s, want at least the stagger", delay)
+	}
+}
+
+func TestBookingHandler_Race(t *testing.T) {
+	client := &strategyClient{taken: map[int]bool{0: true, 1: true, 3: true}}
+	event := Event{Strategy: &Strategy{Name: StrategyRace, RaceSize: 2}}
+
+	result, attempts, err := bookingHandler(context.Background(), client, event, []int{0, 1, 2, 3, 4})
+	if err != nil {
+		t.Fatalf("booking failed: %v", err)
+	}
+	if result.PlatformConfirmation["slot"] != 2 {
+		t.Errorf("booked slot %v, want 2", result.PlatformConfirmation["slot"])
+	}
+	// The second batch is only raced once the first batch failed, and the last slot is never raced
+	if len(attempts) < 3 {
+		t.Fatalf("attempts: got %d, want at least 3", len(attempts))
+	}
+	for _, attempt := range attempts {
+		if attempt.Decision.Strategy != StrategyRace || attempt.Decision.Rank > 3 {
+			t.Errorf("got decision %+v", attempt.Decision)
+		}
+	}
+}
+
+func TestBookingHandler_Upgrade(t *testing.T) {
+	client := &strategyClient{
+		delays: map[int]time.Duration{0: 60 * time.Millisecond, 1: 100 * time.Millisecond, 2: 10 * time.Millisecond, 3: 100 * time.Millisecond},
+	}
+	event := Event{Strategy: &Strategy{Name: StrategyUpgrade}}
+
+	result, attempts, err := bookingHandler(context.Background(), client, event, []int{0, 1, 2, 3})
+	if err != nil {
+		t.Fatalf("booking failed: %v", err)
+	}
+	if result.PlatformConfirmation["slot"] != 0 {
+		t.Errorf("booked slot %v, want the upgraded slot 0", result.PlatformConfirmation["slot"])
+	}
+	if len(client.cancelled) != 1 || client.cancelled[0] != 2 {
+		t.Errorf("cancelled slots %v, want [2]", client.cancelled)
+	}
+	for _, attempt := range attempts {
+		booked := attempt.Error == ""
+		if booked != (attempt.Decision.Rank == 0 || attempt.Decision.Rank == 2) {
+			t.Errorf("attempt of slot %d: booked %t, error %q", attempt.Decision.Rank, booked, attempt.Error)
+		}
+	}
+}
+
+func TestBookingHandler_UpgradeDryRun(t *testing.T) {
+	client := &strategyClient{
+		delays: map[int]time.Duration{0: 50 * time.Millisecond},
+	}
+	event := Event{DryRun: true, Strategy: &Strategy{Name: StrategyUpgrade}}
+
+	result, _, err := bookingHandler(context.Background(), client, event, []int{0, 1})
+	if err != nil {
+		t.Fatalf("booking failed: %v", err)
+	}
+	if result.PlatformConfirmation["slot"] != 1 || len(client.cancelled) != 0 {
+		t.Errorf("booked slot %v and cancelled %v, want slot 1 without upgrade", result.PlatformConfirmation["slot"], client.cancelled)
+	}
+}
@@ -0,0 +1,318 @@
+package reservation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/daylamtayari/cierge/tock"
+)
+
+type TockClient struct {
+	client     *tock.Client
+	httpClient *http.Client
+	tokens     tock.Tokens
+
+	// User retrieved by the pre-booking check, whose payment methods are used for checkout
+	userMu sync.Mutex
+	user   *tock.User
+}
+
+// Tock slot with its date time
+// NOTE: The slot time is in the local time of the business with a UTC timezone
+type tockSlot struct {
+	slot tock.Slot
+	time time.Time
+}
+
+// Returns a Tock booking client
+func NewTockClient(token string) (*TockClient, error) {
+	tockClient := TockClient{}
+	// Unmarshal token string into tock.Tokens
+	err := json.Unmarshal([]byte(token), &tockClient.tokens)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrUnmarshalToken, err)
+	}
+
+	// Idle connections are kept so that connections opened by the warm up are reused
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConnsPerHost = maxWarmUpConnections
+	transport.IdleConnTimeout = 2 * warmUpKeepAlive
+	tockClient.httpClient = &http.Client{
+		Timeout:   15 * time.Second,
+		Transport: transport,
+	}
+
+	tockClient.client = tock.NewClient(tockClient.httpClient, tockClient.tokens.Token, "")
+
+	return &tockClient, nil
+}
+
+// Performs pre-booking checks for the Tock client
+// - Test if the token is valid
+// - Test if the payment method of the payment policy exists
+func (c *TockClient) PreBookingCheck(ctx context.Context, event Event) error {
+	// Test token validity by retrieving the current user
+	user, err := c.getUser()
+	if err != nil {
+		return err
+	}
+	_, _, err = tockPaymentMethod(user, event.PaymentPolicy)
+	return err
+}
+
+// Opens connections to the Tock API concurrently so that they are kept
+// idle and reused by the requests at the drop time
+func (c *TockClient) WarmUp(ctx context.Context, event Event, connections int) error {
+	return warmUpConnections(ctx, c.httpClient, tock.Host, connections)
+}
+
+// Returns the URL of the Tock API whose Date header is used as a reference clock
+func (c *TockClient) DateHeaderURL() string {
+	return tock.Host
+}
+
+// Returns a slice of matching Tock slot candidates of the reservation date and party
+// size of the event and its alternatives in order of preference, and an error that is nil if successful
+func (c *TockClient) FetchSlots(ctx context.Context, event Event) (any, error) {
+	// Get slots of each target until slots are found or the deadline after the drop time
+	deadline := event.DropTime.Add(slotDeadline)
+	targets := event.targets()
+	targetSlots, err := getTargetSlots(ctx, targets, func(ctx context.Context, target Alternative) ([]tockSlot, error) {
+		return getSlotsUntilDeadline(ctx, deadline, func() ([]tockSlot, error) {
+			return c.getSlots(event.PlatformVenueId, target)
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Find matching slots of each target and sort them in order of preference
+	candidates := rankTockCandidates(targetSlots, targets, event)
+	if len(candidates) == 0 {
+		return nil, ErrNoMatchingSlotsFound
+	}
+
+	return candidates, nil
+}
+
+// Retrieves the slots of the reservation date and party size of the event and its alternatives
+// once, concurrently, and returns a slice of matching Tock slot candidates in order of preference
+// An error retrieving the slots of a target is only returned if no matching slots were found
+func (c *TockClient) PollSlots(ctx context.Context, event Event) (any, error) {
+	targets := event.targets()
+	targetSlots := make([][]tockSlot, len(targets))
+	errs := make([]error, len(targets))
+	var wg sync.WaitGroup
+	for i, target := range targets {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			slots, err := c.getSlots(event.PlatformVenueId, target)
+			if errors.Is(err, tock.ErrTooManyRequests) {
+				err = fmt.Errorf("%w: %w", ErrRateLimited, err)
+			}
+			targetSlots[i] = slots
+			errs[i] = err
+		}()
+	}
+	wg.Wait()
+
+	candidates := rankTockCandidates(targetSlots, targets, event)
+	if len(candidates) > 0 {
+		return candidates, nil
+	}
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return nil, ErrNoMatchingSlotsFound
+}
+
+// Books a single slot candidate and returns an Attempt
+// This method is called by the generic bookingHandler for each slot
+func (c *TockClient) Book(ctx context.Context, event Event, slot any) (Attempt, error) {
+	startTime := time.Now().UTC()
+	tockCandidate := slot.(candidate[tockSlot])
+
+	bookingResult, err := c.bookSlot(ctx, event, tockCandidate.slot, tockCandidate.target)
+
+	attempt := Attempt{
+		Result:          bookingResult,
+		SlotTime:        tockCandidate.slot.time,
+		ReservationDate: tockCandidate.target.ReservationDate,
+		PartySize:       tockCandidate.target.PartySize,
+		StartTime:       startTime,
+		Duration:        time.Now().UTC().Sub(startTime),
+	}
+
+	if err != nil {
+		attempt.Error = err.Error()
+		return attempt, err
+	}
+
+	return attempt, nil
+}
+
+// Book slots calls the generic booking handler after type asserting slots
+func (c *TockClient) BookSlots(ctx context.Context, event Event, slots any) (*BookingResult, []Attempt, error) {
+	return bookingHandler(ctx, c, event, slots.([]candidate[tockSlot]))
+}
+
+// Returns the user of the token, retrieving it if it was not retrieved yet
+func (c *TockClient) getUser() (*tock.User, error) {
+	c.userMu.Lock()
+	defer c.userMu.Unlock()
+
+	if c.user != nil {
+		return c.user, nil
+	}
+	user, err := c.client.GetUser()
+	if err != nil {
+		return nil, err
+	}
+	c.user = user
+	return user, nil
+}
+
+// Retrieves the available slots of all experiences of a business for a target
+// Slots whose experience does not accommodate the party size of the target are not included
+func (c *TockClient) getSlots(slug string, target Alternative) ([]tockSlot, error) {
+	reservationDate, err := time.Parse("2006-01-02", target.ReservationDate)
+	if err != nil {
+		return nil, err
+	}
+
+	availability, err := c.client.GetAvailability(slug, reservationDate, int(target.PartySize))
+	if err != nil {
+		return nil, err
+	}
+
+	partySize := int(target.PartySize)
+	slots := make([]tockSlot, 0, len(availability))
+	for _, slot := range availability {
+		if slot.AvailableTickets < partySize ||
+			(slot.MinPartySize > 0 && partySize < slot.MinPartySize) ||
+			(slot.MaxPartySize > 0 && partySize > slot.MaxPartySize) {
+			continue
+		}
+		slotTime, err := slot.DateTime()
+		if err != nil {
+			return nil, err
+		}
+		slots = append(slots, tockSlot{slot: slot, time: slotTime})
+	}
+	return slots, nil
+}
+
+// Books a given slot for the party size of a given target
+// The slot is held and its total checked against the payment policy of the event before checkout,
+// with the hold being released if the slot is not checked out
+// Returns a BookingResult if successful or an error if not
+// Dry runs return the result of the slot without holding or checking it out, so have no deposit
+func (c *TockClient) bookSlot(ctx context.Context, event Event, slot tockSlot, target Alternative) (*BookingResult, error) {
+	result := &BookingResult{
+		ReservationTime: slot.time,
+		ReservationDate: target.ReservationDate,
+		PartySize:       target.PartySize,
+		SeatingType:     tockSeatingType(slot),
+	}
+	if event.DryRun {
+		return result, nil
+	}
+
+	hold, err := c.client.HoldSlot(event.PlatformVenueId, slot.slot, int(target.PartySize))
+	if err != nil {
+		return nil, err
+	}
+	confirmation, err := c.checkout(ctx, event, hold, result)
+	if err != nil {
+		// The hold expires if it fails to be released, so the release error is ignored
+		c.client.ReleaseHold(hold) //nolint:errcheck
+		return nil, err
+	}
+
+	result.PlatformConfirmation = map[string]any{
+		"purchase_id":       confirmation.PurchaseId,
+		"confirmation_code": confirmation.ConfirmationCode,
+		"business_slug":     event.PlatformVenueId,
+	}
+	return result, nil
+}
+
+// Checks out a hold, adding its deposit to the result
+// The total of holds requiring payment, which is the full price of prepaid
+// experiences, is treated as the deposit of the slot
+func (c *TockClient) checkout(ctx context.Context, event Event, hold *tock.Hold, result *BookingResult) (*tock.Confirmation, error) {
+	if hold.RequiresPayment && hold.Total > 0 {
+		if err := event.PaymentPolicy.checkDeposit(hold.Total, true); err != nil {
+			return nil, err
+		}
+		result.Deposit = &Deposit{
+			Fee:                hold.Total,
+			CancellationCutOff: hold.CancellationCutOff,
+		}
+	}
+
+	// The payment method of the payment policy or the default payment method is used
+	// if the user has one, which will work fine if the hold does not require
+	// payment but if it does, a tock.ErrPaymentRequired error will be returned
+	user, err := c.getUser()
+	if err != nil {
+		return nil, err
+	}
+	paymentMethod, found, err := tockPaymentMethod(user, event.PaymentPolicy)
+	if err != nil {
+		return nil, err
+	}
+	var paymentMethodId *string
+	if found {
+		paymentMethodId = &paymentMethod.Id
+	}
+
+	// Check for context cancellation prior to executing checkout
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	return c.client.CompleteCheckout(hold, paymentMethodId)
+}
+
+// Returns the payment method of the payment policy if set, otherwise the default
+// payment method of the user, and whether the user has a payment method
+// Returns ErrPaymentMethodNotFound if the payment method of the policy is not one of the user's
+func tockPaymentMethod(user *tock.User, policy *PaymentPolicy) (tock.PaymentMethod, bool, error) {
+	if policy == nil || policy.PaymentMethodId == nil {
+		paymentMethod, found := tock.GetDefaultPaymentMethod(user)
+		return paymentMethod, found, nil
+	}
+	for _, paymentMethod := range user.PaymentMethods {
+		if paymentMethod.Id == strconv.Itoa(*policy.PaymentMethodId) {
+			return paymentMethod, true, nil
+		}
+	}
+	return tock.PaymentMethod{}, false, fmt.Errorf("%w: %d", ErrPaymentMethodNotFound, *policy.PaymentMethodId)
+}
+
+// Returns the matching slots of each target as candidates in order of preference
+// NOTE: Tock reservations cannot be cancelled by the client so upgrades are not supported
+func rankTockCandidates(targetSlots [][]tockSlot, targets []Alternative, event Event) []candidate[tockSlot] {
+	rankedSlots := make([][]tockSlot, len(targets))
+	for i, slots := range targetSlots {
+		rankedSlots[i] = rankSlots(slots, func(slot tockSlot) time.Time {
+			return slot.time
+		}, tockSeatingType, event)
+	}
+	return combineCandidates(targets, rankedSlots)
+}
+
+// Returns the seating type of a Tock slot, being the name of its experience such as Dining Room or Bar
+func tockSeatingType(slot tockSlot) string {
+	return slot.slot.ExperienceName
+}
@@ -0,0 +1,173 @@
+package reservation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/daylamtayari/cierge/tock"
+)
+
+// Tock platform adapter
+type tockPlatform struct {
+	// Unauthenticated client used for requests not requiring a user
+	client *tock.Client
+}
+
+func newTockPlatform() *tockPlatform {
+	return &tockPlatform{
+		client: tock.NewClient(nil, "", ""),
+	}
+}
+
+func (p *tockPlatform) DisplayName() string {
+	return "Tock"
+}
+
+func (p *tockPlatform) LoginMethod() LoginMethod {
+	return LoginMethodPassword
+}
+
+func (p *tockPlatform) SendLoginCode(ctx context.Context, email string) error {
+	return ErrLoginMethodUnsupported
+}
+
+func (p *tockPlatform) Login(ctx context.Context, credentials Credentials) (any, error) {
+	tokens, err := p.client.Login(credentials.Email, credentials.Password)
+	if errors.Is(err, tock.ErrUnauthorized) {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidCredentials, err)
+	} else if err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+func (p *tockPlatform) ParseToken(token []byte) (any, error) {
+	var tokens tock.Tokens
+	if err := json.Unmarshal(token, &tokens); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrUnmarshalToken, err)
+	}
+	if tokens.Token == "" {
+		return nil, fmt.Errorf("%w: no token", ErrUnmarshalToken)
+	}
+	return tokens, nil
+}
+
+// Returns the expiry of Tock tokens, which is the one returned when they
+// were issued as Tock sessions are not JWTs and have no refresh token
+func (p *tockPlatform) TokenExpiry(token any) (TokenExpiry, error) {
+	tokens, ok := token.(tock.Tokens)
+	if !ok {
+		return TokenExpiry{}, ErrIncorrectToken
+	}
+
+	expiry := TokenExpiry{}
+	if !tokens.Expiry.IsZero() {
+		expiresAt := tokens.Expiry.UTC()
+		expiry.ExpiresAt = &expiresAt
+	}
+	return expiry, nil
+}
+
+// Tock does not issue refresh tokens so users login again once their session expires
+func (p *tockPlatform) RefreshToken(ctx context.Context, token any) (any, error) {
+	return nil, ErrRefreshUnsupported
+}
+
+func (p *tockPlatform) HasPaymentMethod(ctx context.Context, token any) (bool, error) {
+	tokens, ok := token.(tock.Tokens)
+	if !ok {
+		return false, ErrIncorrectToken
+	}
+
+	user, err := tock.NewClient(nil, tokens.Token, "").GetUser()
+	if err != nil {
+		return false, err
+	}
+	return len(user.PaymentMethods) > 0, nil
+}
+
+// Searches Tock businesses matching a query, whose IDs are their slugs
+func (p *tockPlatform) SearchVenues(ctx context.Context, query string) ([]Venue, error) {
+	results, err := p.client.Search(query)
+	if err != nil {
+		return nil, err
+	}
+
+	venues := make([]Venue, 0, len(results))
+	for _, result := range results {
+		venues = append(venues, Venue{
+			Id:           result.Slug,
+			Name:         result.Name,
+			Neighborhood: result.Neighborhood,
+			City:         result.City,
+			Region:       result.State,
+		})
+	}
+	return venues, nil
+}
+
+func (p *tockPlatform) GetVenue(ctx context.Context, venueId string) (*Venue, error) {
+	business, err := p.client.GetBusiness(venueId)
+	if errors.Is(err, tock.ErrNotFound) {
+		return nil, fmt.Errorf("%w: %w", ErrVenueNotFound, err)
+	} else if err != nil {
+		return nil, err
+	}
+
+	venue := &Venue{
+		Id:           venueId,
+		Name:         business.Name,
+		Neighborhood: business.Neighborhood,
+		City:         business.Address.City,
+		Region:       business.Address.State,
+	}
+
+	address := strings.TrimSpace(business.Address.Line1 + " " + business.Address.Line2)
+	if address != "" {
+		venue.Address = &address
+	}
+
+	if business.TimeZone != "" {
+		location, err := time.LoadLocation(business.TimeZone)
+		if err != nil {
+			return nil, err
+		}
+		venue.Timezone = location
+	}
+
+	return venue, nil
+}
+
+// Retrieves the names of the experiences of the slots of the upcoming dates of a Tock business
+func (p *tockPlatform) GetSeatingTypes(ctx context.Context, venueId string, partySize int) ([]string, error) {
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	seatingTypes := make([]string, 0)
+	sampled := 0
+	for day := 0; day < seatingTypeSearchDays && sampled < seatingTypeSampleDates; day++ {
+		slots, err := p.client.GetAvailability(venueId, today.AddDate(0, 0, day), partySize)
+		if err != nil {
+			return nil, err
+		}
+		if len(slots) == 0 {
+			continue
+		}
+		sampled++
+
+		for _, slot := range slots {
+			seatingType := strings.TrimSpace(tockSeatingType(tockSlot{slot: slot}))
+			if seatingType != "" && !slices.Contains(seatingTypes, seatingType) {
+				seatingTypes = append(seatingTypes, seatingType)
+			}
+		}
+	}
+	return seatingTypes, nil
+}
+
+func (p *tockPlatform) NewBookingClient(token string) (BookingClient, error) {
+	return NewTockClient(token)
+}
@@ -0,0 +1,212 @@
+package reservation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/daylamtayari/cierge/tock"
+)
+
+// Fake Tock API of a business serving recorded responses of the tock package,
+// with the availability and holds of the booking scenarios from testdata/tock
+// Holds of prepaid experiences require payment of their total
+type fakeTock struct {
+	mu       sync.Mutex
+	held     []int
+	released []string
+	checkout []*string
+}
+
+// Returns a Tock booking client whose requests are sent to the fake
+func newFakeTockClient(t *testing.T, fake *fakeTock) *TockClient {
+	t.Helper()
+
+	httpClient := newFakeHTTPClient(t, fake)
+	return &TockClient{
+		client:     tock.NewClient(httpClient, "token", ""),
+		httpClient: httpClient,
+	}
+}
+
+func (f *fakeTock) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch {
+	case r.URL.Path == "/api/consumer/user":
+		serveTockFixture(w, tockFixtures, "user.json")
+
+	case r.URL.Path == "/api/consumer/business/test-kitchen":
+		serveTockFixture(w, tockFixtures, "business.json")
+
+	case r.URL.Path == "/api/consumer/business/test-kitchen/availability":
+		if r.URL.Query().Get("date") != "2026-11-20" {
+			w.Write([]byte(`{"slots":[]}`)) //nolint:errcheck
+			return
+		}
+		serveTockFixture(w, tockScenarioFixtures, "availability.json")
+
+	case r.URL.Path == "/api/consumer/business/test-kitchen/hold":
+		var req struct {
+			ExperienceId int `json:"experienceId"`
+		}
+		json.NewDecoder(r.Body).Decode(&req) //nolint:errcheck
+		f.held = append(f.held, req.ExperienceId)
+		// The tasting menu is the prepaid experience of the recorded availability
+		if req.ExperienceId == 12 {
+			serveTockFixture(w, tockScenarioFixtures, "hold_prepaid.json")
+			return
+		}
+		serveTockFixture(w, tockScenarioFixtures, "hold.json")
+
+	case r.Method == http.MethodDelete && strings.HasPrefix(r.URL.Path, "/api/consumer/checkout/"):
+		f.released = append(f.released, strings.TrimPrefix(r.URL.Path, "/api/consumer/checkout/"))
+		w.WriteHeader(http.StatusNoContent)
+
+	case strings.HasPrefix(r.URL.Path, "/api/consumer/checkout/"):
+		var req struct {
+			PaymentMethodId *string `json:"paymentMethodId"`
+		}
+		json.NewDecoder(r.Body).Decode(&req) //nolint:errcheck
+		f.checkout = append(f.checkout, req.PaymentMethodId)
+		serveTockFixture(w, tockFixtures, "checkout.json")
+
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// Recorded responses of the Tock API shared with the tests of the tock package
+var tockFixtures = filepath.Join("..", "tock", "testdata")
+
+// Recorded responses of the Tock API specific to the booking scenarios
+var tockScenarioFixtures = filepath.Join("testdata", "tock")
+
+// Writes a recorded response of the Tock API from a fixture directory
+func serveTockFixture(w http.ResponseWriter, dir string, name string) {
+	body, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Write(body) //nolint:errcheck
+}
+
+// Returns the HH:mm time and seating type of Tock candidates
+func tockCandidateSlots(candidates []candidate[tockSlot]) []string {
+	slots := make([]string, 0, len(candidates))
+	for _, c := range candidates {
+		slots = append(slots, c.slot.time.Format("15:04")+" "+tockSeatingType(c.slot))
+	}
+	return slots
+}
+
+func TestTockClient_FetchAndBook(t *testing.T) {
+	fake := &fakeTock{}
+	client := newFakeTockClient(t, fake)
+	event := Event{
+		PlatformVenueId: "test-kitchen",
+		ReservationDate: "2026-11-20",
+		PartySize:       2,
+		PreferredTimes:  []string{"19:30", "21:00", "18:30"},
+		DropTime:        time.Now(),
+	}
+
+	if err := client.PreBookingCheck(context.Background(), event); err != nil {
+		t.Fatalf("pre-booking check failed: %v", err)
+	}
+	slots, err := client.FetchSlots(context.Background(), event)
+	if err != nil {
+		t.Fatalf("FetchSlots failed: %v", err)
+	}
+	// The 21:00 slot only has a single ticket available so cannot accommodate the party
+	candidates := slots.([]candidate[tockSlot])
+	if got, want := tockCandidateSlots(candidates), []string{"19:30 Tasting Menu", "18:30 Dining Room"}; !slices.Equal(got, want) {
+		t.Fatalf("candidates: got %v, want %v", got, want)
+	}
+
+	result, attempts, err := client.BookSlots(context.Background(), event, slots)
+	if err != nil {
+		t.Fatalf("BookSlots failed: %v", err)
+	}
+	if len(attempts) != 1 || !slices.Equal(fake.held, []int{12}) || len(fake.released) != 0 {
+		t.Errorf("held %v and released %v with %d attempts, want the tasting menu held with a single attempt", fake.held, fake.released, len(attempts))
+	}
+	// The default payment method of the user is used for checkout
+	if len(fake.checkout) != 1 || fake.checkout[0] == nil || *fake.checkout[0] != "302" {
+		t.Errorf("unexpected checkout payment methods %v", fake.checkout)
+	}
+	if result.SeatingType != "Tasting Menu" || result.Deposit == nil || result.Deposit.Fee != 250 {
+		t.Errorf("booked %s with deposit %v, want the tasting menu with its prepaid total", result.SeatingType, result.Deposit)
+	}
+	if result.PlatformConfirmation["purchase_id"] != 555001 || result.PlatformConfirmation["confirmation_code"] != "TK-8H2Q" {
+		t.Errorf("unexpected confirmation %v", result.PlatformConfirmation)
+	}
+}
+
+func TestTockClient_DepositPolicy(t *testing.T) {
+	fake := &fakeTock{}
+	client := newFakeTockClient(t, fake)
+	maxDeposit := float32(100)
+	event := Event{
+		PlatformVenueId: "test-kitchen",
+		ReservationDate: "2026-11-20",
+		PartySize:       2,
+		PreferredTimes:  []string{"19:30", "18:30"},
+		PaymentPolicy:   &PaymentPolicy{MaxDeposit: &maxDeposit},
+		Strategy:        &Strategy{Name: StrategySequential},
+		DropTime:        time.Now(),
+	}
+
+	slots, err := client.PollSlots(context.Background(), event)
+	if err != nil {
+		t.Fatalf("PollSlots failed: %v", err)
+	}
+	result, attempts, err := client.BookSlots(context.Background(), event, slots)
+	if err != nil {
+		t.Fatalf("BookSlots failed: %v", err)
+	}
+	if len(attempts) != 2 || !strings.Contains(attempts[0].Error, ErrDepositExceedsLimit.Error()) {
+		t.Errorf("got %d attempts, want the first failing as its total exceeds the limit", len(attempts))
+	}
+	// The hold of the prepaid slot is released once its total is rejected
+	if !slices.Equal(fake.released, []string{"hold-12"}) {
+		t.Errorf("released %v, want the prepaid hold", fake.released)
+	}
+	if result.ReservationTime.Format("15:04") != "18:30" || result.Deposit != nil {
+		t.Errorf("booked %s with deposit %v, want 18:30 without deposit", result.ReservationTime.Format("15:04"), result.Deposit)
+	}
+}
+
+func TestTockClient_PreBookingCheckPaymentMethod(t *testing.T) {
+	client := newFakeTockClient(t, &fakeTock{})
+	paymentMethodId := 8
+	event := Event{PaymentPolicy: &PaymentPolicy{PaymentMethodId: &paymentMethodId}}
+
+	if err := client.PreBookingCheck(context.Background(), event); !errors.Is(err, ErrPaymentMethodNotFound) {
+		t.Errorf("got %v, want %v", err, ErrPaymentMethodNotFound)
+	}
+
+	paymentMethodId = 301
+	if err := client.PreBookingCheck(context.Background(), event); err != nil {
+		t.Errorf("saved payment method: got %v, want nil", err)
+	}
+}
+
+func TestTockClient_UpgradeUnsupported(t *testing.T) {
+	client := newFakeTockClient(t, &fakeTock{})
+	upgrade := &Upgrade{ReservationDate: "2026-11-20", PartySize: 2, SlotTime: "19:30"}
+
+	if err := upgrade.check(client); !errors.Is(err, ErrUpgradeUnsupported) {
+		t.Errorf("got %v, want %v", err, ErrUpgradeUnsupported)
+	}
+}
@@ -11,24 +11,89 @@ import (
 // NOTE: Drop time must have a UTC timezone
 // NOTE: Strict preference represents whether the preference should be
 // absolutely respected or not (not recommended for highly competitive reservations)
+// NOTE: Strategy is the booking strategy of the slots, the strategy of the
+// preference being used if it is not set
+// NOTE: Preferred times are matched exactly, before the preferred windows
+// NOTE: Only slots of the allowed seating types are booked if any are set, and
+// slots of the preferred seating types are preferred in their order
+// Seating types are matched case insensitively, such as "Dining Room" or "Bar"
+// NOTE: Payment policy restricts the deposits of the booked slots and the payment method used
+// NOTE: Dry runs perform every step of the job, retrieving the slots once at the drop
+// time, but stop before booking and report the slot that would have been booked
+// NOTE: The default warm up is used if no warm up is set
+// NOTE: Watch is set for watch jobs, which poll for slots from the drop time
+// NOTE: Upgrade is set for jobs upgrading a booked slot, which only book slots
+// more preferred than the booked slot and then cancel it
+// NOTE: Alternatives are booked if no slot of the reservation date and party
+// size could be booked, in order of preference, and must be released at the drop time
 type Event struct {
-	JobID                   uuid.UUID `json:"job_id"`
-	Platform                string    `json:"platform"`
-	PlatformVenueId         string    `json:"platform_venue_id"`
-	EncryptedToken          string    `json:"encrypted_token"`
-	EncryptedCallbackSecret string    `json:"encrypted_callback_secret"`
-	ReservationDate         string    `json:"reservation_date"` // YYYY-MM-DD
-	PartySize               int16     `json:"party_size"`
-	PreferredTimes          []string  `json:"preferred_times"` // HH:mm
-	DropTime                time.Time `json:"drop_time"`
-	ServerEndpoint          string    `json:"server_endpoint"`
-	Callback                bool      `json:"callback"`
-	StrictPreference        bool      `json:"strict_preference"`
+	JobID                   uuid.UUID      `json:"job_id"`
+	Platform                string         `json:"platform"`
+	PlatformVenueId         string         `json:"platform_venue_id"`
+	EncryptedToken          string         `json:"encrypted_token"`
+	EncryptedCallbackSecret string         `json:"encrypted_callback_secret"`
+	ReservationDate         string         `json:"reservation_date"` // YYYY-MM-DD
+	PartySize               int16          `json:"party_size"`
+	PreferredTimes          []string       `json:"preferred_times"` // HH:mm
+	PreferredWindows        []TimeWindow   `json:"preferred_windows,omitempty"`
+	Alternatives            []Alternative  `json:"alternatives,omitempty"`
+	Watch                   *Watch         `json:"watch,omitempty"`
+	Upgrade                 *Upgrade       `json:"upgrade,omitempty"`
+	AllowedSeatingTypes     []string       `json:"allowed_seating_types,omitempty"`
+	PreferredSeatingTypes   []string       `json:"preferred_seating_types,omitempty"`
+	PaymentPolicy           *PaymentPolicy `json:"payment_policy,omitempty"`
+	DryRun                  bool           `json:"dry_run,omitempty"`
+	WarmUp                  *WarmUp        `json:"warm_up,omitempty"`
+	DropTime                time.Time      `json:"drop_time"`
+	ServerEndpoint          string         `json:"server_endpoint"`
+	Callback                bool           `json:"callback"`
+	StrictPreference        bool           `json:"strict_preference"`
+	Strategy                *Strategy      `json:"strategy,omitempty"`
+}
+
+// Preferred time window of a reservation
+// Slots between the start and end of the window, extended on both sides
+// by the tolerance, match the window and are ranked by their distance
+// to the ideal time of the window
+type TimeWindow struct {
+	Start     string `json:"start"`               // HH:mm
+	End       string `json:"end,omitempty"`       // HH:mm, start if empty
+	Ideal     string `json:"ideal,omitempty"`     // HH:mm, start if empty
+	Tolerance int16  `json:"tolerance,omitempty"` // Minutes
+}
+
+// Alternative reservation date and party size of an event
+type Alternative struct {
+	ReservationDate string `json:"reservation_date"` // YYYY-MM-DD
+	PartySize       int16  `json:"party_size"`
+}
+
+// Returns the reservation date and party size of the event
+// followed by its alternatives in order of preference
+func (e Event) targets() []Alternative {
+	targets := make([]Alternative, 0, len(e.Alternatives)+1)
+	targets = append(targets, Alternative{
+		ReservationDate: e.ReservationDate,
+		PartySize:       e.PartySize,
+	})
+	return append(targets, e.Alternatives...)
+}
+
+// Represents the notification sent to the server once a job
+// has passed its pre-booking checks and is waiting for the drop
+type Started struct {
+	JobId     uuid.UUID `json:"job_id"`
+	StartTime time.Time `json:"start_time"`
 }
 
 // Result of a booking
+// NOTE: Reservation date and party size are those of the booked alternative
 type BookingResult struct {
 	ReservationTime      time.Time      `json:"reservation_time"`
+	ReservationDate      string         `json:"reservation_date,omitempty"` // YYYY-MM-DD
+	PartySize            int16          `json:"party_size,omitempty"`
+	SeatingType          string         `json:"seating_type,omitempty"`
+	Deposit              *Deposit       `json:"deposit,omitempty"`
 	PlatformConfirmation map[string]any `json:"platform_confirmation"`
 }
 
@@ -36,26 +101,56 @@ type BookingResult struct {
 // as well as the log event for the Lambda
 // It is sent back to the server at completion
 // and logged to stdout
+// NOTE: Notified is only set after the server has been notified and
+// as such is only true in the output that is logged to stdout
+// NOTE: StartNotified is set if the server was notified that the job started
+// NOTE: Clock offset is the offset of the local clock against the clock source,
+// drift being measured against the drop time corrected by the offset if the
+// offset was applied, which is reported by ClockCorrected
+// NOTE: Polls are only set for watch jobs and jobs with early polls and only
+// contain the most recent polls
+// NOTE: The booking result of a dry run is the slot that would have been
+// booked and has no platform confirmation
+// NOTE: Payment policy is the payment policy of the event and the deposit of the
+// booked slot, if any, is part of the booking result
+// NOTE: Upgrade is the outcome of the cancellation of the booked slot of an upgrade
 type Output struct {
-	JobId           uuid.UUID     `json:"job_id"`
-	Success         bool          `json:"success"`
-	Duration        time.Duration `json:"duration"`
-	Message         string        `json:"message"`
-	Error           string        `json:"error,omitempty"`
-	Level           string        `json:"level"`
-	StartTime       time.Time     `json:"start_time"`
-	BookingStart    time.Time     `json:"booking_start"`
-	DriftNs         int64         `json:"drift_ns"`
-	BookingAttempts []Attempt     `json:"booking_attempts"`
+	JobId           uuid.UUID      `json:"job_id"`
+	Success         bool           `json:"success"`
+	Notified        bool           `json:"notified"`
+	StartNotified   bool           `json:"start_notified"`
+	Duration        time.Duration  `json:"duration"`
+	Message         string         `json:"message"`
+	Error           string         `json:"error,omitempty"`
+	Level           string         `json:"level"`
+	StartTime       time.Time      `json:"start_time"`
+	BookingStart    time.Time      `json:"booking_start"`
+	DriftNs         int64          `json:"drift_ns"`
+	BookingAttempts []Attempt      `json:"booking_attempts"`
+	PollCount       int            `json:"poll_count,omitempty"`
+	Polls           []Poll         `json:"polls,omitempty"`
+	PaymentPolicy   *PaymentPolicy `json:"payment_policy,omitempty"`
+	DryRun          bool           `json:"dry_run,omitempty"`
+	Timings         Timings        `json:"timings"`
+	ClockOffsetNs   int64          `json:"clock_offset_ns"`
+	ClockSource     string         `json:"clock_source,omitempty"`
+	ClockCorrected  bool           `json:"clock_corrected"`
+	ClockError      string         `json:"clock_error,omitempty"`
+	WarmUpError     string         `json:"warm_up_error,omitempty"`
+	Upgrade         *UpgradeResult `json:"upgrade,omitempty"`
 	BookingResult
 }
 
 // Booking attempt
 // NOTE: Slot time is in a UTC timezone
+// NOTE: Decision is the decision of the booking strategy that led to the attempt
 type Attempt struct {
-	Result    *BookingResult `json:"result"`
-	Error     string         `json:"error,omitempty"`
-	SlotTime  time.Time      `json:"slot_time"`
-	StartTime time.Time      `json:"start_time"`
-	Duration  time.Duration  `json:"duration"`
+	Result          *BookingResult   `json:"result"`
+	Error           string           `json:"error,omitempty"`
+	SlotTime        time.Time        `json:"slot_time"`
+	ReservationDate string           `json:"reservation_date,omitempty"` // YYYY-MM-DD
+	PartySize       int16            `json:"party_size,omitempty"`
+	StartTime       time.Time        `json:"start_time"`
+	Duration        time.Duration    `json:"duration"`
+	Decision        StrategyDecision `json:"decision"`
 }
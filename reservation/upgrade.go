@@ -0,0 +1,103 @@
+package reservation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrUpgradeNotCancellable = errors.New("booked slot can no longer be cancelled without a fee")
+	ErrUpgradeUnsupported    = errors.New("platform does not support cancelling bookings")
+)
+
+// Booked slot of an event that is upgraded to a more preferred slot
+// Only slots ranked before the booked slot, using the preferences of the event,
+// are booked, after which the booked slot is cancelled using its platform confirmation
+// NOTE: Cancellation cut off is the time until which the booked slot can be
+// cancelled without a fee, the booked slot having no fee if it is not set,
+// and the watch of the event ends at the cut off
+type Upgrade struct {
+	ReservationDate      string         `json:"reservation_date"` // YYYY-MM-DD
+	PartySize            int16          `json:"party_size"`
+	SlotTime             string         `json:"slot_time"` // HH:mm
+	SeatingType          string         `json:"seating_type,omitempty"`
+	PlatformConfirmation map[string]any `json:"platform_confirmation"`
+	CancellationCutOff   *time.Time     `json:"cancellation_cut_off,omitempty"`
+}
+
+// Outcome of the cancellation of the booked slot of an upgrade
+// The more preferred slot is booked regardless of the outcome, so an error
+// means that both slots are booked
+type UpgradeResult struct {
+	Cancelled bool   `json:"cancelled"`
+	Error     string `json:"error,omitempty"`
+}
+
+// Returns an error if the booked slot of the upgrade cannot be cancelled without a fee by the client
+func (u *Upgrade) check(client BookingClient) error {
+	if _, ok := client.(bookingCanceller); !ok {
+		return ErrUpgradeUnsupported
+	}
+	if u.CancellationCutOff != nil && !time.Now().Before(*u.CancellationCutOff) {
+		return fmt.Errorf("%w: cut off at %s", ErrUpgradeNotCancellable, u.CancellationCutOff.UTC().Format(time.RFC3339))
+	}
+	return nil
+}
+
+// Returns the watch of an event ending at the cancellation cut off of the upgrade if it is earlier
+func (u *Upgrade) limitWatch(watch *Watch) *Watch {
+	if watch == nil || u.CancellationCutOff == nil || !u.CancellationCutOff.Before(watch.Until) {
+		return watch
+	}
+	limited := *watch
+	limited.Until = *u.CancellationCutOff
+	return &limited
+}
+
+// Returns whether a target is the reservation date and party size of the booked slot
+func (u *Upgrade) isTarget(target Alternative) bool {
+	return target.ReservationDate == u.ReservationDate && target.PartySize == u.PartySize
+}
+
+// Returns the booked time of the upgrade on its reservation date, in the
+// same representation as the slot times of the platforms
+func (u *Upgrade) slotTime() (time.Time, error) {
+	return time.Parse("2006-01-02 15:04", u.ReservationDate+" "+u.SlotTime)
+}
+
+// Returns the candidates ranked before the booked slot of an upgrade
+// The candidates are expected to include the booked slot, ranked with the slots of
+// its target, and no candidates are returned if it was not ranked as it then matches
+// none of the preferences of the event
+func upgradeCandidates[T any](candidates []candidate[T], isBooked func(candidate[T]) bool) []candidate[T] {
+	for i, c := range candidates {
+		if isBooked(c) {
+			return candidates[:i]
+		}
+	}
+	return nil
+}
+
+// Cancels the booked slot of the upgrade of an event once a more preferred slot was booked
+func cancelUpgraded(ctx context.Context, client BookingClient, event Event) *UpgradeResult {
+	result := &UpgradeResult{}
+	canceller, ok := client.(bookingCanceller)
+	if !ok {
+		result.Error = ErrUpgradeUnsupported.Error()
+		return result
+	}
+
+	// The more preferred slot is booked so the cancellation
+	// is attempted even if the context has expired
+	err := canceller.Cancel(context.WithoutCancel(ctx), BookingResult{
+		PlatformConfirmation: event.Upgrade.PlatformConfirmation,
+	})
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.Cancelled = true
+	return result
+}
@@ -0,0 +1,146 @@
+package reservation
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/daylamtayari/cierge/resy"
+)
+
+// Returns Resy slots at the given HH:mm times of a date of the given seating type
+func resySlots(t *testing.T, date string, seatingType string, values ...string) []resy.Slot {
+	t.Helper()
+	slots := make([]resy.Slot, 0, len(values))
+	for _, value := range values {
+		start, err := time.Parse("2006-01-02 15:04", date+" "+value)
+		if err != nil {
+			t.Fatalf("invalid slot time %q: %v", value, err)
+		}
+		slots = append(slots, resy.Slot{
+			Config: resy.SlotConfig{Token: date + " " + value, Type: seatingType},
+			Date:   resy.SlotDate{Start: resy.ResyDatetime{Time: start}},
+		})
+	}
+	return slots
+}
+
+// Returns the date and HH:mm time of candidates
+func candidateTimes(candidates []candidate[resy.Slot]) []string {
+	times := make([]string, 0, len(candidates))
+	for _, c := range candidates {
+		times = append(times, c.slot.Date.Start.Format("2006-01-02 15:04"))
+	}
+	return times
+}
+
+func TestRankResyCandidates_Upgrade(t *testing.T) {
+	targets := []Alternative{
+		{ReservationDate: "2026-11-20", PartySize: 2},
+		{ReservationDate: "2026-11-21", PartySize: 2},
+	}
+	targetSlots := [][]resy.Slot{
+		resySlots(t, "2026-11-20", "Dining Room", "18:00", "20:00"),
+		resySlots(t, "2026-11-21", "Dining Room", "18:00", "19:00", "20:00"),
+	}
+
+	tests := []struct {
+		name    string
+		upgrade *Upgrade
+		want    []string
+	}{
+		{
+			name: "no upgrade",
+			want: []string{"2026-11-20 20:00", "2026-11-20 18:00", "2026-11-21 20:00", "2026-11-21 19:00", "2026-11-21 18:00"},
+		},
+		{
+			name:    "third choice of the reservation date",
+			upgrade: &Upgrade{ReservationDate: "2026-11-20", PartySize: 2, SlotTime: "19:00", SeatingType: "Dining Room"},
+			want:    []string{"2026-11-20 20:00"},
+		},
+		{
+			name:    "alternative keeps the reservation date",
+			upgrade: &Upgrade{ReservationDate: "2026-11-21", PartySize: 2, SlotTime: "19:00", SeatingType: "Dining Room"},
+			want:    []string{"2026-11-20 20:00", "2026-11-20 18:00", "2026-11-21 20:00"},
+		},
+		{
+			name:    "most preferred slot",
+			upgrade: &Upgrade{ReservationDate: "2026-11-20", PartySize: 2, SlotTime: "20:00", SeatingType: "Dining Room"},
+			want:    []string{},
+		},
+		{
+			name:    "preferred seating type of the same time",
+			upgrade: &Upgrade{ReservationDate: "2026-11-20", PartySize: 2, SlotTime: "20:00", SeatingType: "Bar"},
+			want:    []string{"2026-11-20 20:00"},
+		},
+		{
+			name:    "booked slot matching no preference",
+			upgrade: &Upgrade{ReservationDate: "2026-11-20", PartySize: 2, SlotTime: "12:00"},
+			want:    []string{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			event := Event{
+				PreferredTimes:        []string{"20:00", "19:00", "18:00"},
+				PreferredSeatingTypes: []string{"Dining Room"},
+				Upgrade:               test.upgrade,
+			}
+			got := candidateTimes(rankResyCandidates(targetSlots, targets, event))
+			if !slices.Equal(got, test.want) {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestUpgradeCheck(t *testing.T) {
+	past := time.Now().Add(-time.Minute)
+	future := time.Now().Add(time.Hour)
+
+	if err := (&Upgrade{}).check(&watchClient{}); !errors.Is(err, ErrUpgradeUnsupported) {
+		t.Errorf("client without cancellation: got %v, want %v", err, ErrUpgradeUnsupported)
+	}
+	if err := (&Upgrade{CancellationCutOff: &past}).check(&strategyClient{}); !errors.Is(err, ErrUpgradeNotCancellable) {
+		t.Errorf("past cut off: got %v, want %v", err, ErrUpgradeNotCancellable)
+	}
+	if err := (&Upgrade{CancellationCutOff: &future}).check(&strategyClient{}); err != nil {
+		t.Errorf("future cut off: unexpected error %v", err)
+	}
+}
+
+func TestUpgradeLimitWatch(t *testing.T) {
+	cutOff := time.Now().Add(time.Hour)
+	watch := &Watch{Until: cutOff.Add(time.Hour), Interval: time.Minute}
+
+	limited := (&Upgrade{CancellationCutOff: &cutOff}).limitWatch(watch)
+	if !limited.Until.Equal(cutOff) || limited.Interval != watch.Interval {
+		t.Errorf("got watch until %s, want until the cut off %s", limited.Until, cutOff)
+	}
+	if !watch.Until.Equal(cutOff.Add(time.Hour)) {
+		t.Error("limiting the watch modified the watch of the event")
+	}
+	if got := (&Upgrade{}).limitWatch(watch); got != watch {
+		t.Error("watch without cut off was limited")
+	}
+}
+
+func TestCancelUpgraded(t *testing.T) {
+	client := &strategyClient{}
+	event := Event{Upgrade: &Upgrade{PlatformConfirmation: map[string]any{"slot": 4}}}
+
+	result := cancelUpgraded(context.Background(), client, event)
+	if !result.Cancelled || result.Error != "" {
+		t.Errorf("got %+v, want cancelled", result)
+	}
+	if !slices.Equal(client.cancelled, []int{4}) {
+		t.Errorf("cancelled slots %v, want [4]", client.cancelled)
+	}
+
+	if result := cancelUpgraded(context.Background(), &watchClient{}, event); result.Cancelled {
+		t.Error("cancelled the booked slot with a client that cannot cancel bookings")
+	}
+}
@@ -0,0 +1,134 @@
+package reservation
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// Interval at which warmed connections are kept alive until the drop
+	warmUpKeepAlive = 20 * time.Second
+	// Maximum number of connections opened by a warm up
+	maxWarmUpConnections = 16
+)
+
+// Warm up of an event used if none is specified
+var defaultWarmUp = WarmUp{
+	Lead:        10 * time.Second,
+	Connections: 4,
+}
+
+// Warm up configuration of an event
+// Connections to the platform are opened before the drop and kept alive until
+// the drop, starting at the lead duration before the first early poll, or
+// before the drop time if there are no early polls
+// Early polls are a burst of polls of the slots spaced by the early poll interval,
+// the last being one interval before the drop time, booking slots released ahead of it
+type WarmUp struct {
+	Lead              time.Duration `json:"lead"`
+	Connections       int           `json:"connections"`
+	EarlyPolls        int           `json:"early_polls,omitempty"`
+	EarlyPollInterval time.Duration `json:"early_poll_interval,omitempty"`
+}
+
+// Durations of the phases of a job, used to measure the latency of each phase
+type Timings struct {
+	Decrypt         time.Duration `json:"decrypt"`
+	PreBookingCheck time.Duration `json:"pre_booking_check"`
+	WarmUp          time.Duration `json:"warm_up,omitempty"`
+	EarlyPolls      time.Duration `json:"early_polls,omitempty"`
+	FetchSlots      time.Duration `json:"fetch_slots,omitempty"`
+	Booking         time.Duration `json:"booking,omitempty"`
+}
+
+// Returns the warm up configuration of an event
+// Early polls are not performed for watch jobs as they poll from the drop time
+func (e Event) warmUp() WarmUp {
+	config := defaultWarmUp
+	if e.WarmUp != nil {
+		config = *e.WarmUp
+	}
+	config.Connections = min(max(config.Connections, 1), maxWarmUpConnections)
+	if e.Watch != nil || config.EarlyPollInterval <= 0 {
+		config.EarlyPolls = 0
+	}
+	return config
+}
+
+// Returns the time of the first early poll of the warm up, or the drop time if there are none
+func (w WarmUp) earlyPollStart(dropTime time.Time) time.Time {
+	return dropTime.Add(-time.Duration(w.EarlyPolls) * w.EarlyPollInterval)
+}
+
+// Warms up the booking client before the drop time and performs the early polls of the event
+// Waits until the start of the warm up before warming up, and warm up errors are recorded
+// in the output but are not fatal as the booking can still be performed without
+// Returns the matching slots found by an early poll and whether any were found
+func warmUp(ctx context.Context, client BookingClient, event Event, output *Output) (any, bool) {
+	config := event.warmUp()
+	pollStart := config.earlyPollStart(event.DropTime)
+	waitUntil(ctx, pollStart.Add(-config.Lead))
+
+	start := time.Now()
+	for ctx.Err() == nil {
+		if err := client.WarmUp(ctx, event, config.Connections); err != nil {
+			output.WarmUpError = err.Error()
+		}
+		next := time.Now().Add(warmUpKeepAlive)
+		if next.After(pollStart) {
+			break
+		}
+		waitUntil(ctx, next)
+	}
+	output.Timings.WarmUp = time.Since(start)
+
+	if config.EarlyPolls == 0 {
+		return nil, false
+	}
+
+	start = time.Now()
+	defer func() {
+		output.Timings.EarlyPolls = time.Since(start)
+	}()
+	for i := range config.EarlyPolls {
+		waitUntil(ctx, pollStart.Add(time.Duration(i)*config.EarlyPollInterval))
+		if ctx.Err() != nil {
+			return nil, false
+		}
+		if slots, err := pollSlots(ctx, client, event, output); err == nil {
+			return slots, true
+		}
+	}
+	return nil, false
+}
+
+// Opens connections to a host concurrently using an HTTP client so that they
+// are kept idle and reused by the requests of the client at the drop time
+func warmUpConnections(ctx context.Context, httpClient *http.Client, host string, connections int) error {
+	errs := make([]error, connections)
+	var wg sync.WaitGroup
+	for i := range connections {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			req, err := http.NewRequestWithContext(ctx, http.MethodHead, host, nil)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			res, err := httpClient.Do(req)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			_, _ = io.Copy(io.Discard, res.Body)
+			errs[i] = res.Body.Close()
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
@@ -0,0 +1,63 @@
+package reservation
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestEventWarmUp(t *testing.T) {
+	earlyPolls := &WarmUp{Lead: time.Second, Connections: 64, EarlyPolls: 3, EarlyPollInterval: 100 * time.Millisecond}
+
+	if got := (Event{}).warmUp(); got != defaultWarmUp {
+		t.Errorf("default warm up: got %+v, want %+v", got, defaultWarmUp)
+	}
+	if got := (Event{WarmUp: earlyPolls}).warmUp(); got.Connections != maxWarmUpConnections || got.EarlyPolls != 3 {
+		t.Errorf("warm up: got %+v, want %d connections and 3 early polls", got, maxWarmUpConnections)
+	}
+	if got := (Event{WarmUp: earlyPolls, Watch: &Watch{}}).warmUp(); got.EarlyPolls != 0 {
+		t.Errorf("watch warm up: got %d early polls, want 0", got.EarlyPolls)
+	}
+}
+
+func TestWarmUp_EarlyPollFindsSlots(t *testing.T) {
+	client := &watchClient{polls: []error{ErrNoMatchingSlotsFound, nil}}
+	event := Event{
+		DropTime: time.Now().Add(200 * time.Millisecond),
+		WarmUp:   &WarmUp{Connections: 1, EarlyPolls: 3, EarlyPollInterval: 50 * time.Millisecond},
+	}
+
+	var output Output
+	slots, found := warmUp(context.Background(), client, event, &output)
+	if !found {
+		t.Fatal("expected an early poll to find slots")
+	}
+	if got := slots.([]int); len(got) != 1 || got[0] != 1 {
+		t.Errorf("slots: got %v, want slots of the second poll", got)
+	}
+	if client.warmUps != 1 {
+		t.Errorf("warm ups: got %d, want 1", client.warmUps)
+	}
+	if output.PollCount != 2 {
+		t.Errorf("polls: got %d, want 2", output.PollCount)
+	}
+	if time.Now().After(event.DropTime) {
+		t.Error("slots were not found ahead of the drop time")
+	}
+}
+
+func TestWarmUp_WithoutEarlyPolls(t *testing.T) {
+	client := &watchClient{}
+	event := Event{
+		DropTime: time.Now().Add(50 * time.Millisecond),
+		WarmUp:   &WarmUp{Lead: time.Second, Connections: 2},
+	}
+
+	var output Output
+	if _, found := warmUp(context.Background(), client, event, &output); found {
+		t.Error("expected no slots without early polls")
+	}
+	if client.warmUps != 1 || client.pollIdx != 0 {
+		t.Errorf("got %d warm ups and %d polls, want 1 warm up and no polls", client.warmUps, client.pollIdx)
+	}
+}
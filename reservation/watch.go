@@ -0,0 +1,123 @@
+package reservation
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"time"
+)
+
+var (
+	ErrRateLimited  = errors.New("rate limited by the platform")
+	ErrWatchExpired = errors.New("watch deadline reached without booking a slot")
+)
+
+const (
+	// Minimum interval between polls of a watch to avoid being rate limited
+	MinWatchInterval = 15 * time.Second
+	// Time reserved at the end of the execution of the handler to notify the
+	// server of the output of a watch if the context has a deadline
+	watchCallbackReserve = time.Minute
+	// Maximum number of polls recorded in the output, the oldest being dropped
+	maxRecordedPolls = 500
+)
+
+// Minimum interval between polls enforced by the watch
+var minWatchInterval = MinWatchInterval
+
+// Watch configuration of an event
+// A watch polls the slots of the event from the drop time until its
+// deadline and books the first matching slot, such as from cancellations
+// The interval between polls is doubled on errors up to the maximum interval
+type Watch struct {
+	Until       time.Time     `json:"until"`
+	Interval    time.Duration `json:"interval"`
+	MaxInterval time.Duration `json:"max_interval,omitempty"` // 8 times the interval if not set
+}
+
+// Poll cycle of a watch
+type Poll struct {
+	Time     time.Time     `json:"time"`
+	Duration time.Duration `json:"duration"`
+	Found    bool          `json:"found"`
+	Error    string        `json:"error,omitempty"`
+}
+
+// Polls the slots of an event until a matching slot is booked or the watch deadline is reached
+// The deadline is brought forward if the context expires before it so that the server
+// can still be notified of the output
+// Each poll and booking attempt is recorded in the output
+// Returns the result of the booking or ErrWatchExpired if no slot was booked
+func watch(ctx context.Context, client BookingClient, event Event, output *Output) (*BookingResult, error) {
+	deadline := event.Watch.Until
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Add(-watchCallbackReserve).Before(deadline) {
+		deadline = ctxDeadline.Add(-watchCallbackReserve)
+	}
+
+	interval := max(event.Watch.Interval, minWatchInterval)
+	maxInterval := event.Watch.MaxInterval
+	if maxInterval < interval {
+		maxInterval = 8 * interval
+	}
+
+	backoff := interval
+	for {
+		slots, err := pollSlots(ctx, client, event, output)
+		if err == nil {
+			// Slots may be booked by others before they can be booked
+			// in which case the watch continues
+			result, attempts, bookErr := client.BookSlots(ctx, event, slots)
+			output.BookingAttempts = append(output.BookingAttempts, attempts...)
+			if bookErr == nil {
+				return result, nil
+			}
+		}
+
+		// Polls that did not find matching slots are expected and
+		// keep the interval while errors back off the polling
+		if err == nil || errors.Is(err, ErrNoMatchingSlotsFound) || errors.Is(err, ErrNoSlotsFound) {
+			backoff = interval
+		} else {
+			backoff = min(2*backoff, maxInterval)
+		}
+
+		next := jitter(backoff)
+		if time.Now().Add(next).After(deadline) {
+			return nil, ErrWatchExpired
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(next):
+		}
+	}
+}
+
+// Polls the matching slots of an event once and records the poll in the output
+func pollSlots(ctx context.Context, client BookingClient, event Event, output *Output) (any, error) {
+	poll := Poll{Time: time.Now().UTC()}
+	slots, err := client.PollSlots(ctx, event)
+	poll.Duration = time.Since(poll.Time)
+	poll.Found = err == nil
+	if err != nil {
+		poll.Error = err.Error()
+	}
+
+	output.PollCount++
+	if len(output.Polls) >= maxRecordedPolls {
+		output.Polls = output.Polls[1:]
+	}
+	output.Polls = append(output.Polls, poll)
+
+	return slots, err
+}
+
+// Returns a duration randomised by up to 10% in either direction
+// so that polls do not happen at a fixed cadence
+func jitter(duration time.Duration) time.Duration {
+	spread := duration / 10
+	if spread <= 0 {
+		return duration
+	}
+	return duration - spread + rand.N(2*spread+1)
+}
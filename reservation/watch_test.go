@@ -0,0 +1,113 @@
+package reservation
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// Booking client returning the poll results in order
+// and failing the booking of slots marked as taken
+type watchClient struct {
+	polls    []error
+	taken    map[int]bool
+	pollIdx  int
+	bookings int
+	warmUps  int
+}
+
+func (c *watchClient) PreBookingCheck(ctx context.Context, event Event) error {
+	return nil
+}
+
+func (c *watchClient) WarmUp(ctx context.Context, event Event, connections int) error {
+	c.warmUps++
+	return nil
+}
+
+func (c *watchClient) FetchSlots(ctx context.Context, event Event) (any, error) {
+	return nil, errors.New("not used by watch")
+}
+
+func (c *watchClient) PollSlots(ctx context.Context, event Event) (any, error) {
+	idx := c.pollIdx
+	c.pollIdx++
+	if idx >= len(c.polls) {
+		return nil, ErrNoMatchingSlotsFound
+	}
+	if c.polls[idx] != nil {
+		return nil, c.polls[idx]
+	}
+	return []int{idx}, nil
+}
+
+func (c *watchClient) Book(ctx context.Context, event Event, slot any) (Attempt, error) {
+	c.bookings++
+	if c.taken[slot.(int)] {
+		return Attempt{Error: "taken"}, errors.New("taken")
+	}
+	return Attempt{Result: &BookingResult{PlatformConfirmation: map[string]any{"poll": slot}}}, nil
+}
+
+func (c *watchClient) BookSlots(ctx context.Context, event Event, slots any) (*BookingResult, []Attempt, error) {
+	return bookingHandler(ctx, c, event, slots.([]int))
+}
+
+func setWatchInterval(t *testing.T, interval time.Duration) {
+	t.Helper()
+	defaultInterval := minWatchInterval
+	minWatchInterval = interval
+	t.Cleanup(func() { minWatchInterval = defaultInterval })
+}
+
+func TestWatch_BooksFirstMatchingSlot(t *testing.T) {
+	setWatchInterval(t, time.Millisecond)
+
+	client := &watchClient{
+		polls: []error{ErrNoMatchingSlotsFound, ErrRateLimited, nil, nil},
+		taken: map[int]bool{2: true},
+	}
+	event := Event{
+		StrictPreference: true,
+		Watch:            &Watch{Until: time.Now().Add(time.Second), Interval: time.Millisecond},
+	}
+
+	var output Output
+	result, err := watch(context.Background(), client, event, &output)
+	if err != nil {
+		t.Fatalf("watch failed: %v", err)
+	}
+	if result.PlatformConfirmation["poll"] != 3 {
+		t.Errorf("booked slot of poll %v, want 3", result.PlatformConfirmation["poll"])
+	}
+
+	if output.PollCount != 4 || len(output.Polls) != 4 {
+		t.Fatalf("polls: got %d recorded of %d, want 4", len(output.Polls), output.PollCount)
+	}
+	for i, want := range []bool{false, false, true, true} {
+		if output.Polls[i].Found != want {
+			t.Errorf("poll %d: found %t, want %t", i, output.Polls[i].Found, want)
+		}
+	}
+	if len(output.BookingAttempts) != 2 {
+		t.Errorf("booking attempts: got %d, want 2", len(output.BookingAttempts))
+	}
+}
+
+func TestWatch_ExpiresAtDeadline(t *testing.T) {
+	setWatchInterval(t, 10*time.Millisecond)
+
+	client := &watchClient{}
+	event := Event{
+		Watch: &Watch{Until: time.Now().Add(100 * time.Millisecond), Interval: 10 * time.Millisecond},
+	}
+
+	var output Output
+	if _, err := watch(context.Background(), client, event, &output); !errors.Is(err, ErrWatchExpired) {
+		t.Fatalf("expected watch to expire, got %v", err)
+	}
+	if output.PollCount == 0 {
+		t.Error("expected the watch to poll before expiring")
+	}
+}
@@ -25,6 +25,7 @@ var (
 	ErrNotFound           = errors.New("not found")
 	ErrPaymentRequired    = errors.New("payment required")
 	ErrPreconditionFailed = errors.New("precondition failed")
+	ErrTooManyRequests    = errors.New("too many requests")
 	ErrUnauthorized       = errors.New("unauthorized")
 	ErrUnhandledStatus    = errors.New("unhandled status code returned")
 )
@@ -203,6 +204,8 @@ func (c *Client) DoWithCookies(req *http.Request, v any) ([]*http.Cookie, error)
 		// Resy returns the 419 status code for 'Unauthorized' error messages
 		// why not a 401 or 403? don't ask me...
 		return nil, fmt.Errorf("%w: %v", ErrUnauthorized, string(body))
+	case 429:
+		return nil, fmt.Errorf("%w: %v", ErrTooManyRequests, string(body))
 	case 502:
 		// Identified that Resy will return 502s on various errors related to
 		// malformed or unexpected input values
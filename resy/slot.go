@@ -73,15 +73,24 @@ type SlotPayment struct {
 // Represents a slot's details that are
 // returned when fetching a slot's details
 // Numerous fields are returned but the booking
-// token is the only thing handled at this point
-// as a lot of the other fields contain
+// token and payment are the only things handled
+// at this point as a lot of the other fields contain
 // repetitive data that is mostly unnecessary if
 // you're not trying to render a frontend
 type SlotDetails struct {
 	BookingToken BookingToken `json:"book_token"`
+	Payment      SlotPayment  `json:"payment"`
 	User         User         `json:"user"`
 }
 
+// Returns whether the slot requires a deposit and its fee
+func (p SlotPayment) Deposit() (float32, bool) {
+	if p.DepositFee != nil && *p.DepositFee > 0 {
+		return *p.DepositFee, true
+	}
+	return 0, p.IsPaid
+}
+
 type BookingToken struct {
 	Expiry time.Time `json:"date_expires"`
 	Value  string    `json:"value"`
@@ -7,11 +7,13 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws/arn"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
 	"github.com/aws/aws-sdk-go-v2/service/kms"
 	"github.com/aws/aws-sdk-go-v2/service/scheduler"
 	schedulertypes "github.com/aws/aws-sdk-go-v2/service/scheduler/types"
@@ -38,15 +40,22 @@ var (
 	ErrCredentialValidation     = errors.New("invalid credentials provided")
 )
 
-const scheduleNamePrefix = "cierge-job-"
+const (
+	scheduleNamePrefix = "cierge-job-"
+
+	// Maximum duration of a Lambda invocation
+	maxInvocationDuration = 15 * time.Minute
+)
 
 type Provider struct {
 	scheduler         *scheduler.Client
 	kms               *kms.Client
+	logs              *cloudwatchlogs.Client
 	lambdaARN         string
 	roleARN           string
 	kmsKeyID          string
 	scheduleGroupName string
+	logGroupName      string
 	coldStartBuffer   time.Duration
 }
 
@@ -58,6 +67,7 @@ type providerConfig struct {
 	SchedulerRoleARN  string `json:"scheduler_role_arn"`
 	ScheduleGroupName string `json:"schedule_group_name"`
 	ColdStartBuffer   string `json:"cold_start_buffer"`
+	LogGroupName      string `json:"log_group_name"`
 	AccessKeyID       string `json:"access_key_id"`
 	SecretAccessKey   string `json:"secret_access_key"`
 	SessionToken      string `json:"session_token"`
@@ -82,13 +92,20 @@ func NewProvider(cfg map[string]any) (cloud.Provider, error) {
 		return nil, err
 	}
 
+	logGroupName := pCfg.LogGroupName
+	if logGroupName == "" {
+		logGroupName = defaultLogGroupName(pCfg.LambdaARN)
+	}
+
 	return &Provider{
 		scheduler:         scheduler.NewFromConfig(awsCfg),
 		kms:               kms.NewFromConfig(awsCfg),
+		logs:              cloudwatchlogs.NewFromConfig(awsCfg),
 		lambdaARN:         pCfg.LambdaARN,
 		roleARN:           pCfg.SchedulerRoleARN,
 		kmsKeyID:          pCfg.KMSKeyID,
 		scheduleGroupName: pCfg.ScheduleGroupName,
+		logGroupName:      logGroupName,
 		coldStartBuffer:   coldStartBuffer,
 	}, nil
 }
@@ -147,6 +164,36 @@ func (p *Provider) CancelJob(ctx context.Context, jobID uuid.UUID) error {
 	return nil
 }
 
+// Returns the job IDs of all EventBridge schedules in the schedule group
+// Schedules are deleted after completion so only pending schedules are listed
+func (p *Provider) ListScheduledJobs(ctx context.Context) ([]uuid.UUID, error) {
+	namePrefix := scheduleNamePrefix
+	paginator := scheduler.NewListSchedulesPaginator(p.scheduler, &scheduler.ListSchedulesInput{
+		GroupName:  &p.scheduleGroupName,
+		NamePrefix: &namePrefix,
+	})
+
+	var jobIDs []uuid.UUID
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, schedule := range page.Schedules {
+			if schedule.Name == nil {
+				continue
+			}
+			jobID, err := uuid.Parse(strings.TrimPrefix(*schedule.Name, scheduleNamePrefix))
+			if err != nil {
+				continue
+			}
+			jobIDs = append(jobIDs, jobID)
+		}
+	}
+
+	return jobIDs, nil
+}
+
 // Updates the platform credentials for a scheduled event
 func (p *Provider) UpdateJobCredentials(ctx context.Context, jobID uuid.UUID, encryptedToken string) error {
 	name := scheduleNamePrefix + jobID.String()
@@ -169,6 +216,9 @@ func (p *Provider) UpdateJobCredentials(ctx context.Context, jobID uuid.UUID, en
 	}
 
 	event.EncryptedToken = encryptedToken
+	if err := cloud.ReEncryptCallbackSecret(ctx, p, &event); err != nil {
+		return err
+	}
 
 	payload, err := json.Marshal(event)
 	if err != nil {
@@ -197,6 +247,52 @@ func (p *Provider) UpdateJobCredentials(ctx context.Context, jobID uuid.UUID, en
 	return nil
 }
 
+// Retrieves the output of an executed job from the CloudWatch logs of the lambda
+// The output is the JSON line written by the lambda after handling the event
+func (p *Provider) GetJobOutput(ctx context.Context, jobID uuid.UUID, scheduledAt time.Time) (reservation.Output, error) {
+	startTime := scheduledAt.Add(-p.coldStartBuffer).UnixMilli()
+	endTime := scheduledAt.Add(maxInvocationDuration).UnixMilli()
+	filterPattern := `{ $.job_id = "` + jobID.String() + `" }`
+
+	paginator := cloudwatchlogs.NewFilterLogEventsPaginator(p.logs, &cloudwatchlogs.FilterLogEventsInput{
+		LogGroupName:  &p.logGroupName,
+		FilterPattern: &filterPattern,
+		StartTime:     &startTime,
+		EndTime:       &endTime,
+	})
+
+	var output reservation.Output
+	found := false
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return output, err
+		}
+		for _, event := range page.Events {
+			if event.Message == nil {
+				continue
+			}
+			var eventOutput reservation.Output
+			if err := json.Unmarshal([]byte(*event.Message), &eventOutput); err != nil || eventOutput.JobId != jobID {
+				continue
+			}
+			output = eventOutput
+			found = true
+		}
+	}
+
+	if !found {
+		return output, fmt.Errorf("%w: %s", cloud.ErrOutputNotFound, jobID)
+	}
+	return output, nil
+}
+
+// Jobs are invoked the cold start buffer before their drop time and
+// are bounded by the maximum duration of a Lambda invocation
+func (p *Provider) MaxRunTime() time.Duration {
+	return maxInvocationDuration - p.coldStartBuffer
+}
+
 // Encrypts a provided string using KMS and returns the base64 encoded ciphertext
 func (p *Provider) EncryptData(ctx context.Context, plaintext string) (string, error) {
 	output, err := p.kms.Encrypt(ctx, &kms.EncryptInput{
@@ -311,6 +407,20 @@ func validateCredentials(cfg map[string]any, pCfg *providerConfig) error {
 	return nil
 }
 
+// Returns the default log group of a lambda from its ARN
+func defaultLogGroupName(lambdaARN string) string {
+	parsedARN, err := arn.Parse(lambdaARN)
+	if err != nil {
+		return ""
+	}
+	// Resource is function:<name> optionally followed by a qualifier
+	resource := strings.Split(parsedARN.Resource, ":")
+	if len(resource) < 2 {
+		return ""
+	}
+	return "/aws/lambda/" + resource[1]
+}
+
 // Decodes the config map into a struct
 func decodeConfig(cfg map[string]any) (providerConfig, error) {
 	var pCfg providerConfig
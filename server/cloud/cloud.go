@@ -6,6 +6,7 @@ import (
 	"maps"
 	"slices"
 	"strings"
+	"time"
 
 	"github.com/daylamtayari/cierge/reservation"
 	"github.com/google/uuid"
@@ -16,21 +17,30 @@ var (
 	ErrNilConstructor      = errors.New("cloud register constructor is nil")
 	ErrUnsupportedProvider = errors.New("unsupported cloud provider specified")
 	ErrJobNotFound         = errors.New("job was not found")
+	ErrOutputNotFound      = errors.New("job output was not found")
+	ErrScheduleTooFar      = errors.New("job is scheduled too far in advance for the cloud provider")
 )
 
 // Provider defines the interface that all cloud providers must implement
 type Provider interface {
 	// Creates a scheduled invocation of a reservation
+	// Returns an error wrapping ErrScheduleTooFar if the invocation is later
+	// than the provider can schedule
 	ScheduleJob(ctx context.Context, event reservation.Event) error
 
 	// Updates the encrypted platform token in an already-scheduled job
 	// For when a user updates their credentials but they have already scheduled jobs
+	// The encrypted callback secret of the job is re-encrypted under the current
+	// encryption key so that the job can be decrypted once previous keys are retired
 	UpdateJobCredentials(ctx context.Context, jobID uuid.UUID, encryptedToken string) error
 
 	// Deletes the scheduled invocation for a given job
 	// Returned error will be nil if the job already executed or does not exist
 	CancelJob(ctx context.Context, jobID uuid.UUID) error
 
+	// Returns the IDs of all jobs that have a pending scheduled invocation
+	ListScheduledJobs(ctx context.Context) ([]uuid.UUID, error)
+
 	// Encrypts plaintext and returns base64-encoded ciphertext
 	EncryptData(ctx context.Context, plaintext string) (string, error)
 
@@ -38,6 +48,43 @@ type Provider interface {
 	DecryptData(ctx context.Context, ciphertext string) (string, error)
 }
 
+// OutputReporter is implemented by providers that can report
+// the output of a job that was executed
+type OutputReporter interface {
+	// Returns the output of an executed job, the scheduled time of
+	// the job bounds the search for providers that search logs
+	// Returned error wraps ErrOutputNotFound if no output is available
+	GetJobOutput(ctx context.Context, jobID uuid.UUID, scheduledAt time.Time) (reservation.Output, error)
+}
+
+// RunTimeLimiter is implemented by providers that bound the execution
+// time of a job, which bounds how long watch jobs can poll for
+type RunTimeLimiter interface {
+	// Returns the maximum duration a job can run for from its drop time
+	MaxRunTime() time.Duration
+}
+
+// Re-encrypts the encrypted callback secret of a scheduled event under the
+// current encryption key of the provider
+// Events without a callback secret are left unchanged
+func ReEncryptCallbackSecret(ctx context.Context, provider Provider, event *reservation.Event) error {
+	if event.EncryptedCallbackSecret == "" {
+		return nil
+	}
+
+	callbackSecret, err := provider.DecryptData(ctx, event.EncryptedCallbackSecret)
+	if err != nil {
+		return err
+	}
+	encryptedCallbackSecret, err := provider.EncryptData(ctx, callbackSecret)
+	if err != nil {
+		return err
+	}
+
+	event.EncryptedCallbackSecret = encryptedCallbackSecret
+	return nil
+}
+
 // Represents a cloud provider's constructor
 type ProviderConstructor func(config map[string]any) (Provider, error)
 
@@ -0,0 +1,159 @@
+package gcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const (
+	defaultTasksEndpoint = "https://cloudtasks.googleapis.com"
+
+	cloudPlatformScope = "https://www.googleapis.com/auth/cloud-platform"
+)
+
+// Error returned by a Google Cloud API
+type APIError struct {
+	StatusCode int
+	Status     string `json:"status"`
+	Message    string `json:"message"`
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("google cloud API error %d %s: %s", e.StatusCode, e.Status, e.Message)
+}
+
+// Minimal client of the Cloud Tasks REST API
+// The HTTP client is expected to add the authentication to requests
+type apiClient struct {
+	httpClient    *http.Client
+	tasksEndpoint string
+}
+
+// Cloud Tasks task
+// See https://cloud.google.com/tasks/docs/reference/rest/v2/projects.locations.queues.tasks
+type task struct {
+	Name             string       `json:"name,omitempty"`
+	ScheduleTime     string       `json:"scheduleTime,omitempty"`
+	DispatchDeadline string       `json:"dispatchDeadline,omitempty"`
+	HTTPRequest      *httpRequest `json:"httpRequest,omitempty"`
+}
+
+type httpRequest struct {
+	URL        string            `json:"url"`
+	HTTPMethod string            `json:"httpMethod"`
+	Headers    map[string]string `json:"headers,omitempty"`
+	Body       []byte            `json:"body,omitempty"` // Base64 encoded when marshalled
+	OIDCToken  *oidcToken        `json:"oidcToken,omitempty"`
+}
+
+type oidcToken struct {
+	ServiceAccountEmail string `json:"serviceAccountEmail"`
+	Audience            string `json:"audience,omitempty"`
+}
+
+// Creates a task in a queue
+func (c *apiClient) createTask(ctx context.Context, queue string, t task) (task, error) {
+	reqBody := map[string]any{
+		"task":         t,
+		"responseView": "FULL",
+	}
+
+	var created task
+	err := c.do(ctx, http.MethodPost, c.tasksEndpoint+"/v2/"+queue+"/tasks", reqBody, &created)
+	return created, err
+}
+
+// Lists all tasks in a queue including their HTTP request body
+func (c *apiClient) listTasks(ctx context.Context, queue string) ([]task, error) {
+	var tasks []task
+	pageToken := ""
+	for {
+		query := url.Values{}
+		query.Set("responseView", "FULL")
+		query.Set("pageSize", "1000")
+		if pageToken != "" {
+			query.Set("pageToken", pageToken)
+		}
+
+		var res struct {
+			Tasks         []task `json:"tasks"`
+			NextPageToken string `json:"nextPageToken"`
+		}
+		if err := c.do(ctx, http.MethodGet, c.tasksEndpoint+"/v2/"+queue+"/tasks?"+query.Encode(), nil, &res); err != nil {
+			return nil, err
+		}
+
+		tasks = append(tasks, res.Tasks...)
+		if res.NextPageToken == "" {
+			return tasks, nil
+		}
+		pageToken = res.NextPageToken
+	}
+}
+
+// Deletes a task
+func (c *apiClient) deleteTask(ctx context.Context, name string) error {
+	return c.do(ctx, http.MethodDelete, c.tasksEndpoint+"/v2/"+name, nil, nil)
+}
+
+// Performs an API request, marshalling the request body and unmarshalling
+// the response into a given value if it is not nil
+// Returns an *APIError if a non-200 status code is returned
+func (c *apiClient) do(ctx context.Context, method string, reqUrl string, reqBody any, v any) error {
+	var body io.Reader
+	if reqBody != nil {
+		payload, err := json.Marshal(reqBody)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, reqUrl, body)
+	if err != nil {
+		return err
+	}
+	if reqBody != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close() //nolint:errcheck
+
+	resBody, err := io.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+
+	if res.StatusCode != http.StatusOK {
+		apiErr := struct {
+			Error APIError `json:"error"`
+		}{}
+		_ = json.Unmarshal(resBody, &apiErr)
+		apiErr.Error.StatusCode = res.StatusCode
+		if apiErr.Error.Message == "" {
+			apiErr.Error.Message = strings.TrimSpace(string(resBody))
+		}
+		return &apiErr.Error
+	}
+
+	if v == nil {
+		return nil
+	}
+	return json.Unmarshal(resBody, v)
+}
+
+// Returns whether an error is a not found API error
+func isNotFound(err error) bool {
+	apiErr, ok := err.(*APIError)
+	return ok && apiErr.StatusCode == http.StatusNotFound
+}
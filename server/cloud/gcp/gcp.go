@@ -0,0 +1,420 @@
+package gcp
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/daylamtayari/cierge/reservation"
+	"github.com/daylamtayari/cierge/reservation/gcpkms"
+	"github.com/daylamtayari/cierge/server/cloud"
+	"github.com/go-viper/mapstructure/v2"
+	"github.com/google/uuid"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+)
+
+var (
+	ErrDecodeConfig               = errors.New("failed to decode config")
+	ErrMissingProjectID           = errors.New("project ID is required")
+	ErrMissingLocation            = errors.New("location is required")
+	ErrMissingQueue               = errors.New("queue is required")
+	ErrMissingKMSKeyName          = errors.New("KMS key name is required")
+	ErrMissingExecutorURL         = errors.New("executor URL is required")
+	ErrMissingColdStartBuffer     = errors.New("cold start buffer is required")
+	ErrInvalidColdStartBuffer     = errors.New("cold start buffer is not a valid duration")
+	ErrInvalidDispatchDeadline    = errors.New("dispatch deadline is not a valid duration")
+	ErrDispatchDeadlineOutOfRange = errors.New("dispatch deadline must be between 15 seconds and 30 minutes")
+	ErrInvalidKMSKeyName          = errors.New("KMS key name value is invalid")
+	ErrInvalidExecutorURL         = errors.New("executor URL value is invalid")
+	ErrInsecureExecutorURL        = errors.New("executor URL must use HTTPS in production")
+	ErrMissingCredentials         = errors.New("no credentials found in environment or config")
+	ErrCredentialValidation       = errors.New("invalid credentials provided")
+)
+
+const (
+	taskNamePrefix = "cierge-job-"
+
+	// Cloud Tasks rejects tasks scheduled more than 30 days in the future
+	maxScheduleAhead = 30 * 24 * time.Hour
+
+	defaultDispatchDeadline = 30 * time.Minute
+	minDispatchDeadline     = 15 * time.Second
+	maxDispatchDeadline     = 30 * time.Minute
+)
+
+var kmsKeyNameRegex = regexp.MustCompile(`^projects/[^/]+/locations/[^/]+/keyRings/[^/]+/cryptoKeys/[^/]+$`)
+
+// GCP provider that schedules reservation jobs as Cloud Tasks HTTP tasks
+// targeting the executor service and encrypts data with Cloud KMS
+// Task names cannot be reused for some time after deletion so each
+// task of a job has a unique name starting with the job ID
+type Provider struct {
+	client              *apiClient
+	kms                 *gcpkms.Client
+	queue               string
+	executorURL         string
+	serviceAccountEmail string
+	audience            string
+	coldStartBuffer     time.Duration
+	dispatchDeadline    time.Duration
+}
+
+// GCP provider configuration
+type providerConfig struct {
+	ProjectID           string `json:"project_id"`
+	Location            string `json:"location"`
+	Queue               string `json:"queue"`
+	KMSKeyName          string `json:"kms_key_name"`
+	ExecutorURL         string `json:"executor_url"`
+	ServiceAccountEmail string `json:"service_account_email"`
+	Audience            string `json:"audience"`
+	ColdStartBuffer     string `json:"cold_start_buffer"`
+	DispatchDeadline    string `json:"dispatch_deadline"`
+	CredentialsFile     string `json:"credentials_file"`
+}
+
+// Creates a new GCP provider
+func NewProvider(cfg map[string]any) (cloud.Provider, error) {
+	pCfg, err := decodeConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	creds, err := findCredentials(context.Background(), pCfg)
+	if err != nil {
+		return nil, err
+	}
+
+	httpClient := oauth2.NewClient(context.Background(), creds.TokenSource)
+	return newProvider(pCfg, httpClient, defaultTasksEndpoint, gcpkms.DefaultEndpoint), nil
+}
+
+// Creates a new GCP provider using the given HTTP client and API endpoints
+func newProvider(pCfg providerConfig, httpClient *http.Client, tasksEndpoint string, kmsEndpoint string) *Provider {
+	// Values have already been validated
+	coldStartBuffer, _ := time.ParseDuration(pCfg.ColdStartBuffer)
+	dispatchDeadline := defaultDispatchDeadline
+	if pCfg.DispatchDeadline != "" {
+		dispatchDeadline, _ = time.ParseDuration(pCfg.DispatchDeadline)
+	}
+
+	audience := pCfg.Audience
+	if audience == "" {
+		audience = pCfg.ExecutorURL
+	}
+
+	return &Provider{
+		client: &apiClient{
+			httpClient:    httpClient,
+			tasksEndpoint: strings.TrimSuffix(tasksEndpoint, "/"),
+		},
+		kms:                 gcpkms.NewClient(httpClient, kmsEndpoint, pCfg.KMSKeyName),
+		queue:               "projects/" + pCfg.ProjectID + "/locations/" + pCfg.Location + "/queues/" + pCfg.Queue,
+		executorURL:         pCfg.ExecutorURL,
+		serviceAccountEmail: pCfg.ServiceAccountEmail,
+		audience:            audience,
+		coldStartBuffer:     coldStartBuffer,
+		dispatchDeadline:    dispatchDeadline,
+	}
+}
+
+// Creates a Cloud Tasks task that invokes the executor
+// with the reservation event as the payload
+// Returns an error wrapping cloud.ErrScheduleTooFar if the task would be
+// scheduled more than 30 days in the future
+func (p *Provider) ScheduleJob(ctx context.Context, event reservation.Event) error {
+	scheduledAt := event.DropTime.Add(-p.coldStartBuffer)
+	if time.Until(scheduledAt) > maxScheduleAhead {
+		return fmt.Errorf("%w: Cloud Tasks cannot schedule tasks more than 30 days in advance", cloud.ErrScheduleTooFar)
+	}
+	_, err := p.createTask(ctx, event, scheduledAt.UTC().Format(time.RFC3339Nano))
+	return err
+}
+
+// Deletes all Cloud Tasks tasks of a job
+func (p *Provider) CancelJob(ctx context.Context, jobID uuid.UUID) error {
+	tasks, err := p.jobTasks(ctx, jobID)
+	if err != nil {
+		return err
+	}
+
+	for _, t := range tasks {
+		if err := p.client.deleteTask(ctx, t.Name); err != nil && !isNotFound(err) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Returns the job IDs of all tasks in the queue
+// Tasks are removed from the queue once they have been dispatched successfully
+func (p *Provider) ListScheduledJobs(ctx context.Context) ([]uuid.UUID, error) {
+	tasks, err := p.client.listTasks(ctx, p.queue)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := p.queue + "/tasks/" + taskNamePrefix
+	seen := make(map[uuid.UUID]bool, len(tasks))
+	jobIDs := make([]uuid.UUID, 0, len(tasks))
+	for _, t := range tasks {
+		name, found := strings.CutPrefix(t.Name, prefix)
+		// Task IDs are the job ID followed by the task creation time
+		if !found || len(name) < 36 {
+			continue
+		}
+		jobID, err := uuid.Parse(name[:36])
+		if err != nil || seen[jobID] {
+			continue
+		}
+		seen[jobID] = true
+		jobIDs = append(jobIDs, jobID)
+	}
+
+	return jobIDs, nil
+}
+
+// Updates the platform credentials for a scheduled event
+// Tasks cannot be modified so the task is replaced by a new
+// task with the same schedule time and the updated event
+func (p *Provider) UpdateJobCredentials(ctx context.Context, jobID uuid.UUID, encryptedToken string) error {
+	tasks, err := p.jobTasks(ctx, jobID)
+	if err != nil {
+		return err
+	}
+	if len(tasks) == 0 {
+		return fmt.Errorf("%w: %s", cloud.ErrJobNotFound, jobID)
+	}
+
+	existingTask := tasks[len(tasks)-1]
+	if existingTask.HTTPRequest == nil {
+		return fmt.Errorf("%w: %s", cloud.ErrJobNotFound, jobID)
+	}
+
+	var event reservation.Event
+	if err := json.Unmarshal(existingTask.HTTPRequest.Body, &event); err != nil {
+		return err
+	}
+	event.EncryptedToken = encryptedToken
+	if err := cloud.ReEncryptCallbackSecret(ctx, p, &event); err != nil {
+		return err
+	}
+
+	replacement, err := p.createTask(ctx, event, existingTask.ScheduleTime)
+	if err != nil {
+		return err
+	}
+
+	for _, t := range tasks {
+		err := p.client.deleteTask(ctx, t.Name)
+		if isNotFound(err) {
+			// The existing task was dispatched while it was being
+			// replaced so the replacement must not run a second time
+			if err := p.client.deleteTask(ctx, replacement.Name); err != nil && !isNotFound(err) {
+				return err
+			}
+			return fmt.Errorf("%w: %s", cloud.ErrJobNotFound, jobID)
+		} else if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Jobs are invoked the cold start buffer before their drop time and must
+// respond within the dispatch deadline
+func (p *Provider) MaxRunTime() time.Duration {
+	return p.dispatchDeadline - p.coldStartBuffer
+}
+
+// Encrypts a provided string using Cloud KMS and returns the base64 encoded ciphertext
+func (p *Provider) EncryptData(ctx context.Context, plaintext string) (string, error) {
+	ciphertext, err := p.kms.Encrypt(ctx, []byte(plaintext))
+	if err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(ciphertext), nil
+}
+
+// Decrypts a base64-encoded ciphertext using Cloud KMS and returns the plaintext
+func (p *Provider) DecryptData(ctx context.Context, ciphertext string) (string, error) {
+	ciphertextBlob, err := base64.StdEncoding.DecodeString(ciphertext)
+	if err != nil {
+		return "", err
+	}
+
+	return p.kms.Decrypt(ctx, ciphertextBlob)
+}
+
+// Creates a task that invokes the executor with an event at a given schedule time
+func (p *Provider) createTask(ctx context.Context, event reservation.Event, scheduleTime string) (task, error) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return task{}, err
+	}
+
+	request := &httpRequest{
+		URL:        p.executorURL,
+		HTTPMethod: http.MethodPost,
+		Headers: map[string]string{
+			"Content-Type": "application/json",
+		},
+		Body: payload,
+	}
+	if p.serviceAccountEmail != "" {
+		request.OIDCToken = &oidcToken{
+			ServiceAccountEmail: p.serviceAccountEmail,
+			Audience:            p.audience,
+		}
+	}
+
+	return p.client.createTask(ctx, p.queue, task{
+		Name:             p.queue + "/tasks/" + taskNamePrefix + event.JobID.String() + "-" + strconv.FormatInt(time.Now().UnixNano(), 10),
+		ScheduleTime:     scheduleTime,
+		DispatchDeadline: strconv.Itoa(int(p.dispatchDeadline.Seconds())) + "s",
+		HTTPRequest:      request,
+	})
+}
+
+// Returns the tasks of a job in order of creation
+func (p *Provider) jobTasks(ctx context.Context, jobID uuid.UUID) ([]task, error) {
+	tasks, err := p.client.listTasks(ctx, p.queue)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := p.queue + "/tasks/" + taskNamePrefix + jobID.String() + "-"
+	jobTasks := make([]task, 0, 1)
+	for _, t := range tasks {
+		if strings.HasPrefix(t.Name, prefix) {
+			jobTasks = append(jobTasks, t)
+		}
+	}
+
+	// Task names end with their creation time and have the same length
+	slices.SortFunc(jobTasks, func(a, b task) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return jobTasks, nil
+}
+
+// Validates a GCP config
+func ValidateConfig(cfg map[string]any, isProduction bool) error {
+	pCfg, err := decodeConfig(cfg)
+	if err != nil {
+		return err
+	}
+
+	if pCfg.ProjectID == "" {
+		return ErrMissingProjectID
+	}
+	if pCfg.Location == "" {
+		return ErrMissingLocation
+	}
+	if pCfg.Queue == "" {
+		return ErrMissingQueue
+	}
+
+	if pCfg.ColdStartBuffer == "" {
+		return ErrMissingColdStartBuffer
+	}
+	if _, err := time.ParseDuration(pCfg.ColdStartBuffer); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidColdStartBuffer, pCfg.ColdStartBuffer)
+	}
+
+	if pCfg.DispatchDeadline != "" {
+		dispatchDeadline, err := time.ParseDuration(pCfg.DispatchDeadline)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidDispatchDeadline, pCfg.DispatchDeadline)
+		}
+		if dispatchDeadline < minDispatchDeadline || dispatchDeadline > maxDispatchDeadline {
+			return ErrDispatchDeadlineOutOfRange
+		}
+	}
+
+	if pCfg.KMSKeyName == "" {
+		return ErrMissingKMSKeyName
+	} else if !kmsKeyNameRegex.MatchString(pCfg.KMSKeyName) {
+		return ErrInvalidKMSKeyName
+	}
+
+	if pCfg.ExecutorURL == "" {
+		return ErrMissingExecutorURL
+	}
+	executorURL, err := url.Parse(pCfg.ExecutorURL)
+	if err != nil || executorURL.Host == "" || (executorURL.Scheme != "https" && executorURL.Scheme != "http") {
+		return ErrInvalidExecutorURL
+	}
+	if isProduction && executorURL.Scheme != "https" {
+		return ErrInsecureExecutorURL
+	}
+
+	return validateCredentials(pCfg)
+}
+
+// Retrieve and test credentials
+// Credential resolution: config credentials file -> application default credentials -> error
+func validateCredentials(pCfg providerConfig) error {
+	creds, err := findCredentials(context.Background(), pCfg)
+	if err != nil {
+		return err
+	}
+
+	if _, err := creds.TokenSource.Token(); err != nil {
+		return fmt.Errorf("%w: %w", ErrCredentialValidation, err)
+	}
+
+	return nil
+}
+
+// Returns the credentials from the configured credentials
+// file or the application default credentials
+func findCredentials(ctx context.Context, pCfg providerConfig) (*google.Credentials, error) {
+	if pCfg.CredentialsFile != "" {
+		data, err := os.ReadFile(pCfg.CredentialsFile)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrMissingCredentials, err)
+		}
+		creds, err := google.CredentialsFromJSON(ctx, data, cloudPlatformScope)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrCredentialValidation, err)
+		}
+		return creds, nil
+	}
+
+	creds, err := google.FindDefaultCredentials(ctx, cloudPlatformScope)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrMissingCredentials, err)
+	}
+	return creds, nil
+}
+
+// Decodes the config map into a struct
+func decodeConfig(cfg map[string]any) (providerConfig, error) {
+	var pCfg providerConfig
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		Result:  &pCfg,
+		TagName: "json",
+	})
+	if err != nil {
+		return pCfg, fmt.Errorf("%w: %w", ErrDecodeConfig, err)
+	}
+	if err := decoder.Decode(cfg); err != nil {
+		return pCfg, fmt.Errorf("%w: %w", ErrDecodeConfig, err)
+	}
+	return pCfg, nil
+}
@@ -0,0 +1,443 @@
+package gcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/daylamtayari/cierge/reservation"
+	"github.com/daylamtayari/cierge/reservation/gcpkms"
+	"github.com/daylamtayari/cierge/server/cloud"
+	"github.com/google/uuid"
+)
+
+const (
+	testQueue      = "projects/test-project/locations/us-central1/queues/cierge"
+	testKMSKeyName = "projects/test-project/locations/us-central1/keyRings/cierge/cryptoKeys/tokens"
+	testKMSPrefix  = "kms:"
+)
+
+// fakeGCP is an HTTP fake of the Cloud Tasks and Cloud KMS REST APIs
+// Encryption prefixes the plaintext so that ciphertexts can be inspected
+type fakeGCP struct {
+	mu    sync.Mutex
+	tasks map[string]task
+}
+
+func newFakeGCP(t *testing.T) (*fakeGCP, *httptest.Server) {
+	t.Helper()
+	fake := &fakeGCP{
+		tasks: make(map[string]task),
+	}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	return fake, server
+}
+
+func (f *fakeGCP) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	path := strings.TrimPrefix(r.URL.Path, "/")
+	switch {
+	case r.Method == http.MethodPost && path == "v2/"+testQueue+"/tasks":
+		var req struct {
+			Task task `json:"task"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, "INVALID_ARGUMENT", err.Error())
+			return
+		}
+		if _, exists := f.tasks[req.Task.Name]; exists {
+			writeError(w, http.StatusConflict, "ALREADY_EXISTS", "task already exists")
+			return
+		}
+		f.tasks[req.Task.Name] = req.Task
+		writeJSON(w, req.Task)
+
+	case r.Method == http.MethodGet && path == "v2/"+testQueue+"/tasks":
+		tasks := make([]task, 0, len(f.tasks))
+		for _, t := range f.tasks {
+			tasks = append(tasks, t)
+		}
+		writeJSON(w, map[string]any{"tasks": tasks})
+
+	case r.Method == http.MethodDelete && strings.HasPrefix(path, "v2/"+testQueue+"/tasks/"):
+		name := strings.TrimPrefix(path, "v2/")
+		if _, exists := f.tasks[name]; !exists {
+			writeError(w, http.StatusNotFound, "NOT_FOUND", "task does not exist")
+			return
+		}
+		delete(f.tasks, name)
+		writeJSON(w, map[string]any{})
+
+	case r.Method == http.MethodPost && path == "v1/"+testKMSKeyName+":encrypt":
+		var req struct {
+			Plaintext []byte `json:"plaintext"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, "INVALID_ARGUMENT", err.Error())
+			return
+		}
+		writeJSON(w, map[string]any{
+			"name":       testKMSKeyName + "/cryptoKeyVersions/1",
+			"ciphertext": append([]byte(testKMSPrefix), req.Plaintext...),
+		})
+
+	case r.Method == http.MethodPost && path == "v1/"+testKMSKeyName+":decrypt":
+		var req struct {
+			Ciphertext []byte `json:"ciphertext"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, "INVALID_ARGUMENT", err.Error())
+			return
+		}
+		if !bytes.HasPrefix(req.Ciphertext, []byte(testKMSPrefix)) {
+			writeError(w, http.StatusBadRequest, "INVALID_ARGUMENT", "Decryption failed")
+			return
+		}
+		writeJSON(w, map[string]any{
+			"plaintext": bytes.TrimPrefix(req.Ciphertext, []byte(testKMSPrefix)),
+		})
+
+	default:
+		writeError(w, http.StatusNotFound, "NOT_FOUND", "unknown route "+r.Method+" "+path)
+	}
+}
+
+// Returns the tasks of the fake sorted by name
+func (f *fakeGCP) taskList() []task {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	tasks := make([]task, 0, len(f.tasks))
+	for _, t := range f.tasks {
+		tasks = append(tasks, t)
+	}
+	sort.Slice(tasks, func(i, j int) bool { return tasks[i].Name < tasks[j].Name })
+	return tasks
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, code int, status string, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"error": map[string]any{
+			"code":    code,
+			"status":  status,
+			"message": message,
+		},
+	})
+}
+
+func newTestProvider(t *testing.T) (*Provider, *fakeGCP) {
+	t.Helper()
+	fake, server := newFakeGCP(t)
+	provider := newProvider(providerConfig{
+		ProjectID:           "test-project",
+		Location:            "us-central1",
+		Queue:               "cierge",
+		KMSKeyName:          testKMSKeyName,
+		ExecutorURL:         "https://executor.example.com/",
+		ServiceAccountEmail: "cierge-tasks@test-project.iam.gserviceaccount.com",
+		ColdStartBuffer:     "2m",
+	}, server.Client(), server.URL, server.URL+"/")
+	return provider, fake
+}
+
+func newTestEvent() reservation.Event {
+	return reservation.Event{
+		JobID:          uuid.New(),
+		Platform:       "resy",
+		EncryptedToken: "token",
+		DropTime:       time.Now().Add(time.Hour).UTC().Truncate(time.Second),
+	}
+}
+
+func decodeTaskEvent(t *testing.T, tk task) reservation.Event {
+	t.Helper()
+	if tk.HTTPRequest == nil {
+		t.Fatal("task has no HTTP request")
+	}
+	var event reservation.Event
+	if err := json.Unmarshal(tk.HTTPRequest.Body, &event); err != nil {
+		t.Fatalf("failed to unmarshal task body: %v", err)
+	}
+	return event
+}
+
+func TestScheduleJob_CreatesTask(t *testing.T) {
+	ctx := context.Background()
+	provider, fake := newTestProvider(t)
+	event := newTestEvent()
+
+	if err := provider.ScheduleJob(ctx, event); err != nil {
+		t.Fatalf("ScheduleJob failed: %v", err)
+	}
+
+	tasks := fake.taskList()
+	if len(tasks) != 1 {
+		t.Fatalf("expected 1 task, got %d", len(tasks))
+	}
+	created := tasks[0]
+
+	if !strings.HasPrefix(created.Name, testQueue+"/tasks/"+taskNamePrefix+event.JobID.String()+"-") {
+		t.Errorf("unexpected task name %q", created.Name)
+	}
+	scheduleTime, err := time.Parse(time.RFC3339Nano, created.ScheduleTime)
+	if err != nil {
+		t.Fatalf("invalid schedule time %q: %v", created.ScheduleTime, err)
+	}
+	if want := event.DropTime.Add(-2 * time.Minute); !scheduleTime.Equal(want) {
+		t.Errorf("schedule time: got %s, want %s", scheduleTime, want)
+	}
+	if created.DispatchDeadline != "1800s" {
+		t.Errorf("dispatch deadline: got %q, want %q", created.DispatchDeadline, "1800s")
+	}
+
+	request := created.HTTPRequest
+	if request.URL != "https://executor.example.com/" || request.HTTPMethod != http.MethodPost {
+		t.Errorf("unexpected request target %s %s", request.HTTPMethod, request.URL)
+	}
+	if request.OIDCToken == nil || request.OIDCToken.Audience != "https://executor.example.com/" {
+		t.Errorf("unexpected OIDC token %+v", request.OIDCToken)
+	}
+	if got := decodeTaskEvent(t, created); got.JobID != event.JobID || got.EncryptedToken != event.EncryptedToken {
+		t.Errorf("unexpected task event %+v", got)
+	}
+}
+
+func TestScheduleJob_TooFar(t *testing.T) {
+	provider, fake := newTestProvider(t)
+	event := newTestEvent()
+	event.DropTime = time.Now().Add(31 * 24 * time.Hour).UTC()
+
+	if err := provider.ScheduleJob(context.Background(), event); !errors.Is(err, cloud.ErrScheduleTooFar) {
+		t.Errorf("expected %v, got %v", cloud.ErrScheduleTooFar, err)
+	}
+	if tasks := fake.taskList(); len(tasks) != 0 {
+		t.Errorf("expected no task, got %d", len(tasks))
+	}
+
+	// The cold start buffer brings the task within the limit
+	event.DropTime = time.Now().Add(30*24*time.Hour + time.Minute).UTC()
+	if err := provider.ScheduleJob(context.Background(), event); err != nil {
+		t.Fatalf("ScheduleJob failed: %v", err)
+	}
+}
+
+func TestCancelJob_DeletesTasks(t *testing.T) {
+	ctx := context.Background()
+	provider, fake := newTestProvider(t)
+	event := newTestEvent()
+	other := newTestEvent()
+
+	for _, e := range []reservation.Event{event, other} {
+		if err := provider.ScheduleJob(ctx, e); err != nil {
+			t.Fatalf("ScheduleJob failed: %v", err)
+		}
+	}
+
+	if err := provider.CancelJob(ctx, event.JobID); err != nil {
+		t.Fatalf("CancelJob failed: %v", err)
+	}
+
+	tasks := fake.taskList()
+	if len(tasks) != 1 || decodeTaskEvent(t, tasks[0]).JobID != other.JobID {
+		t.Errorf("expected only the other job's task to remain, got %d tasks", len(tasks))
+	}
+}
+
+func TestCancelJob_NotFound(t *testing.T) {
+	provider, _ := newTestProvider(t)
+
+	if err := provider.CancelJob(context.Background(), uuid.New()); err != nil {
+		t.Errorf("CancelJob of unknown job should not fail: %v", err)
+	}
+}
+
+func TestListScheduledJobs(t *testing.T) {
+	ctx := context.Background()
+	provider, _ := newTestProvider(t)
+	event := newTestEvent()
+	other := newTestEvent()
+
+	for _, e := range []reservation.Event{event, other} {
+		if err := provider.ScheduleJob(ctx, e); err != nil {
+			t.Fatalf("ScheduleJob failed: %v", err)
+		}
+	}
+	// Replacing a task must not list the job twice
+	if err := provider.UpdateJobCredentials(ctx, event.JobID, "new-token"); err != nil {
+		t.Fatalf("UpdateJobCredentials failed: %v", err)
+	}
+
+	jobIDs, err := provider.ListScheduledJobs(ctx)
+	if err != nil {
+		t.Fatalf("ListScheduledJobs failed: %v", err)
+	}
+	if len(jobIDs) != 2 {
+		t.Fatalf("expected 2 jobs, got %v", jobIDs)
+	}
+	for _, jobID := range jobIDs {
+		if jobID != event.JobID && jobID != other.JobID {
+			t.Errorf("unexpected job %s", jobID)
+		}
+	}
+}
+
+func TestUpdateJobCredentials_ReplacesTask(t *testing.T) {
+	ctx := context.Background()
+	provider, fake := newTestProvider(t)
+	event := newTestEvent()
+
+	if err := provider.ScheduleJob(ctx, event); err != nil {
+		t.Fatalf("ScheduleJob failed: %v", err)
+	}
+	original := fake.taskList()[0]
+
+	if err := provider.UpdateJobCredentials(ctx, event.JobID, "new-token"); err != nil {
+		t.Fatalf("UpdateJobCredentials failed: %v", err)
+	}
+
+	tasks := fake.taskList()
+	if len(tasks) != 1 {
+		t.Fatalf("expected 1 task, got %d", len(tasks))
+	}
+	if tasks[0].Name == original.Name {
+		t.Error("task should have been replaced by a task with a new name")
+	}
+	if tasks[0].ScheduleTime != original.ScheduleTime {
+		t.Errorf("schedule time: got %q, want %q", tasks[0].ScheduleTime, original.ScheduleTime)
+	}
+	if got := decodeTaskEvent(t, tasks[0]); got.EncryptedToken != "new-token" || got.JobID != event.JobID {
+		t.Errorf("unexpected task event %+v", got)
+	}
+}
+
+func TestUpdateJobCredentials_NotFound(t *testing.T) {
+	provider, _ := newTestProvider(t)
+
+	err := provider.UpdateJobCredentials(context.Background(), uuid.New(), "new-token")
+	if !errors.Is(err, cloud.ErrJobNotFound) {
+		t.Errorf("expected ErrJobNotFound, got %v", err)
+	}
+}
+
+func TestUpdateJobCredentials_DispatchedDuringReplace(t *testing.T) {
+	ctx := context.Background()
+	provider, fake := newTestProvider(t)
+	event := newTestEvent()
+
+	if err := provider.ScheduleJob(ctx, event); err != nil {
+		t.Fatalf("ScheduleJob failed: %v", err)
+	}
+
+	// Remove the original task after it is listed as if it was dispatched
+	original := fake.taskList()[0]
+	provider.client.httpClient.Transport = roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		if r.Method == http.MethodPost {
+			fake.mu.Lock()
+			delete(fake.tasks, original.Name)
+			fake.mu.Unlock()
+		}
+		return http.DefaultTransport.RoundTrip(r)
+	})
+
+	err := provider.UpdateJobCredentials(ctx, event.JobID, "new-token")
+	if !errors.Is(err, cloud.ErrJobNotFound) {
+		t.Errorf("expected ErrJobNotFound, got %v", err)
+	}
+	if tasks := fake.taskList(); len(tasks) != 0 {
+		t.Errorf("replacement task should have been deleted, got %d tasks", len(tasks))
+	}
+}
+
+type roundTripFunc func(r *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func TestEncryptDecrypt_RoundTrip(t *testing.T) {
+	ctx := context.Background()
+	provider, _ := newTestProvider(t)
+
+	ciphertext, err := provider.EncryptData(ctx, "secret-token")
+	if err != nil {
+		t.Fatalf("EncryptData failed: %v", err)
+	}
+	if strings.Contains(ciphertext, "secret-token") {
+		t.Error("ciphertext should be base64 encoded")
+	}
+
+	plaintext, err := provider.DecryptData(ctx, ciphertext)
+	if err != nil {
+		t.Fatalf("DecryptData failed: %v", err)
+	}
+	if plaintext != "secret-token" {
+		t.Errorf("plaintext: got %q, want %q", plaintext, "secret-token")
+	}
+}
+
+func TestDecryptData_APIError(t *testing.T) {
+	provider, _ := newTestProvider(t)
+
+	_, err := provider.DecryptData(context.Background(), "aW52YWxpZA==")
+	var apiErr *gcpkms.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected bad request API error, got %v", err)
+	}
+}
+
+func TestValidateConfig(t *testing.T) {
+	valid := func() map[string]any {
+		return map[string]any{
+			"project_id":        "test-project",
+			"location":          "us-central1",
+			"queue":             "cierge",
+			"kms_key_name":      testKMSKeyName,
+			"executor_url":      "https://executor.example.com/",
+			"cold_start_buffer": "2m",
+			"credentials_file":  "/nonexistent/credentials.json",
+		}
+	}
+
+	tests := []struct {
+		name         string
+		modify       func(cfg map[string]any)
+		isProduction bool
+		wantErr      error
+	}{
+		{"missing project", func(cfg map[string]any) { delete(cfg, "project_id") }, false, ErrMissingProjectID},
+		{"missing queue", func(cfg map[string]any) { delete(cfg, "queue") }, false, ErrMissingQueue},
+		{"missing cold start buffer", func(cfg map[string]any) { delete(cfg, "cold_start_buffer") }, false, ErrMissingColdStartBuffer},
+		{"invalid dispatch deadline", func(cfg map[string]any) { cfg["dispatch_deadline"] = "1h" }, false, ErrDispatchDeadlineOutOfRange},
+		{"invalid KMS key name", func(cfg map[string]any) { cfg["kms_key_name"] = "tokens" }, false, ErrInvalidKMSKeyName},
+		{"invalid executor URL", func(cfg map[string]any) { cfg["executor_url"] = "executor" }, false, ErrInvalidExecutorURL},
+		{"insecure executor URL", func(cfg map[string]any) { cfg["executor_url"] = "http://executor.example.com/" }, true, ErrInsecureExecutorURL},
+		{"missing credentials file", func(cfg map[string]any) {}, false, ErrMissingCredentials},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := valid()
+			tt.modify(cfg)
+			if err := ValidateConfig(cfg, tt.isProduction); !errors.Is(err, tt.wantErr) {
+				t.Errorf("expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
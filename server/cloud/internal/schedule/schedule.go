@@ -0,0 +1,157 @@
+// Package schedule implements a persistent in-process scheduler of
+// reservation events shared by the cloud providers that execute
+// jobs on the same host as the server.
+package schedule
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"sync"
+	"time"
+
+	"github.com/daylamtayari/cierge/reservation"
+	"github.com/daylamtayari/cierge/server/cloud"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrLoadSchedule = errors.New("failed to load existing schedule")
+)
+
+// Function that executes a scheduled event
+type RunFunc func(event reservation.Event)
+
+// Scheduler of reservation events
+// Scheduled events are persisted to disk and re-armed on startup
+type Scheduler struct {
+	store        *store
+	warmUpBuffer time.Duration
+	run          RunFunc
+
+	mu     sync.Mutex
+	timers map[uuid.UUID]*time.Timer
+}
+
+// Creates a new scheduler and re-arms any events that were
+// scheduled prior to the server being restarted
+// Each event is run at its drop time minus the warm up buffer
+func New(path string, warmUpBuffer time.Duration, run RunFunc) (*Scheduler, error) {
+	eventStore, err := newStore(path)
+	if err != nil {
+		return nil, err
+	}
+
+	scheduler := &Scheduler{
+		store:        eventStore,
+		warmUpBuffer: warmUpBuffer,
+		run:          run,
+		timers:       make(map[uuid.UUID]*time.Timer),
+	}
+
+	events, err := eventStore.list()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrLoadSchedule, err)
+	}
+	scheduler.mu.Lock()
+	for _, event := range events {
+		// Events whose wake up time has passed are run immediately
+		// so that the job reports its outcome instead of remaining scheduled
+		scheduler.arm(event)
+	}
+	scheduler.mu.Unlock()
+
+	return scheduler, nil
+}
+
+// Persists the event and arms a timer that runs it
+func (s *Scheduler) Schedule(event reservation.Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.store.put(event); err != nil {
+		return err
+	}
+	s.arm(event)
+
+	return nil
+}
+
+// Cancels a pending event by stopping its timer and removing it from the store
+func (s *Scheduler) Cancel(jobID uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if timer, exists := s.timers[jobID]; exists {
+		timer.Stop()
+		delete(s.timers, jobID)
+	}
+
+	if err := s.store.delete(jobID); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// Updates a pending event, the event being stored again
+// only if the update does not return an error
+func (s *Scheduler) Update(jobID uuid.UUID, update func(event *reservation.Event) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	event, err := s.store.get(jobID)
+	if errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("%w: %s", cloud.ErrJobNotFound, jobID)
+	} else if err != nil {
+		return err
+	}
+
+	if err := update(&event); err != nil {
+		return err
+	}
+	return s.store.put(event)
+}
+
+// Returns the job IDs of all pending events
+func (s *Scheduler) List() ([]uuid.UUID, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.store.ids()
+}
+
+// Arms a timer for an event, replacing any existing timer for the job
+// NOTE: Must be called with the mutex held
+func (s *Scheduler) arm(event reservation.Event) {
+	if timer, exists := s.timers[event.JobID]; exists {
+		timer.Stop()
+	}
+
+	jobID := event.JobID
+	wakeAt := event.DropTime.Add(-s.warmUpBuffer)
+	s.timers[jobID] = time.AfterFunc(time.Until(wakeAt), func() {
+		s.fire(jobID)
+	})
+}
+
+// Runs a pending event
+// The event is read back from the store to pick up any credential updates
+// and is removed prior to execution so that it is never run twice
+// NOTE: An event whose run is interrupted by the server stopping is not re-armed
+// on restart as it may have booked, and its job is resolved by the sweeper instead
+func (s *Scheduler) fire(jobID uuid.UUID) {
+	s.mu.Lock()
+	delete(s.timers, jobID)
+	event, err := s.store.get(jobID)
+	if err == nil {
+		err = s.store.delete(jobID)
+	}
+	s.mu.Unlock()
+
+	// Event was cancelled or could not be read
+	if err != nil {
+		return
+	}
+
+	s.run(event)
+}
@@ -0,0 +1,233 @@
+package schedule
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/daylamtayari/cierge/reservation"
+	"github.com/daylamtayari/cierge/server/cloud"
+	"github.com/google/uuid"
+)
+
+// Returns a run function sending the events it runs to the returned channel
+func recordRuns() (RunFunc, chan reservation.Event) {
+	runs := make(chan reservation.Event, 10)
+	return func(event reservation.Event) { runs <- event }, runs
+}
+
+// Waits for an event to be run
+func waitForRun(t *testing.T, runs chan reservation.Event) reservation.Event {
+	t.Helper()
+	select {
+	case event := <-runs:
+		return event
+	case <-time.After(5 * time.Second):
+		t.Fatal("event was not run")
+		return reservation.Event{}
+	}
+}
+
+// Fails if an event is run within the duration
+func requireNoRun(t *testing.T, runs chan reservation.Event, d time.Duration) {
+	t.Helper()
+	select {
+	case event := <-runs:
+		t.Fatalf("event of job %s was run", event.JobID)
+	case <-time.After(d):
+	}
+}
+
+func TestScheduler_RestartRecovery(t *testing.T) {
+	path := t.TempDir()
+	pending := reservation.Event{JobID: uuid.New(), DropTime: time.Now().Add(time.Hour)}
+	missed := reservation.Event{JobID: uuid.New(), DropTime: time.Now().Add(-time.Minute)}
+
+	// Persisted before the restart, with the drop of the missed event passing while the server was down
+	s, err := newStore(path)
+	if err != nil {
+		t.Fatalf("newStore failed: %v", err)
+	}
+	for _, event := range []reservation.Event{pending, missed} {
+		if err := s.put(event); err != nil {
+			t.Fatalf("put failed: %v", err)
+		}
+	}
+
+	run, runs := recordRuns()
+	restarted, err := New(path, 0, run)
+	if err != nil {
+		t.Fatalf("New after restart failed: %v", err)
+	}
+
+	// Events whose wake up time passed are run immediately and removed from the store
+	if got := waitForRun(t, runs); got.JobID != missed.JobID {
+		t.Errorf("ran job %s, want missed job %s", got.JobID, missed.JobID)
+	}
+	requireNoRun(t, runs, 50*time.Millisecond)
+
+	ids, err := restarted.List()
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if !slices.Equal(ids, []uuid.UUID{pending.JobID}) {
+		t.Errorf("pending jobs %v, want %s", ids, pending.JobID)
+	}
+	restarted.mu.Lock()
+	_, armed := restarted.timers[pending.JobID]
+	restarted.mu.Unlock()
+	if !armed {
+		t.Error("pending event was not re-armed")
+	}
+}
+
+func TestScheduler_CorruptEvent(t *testing.T) {
+	path := t.TempDir()
+	pending := reservation.Event{JobID: uuid.New(), DropTime: time.Now().Add(time.Hour)}
+
+	s, err := newStore(path)
+	if err != nil {
+		t.Fatalf("newStore failed: %v", err)
+	}
+	if err := s.put(pending); err != nil {
+		t.Fatalf("put failed: %v", err)
+	}
+	// Truncated event, such as one left by an interrupted write
+	corruptName := uuid.NewString() + eventFileSuffix
+	if err := os.WriteFile(filepath.Join(path, corruptName), []byte(`{"job_id":`), 0o600); err != nil {
+		t.Fatalf("failed to write corrupt event: %v", err)
+	}
+
+	run, runs := recordRuns()
+	scheduler, err := New(path, 0, run)
+	if err != nil {
+		t.Fatalf("New with a corrupt event failed: %v", err)
+	}
+	requireNoRun(t, runs, 50*time.Millisecond)
+
+	ids, err := scheduler.List()
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if !slices.Equal(ids, []uuid.UUID{pending.JobID}) {
+		t.Errorf("pending jobs %v, want %s", ids, pending.JobID)
+	}
+	if _, err := os.Stat(filepath.Join(path, corruptName+quarantineSuffix)); err != nil {
+		t.Errorf("corrupt event was not quarantined: %v", err)
+	}
+}
+
+func TestScheduler_CancelBeforeFire(t *testing.T) {
+	run, runs := recordRuns()
+	scheduler, err := New(t.TempDir(), 0, run)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	event := reservation.Event{JobID: uuid.New(), DropTime: time.Now().Add(100 * time.Millisecond)}
+	if err := scheduler.Schedule(event); err != nil {
+		t.Fatalf("Schedule failed: %v", err)
+	}
+	if err := scheduler.Cancel(event.JobID); err != nil {
+		t.Fatalf("Cancel failed: %v", err)
+	}
+	requireNoRun(t, runs, 300*time.Millisecond)
+
+	if ids, _ := scheduler.List(); len(ids) != 0 {
+		t.Errorf("pending jobs %v after cancellation, want none", ids)
+	}
+	// Cancelling an event that is not pending succeeds
+	if err := scheduler.Cancel(event.JobID); err != nil {
+		t.Errorf("second Cancel failed: %v", err)
+	}
+}
+
+func TestScheduler_Update(t *testing.T) {
+	run, runs := recordRuns()
+	scheduler, err := New(t.TempDir(), 0, run)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	event := reservation.Event{JobID: uuid.New(), EncryptedToken: "old", DropTime: time.Now().Add(200 * time.Millisecond)}
+	if err := scheduler.Schedule(event); err != nil {
+		t.Fatalf("Schedule failed: %v", err)
+	}
+
+	// Failed updates leave the event unchanged
+	updateErr := errors.New("update failed")
+	err = scheduler.Update(event.JobID, func(event *reservation.Event) error {
+		event.EncryptedToken = "failed"
+		return updateErr
+	})
+	if !errors.Is(err, updateErr) {
+		t.Errorf("failed update: got %v, want %v", err, updateErr)
+	}
+	err = scheduler.Update(event.JobID, func(event *reservation.Event) error {
+		event.EncryptedToken = "new"
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+
+	// The pending event runs with the updated credentials
+	if got := waitForRun(t, runs); got.EncryptedToken != "new" {
+		t.Errorf("ran with token %q, want the updated token", got.EncryptedToken)
+	}
+
+	err = scheduler.Update(event.JobID, func(*reservation.Event) error { return nil })
+	if !errors.Is(err, cloud.ErrJobNotFound) {
+		t.Errorf("update of run event: got %v, want %v", err, cloud.ErrJobNotFound)
+	}
+}
+
+func TestStore_List(t *testing.T) {
+	path := t.TempDir()
+	s, err := newStore(path)
+	if err != nil {
+		t.Fatalf("newStore failed: %v", err)
+	}
+
+	event := reservation.Event{JobID: uuid.New(), Platform: "resy"}
+	if err := s.put(event); err != nil {
+		t.Fatalf("put failed: %v", err)
+	}
+	// Files of other names are skipped and unparsable events are quarantined
+	invalidName := "invalid" + eventFileSuffix
+	corruptName := uuid.NewString() + eventFileSuffix
+	os.WriteFile(filepath.Join(path, "notes.txt"), []byte("notes"), 0o600) //nolint:errcheck
+	os.WriteFile(filepath.Join(path, invalidName), []byte("{}"), 0o600)    //nolint:errcheck
+	os.WriteFile(filepath.Join(path, corruptName), []byte("{"), 0o600)     //nolint:errcheck
+
+	events, err := s.list()
+	if err != nil {
+		t.Fatalf("list failed: %v", err)
+	}
+	if len(events) != 1 || events[0].JobID != event.JobID || events[0].Platform != "resy" {
+		t.Errorf("got events %+v, want the stored event", events)
+	}
+	for _, name := range []string{invalidName, corruptName} {
+		if _, err := os.Stat(filepath.Join(path, name+quarantineSuffix)); err != nil {
+			t.Errorf("unparsable event %s was not quarantined: %v", name, err)
+		}
+	}
+
+	ids, err := s.ids()
+	if err != nil {
+		t.Fatalf("ids failed: %v", err)
+	}
+	if !slices.Equal(ids, []uuid.UUID{event.JobID}) {
+		t.Errorf("got IDs %v, want the stored event", ids)
+	}
+
+	if err := s.delete(event.JobID); err != nil {
+		t.Fatalf("delete failed: %v", err)
+	}
+	if _, err := s.get(event.JobID); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("get of deleted event: got %v, want %v", err, os.ErrNotExist)
+	}
+}
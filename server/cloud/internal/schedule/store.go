@@ -0,0 +1,133 @@
+package schedule
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/daylamtayari/cierge/reservation"
+	"github.com/google/uuid"
+)
+
+const (
+	eventFileSuffix = ".event.json"
+	// Suffix appended to the name of event files that cannot be parsed
+	quarantineSuffix = ".bad"
+)
+
+// File backed store of scheduled reservation events
+// Each event is stored as its own JSON file named after the job ID
+// so that the schedule survives a server restart
+type store struct {
+	path string
+}
+
+// Creates a new store, creating the directory if it does not exist
+func newStore(path string) (*store, error) {
+	if err := os.MkdirAll(path, 0o700); err != nil {
+		return nil, err
+	}
+	return &store{path: path}, nil
+}
+
+// Writes an event to the store, replacing any existing event for the same job
+// The event is written to a temporary file and renamed to avoid partial writes
+func (s *store) put(event reservation.Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	tmpFile := s.eventPath(event.JobID) + ".tmp"
+	if err := os.WriteFile(tmpFile, payload, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmpFile, s.eventPath(event.JobID))
+}
+
+// Retrieves an event from the store
+// Returns an os.ErrNotExist error if no event is stored for the job
+func (s *store) get(jobID uuid.UUID) (reservation.Event, error) {
+	var event reservation.Event
+	payload, err := os.ReadFile(s.eventPath(jobID))
+	if err != nil {
+		return event, err
+	}
+	return event, json.Unmarshal(payload, &event)
+}
+
+// Deletes an event from the store
+// Returns an os.ErrNotExist error if no event is stored for the job
+func (s *store) delete(jobID uuid.UUID) error {
+	return os.Remove(s.eventPath(jobID))
+}
+
+// Returns all events present in the store
+// Event files that cannot be parsed are quarantined and skipped so
+// that a corrupt file does not prevent the schedule from being loaded
+func (s *store) list() ([]reservation.Event, error) {
+	entries, err := os.ReadDir(s.path)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]reservation.Event, 0, len(entries))
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, eventFileSuffix) {
+			continue
+		}
+
+		jobID, err := uuid.Parse(strings.TrimSuffix(name, eventFileSuffix))
+		if err != nil {
+			s.quarantine(name)
+			continue
+		}
+		event, err := s.get(jobID)
+		if err != nil {
+			s.quarantine(name)
+			continue
+		}
+		events = append(events, event)
+	}
+
+	return events, nil
+}
+
+// Renames an event file with the quarantine suffix so that it is no longer
+// loaded while being kept for inspection
+// A file that cannot be renamed is left in place and skipped again on the next load
+func (s *store) quarantine(name string) {
+	path := filepath.Join(s.path, name)
+	os.Rename(path, path+quarantineSuffix) //nolint:errcheck
+}
+
+// Returns the job IDs of all events present in the store
+// Files whose name is not a job ID are skipped
+func (s *store) ids() ([]uuid.UUID, error) {
+	entries, err := os.ReadDir(s.path)
+	if err != nil {
+		return nil, err
+	}
+
+	jobIDs := make([]uuid.UUID, 0, len(entries))
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, eventFileSuffix) {
+			continue
+		}
+
+		jobID, err := uuid.Parse(strings.TrimSuffix(name, eventFileSuffix))
+		if err != nil {
+			continue
+		}
+		jobIDs = append(jobIDs, jobID)
+	}
+
+	return jobIDs, nil
+}
+
+func (s *store) eventPath(jobID uuid.UUID) string {
+	return filepath.Join(s.path, jobID.String()+eventFileSuffix)
+}
@@ -0,0 +1,574 @@
+package k8s
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/daylamtayari/cierge/reservation"
+	"github.com/daylamtayari/cierge/reservation/envelope"
+	"github.com/daylamtayari/cierge/server/cloud"
+	"github.com/go-viper/mapstructure/v2"
+	"github.com/google/uuid"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+var (
+	ErrDecodeConfig         = errors.New("failed to decode config")
+	ErrMissingImage         = errors.New("executor image is required")
+	ErrMissingKeySecret     = errors.New("key secret name is required")
+	ErrInvalidNamespace     = errors.New("namespace is not a valid namespace name")
+	ErrInvalidWarmUpBuffer  = errors.New("warm up buffer is not a valid duration")
+	ErrNegativeWarmUpBuffer = errors.New("warm up buffer cannot be negative")
+	ErrNegativeTTL          = errors.New("TTL after finished cannot be negative")
+	ErrKubeconfigNotFound   = errors.New("kubeconfig file was not found")
+	ErrClientConfig         = errors.New("failed to load kubernetes client config")
+	ErrLoadSchedule         = errors.New("failed to load existing schedule")
+	ErrInvalidReleaseTime   = errors.New("job has an invalid release time annotation")
+)
+
+const (
+	defaultNamespace        = "cierge"
+	defaultWarmUpBuffer     = 60 * time.Second
+	defaultTTLAfterFinished = 24 * 60 * 60
+
+	// Name of the key in the secret containing the event
+	eventSecretKey = "event.json"
+	// Directory in which the event secret is mounted in the executor container
+	eventMountPath = "/var/run/cierge"
+	// Environment variable of the executor specifying the event file
+	eventFileEnv = "CIERGE_EXECUTOR_EVENT_FILE"
+
+	managedByLabel      = "app.kubernetes.io/managed-by"
+	managedByValue      = "cierge"
+	jobIDLabel          = "cierge.io/job-id"
+	releaseAtAnnotation = "cierge.io/release-at"
+	executorContainer   = "executor"
+)
+
+// Kubernetes provider that schedules each reservation job as a suspended
+// Kubernetes Job running the executor which is released at the drop time
+// minus the warm up buffer
+// The event is stored in a Secret owned by the Job and mounted in the executor
+// Pending Jobs are retrieved from the cluster and re-armed on startup
+type Provider struct {
+	client           kubernetes.Interface
+	keyring          *envelope.Keyring
+	namespace        string
+	image            string
+	imagePullPolicy  corev1.PullPolicy
+	serviceAccount   string
+	keySecretName    string
+	warmUpBuffer     time.Duration
+	ttlAfterFinished int32
+
+	mu     sync.Mutex
+	timers map[uuid.UUID]*time.Timer
+}
+
+// Kubernetes provider configuration
+type providerConfig struct {
+	Kubeconfig       string `json:"kubeconfig"`
+	Namespace        string `json:"namespace"`
+	Image            string `json:"image"`
+	ImagePullPolicy  string `json:"image_pull_policy"`
+	ServiceAccount   string `json:"service_account"`
+	KeySecretName    string `json:"key_secret_name"`
+	WarmUpBuffer     string `json:"warm_up_buffer"`
+	TTLAfterFinished *int32 `json:"ttl_after_finished"`
+	MasterKey        string `json:"master_key"`
+	MasterKeyID      string `json:"master_key_id"`
+	KeyFile          string `json:"key_file"`
+}
+
+// Creates a new kubernetes provider using either the configured
+// kubeconfig or the in-cluster config
+func NewProvider(cfg map[string]any) (cloud.Provider, error) {
+	pCfg, err := decodeConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	restConfig, err := clientConfig(pCfg.Kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+	client, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrClientConfig, err)
+	}
+
+	return newProvider(context.Background(), client, pCfg)
+}
+
+// Creates a new kubernetes provider with a given client
+// and re-arms all pending Jobs
+func newProvider(ctx context.Context, client kubernetes.Interface, pCfg providerConfig) (*Provider, error) {
+	keyring, err := envelope.LoadKeyring(pCfg.MasterKey, pCfg.MasterKeyID, pCfg.KeyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	namespace := defaultNamespace
+	if pCfg.Namespace != "" {
+		namespace = pCfg.Namespace
+	}
+	// Value has already been validated
+	warmUpBuffer := defaultWarmUpBuffer
+	if pCfg.WarmUpBuffer != "" {
+		warmUpBuffer, _ = time.ParseDuration(pCfg.WarmUpBuffer)
+	}
+	ttlAfterFinished := int32(defaultTTLAfterFinished)
+	if pCfg.TTLAfterFinished != nil {
+		ttlAfterFinished = *pCfg.TTLAfterFinished
+	}
+
+	provider := &Provider{
+		client:           client,
+		keyring:          keyring,
+		namespace:        namespace,
+		image:            pCfg.Image,
+		imagePullPolicy:  corev1.PullPolicy(pCfg.ImagePullPolicy),
+		serviceAccount:   pCfg.ServiceAccount,
+		keySecretName:    pCfg.KeySecretName,
+		warmUpBuffer:     warmUpBuffer,
+		ttlAfterFinished: ttlAfterFinished,
+		timers:           make(map[uuid.UUID]*time.Timer),
+	}
+
+	if err := provider.armPending(ctx); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrLoadSchedule, err)
+	}
+
+	return provider, nil
+}
+
+// Creates a suspended Job and the Secret containing the event
+// and arms a timer that releases the Job
+// If the job is already scheduled, its event and release time are updated
+func (p *Provider) ScheduleJob(ctx context.Context, event reservation.Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	releaseAt := event.DropTime.Add(-p.warmUpBuffer).UTC()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	job, err := p.client.BatchV1().Jobs(p.namespace).Create(ctx, p.newJob(event.JobID, releaseAt), metav1.CreateOptions{})
+	if apierrors.IsAlreadyExists(err) {
+		job, err = p.client.BatchV1().Jobs(p.namespace).Get(ctx, resourceName(event.JobID), metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		job.Annotations[releaseAtAnnotation] = releaseAt.Format(time.RFC3339Nano)
+		if job, err = p.client.BatchV1().Jobs(p.namespace).Update(ctx, job, metav1.UpdateOptions{}); err != nil {
+			return err
+		}
+	} else if err != nil {
+		return err
+	}
+
+	secret := p.newSecret(job, payload)
+	_, err = p.client.CoreV1().Secrets(p.namespace).Create(ctx, secret, metav1.CreateOptions{})
+	if apierrors.IsAlreadyExists(err) {
+		_, err = p.client.CoreV1().Secrets(p.namespace).Update(ctx, secret, metav1.UpdateOptions{})
+	}
+	if err != nil {
+		return err
+	}
+
+	p.arm(event.JobID, releaseAt)
+	return nil
+}
+
+// Deletes the Job and its Secret and stops the release timer
+func (p *Provider) CancelJob(ctx context.Context, jobID uuid.UUID) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if timer, exists := p.timers[jobID]; exists {
+		timer.Stop()
+		delete(p.timers, jobID)
+	}
+
+	propagation := metav1.DeletePropagationBackground
+	err := p.client.BatchV1().Jobs(p.namespace).Delete(ctx, resourceName(jobID), metav1.DeleteOptions{
+		PropagationPolicy: &propagation,
+	})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+
+	// The Secret is owned by the Job but is deleted explicitly
+	// in case the Job was created without it
+	err = p.client.CoreV1().Secrets(p.namespace).Delete(ctx, resourceName(jobID), metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// Retrieves the output of an executed job from the logs of its pod
+// The output is the last JSON line written by the executor
+func (p *Provider) GetJobOutput(ctx context.Context, jobID uuid.UUID, scheduledAt time.Time) (reservation.Output, error) {
+	var output reservation.Output
+	pods, err := p.client.CoreV1().Pods(p.namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: managedByLabel + "=" + managedByValue + "," + jobIDLabel + "=" + jobID.String(),
+	})
+	if err != nil {
+		return output, err
+	}
+
+	for _, pod := range pods.Items {
+		logs, err := p.client.CoreV1().Pods(p.namespace).GetLogs(pod.Name, &corev1.PodLogOptions{
+			Container: executorContainer,
+		}).DoRaw(ctx)
+		if err != nil {
+			continue
+		}
+
+		lines := strings.Split(strings.TrimSpace(string(logs)), "\n")
+		for i := len(lines) - 1; i >= 0; i-- {
+			var podOutput reservation.Output
+			if err := json.Unmarshal([]byte(lines[i]), &podOutput); err == nil && podOutput.JobId == jobID {
+				return podOutput, nil
+			}
+		}
+	}
+
+	return output, fmt.Errorf("%w: %s", cloud.ErrOutputNotFound, jobID)
+}
+
+// Returns the job IDs of all managed Jobs that have not finished
+// Released Jobs that are still running are included so that
+// they are not scheduled a second time
+func (p *Provider) ListScheduledJobs(ctx context.Context) ([]uuid.UUID, error) {
+	jobs, err := p.client.BatchV1().Jobs(p.namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: managedByLabel + "=" + managedByValue,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	jobIDs := make([]uuid.UUID, 0, len(jobs.Items))
+	for _, job := range jobs.Items {
+		if isFinished(&job) {
+			continue
+		}
+		jobID, err := uuid.Parse(job.Labels[jobIDLabel])
+		if err != nil {
+			continue
+		}
+		jobIDs = append(jobIDs, jobID)
+	}
+
+	return jobIDs, nil
+}
+
+// Updates the encrypted platform token in the event Secret of a pending Job
+// and re-encrypts its callback secret under the primary master key
+func (p *Provider) UpdateJobCredentials(ctx context.Context, jobID uuid.UUID, encryptedToken string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	secret, err := p.client.CoreV1().Secrets(p.namespace).Get(ctx, resourceName(jobID), metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return fmt.Errorf("%w: %s", cloud.ErrJobNotFound, jobID)
+	} else if err != nil {
+		return err
+	}
+
+	var event reservation.Event
+	if err := json.Unmarshal(secret.Data[eventSecretKey], &event); err != nil {
+		return err
+	}
+	event.EncryptedToken = encryptedToken
+	if err := cloud.ReEncryptCallbackSecret(ctx, p, &event); err != nil {
+		return err
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	patch, err := json.Marshal(map[string]any{
+		"data": map[string][]byte{
+			eventSecretKey: payload,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = p.client.CoreV1().Secrets(p.namespace).Patch(ctx, secret.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+	return err
+}
+
+// Encrypts a provided string using the primary master key
+// and returns the base64 encoded ciphertext
+func (p *Provider) EncryptData(ctx context.Context, plaintext string) (string, error) {
+	ciphertext, err := p.keyring.Encrypt([]byte(plaintext))
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(ciphertext), nil
+}
+
+// Decrypts a base64-encoded ciphertext using the master key
+// it was encrypted with and returns the plaintext
+func (p *Provider) DecryptData(ctx context.Context, ciphertext string) (string, error) {
+	ciphertextBlob, err := base64.StdEncoding.DecodeString(ciphertext)
+	if err != nil {
+		return "", err
+	}
+	return p.keyring.Decrypt(ctx, ciphertextBlob)
+}
+
+// Arms a release timer for all suspended Jobs managed by the provider
+func (p *Provider) armPending(ctx context.Context) error {
+	jobs, err := p.client.BatchV1().Jobs(p.namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: managedByLabel + "=" + managedByValue,
+	})
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var errs []error
+	for _, job := range jobs.Items {
+		if job.Spec.Suspend == nil || !*job.Spec.Suspend {
+			continue
+		}
+
+		jobID, err := uuid.Parse(job.Labels[jobIDLabel])
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		releaseAt, err := time.Parse(time.RFC3339Nano, job.Annotations[releaseAtAnnotation])
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%w: %s", ErrInvalidReleaseTime, job.Name))
+			continue
+		}
+
+		// Jobs whose release time has passed are released immediately
+		// so that the job reports its outcome instead of remaining scheduled
+		p.arm(jobID, releaseAt)
+	}
+
+	return errors.Join(errs...)
+}
+
+// Arms a timer releasing a Job, replacing any existing timer for the job
+// NOTE: Must be called with the mutex held
+func (p *Provider) arm(jobID uuid.UUID, releaseAt time.Time) {
+	if timer, exists := p.timers[jobID]; exists {
+		timer.Stop()
+	}
+
+	p.timers[jobID] = time.AfterFunc(time.Until(releaseAt), func() {
+		p.release(jobID)
+	})
+}
+
+// Releases a suspended Job so that its executor pod is started
+func (p *Provider) release(jobID uuid.UUID) {
+	p.mu.Lock()
+	delete(p.timers, jobID)
+	p.mu.Unlock()
+
+	patch := []byte(`{"spec":{"suspend":false}}`)
+	// The Job not existing means that it was cancelled
+	_, _ = p.client.BatchV1().Jobs(p.namespace).Patch(context.Background(), resourceName(jobID), types.MergePatchType, patch, metav1.PatchOptions{})
+}
+
+// Builds a suspended Job running the executor for a job
+func (p *Provider) newJob(jobID uuid.UUID, releaseAt time.Time) *batchv1.Job {
+	suspend := true
+	backoffLimit := int32(0)
+	ttlAfterFinished := p.ttlAfterFinished
+	labels := map[string]string{
+		managedByLabel: managedByValue,
+		jobIDLabel:     jobID.String(),
+	}
+
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      resourceName(jobID),
+			Namespace: p.namespace,
+			Labels:    labels,
+			Annotations: map[string]string{
+				releaseAtAnnotation: releaseAt.Format(time.RFC3339Nano),
+			},
+		},
+		Spec: batchv1.JobSpec{
+			Suspend:                 &suspend,
+			BackoffLimit:            &backoffLimit,
+			TTLSecondsAfterFinished: &ttlAfterFinished,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: labels,
+				},
+				Spec: corev1.PodSpec{
+					RestartPolicy:      corev1.RestartPolicyNever,
+					ServiceAccountName: p.serviceAccount,
+					Containers: []corev1.Container{
+						{
+							Name:            executorContainer,
+							Image:           p.image,
+							ImagePullPolicy: p.imagePullPolicy,
+							Env: []corev1.EnvVar{
+								{Name: eventFileEnv, Value: eventMountPath + "/" + eventSecretKey},
+							},
+							EnvFrom: []corev1.EnvFromSource{
+								{SecretRef: &corev1.SecretEnvSource{
+									LocalObjectReference: corev1.LocalObjectReference{Name: p.keySecretName},
+								}},
+							},
+							VolumeMounts: []corev1.VolumeMount{
+								{Name: "event", MountPath: eventMountPath, ReadOnly: true},
+							},
+						},
+					},
+					Volumes: []corev1.Volume{
+						{
+							Name: "event",
+							VolumeSource: corev1.VolumeSource{
+								Secret: &corev1.SecretVolumeSource{SecretName: resourceName(jobID)},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// Builds the Secret containing the event of a Job
+// The Secret is owned by the Job so that it is garbage collected with it
+func (p *Provider) newSecret(job *batchv1.Job, payload []byte) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      job.Name,
+			Namespace: p.namespace,
+			Labels:    job.Labels,
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(job, batchv1.SchemeGroupVersion.WithKind("Job")),
+			},
+		},
+		Type: corev1.SecretTypeOpaque,
+		Data: map[string][]byte{
+			eventSecretKey: payload,
+		},
+	}
+}
+
+// Returns whether a Job has completed or failed
+func isFinished(job *batchv1.Job) bool {
+	for _, condition := range job.Status.Conditions {
+		if (condition.Type == batchv1.JobComplete || condition.Type == batchv1.JobFailed) && condition.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns the name of the Job and Secret of a job
+func resourceName(jobID uuid.UUID) string {
+	return "cierge-job-" + jobID.String()
+}
+
+// Loads the kubernetes client config from a kubeconfig
+// file or the in-cluster config if no file is specified
+func clientConfig(kubeconfig string) (*rest.Config, error) {
+	var restConfig *rest.Config
+	var err error
+	if kubeconfig != "" {
+		restConfig, err = clientcmd.BuildConfigFromFlags("", kubeconfig)
+	} else {
+		restConfig, err = rest.InClusterConfig()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrClientConfig, err)
+	}
+	return restConfig, nil
+}
+
+// Validates a kubernetes config
+func ValidateConfig(cfg map[string]any, isProduction bool) error {
+	pCfg, err := decodeConfig(cfg)
+	if err != nil {
+		return err
+	}
+
+	if pCfg.Image == "" {
+		return ErrMissingImage
+	}
+	if pCfg.KeySecretName == "" {
+		return ErrMissingKeySecret
+	}
+	if pCfg.Namespace != "" && len(validation.IsDNS1123Label(pCfg.Namespace)) > 0 {
+		return fmt.Errorf("%w: %s", ErrInvalidNamespace, pCfg.Namespace)
+	}
+
+	if pCfg.WarmUpBuffer != "" {
+		warmUpBuffer, err := time.ParseDuration(pCfg.WarmUpBuffer)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidWarmUpBuffer, pCfg.WarmUpBuffer)
+		}
+		if warmUpBuffer < 0 {
+			return ErrNegativeWarmUpBuffer
+		}
+	}
+	if pCfg.TTLAfterFinished != nil && *pCfg.TTLAfterFinished < 0 {
+		return ErrNegativeTTL
+	}
+
+	if pCfg.Kubeconfig != "" {
+		if _, err := os.Stat(pCfg.Kubeconfig); err != nil {
+			return fmt.Errorf("%w: %s", ErrKubeconfigNotFound, pCfg.Kubeconfig)
+		}
+	}
+	if _, err := clientConfig(pCfg.Kubeconfig); err != nil {
+		return err
+	}
+
+	if _, err := envelope.LoadKeyring(pCfg.MasterKey, pCfg.MasterKeyID, pCfg.KeyFile); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Decodes the config map into a struct
+func decodeConfig(cfg map[string]any) (providerConfig, error) {
+	var pCfg providerConfig
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		Result:  &pCfg,
+		TagName: "json",
+	})
+	if err != nil {
+		return pCfg, fmt.Errorf("%w: %w", ErrDecodeConfig, err)
+	}
+	if err := decoder.Decode(cfg); err != nil {
+		return pCfg, fmt.Errorf("%w: %w", ErrDecodeConfig, err)
+	}
+	return pCfg, nil
+}
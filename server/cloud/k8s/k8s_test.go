@@ -0,0 +1,306 @@
+package k8s
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/daylamtayari/cierge/reservation"
+	"github.com/daylamtayari/cierge/reservation/envelope"
+	"github.com/daylamtayari/cierge/server/cloud"
+	"github.com/google/uuid"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// newTestProvider creates a provider backed by a fake clientset
+func newTestProvider(t *testing.T, client *fake.Clientset) *Provider {
+	t.Helper()
+	key, err := envelope.GenerateKey()
+	if err != nil {
+		t.Fatalf("GenerateKey failed: %v", err)
+	}
+
+	provider, err := newProvider(context.Background(), client, providerConfig{
+		Image:         "ghcr.io/daylamtayari/cierge/executor:latest",
+		KeySecretName: "cierge-executor-keys",
+		MasterKey:     base64.StdEncoding.EncodeToString(key),
+	})
+	if err != nil {
+		t.Fatalf("newProvider failed: %v", err)
+	}
+	t.Cleanup(func() {
+		provider.mu.Lock()
+		defer provider.mu.Unlock()
+		for _, timer := range provider.timers {
+			timer.Stop()
+		}
+	})
+	return provider
+}
+
+func newTestEvent() reservation.Event {
+	return reservation.Event{
+		JobID:          uuid.New(),
+		Platform:       "resy",
+		EncryptedToken: "token",
+		DropTime:       time.Now().Add(time.Hour).UTC(),
+	}
+}
+
+func TestScheduleJob_CreatesSuspendedJob(t *testing.T) {
+	ctx := context.Background()
+	client := fake.NewClientset()
+	provider := newTestProvider(t, client)
+	event := newTestEvent()
+
+	if err := provider.ScheduleJob(ctx, event); err != nil {
+		t.Fatalf("ScheduleJob failed: %v", err)
+	}
+
+	job, err := client.BatchV1().Jobs(defaultNamespace).Get(ctx, resourceName(event.JobID), metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed to get job: %v", err)
+	}
+	if job.Spec.Suspend == nil || !*job.Spec.Suspend {
+		t.Error("job should be suspended")
+	}
+	if job.Labels[jobIDLabel] != event.JobID.String() {
+		t.Errorf("job ID label: got %q, want %q", job.Labels[jobIDLabel], event.JobID)
+	}
+
+	secret, err := client.CoreV1().Secrets(defaultNamespace).Get(ctx, resourceName(event.JobID), metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed to get secret: %v", err)
+	}
+	if len(secret.OwnerReferences) != 1 || secret.OwnerReferences[0].Name != job.Name {
+		t.Error("secret should be owned by the job")
+	}
+	var storedEvent reservation.Event
+	if err := json.Unmarshal(secret.Data[eventSecretKey], &storedEvent); err != nil {
+		t.Fatalf("failed to unmarshal stored event: %v", err)
+	}
+	if storedEvent.JobID != event.JobID {
+		t.Errorf("stored event job ID: got %s, want %s", storedEvent.JobID, event.JobID)
+	}
+
+	if _, exists := provider.timers[event.JobID]; !exists {
+		t.Error("release timer should be armed")
+	}
+}
+
+func TestRelease_UnsuspendsJob(t *testing.T) {
+	ctx := context.Background()
+	client := fake.NewClientset()
+	provider := newTestProvider(t, client)
+	event := newTestEvent()
+
+	if err := provider.ScheduleJob(ctx, event); err != nil {
+		t.Fatalf("ScheduleJob failed: %v", err)
+	}
+	provider.release(event.JobID)
+
+	job, err := client.BatchV1().Jobs(defaultNamespace).Get(ctx, resourceName(event.JobID), metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed to get job: %v", err)
+	}
+	if job.Spec.Suspend != nil && *job.Spec.Suspend {
+		t.Error("job should have been released")
+	}
+}
+
+func TestUpdateJobCredentials_PatchesSecret(t *testing.T) {
+	ctx := context.Background()
+	client := fake.NewClientset()
+	provider := newTestProvider(t, client)
+	event := newTestEvent()
+
+	if err := provider.ScheduleJob(ctx, event); err != nil {
+		t.Fatalf("ScheduleJob failed: %v", err)
+	}
+	if err := provider.UpdateJobCredentials(ctx, event.JobID, "new-token"); err != nil {
+		t.Fatalf("UpdateJobCredentials failed: %v", err)
+	}
+
+	secret, err := client.CoreV1().Secrets(defaultNamespace).Get(ctx, resourceName(event.JobID), metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed to get secret: %v", err)
+	}
+	var storedEvent reservation.Event
+	if err := json.Unmarshal(secret.Data[eventSecretKey], &storedEvent); err != nil {
+		t.Fatalf("failed to unmarshal stored event: %v", err)
+	}
+	if storedEvent.EncryptedToken != "new-token" {
+		t.Errorf("encrypted token: got %q, want %q", storedEvent.EncryptedToken, "new-token")
+	}
+
+	if err := provider.UpdateJobCredentials(ctx, uuid.New(), "new-token"); !errors.Is(err, cloud.ErrJobNotFound) {
+		t.Errorf("expected ErrJobNotFound, got: %v", err)
+	}
+}
+
+func TestUpdateJobCredentials_ReEncryptsCallbackSecret(t *testing.T) {
+	ctx := context.Background()
+	client := fake.NewClientset()
+	provider := newTestProvider(t, client)
+	event := newTestEvent()
+
+	oldKey, err := envelope.GenerateKey()
+	if err != nil {
+		t.Fatalf("GenerateKey failed: %v", err)
+	}
+	provider.keyring, err = envelope.NewKeyring("old", map[string][]byte{"old": oldKey})
+	if err != nil {
+		t.Fatalf("NewKeyring failed: %v", err)
+	}
+
+	encryptedSecret, err := provider.EncryptData(ctx, "callback-secret")
+	if err != nil {
+		t.Fatalf("EncryptData failed: %v", err)
+	}
+	event.EncryptedCallbackSecret = encryptedSecret
+	if err := provider.ScheduleJob(ctx, event); err != nil {
+		t.Fatalf("ScheduleJob failed: %v", err)
+	}
+
+	// Rotate to a new primary key while keeping the previous key
+	newKey, err := envelope.GenerateKey()
+	if err != nil {
+		t.Fatalf("GenerateKey failed: %v", err)
+	}
+	provider.keyring, err = envelope.NewKeyring("new", map[string][]byte{"old": oldKey, "new": newKey})
+	if err != nil {
+		t.Fatalf("NewKeyring failed: %v", err)
+	}
+
+	if err := provider.UpdateJobCredentials(ctx, event.JobID, "new-token"); err != nil {
+		t.Fatalf("UpdateJobCredentials failed: %v", err)
+	}
+
+	secret, err := client.CoreV1().Secrets(defaultNamespace).Get(ctx, resourceName(event.JobID), metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed to get secret: %v", err)
+	}
+	var storedEvent reservation.Event
+	if err := json.Unmarshal(secret.Data[eventSecretKey], &storedEvent); err != nil {
+		t.Fatalf("failed to unmarshal stored event: %v", err)
+	}
+	ciphertext, err := base64.StdEncoding.DecodeString(storedEvent.EncryptedCallbackSecret)
+	if err != nil {
+		t.Fatalf("invalid callback secret: %v", err)
+	}
+	if keyID, err := envelope.KeyID(ciphertext); err != nil || keyID != "new" {
+		t.Errorf("callback secret key ID: got %q (%v), want %q", keyID, err, "new")
+	}
+	if plaintext, err := provider.DecryptData(ctx, storedEvent.EncryptedCallbackSecret); err != nil || plaintext != "callback-secret" {
+		t.Errorf("callback secret: got %q (%v), want %q", plaintext, err, "callback-secret")
+	}
+}
+
+func TestCancelJob_DeletesJobAndSecret(t *testing.T) {
+	ctx := context.Background()
+	client := fake.NewClientset()
+	provider := newTestProvider(t, client)
+	event := newTestEvent()
+
+	if err := provider.ScheduleJob(ctx, event); err != nil {
+		t.Fatalf("ScheduleJob failed: %v", err)
+	}
+	if err := provider.CancelJob(ctx, event.JobID); err != nil {
+		t.Fatalf("CancelJob failed: %v", err)
+	}
+
+	if _, err := client.BatchV1().Jobs(defaultNamespace).Get(ctx, resourceName(event.JobID), metav1.GetOptions{}); !apierrors.IsNotFound(err) {
+		t.Errorf("expected job to be deleted, got: %v", err)
+	}
+	if _, err := client.CoreV1().Secrets(defaultNamespace).Get(ctx, resourceName(event.JobID), metav1.GetOptions{}); !apierrors.IsNotFound(err) {
+		t.Errorf("expected secret to be deleted, got: %v", err)
+	}
+	if _, exists := provider.timers[event.JobID]; exists {
+		t.Error("release timer should be stopped")
+	}
+
+	// Cancelling a job that does not exist is not an error
+	if err := provider.CancelJob(ctx, uuid.New()); err != nil {
+		t.Errorf("CancelJob of missing job failed: %v", err)
+	}
+}
+
+func TestListScheduledJobs_SkipsFinishedJobs(t *testing.T) {
+	ctx := context.Background()
+	client := fake.NewClientset()
+	provider := newTestProvider(t, client)
+	pending := newTestEvent()
+	finished := newTestEvent()
+
+	for _, event := range []reservation.Event{pending, finished} {
+		if err := provider.ScheduleJob(ctx, event); err != nil {
+			t.Fatalf("ScheduleJob failed: %v", err)
+		}
+	}
+
+	job, err := client.BatchV1().Jobs(defaultNamespace).Get(ctx, resourceName(finished.JobID), metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed to get job: %v", err)
+	}
+	job.Status.Conditions = append(job.Status.Conditions, batchv1.JobCondition{
+		Type:   batchv1.JobComplete,
+		Status: corev1.ConditionTrue,
+	})
+	if _, err := client.BatchV1().Jobs(defaultNamespace).UpdateStatus(ctx, job, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("failed to update job status: %v", err)
+	}
+
+	jobIDs, err := provider.ListScheduledJobs(ctx)
+	if err != nil {
+		t.Fatalf("ListScheduledJobs failed: %v", err)
+	}
+	if len(jobIDs) != 1 || jobIDs[0] != pending.JobID {
+		t.Errorf("expected only the pending job, got %v", jobIDs)
+	}
+}
+
+func TestNewProvider_RearmsPendingJobs(t *testing.T) {
+	ctx := context.Background()
+	client := fake.NewClientset()
+	provider := newTestProvider(t, client)
+	event := newTestEvent()
+
+	if err := provider.ScheduleJob(ctx, event); err != nil {
+		t.Fatalf("ScheduleJob failed: %v", err)
+	}
+
+	// A new provider sharing the cluster simulates a server restart
+	restarted := newTestProvider(t, client)
+	if _, exists := restarted.timers[event.JobID]; !exists {
+		t.Error("release timer should be re-armed on startup")
+	}
+}
+
+func TestValidateConfig(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  map[string]any
+		want error
+	}{
+		{"missing image", map[string]any{"key_secret_name": "keys"}, ErrMissingImage},
+		{"missing key secret", map[string]any{"image": "executor"}, ErrMissingKeySecret},
+		{"invalid namespace", map[string]any{"image": "executor", "key_secret_name": "keys", "namespace": "Not_Valid"}, ErrInvalidNamespace},
+		{"invalid warm up buffer", map[string]any{"image": "executor", "key_secret_name": "keys", "warm_up_buffer": "soon"}, ErrInvalidWarmUpBuffer},
+		{"missing kubeconfig", map[string]any{"image": "executor", "key_secret_name": "keys", "kubeconfig": "/does/not/exist"}, ErrKubeconfigNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateConfig(tt.cfg, true); !errors.Is(err, tt.want) {
+				t.Errorf("expected %v, got: %v", tt.want, err)
+			}
+		})
+	}
+}
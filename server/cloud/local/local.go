@@ -2,39 +2,223 @@ package local
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
 
 	"github.com/daylamtayari/cierge/reservation"
+	"github.com/daylamtayari/cierge/reservation/envelope"
 	"github.com/daylamtayari/cierge/server/cloud"
+	"github.com/daylamtayari/cierge/server/cloud/internal/schedule"
+	"github.com/go-viper/mapstructure/v2"
 	"github.com/google/uuid"
 )
 
+var (
+	ErrDecodeConfig         = errors.New("failed to decode config")
+	ErrInvalidWarmUpBuffer  = errors.New("warm up buffer is not a valid duration")
+	ErrNegativeWarmUpBuffer = errors.New("warm up buffer cannot be negative")
+	ErrGenerateKeyFile      = errors.New("failed to generate key file")
+)
+
+const (
+	defaultSchedulePath = "./data/schedule"
+	defaultWarmUpBuffer = 30 * time.Second
+	// Key file generated on first start if neither a master key nor a key file is set
+	defaultKeyFile = "./data/local_key.json"
+)
+
+// Local provider that schedules and runs reservation jobs in-process
+// Scheduled events are persisted to disk and re-armed on startup
 type Provider struct {
+	scheduler *schedule.Scheduler
+	keyring   *envelope.Keyring
 }
 
-func NewProvider(config map[string]any) (cloud.Provider, error) {
-	return &Provider{}, nil
+// Local provider configuration
+type providerConfig struct {
+	SchedulePath string `json:"schedule_path"`
+	WarmUpBuffer string `json:"warm_up_buffer"`
+	MasterKey    string `json:"master_key"`
+	MasterKeyID  string `json:"master_key_id"`
+	KeyFile      string `json:"key_file"`
 }
 
-func (p *Provider) ScheduleJob(ctx context.Context, event reservation.Event) error {
-	return nil
+// Creates a new local provider and re-arms any events that were
+// scheduled prior to the server being restarted
+func NewProvider(cfg map[string]any) (cloud.Provider, error) {
+	pCfg, err := decodeConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	schedulePath := defaultSchedulePath
+	if pCfg.SchedulePath != "" {
+		schedulePath = pCfg.SchedulePath
+	}
+	// Value has already been validated
+	warmUpBuffer := defaultWarmUpBuffer
+	if pCfg.WarmUpBuffer != "" {
+		warmUpBuffer, _ = time.ParseDuration(pCfg.WarmUpBuffer)
+	}
+
+	// Configs without a key use a generated key file so that they keep working
+	keyFile := pCfg.KeyFile
+	if pCfg.MasterKey == "" && keyFile == "" {
+		keyFile = defaultKeyFile
+		if err := ensureKeyFile(keyFile); err != nil {
+			return nil, err
+		}
+	}
+	keyring, err := envelope.LoadKeyring(pCfg.MasterKey, pCfg.MasterKeyID, keyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	provider := &Provider{
+		keyring: keyring,
+	}
+	provider.scheduler, err = schedule.New(schedulePath, warmUpBuffer, provider.run)
+	if err != nil {
+		return nil, err
+	}
+
+	return provider, nil
 }
 
-func (p *Provider) UpdateJobCredentials(ctx context.Context, jobID uuid.UUID, encryptedToken string) error {
-	return nil
+// Persists the event and arms a timer that runs the reservation handler
+// at the drop time minus the warm up buffer
+func (p *Provider) ScheduleJob(ctx context.Context, event reservation.Event) error {
+	return p.scheduler.Schedule(event)
 }
 
+// Cancels a pending event by stopping its timer and removing it from the store
 func (p *Provider) CancelJob(ctx context.Context, jobID uuid.UUID) error {
-	return nil
+	return p.scheduler.Cancel(jobID)
+}
+
+// Returns the job IDs of all pending events
+func (p *Provider) ListScheduledJobs(ctx context.Context) ([]uuid.UUID, error) {
+	return p.scheduler.List()
 }
 
+// Updates the encrypted platform token of a pending event and
+// re-encrypts its callback secret under the primary master key
+func (p *Provider) UpdateJobCredentials(ctx context.Context, jobID uuid.UUID, encryptedToken string) error {
+	return p.scheduler.Update(jobID, func(event *reservation.Event) error {
+		event.EncryptedToken = encryptedToken
+		return cloud.ReEncryptCallbackSecret(ctx, p, event)
+	})
+}
+
+// Encrypts a provided string using the primary master key
+// and returns the base64 encoded ciphertext
 func (p *Provider) EncryptData(ctx context.Context, plaintext string) (string, error) {
-	return "", nil
+	ciphertext, err := p.keyring.Encrypt([]byte(plaintext))
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(ciphertext), nil
 }
 
+// Decrypts a base64-encoded ciphertext using the master key
+// it was encrypted with and returns the plaintext
 func (p *Provider) DecryptData(ctx context.Context, ciphertext string) (string, error) {
-	return "", nil
+	ciphertextBlob, err := base64.StdEncoding.DecodeString(ciphertext)
+	if err != nil {
+		return "", err
+	}
+	return p.keyring.Decrypt(ctx, ciphertextBlob)
+}
+
+// Runs the reservation handler for an event in-process
+func (p *Provider) run(event reservation.Event) {
+	reservation.Handle(context.Background(), event, p.keyring)
 }
 
-func ValidateConfig(config map[string]any, isProduction bool) error {
+// Validates a local config
+func ValidateConfig(cfg map[string]any, isProduction bool) error {
+	pCfg, err := decodeConfig(cfg)
+	if err != nil {
+		return err
+	}
+
+	if pCfg.WarmUpBuffer != "" {
+		warmUpBuffer, err := time.ParseDuration(pCfg.WarmUpBuffer)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidWarmUpBuffer, pCfg.WarmUpBuffer)
+		}
+		if warmUpBuffer < 0 {
+			return ErrNegativeWarmUpBuffer
+		}
+	}
+
+	// The default key file is generated when the provider is created if neither is set
+	if pCfg.MasterKey != "" || pCfg.KeyFile != "" {
+		if _, err := envelope.LoadKeyring(pCfg.MasterKey, pCfg.MasterKeyID, pCfg.KeyFile); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
+
+// Generates a key file with a single random master key if it does not exist
+func ensureKeyFile(path string) error {
+	if _, err := os.Stat(path); err == nil {
+		return nil
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("%w: %w", ErrGenerateKeyFile, err)
+	}
+
+	key, err := envelope.GenerateKey()
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrGenerateKeyFile, err)
+	}
+	content, err := json.Marshal(envelope.KeyFile{
+		PrimaryKeyID: envelope.DefaultKeyID,
+		Keys:         map[string]string{envelope.DefaultKeyID: base64.StdEncoding.EncodeToString(key)},
+	})
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrGenerateKeyFile, err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("%w: %w", ErrGenerateKeyFile, err)
+	}
+	// The file is created exclusively so that an existing key is never overwritten
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrGenerateKeyFile, err)
+	}
+	_, err = file.Write(content)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		// A partially written key file would prevent the provider from starting
+		os.Remove(path) // nolint:errcheck
+		return fmt.Errorf("%w: %w", ErrGenerateKeyFile, err)
+	}
+	return nil
+}
+
+// Decodes the config map into a struct
+func decodeConfig(cfg map[string]any) (providerConfig, error) {
+	var pCfg providerConfig
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		Result:  &pCfg,
+		TagName: "json",
+	})
+	if err != nil {
+		return pCfg, fmt.Errorf("%w: %w", ErrDecodeConfig, err)
+	}
+	if err := decoder.Decode(cfg); err != nil {
+		return pCfg, fmt.Errorf("%w: %w", ErrDecodeConfig, err)
+	}
+	return pCfg, nil
+}
@@ -0,0 +1,390 @@
+package subprocess
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"github.com/daylamtayari/cierge/reservation"
+	"github.com/daylamtayari/cierge/reservation/envelope"
+	"github.com/daylamtayari/cierge/server/cloud"
+	"github.com/daylamtayari/cierge/server/cloud/internal/schedule"
+	"github.com/go-viper/mapstructure/v2"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrDecodeConfig         = errors.New("failed to decode config")
+	ErrMissingCommand       = errors.New("executor command is required")
+	ErrCommandNotFound      = errors.New("executor command was not found")
+	ErrInvalidTimeout       = errors.New("timeout is not a valid duration")
+	ErrNonPositiveTimeout   = errors.New("timeout must be positive")
+	ErrInvalidWarmUpBuffer  = errors.New("warm up buffer is not a valid duration")
+	ErrNegativeWarmUpBuffer = errors.New("warm up buffer cannot be negative")
+	ErrNoExecutorOutput     = errors.New("executor did not write an output")
+)
+
+const (
+	defaultSchedulePath = "./data/schedule"
+	defaultOutputPath   = "./data/output"
+	defaultWarmUpBuffer = 30 * time.Second
+	defaultTimeout      = 15 * time.Minute
+
+	// Maximum number of bytes of the executor's stderr included in an error
+	maxStderrLength = 1024
+
+	outputFileSuffix = ".output.json"
+)
+
+// Environment variables used to provide the keys to the executor
+const (
+	masterKeyEnv   = "CIERGE_EXECUTOR_MASTER_KEY"
+	masterKeyIDEnv = "CIERGE_EXECUTOR_MASTER_KEY_ID"
+	keyFileEnv     = "CIERGE_EXECUTOR_KEY_FILE"
+)
+
+// Environment variables of the server that are passed to the executor
+var inheritedEnv = []string{"PATH", "HOME", "TZ"}
+
+// Subprocess provider that runs each reservation job by invoking an executor
+// command, either the executor binary directly or a container runtime
+// The event is written to the executor's stdin and the output it writes
+// to stdout is stored and delivered to the server if the executor failed to
+// notify the server itself
+type Provider struct {
+	scheduler    *schedule.Scheduler
+	keyring      *envelope.Keyring
+	command      []string
+	env          []string
+	timeout      time.Duration
+	warmUpBuffer time.Duration
+	outputPath   string
+}
+
+// Subprocess provider configuration
+type providerConfig struct {
+	Command      []string          `json:"command"`
+	Env          map[string]string `json:"env"`
+	Timeout      string            `json:"timeout"`
+	SchedulePath string            `json:"schedule_path"`
+	OutputPath   string            `json:"output_path"`
+	WarmUpBuffer string            `json:"warm_up_buffer"`
+	MasterKey    string            `json:"master_key"`
+	MasterKeyID  string            `json:"master_key_id"`
+	KeyFile      string            `json:"key_file"`
+}
+
+// Creates a new subprocess provider and re-arms any events that were
+// scheduled prior to the server being restarted
+func NewProvider(cfg map[string]any) (cloud.Provider, error) {
+	pCfg, err := decodeConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	schedulePath := defaultSchedulePath
+	if pCfg.SchedulePath != "" {
+		schedulePath = pCfg.SchedulePath
+	}
+	outputPath := defaultOutputPath
+	if pCfg.OutputPath != "" {
+		outputPath = pCfg.OutputPath
+	}
+	// Values have already been validated
+	warmUpBuffer := defaultWarmUpBuffer
+	if pCfg.WarmUpBuffer != "" {
+		warmUpBuffer, _ = time.ParseDuration(pCfg.WarmUpBuffer)
+	}
+	timeout := defaultTimeout
+	if pCfg.Timeout != "" {
+		timeout, _ = time.ParseDuration(pCfg.Timeout)
+	}
+
+	keyring, err := envelope.LoadKeyring(pCfg.MasterKey, pCfg.MasterKeyID, pCfg.KeyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(outputPath, 0o700); err != nil {
+		return nil, err
+	}
+
+	provider := &Provider{
+		keyring:      keyring,
+		command:      pCfg.Command,
+		env:          executorEnv(pCfg),
+		timeout:      timeout,
+		warmUpBuffer: warmUpBuffer,
+		outputPath:   outputPath,
+	}
+	provider.scheduler, err = schedule.New(schedulePath, warmUpBuffer, provider.run)
+	if err != nil {
+		return nil, err
+	}
+
+	return provider, nil
+}
+
+// Persists the event and arms a timer that invokes the executor
+// at the drop time minus the warm up buffer
+func (p *Provider) ScheduleJob(ctx context.Context, event reservation.Event) error {
+	return p.scheduler.Schedule(event)
+}
+
+// Cancels a pending event by stopping its timer and removing it from the store
+func (p *Provider) CancelJob(ctx context.Context, jobID uuid.UUID) error {
+	return p.scheduler.Cancel(jobID)
+}
+
+// Returns the job IDs of all pending events
+func (p *Provider) ListScheduledJobs(ctx context.Context) ([]uuid.UUID, error) {
+	return p.scheduler.List()
+}
+
+// Updates the encrypted platform token of a pending event and
+// re-encrypts its callback secret under the primary master key
+func (p *Provider) UpdateJobCredentials(ctx context.Context, jobID uuid.UUID, encryptedToken string) error {
+	return p.scheduler.Update(jobID, func(event *reservation.Event) error {
+		event.EncryptedToken = encryptedToken
+		return cloud.ReEncryptCallbackSecret(ctx, p, event)
+	})
+}
+
+// Returns the output of an executed job from the output directory
+func (p *Provider) GetJobOutput(ctx context.Context, jobID uuid.UUID, scheduledAt time.Time) (reservation.Output, error) {
+	var output reservation.Output
+	payload, err := os.ReadFile(filepath.Join(p.outputPath, jobID.String()+outputFileSuffix))
+	if errors.Is(err, fs.ErrNotExist) {
+		return output, fmt.Errorf("%w: %s", cloud.ErrOutputNotFound, jobID)
+	} else if err != nil {
+		return output, err
+	}
+
+	if err := json.Unmarshal(payload, &output); err != nil {
+		return output, err
+	}
+	return output, nil
+}
+
+// The executor is invoked the warm up buffer before the drop time and is killed after the timeout
+func (p *Provider) MaxRunTime() time.Duration {
+	return p.timeout - p.warmUpBuffer
+}
+
+// Encrypts a provided string using the primary master key
+// and returns the base64 encoded ciphertext
+func (p *Provider) EncryptData(ctx context.Context, plaintext string) (string, error) {
+	ciphertext, err := p.keyring.Encrypt([]byte(plaintext))
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(ciphertext), nil
+}
+
+// Decrypts a base64-encoded ciphertext using the master key
+// it was encrypted with and returns the plaintext
+func (p *Provider) DecryptData(ctx context.Context, ciphertext string) (string, error) {
+	ciphertextBlob, err := base64.StdEncoding.DecodeString(ciphertext)
+	if err != nil {
+		return "", err
+	}
+	return p.keyring.Decrypt(ctx, ciphertextBlob)
+}
+
+// Invokes the executor for an event and stores its output
+// If the executor did not notify the server, the output
+// captured from its stdout is delivered instead
+func (p *Provider) run(event reservation.Event) {
+	ctx, cancel := context.WithTimeout(context.Background(), p.timeout)
+	defer cancel()
+
+	output := p.execute(ctx, event)
+
+	if event.Callback && !output.Notified {
+		if err := reservation.NotifyServer(context.Background(), event, output, p.keyring); err == nil {
+			output.Notified = true
+		}
+	}
+
+	// The output file is the only record of the
+	// job if the server could not be notified
+	_ = p.writeOutput(event.JobID, output)
+}
+
+// Runs the executor command, writing the event to its stdin
+// and parsing the output it writes to stdout
+// If the executor did not write an output, a failed output is returned
+func (p *Provider) execute(ctx context.Context, event reservation.Event) reservation.Output {
+	startTime := time.Now().UTC()
+
+	var stdout, stderr bytes.Buffer
+	payload, err := json.Marshal(event)
+	if err == nil {
+		cmd := exec.CommandContext(ctx, p.command[0], p.command[1:]...)
+		cmd.Stdin = bytes.NewReader(payload)
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+		cmd.Env = p.env
+		err = cmd.Run()
+	}
+
+	output, parseErr := parseOutput(stdout.Bytes())
+	if parseErr == nil {
+		return output
+	}
+
+	if err == nil {
+		err = parseErr
+	}
+	if stderr.Len() > 0 {
+		err = fmt.Errorf("%w: %s", err, tail(stderr.Bytes(), maxStderrLength))
+	}
+	return reservation.Output{
+		JobId:     event.JobID,
+		Success:   false,
+		Message:   "failed to run executor",
+		Error:     err.Error(),
+		Level:     "error",
+		StartTime: startTime,
+		Duration:  time.Since(startTime),
+	}
+}
+
+// Writes the output of a job to the output directory
+func (p *Provider) writeOutput(jobID uuid.UUID, output reservation.Output) error {
+	payload, err := json.Marshal(output)
+	if err != nil {
+		return err
+	}
+
+	outputFile := filepath.Join(p.outputPath, jobID.String()+outputFileSuffix)
+	tmpFile := outputFile + ".tmp"
+	if err := os.WriteFile(tmpFile, payload, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmpFile, outputFile)
+}
+
+// Parses the last line of the executor's stdout as an output
+func parseOutput(stdout []byte) (reservation.Output, error) {
+	var lastLine []byte
+	scanner := bufio.NewScanner(bytes.NewReader(stdout))
+	scanner.Buffer(make([]byte, 0, 64*1024), len(stdout)+1)
+	for scanner.Scan() {
+		if line := bytes.TrimSpace(scanner.Bytes()); len(line) > 0 {
+			lastLine = line
+		}
+	}
+
+	var output reservation.Output
+	if lastLine == nil {
+		return output, ErrNoExecutorOutput
+	}
+	if err := json.Unmarshal(lastLine, &output); err != nil {
+		return output, fmt.Errorf("%w: %w", ErrNoExecutorOutput, err)
+	}
+	return output, nil
+}
+
+// Returns the last n bytes of a value
+func tail(value []byte, n int) []byte {
+	value = bytes.TrimSpace(value)
+	if len(value) > n {
+		return value[len(value)-n:]
+	}
+	return value
+}
+
+// Builds the environment of the executor from the inherited
+// server environment, the configured environment, and the keys
+func executorEnv(pCfg providerConfig) []string {
+	env := make([]string, 0, len(inheritedEnv)+len(pCfg.Env)+2)
+	for _, key := range inheritedEnv {
+		if value, exists := os.LookupEnv(key); exists {
+			env = append(env, key+"="+value)
+		}
+	}
+	for key, value := range pCfg.Env {
+		env = append(env, key+"="+value)
+	}
+
+	if pCfg.KeyFile != "" {
+		keyFile, err := filepath.Abs(pCfg.KeyFile)
+		if err != nil {
+			keyFile = pCfg.KeyFile
+		}
+		env = append(env, keyFileEnv+"="+keyFile)
+	} else {
+		env = append(env, masterKeyEnv+"="+pCfg.MasterKey)
+		if pCfg.MasterKeyID != "" {
+			env = append(env, masterKeyIDEnv+"="+pCfg.MasterKeyID)
+		}
+	}
+	return env
+}
+
+// Validates a subprocess config
+func ValidateConfig(cfg map[string]any, isProduction bool) error {
+	pCfg, err := decodeConfig(cfg)
+	if err != nil {
+		return err
+	}
+
+	if len(pCfg.Command) == 0 || pCfg.Command[0] == "" {
+		return ErrMissingCommand
+	}
+	if _, err := exec.LookPath(pCfg.Command[0]); err != nil {
+		return fmt.Errorf("%w: %s", ErrCommandNotFound, pCfg.Command[0])
+	}
+
+	if pCfg.Timeout != "" {
+		timeout, err := time.ParseDuration(pCfg.Timeout)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidTimeout, pCfg.Timeout)
+		}
+		if timeout <= 0 {
+			return ErrNonPositiveTimeout
+		}
+	}
+
+	if pCfg.WarmUpBuffer != "" {
+		warmUpBuffer, err := time.ParseDuration(pCfg.WarmUpBuffer)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidWarmUpBuffer, pCfg.WarmUpBuffer)
+		}
+		if warmUpBuffer < 0 {
+			return ErrNegativeWarmUpBuffer
+		}
+	}
+
+	if _, err := envelope.LoadKeyring(pCfg.MasterKey, pCfg.MasterKeyID, pCfg.KeyFile); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Decodes the config map into a struct
+func decodeConfig(cfg map[string]any) (providerConfig, error) {
+	var pCfg providerConfig
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		Result:  &pCfg,
+		TagName: "json",
+	})
+	if err != nil {
+		return pCfg, fmt.Errorf("%w: %w", ErrDecodeConfig, err)
+	}
+	if err := decoder.Decode(cfg); err != nil {
+		return pCfg, fmt.Errorf("%w: %w", ErrDecodeConfig, err)
+	}
+	return pCfg, nil
+}
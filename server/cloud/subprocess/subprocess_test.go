@@ -0,0 +1,93 @@
+package subprocess
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/daylamtayari/cierge/reservation"
+	"github.com/daylamtayari/cierge/server/cloud"
+	"github.com/google/uuid"
+)
+
+// newTestProvider creates a provider that runs a shell script as the executor
+func newTestProvider(script string) *Provider {
+	return &Provider{
+		command: []string{"sh", "-c", script},
+		env:     executorEnv(providerConfig{MasterKey: "key"}),
+		timeout: 10 * time.Second,
+	}
+}
+
+func TestExecute_ParsesOutput(t *testing.T) {
+	jobID := uuid.New()
+	// Echo the job ID read from stdin back as part of the output
+	provider := newTestProvider(`read -r event; echo "not json"; echo '{"job_id":"` + jobID.String() + `","success":true,"notified":true,"message":"done"}'`)
+
+	output := provider.execute(context.Background(), reservation.Event{JobID: jobID})
+	if !output.Success {
+		t.Errorf("expected successful output, got error: %s", output.Error)
+	}
+	if output.JobId != jobID {
+		t.Errorf("JobId: got %s, want %s", output.JobId, jobID)
+	}
+	if !output.Notified {
+		t.Error("expected output to be marked as notified")
+	}
+}
+
+func TestExecute_NoOutput(t *testing.T) {
+	jobID := uuid.New()
+	provider := newTestProvider(`echo "executor crashed" >&2; exit 2`)
+
+	output := provider.execute(context.Background(), reservation.Event{JobID: jobID})
+	if output.Success {
+		t.Error("expected failed output")
+	}
+	if output.JobId != jobID {
+		t.Errorf("JobId: got %s, want %s", output.JobId, jobID)
+	}
+	if !strings.Contains(output.Error, "executor crashed") {
+		t.Errorf("expected error to contain stderr, got: %s", output.Error)
+	}
+}
+
+func TestExecute_ReceivesKeys(t *testing.T) {
+	provider := newTestProvider(`echo "{\"success\":true,\"message\":\"$` + masterKeyEnv + `\"}"`)
+
+	output := provider.execute(context.Background(), reservation.Event{JobID: uuid.New()})
+	if output.Message != "key" {
+		t.Errorf("expected master key to be passed to the executor, got: %q", output.Message)
+	}
+}
+
+func TestParseOutput_Empty(t *testing.T) {
+	if _, err := parseOutput([]byte("\n\n")); !errors.Is(err, ErrNoExecutorOutput) {
+		t.Errorf("expected ErrNoExecutorOutput, got: %v", err)
+	}
+}
+
+func TestGetJobOutput_ReadsStoredOutput(t *testing.T) {
+	provider := newTestProvider("")
+	provider.outputPath = t.TempDir()
+	jobID := uuid.New()
+
+	if _, err := provider.GetJobOutput(context.Background(), jobID, time.Now()); !errors.Is(err, cloud.ErrOutputNotFound) {
+		t.Errorf("expected ErrOutputNotFound, got: %v", err)
+	}
+
+	stored := reservation.Output{JobId: jobID, Success: false, Error: "no slots"}
+	if err := provider.writeOutput(jobID, stored); err != nil {
+		t.Fatalf("writeOutput failed: %v", err)
+	}
+
+	output, err := provider.GetJobOutput(context.Background(), jobID, time.Now())
+	if err != nil {
+		t.Fatalf("GetJobOutput failed: %v", err)
+	}
+	if output.JobId != jobID || output.Error != stored.Error {
+		t.Errorf("unexpected output %+v", output)
+	}
+}
@@ -18,6 +18,9 @@ type Config struct {
 	Notification   []NotificationProvider `json:"notification"`
 	DefaultAdmin   User                   `json:"default_admin"`
 	PlatformToken  PlatformToken          `json:"platform_token"`
+	Reconciler     Reconciler             `json:"reconciler"`
+	Sweeper        Sweeper                `json:"sweeper"`
+	WarmUp         WarmUp                 `json:"warm_up"`
 }
 
 type Environment string
@@ -148,3 +151,25 @@ type PlatformToken struct {
 	RenewalInterval Duration `json:"renewal_interval" default:"24h"`
 	RenewBefore     Duration `json:"renew_before" default:"336h"`
 }
+
+// Sweeping of scheduled jobs that never received a callback configuration
+type Sweeper struct {
+	Interval    Duration `json:"interval" default:"15m"`
+	GracePeriod Duration `json:"grace_period" default:"1h"`
+	// Directory of the outbox in which executors store undelivered outputs
+	OutboxPath string `json:"outbox_path"`
+}
+
+// Reconciliation of scheduled jobs with the cloud provider configuration
+type Reconciler struct {
+	Interval    Duration `json:"interval" default:"1h"`
+	MinLeadTime Duration `json:"min_lead_time" default:"10m"`
+}
+
+// Warm up of jobs before their drop time configuration
+type WarmUp struct {
+	Lead              Duration `json:"lead" default:"10s"`
+	Connections       int      `json:"connections" default:"4"`
+	EarlyPolls        int      `json:"early_polls" default:"0"`
+	EarlyPollInterval Duration `json:"early_poll_interval" default:"500ms"`
+}
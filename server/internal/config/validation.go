@@ -136,6 +136,36 @@ func (c *Config) Validate() error {
 		}
 	}
 
+	// Reconciler validation
+	if c.Reconciler.Interval <= 0 {
+		errs = append(errs, ValidationError{"reconciler.interval", "reconciliation interval must be positive"})
+	}
+	if c.Reconciler.MinLeadTime < 0 {
+		errs = append(errs, ValidationError{"reconciler.min_lead_time", "minimum lead time cannot be negative"})
+	}
+
+	// Sweeper validation
+	if c.Sweeper.Interval <= 0 {
+		errs = append(errs, ValidationError{"sweeper.interval", "sweep interval must be positive"})
+	}
+	if c.Sweeper.GracePeriod <= 0 {
+		errs = append(errs, ValidationError{"sweeper.grace_period", "grace period must be positive"})
+	}
+
+	// Warm up validation
+	if c.WarmUp.Lead < 0 {
+		errs = append(errs, ValidationError{"warm_up.lead", "warm up lead cannot be negative"})
+	}
+	if c.WarmUp.Connections < 1 {
+		errs = append(errs, ValidationError{"warm_up.connections", "at least one connection must be warmed up"})
+	}
+	if c.WarmUp.EarlyPolls < 0 {
+		errs = append(errs, ValidationError{"warm_up.early_polls", "number of early polls cannot be negative"})
+	}
+	if c.WarmUp.EarlyPolls > 0 && c.WarmUp.EarlyPollInterval <= 0 {
+		errs = append(errs, ValidationError{"warm_up.early_poll_interval", "early poll interval must be positive"})
+	}
+
 	// Notification validation
 	availableNotificationProviders := notification.AvailableProviders()
 	for i, notificationProvider := range c.Notification {
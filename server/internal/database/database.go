@@ -63,10 +63,12 @@ func createCustomTypes(db *gorm.DB) error {
 			WHEN duplicate_object THEN null;
 		END $$`,
 		`DO $$ BEGIN
-			CREATE TYPE platform AS ENUM ('resy', 'opentable');
+			CREATE TYPE platform AS ENUM ('resy', 'opentable', 'tock');
 		EXCEPTION
 			WHEN duplicate_object THEN null;
 		END $$`,
+		// Platforms added after the platform type was created are added to existing databases
+		`ALTER TYPE platform ADD VALUE IF NOT EXISTS 'tock'`,
 	}
 
 	for _, t := range types {
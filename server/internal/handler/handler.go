@@ -15,18 +15,20 @@ type Handlers struct {
 	PlatformToken *PlatformToken
 	DropConfig    *DropConfig
 	Proxy         *Proxy
+	KeyRotation   *KeyRotation
 }
 
 func New(services *service.Services, cfg *config.Config) *Handlers {
 	return &Handlers{
 		Auth:          NewAuth(services.Auth, cfg.IsDevelopment()),
 		Job:           NewJob(services.Job, services.Restaurant, services.DropConfig),
-		JobCallback:   NewJobCallback(services.Job, services.Reservation),
+		JobCallback:   NewJobCallback(services.Job, services.Callback),
 		User:          NewUser(services.User, services.Token, services.Auth),
 		Reservation:   NewReservation(services.Reservation),
 		Restaurant:    NewRestaurant(services.Restaurant),
 		PlatformToken: NewPlatformToken(services.PlatformToken),
 		DropConfig:    NewDropConfig(services.DropConfig),
-		Proxy:         NewProxy(services.ProxyResy, services.PlatformToken),
+		Proxy:         NewProxy(services.Proxy, services.PlatformToken),
+		KeyRotation:   NewKeyRotation(services.KeyRotation),
 	}
 }
@@ -2,11 +2,15 @@ package handler
 
 import (
 	"errors"
+	"fmt"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/daylamtayari/cierge/api"
+	"github.com/daylamtayari/cierge/reservation"
+	"github.com/daylamtayari/cierge/server/cloud"
 	appctx "github.com/daylamtayari/cierge/server/internal/context"
 	"github.com/daylamtayari/cierge/server/internal/model"
 	"github.com/daylamtayari/cierge/server/internal/service"
@@ -48,7 +52,7 @@ func (h *Job) List(c *gin.Context) {
 	for _, job := range jobs {
 		if !upcomingOnly {
 			apiJobs = append(apiJobs, job.ToAPI())
-		} else if job.Status == model.JobStatusCreated || job.Status == model.JobStatusScheduled {
+		} else if job.Status == model.JobStatusCreated || job.Status == model.JobStatusScheduled || job.Status == model.JobStatusRunning {
 			apiJobs = append(apiJobs, job.ToAPI())
 		}
 	}
@@ -122,10 +126,18 @@ func (h *Job) Create(c *gin.Context) {
 
 	logger.UpdateContext(func(c zerolog.Context) zerolog.Context {
 		return c.
+			Str("type", string(jobCreationReq.Type)).
 			Str("restaurant_id", jobCreationReq.RestaurantID.String()).
 			Str("reservation_date", jobCreationReq.ReservationDate).
 			Int16("party_size", jobCreationReq.PartySize).
 			Strs("preferred_times", jobCreationReq.PreferredTimes).
+			Interface("preferred_windows", jobCreationReq.PreferredWindows).
+			Interface("alternatives", jobCreationReq.Alternatives).
+			Strs("allowed_seating_types", jobCreationReq.AllowedSeatingTypes).
+			Strs("preferred_seating_types", jobCreationReq.PreferredSeatingTypes).
+			Interface("payment_policy", jobCreationReq.PaymentPolicy).
+			Interface("strategy", jobCreationReq.Strategy).
+			Interface("upgrade", jobCreationReq.Upgrade).
 			Str("drop_config_id", jobCreationReq.DropConfigID.String())
 	})
 
@@ -150,6 +162,11 @@ func (h *Job) Create(c *gin.Context) {
 		util.RespondBadRequest(c, "Reservation date is in the past")
 		return
 	}
+	if len(jobCreationReq.PreferredTimes) == 0 && len(jobCreationReq.PreferredWindows) == 0 {
+		errorCol.Add(nil, zerolog.InfoLevel, true, nil, "no preferred times or windows specified")
+		util.RespondBadRequest(c, "No preferred times or windows specified")
+		return
+	}
 	for _, preferredTime := range jobCreationReq.PreferredTimes {
 		_, err := time.Parse("15:04", preferredTime)
 		if err != nil {
@@ -158,34 +175,132 @@ func (h *Job) Create(c *gin.Context) {
 			return
 		}
 	}
-	dropConfig, err := h.dropConfigService.GetByID(c.Request.Context(), jobCreationReq.DropConfigID)
-	if err != nil && errors.Is(err, service.ErrDropConfigDNE) {
-		errorCol.Add(err, zerolog.InfoLevel, true, nil, "no drop config exists with specified ID")
-		util.RespondBadRequest(c, "Invalid drop configuration ID")
+	for _, preferredWindow := range jobCreationReq.PreferredWindows {
+		if err := preferredWindow.Validate(); err != nil {
+			errorCol.Add(err, zerolog.InfoLevel, true, nil, "invalid preferred window")
+			util.RespondBadRequest(c, "Invalid preferred window")
+			return
+		}
+	}
+	if len(jobCreationReq.Alternatives) > api.MaxJobAlternatives {
+		errorCol.Add(nil, zerolog.InfoLevel, true, nil, "too many alternatives specified")
+		util.RespondBadRequest(c, fmt.Sprintf("No more than %d alternatives can be specified", api.MaxJobAlternatives))
 		return
 	}
-	if h.dropConfigService.IsScheduledAtPast(dropConfig, reservationDate, restaurant.Timezone.Location) {
-		errorCol.Add(nil, zerolog.InfoLevel, true, nil, "job execution date is in the past")
-		util.RespondBadRequest(c, "Job cannot be scheduled in the past")
+	for _, alternative := range jobCreationReq.Alternatives {
+		alternativeDate, err := time.Parse("2006-01-02", alternative.ReservationDate)
+		if err != nil {
+			errorCol.Add(err, zerolog.InfoLevel, true, nil, "invalid alternative reservation date")
+			util.RespondBadRequest(c, "Invalid alternative reservation date")
+			return
+		}
+		if time.Now().After(alternativeDate) {
+			errorCol.Add(nil, zerolog.InfoLevel, true, nil, "alternative reservation date is in the past")
+			util.RespondBadRequest(c, "Alternative reservation date is in the past")
+			return
+		}
+		if alternative.PartySize <= 0 {
+			errorCol.Add(nil, zerolog.InfoLevel, true, nil, "invalid alternative party size")
+			util.RespondBadRequest(c, "Invalid alternative party size")
+			return
+		}
+	}
+	for _, seatingType := range slices.Concat(jobCreationReq.AllowedSeatingTypes, jobCreationReq.PreferredSeatingTypes) {
+		if strings.TrimSpace(seatingType) == "" || len(seatingType) > 255 {
+			errorCol.Add(nil, zerolog.InfoLevel, true, nil, "invalid seating type")
+			util.RespondBadRequest(c, "Invalid seating type")
+			return
+		}
+	}
+	if policy := jobCreationReq.PaymentPolicy; policy != nil {
+		if policy.MaxDeposit != nil && *policy.MaxDeposit < 0 {
+			errorCol.Add(nil, zerolog.InfoLevel, true, nil, "negative maximum deposit")
+			util.RespondBadRequest(c, "Maximum deposit cannot be negative")
+			return
+		}
+		if policy.PaymentMethodID != nil && *policy.PaymentMethodID <= 0 {
+			errorCol.Add(nil, zerolog.InfoLevel, true, nil, "invalid payment method ID")
+			util.RespondBadRequest(c, "Invalid payment method ID")
+			return
+		}
+	}
+	if strategy := jobCreationReq.Strategy; strategy != nil {
+		if err := strategy.Validate(); err != nil {
+			errorCol.Add(err, zerolog.InfoLevel, true, nil, "invalid booking strategy")
+			util.RespondBadRequest(c, "Invalid booking strategy")
+			return
+		}
+	}
+	if msg, ok := validateJobUpgrade(jobCreationReq.Upgrade); !ok {
+		errorCol.Add(nil, zerolog.InfoLevel, true, map[string]any{"upgrade": jobCreationReq.Upgrade}, "invalid upgrade configuration")
+		util.RespondBadRequest(c, msg)
 		return
 	}
 
 	// Creation of job
-	job, err := h.jobService.Create(c.Request.Context(), &jobCreationReq, restaurant, dropConfig)
-	if err != nil {
-		errorCol.Add(err, zerolog.ErrorLevel, false, nil, "failed to create job")
-		util.RespondInternalServerError(c)
+	var job *model.Job
+	switch jobCreationReq.Type {
+	case "", api.JobTypeDrop:
+		dropConfig, err := h.dropConfigService.GetByID(c.Request.Context(), jobCreationReq.DropConfigID)
+		if err != nil && errors.Is(err, service.ErrDropConfigDNE) {
+			errorCol.Add(err, zerolog.InfoLevel, true, nil, "no drop config exists with specified ID")
+			util.RespondBadRequest(c, "Invalid drop configuration ID")
+			return
+		}
+		if h.dropConfigService.IsScheduledAtPast(dropConfig, reservationDate, restaurant.Timezone.Location) {
+			errorCol.Add(nil, zerolog.InfoLevel, true, nil, "job execution date is in the past")
+			util.RespondBadRequest(c, "Job cannot be scheduled in the past")
+			return
+		}
+		// Alternatives are queried at the drop of the job so must release at the same time
+		scheduledAt := h.dropConfigService.ScheduledAt(dropConfig, reservationDate, restaurant.Timezone.Location)
+		for _, alternative := range jobCreationReq.Alternatives {
+			alternativeDate, _ := time.Parse("2006-01-02", alternative.ReservationDate)
+			if !h.dropConfigService.ScheduledAt(dropConfig, alternativeDate, restaurant.Timezone.Location).Equal(scheduledAt) {
+				errorCol.Add(nil, zerolog.InfoLevel, true, map[string]any{"alternative_date": alternative.ReservationDate}, "alternative reservation date drops at a different time")
+				util.RespondBadRequest(c, "Alternative reservation dates must drop at the same time as the reservation date")
+				return
+			}
+		}
+
+		job, err = h.jobService.Create(c.Request.Context(), &jobCreationReq, restaurant, dropConfig)
+		if err != nil {
+			errorCol.Add(err, zerolog.ErrorLevel, false, nil, "failed to create job")
+			util.RespondInternalServerError(c)
+			return
+		}
+		err = h.dropConfigService.IncrementConfidence(c.Request.Context(), dropConfig.ID, restaurant.ID)
+		if err != nil {
+			errorCol.Add(err, zerolog.ErrorLevel, false, nil, "failed to increment drop config confidence")
+		}
+	case api.JobTypeWatch:
+		maxWatchDuration, _ := h.jobService.MaxWatchDuration()
+		if msg, ok := validateJobWatch(jobCreationReq.Watch, maxWatchDuration); !ok {
+			errorCol.Add(nil, zerolog.InfoLevel, true, map[string]any{"watch": jobCreationReq.Watch}, "invalid watch configuration")
+			util.RespondBadRequest(c, msg)
+			return
+		}
+
+		job, err = h.jobService.CreateWatch(c.Request.Context(), &jobCreationReq, restaurant)
+		if err != nil {
+			errorCol.Add(err, zerolog.ErrorLevel, false, nil, "failed to create watch job")
+			util.RespondInternalServerError(c)
+			return
+		}
+	default:
+		errorCol.Add(nil, zerolog.InfoLevel, true, nil, "invalid job type")
+		util.RespondBadRequest(c, "Invalid job type")
 		return
 	}
-	err = h.dropConfigService.IncrementConfidence(c.Request.Context(), dropConfig.ID, restaurant.ID)
-	if err != nil {
-		errorCol.Add(err, zerolog.ErrorLevel, false, nil, "failed to increment drop config confidence")
-	}
 	err = h.jobService.Schedule(c.Request.Context(), job, restaurant)
 	if err != nil && errors.Is(err, service.ErrTokenDNE) {
 		errorCol.Add(err, zerolog.InfoLevel, false, nil, "no token configured")
 		util.RespondConflict(c, "Platform token not configured for platform")
 		return
+	} else if err != nil && errors.Is(err, cloud.ErrScheduleTooFar) {
+		errorCol.Add(err, zerolog.InfoLevel, true, nil, "job drop too far in advance for cloud provider")
+		util.RespondBadRequest(c, "Job drop is too far in advance for the cloud provider")
+		return
 	} else if err != nil {
 		errorCol.Add(err, zerolog.ErrorLevel, false, nil, "failed to schedule job")
 		util.RespondInternalServerError(c)
@@ -243,6 +358,10 @@ func (h *Job) Cancel(c *gin.Context) {
 		errorCol.Add(nil, zerolog.InfoLevel, true, nil, "job cannot be cancelled as it has been executed")
 		util.RespondConflict(c, "Job has already been executed")
 		return
+	case model.JobStatusRunning:
+		errorCol.Add(nil, zerolog.InfoLevel, true, nil, "job cannot be cancelled as it is running")
+		util.RespondConflict(c, "Job execution has already started")
+		return
 	}
 
 	if time.Now().After(job.ScheduledAt) {
@@ -265,3 +384,131 @@ func (h *Job) Cancel(c *gin.Context) {
 	c.Status(200)
 	c.Set("message", "cancelled job")
 }
+
+// Validates the watch configuration of a watch job
+// Watches cannot last longer than the maximum duration if it is positive
+// Returns the message of the validation failure and whether it is valid
+func validateJobWatch(watch *api.JobWatch, maxDuration time.Duration) (string, bool) {
+	if watch == nil {
+		return "Watch configuration is required for watch jobs", false
+	}
+	if !watch.Until.After(time.Now()) {
+		return "Watch deadline is in the past", false
+	}
+	if watch.StartAt != nil && !watch.Until.After(*watch.StartAt) {
+		return "Watch deadline must be after its start", false
+	}
+	if time.Duration(watch.Interval)*time.Second < reservation.MinWatchInterval {
+		return fmt.Sprintf("Watch interval must be at least %d seconds", int(reservation.MinWatchInterval.Seconds())), false
+	}
+	start := time.Now()
+	if watch.StartAt != nil && watch.StartAt.After(start) {
+		start = *watch.StartAt
+	}
+	if maxDuration > 0 && watch.Until.Sub(start) > maxDuration {
+		return fmt.Sprintf("Watch cannot last longer than %d minutes with the cloud provider", int(maxDuration.Minutes())), false
+	}
+	return "", true
+}
+
+// Validates the upgrade configuration of a job if set
+// Returns the message of the validation failure and whether it is valid
+func validateJobUpgrade(upgrade *api.JobUpgrade) (string, bool) {
+	if upgrade == nil {
+		return "", true
+	}
+	if !upgrade.Until.After(time.Now()) {
+		return "Upgrade deadline is in the past", false
+	}
+	if time.Duration(upgrade.Interval)*time.Second < reservation.MinWatchInterval {
+		return fmt.Sprintf("Upgrade interval must be at least %d seconds", int(reservation.MinWatchInterval.Seconds())), false
+	}
+	return "", true
+}
+
+// POST /api/job/:job/test - Performs a dry run of a job, running every step
+// of the job without booking, and returns the slot that would have been booked
+func (h *Job) Test(c *gin.Context) {
+	errorCol := appctx.ErrorCollector(c.Request.Context())
+	logger := appctx.Logger(c.Request.Context())
+
+	jobUid, err := uuid.Parse(c.Param("job"))
+	if err != nil {
+		errorCol.Add(err, zerolog.InfoLevel, true, nil, "invalid job ID")
+		util.RespondBadRequest(c, "Job ID must be a valid UUID")
+		return
+	}
+
+	var testReq api.JobTestRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&testReq); err != nil {
+			errorCol.Add(err, zerolog.InfoLevel, true, nil, "job test request has improper format")
+			util.RespondBadRequest(c, "Invalid job test request")
+			return
+		}
+	}
+	if testReq.ReservationDate != "" {
+		if _, err := time.Parse("2006-01-02", testReq.ReservationDate); err != nil {
+			errorCol.Add(err, zerolog.InfoLevel, true, nil, "invalid reservation date")
+			util.RespondBadRequest(c, "Invalid reservation date")
+			return
+		}
+	}
+
+	logger.UpdateContext(func(c zerolog.Context) zerolog.Context {
+		return c.
+			Str("job_id", jobUid.String()).
+			Str("reservation_date", testReq.ReservationDate)
+	})
+
+	job, err := h.jobService.GetByID(c.Request.Context(), jobUid)
+	if err != nil && errors.Is(err, service.ErrJobDNE) {
+		util.RespondNotFound(c, "Job not found")
+		return
+	} else if err != nil {
+		errorCol.Add(err, zerolog.ErrorLevel, false, nil, "failed to retrieve job")
+		util.RespondInternalServerError(c)
+		return
+	}
+	if job.UserID != appctx.UserID(c.Request.Context()) {
+		util.RespondNotFound(c, "Job not found")
+		return
+	}
+
+	restaurant, err := h.restaurantService.GetByID(c.Request.Context(), job.RestaurantID)
+	if err != nil {
+		errorCol.Add(err, zerolog.ErrorLevel, false, nil, "failed to retrieve restaurant of job")
+		util.RespondInternalServerError(c)
+		return
+	}
+
+	output, err := h.jobService.DryRun(c.Request.Context(), job, restaurant, testReq.ReservationDate)
+	if err != nil && errors.Is(err, service.ErrTokenDNE) {
+		errorCol.Add(err, zerolog.InfoLevel, true, nil, "no platform token for job platform")
+		util.RespondBadRequest(c, "No token is stored for the platform of the job")
+		return
+	} else if err != nil {
+		errorCol.Add(err, zerolog.ErrorLevel, false, nil, "failed to perform dry run of job")
+		util.RespondInternalServerError(c)
+		return
+	}
+
+	result := api.JobTestResult{
+		Success:  output.Success,
+		Message:  output.Message,
+		Error:    output.Error,
+		Attempts: len(output.BookingAttempts),
+		Duration: output.Duration,
+	}
+	if output.Success {
+		result.SlotTime = &output.ReservationTime
+		result.ReservationDate = output.ReservationDate
+		result.PartySize = output.PartySize
+		if output.Deposit != nil {
+			result.Deposit = &output.Deposit.Fee
+		}
+	}
+
+	c.JSON(200, result)
+	c.Set("message", "performed dry run of job")
+}
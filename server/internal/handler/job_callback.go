@@ -13,19 +13,63 @@ import (
 )
 
 type JobCallback struct {
-	jobService         *service.Job
-	reservationService *service.Reservation
+	jobService      *service.Job
+	callbackService *service.Callback
 }
 
-func NewJobCallback(jobService *service.Job, reservationService *service.Reservation) *JobCallback {
+func NewJobCallback(jobService *service.Job, callbackService *service.Callback) *JobCallback {
 	return &JobCallback{
-		jobService:         jobService,
-		reservationService: reservationService,
+		jobService:      jobService,
+		callbackService: callbackService,
 	}
 }
 
+// Handles a started callback request from a job and marks the job as running
+func (h *JobCallback) HandleJobStarted(c *gin.Context) {
+	errorCol := appctx.ErrorCollector(c.Request.Context())
+
+	var startedReq reservation.Started
+	if err := c.ShouldBindJSON(&startedReq); err != nil {
+		errorCol.Add(err, zerolog.WarnLevel, true, nil, "job started request has improper format")
+		util.RespondBadRequest(c, "")
+		return
+	}
+
+	contextJob, ok := c.Get("job")
+	if !ok {
+		errorCol.Add(nil, zerolog.ErrorLevel, false, nil, "job object not found in context")
+		util.RespondInternalServerError(c)
+		return
+	}
+	job, ok := contextJob.(*model.Job)
+	if !ok {
+		errorCol.Add(nil, zerolog.ErrorLevel, false, map[string]any{"job": contextJob}, "job object in context is not a pointer to a Job type")
+		util.RespondInternalServerError(c)
+		return
+	}
+
+	if startedReq.JobId != job.ID {
+		errorCol.Add(nil, zerolog.ErrorLevel, false, map[string]any{"callback_job_id": startedReq.JobId, "retrieved_job_id": job.ID}, "job ID in the started request is different than the retrieved job")
+		util.RespondInternalServerError(c)
+		return
+	}
+
+	if err := h.jobService.MarkRunning(c.Request.Context(), job, startedReq); err != nil {
+		errorCol.Add(err, zerolog.ErrorLevel, false, nil, "failed to mark job as running")
+		util.RespondInternalServerError(c)
+		return
+	}
+
+	// TODO: Send notification
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Started request accepted successfully",
+	})
+	c.Set("message", "received and handled job started request")
+}
+
 // Handles a callback request from a job output and updates the
-// job value, creates a reservation, and send a notification
+// job value, creates a reservation, handles its upgrade, and send a notification
 func (h *JobCallback) HandleJobCallback(c *gin.Context) {
 	errorCol := appctx.ErrorCollector(c.Request.Context())
 
@@ -55,18 +99,21 @@ func (h *JobCallback) HandleJobCallback(c *gin.Context) {
 		return
 	}
 
-	updatedJob, err := h.jobService.UpdateFromCallback(c.Request.Context(), job, callbackReq)
+	// Jobs retry their callback if they could not confirm that it was received
+	// so the callback is only handled once, with repeated callbacks accepted
+	// A callback that failed to be handled can be retried by the job
+	handled, err := h.callbackService.Handle(c.Request.Context(), job, callbackReq)
 	if err != nil {
-		errorCol.Add(err, zerolog.ErrorLevel, false, map[string]any{"job": updatedJob}, "failed to update job from callback")
+		errorCol.Add(err, zerolog.ErrorLevel, false, map[string]any{"job": job}, "failed to handle job callback")
 		util.RespondInternalServerError(c)
 		return
 	}
-
-	if updatedJob.Status == model.JobStatusSuccess {
-		_, err = h.reservationService.CreateFromJob(c.Request.Context(), updatedJob)
-		if err != nil {
-			errorCol.Add(err, zerolog.ErrorLevel, false, map[string]any{"job": updatedJob}, "failed to create reservation from job")
-		}
+	if !handled {
+		c.JSON(http.StatusOK, gin.H{
+			"message": "Callback request already received",
+		})
+		c.Set("message", "received repeated job callback")
+		return
 	}
 
 	// TODO: Send notification
@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/daylamtayari/cierge/api"
+	appctx "github.com/daylamtayari/cierge/server/internal/context"
+	"github.com/daylamtayari/cierge/server/internal/service"
+	"github.com/daylamtayari/cierge/server/internal/util"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+)
+
+type KeyRotation struct {
+	keyRotationService *service.KeyRotation
+}
+
+func NewKeyRotation(keyRotationService *service.KeyRotation) *KeyRotation {
+	return &KeyRotation{
+		keyRotationService: keyRotationService,
+	}
+}
+
+// POST /api/admin/key-rotation - Starts re-encrypting all platform tokens
+// under the cloud provider's current encryption key
+func (h *KeyRotation) Start(c *gin.Context) {
+	errorCol := appctx.ErrorCollector(c.Request.Context())
+
+	var keyRotationReq api.KeyRotationRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindBodyWithJSON(&keyRotationReq); err != nil {
+			errorCol.Add(err, zerolog.InfoLevel, true, nil, "key rotation request has invalid format")
+			util.RespondBadRequest(c, "Invalid key rotation request")
+			return
+		}
+	}
+
+	progress, err := h.keyRotationService.Start(c.Request.Context(), keyRotationReq.BatchSize)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidBatchSize):
+			errorCol.Add(err, zerolog.InfoLevel, true, nil, "invalid batch size provided for key rotation")
+			util.RespondBadRequest(c, "Batch size must be between 1 and 1000")
+		case errors.Is(err, service.ErrKeyRotationInProgress):
+			errorCol.Add(err, zerolog.InfoLevel, true, nil, "key rotation is already in progress")
+			util.RespondConflict(c, "A key rotation is already in progress")
+		default:
+			errorCol.Add(err, zerolog.ErrorLevel, false, nil, "failed to start key rotation")
+			util.RespondInternalServerError(c)
+		}
+		return
+	}
+
+	c.JSON(200, progress)
+	c.Set("message", "started key rotation")
+}
+
+// GET /api/admin/key-rotation - Retrieves the progress of the current or most recent key rotation
+func (h *KeyRotation) Get(c *gin.Context) {
+	progress, err := h.keyRotationService.Status()
+	if err != nil {
+		util.RespondNotFound(c, "No key rotation has been started")
+		return
+	}
+
+	c.JSON(200, progress)
+	c.Set("message", "retrieved key rotation progress")
+}
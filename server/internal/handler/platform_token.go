@@ -1,11 +1,12 @@
 package handler
 
 import (
+	"encoding/json"
 	"errors"
 	"strings"
 
 	"github.com/daylamtayari/cierge/api"
-	"github.com/daylamtayari/cierge/resy"
+	"github.com/daylamtayari/cierge/reservation"
 	appctx "github.com/daylamtayari/cierge/server/internal/context"
 	"github.com/daylamtayari/cierge/server/internal/model"
 	"github.com/daylamtayari/cierge/server/internal/service"
@@ -40,17 +41,17 @@ func (h *PlatformToken) Get(c *gin.Context) {
 	case "":
 		tokens, err = h.ptService.GetByUser(c.Request.Context(), userID)
 
-	case "resy", "opentable":
+	default:
+		if _, err := reservation.GetPlatform(platform); err != nil {
+			errorCol.Add(nil, zerolog.InfoLevel, true, map[string]any{"platform": platform}, "unsupported platform specified")
+			util.RespondBadRequest(c, "unsupported platform specified")
+			return
+		}
 		var token *model.PlatformToken
 		token, err = h.ptService.GetByUserAndPlatform(c.Request.Context(), userID, platform)
 		if token != nil {
 			tokens = append(tokens, token)
 		}
-
-	default:
-		errorCol.Add(nil, zerolog.InfoLevel, true, map[string]any{"platform": platform}, "unsupported platform specified")
-		util.RespondBadRequest(c, "unsupported platform specified")
-		return
 	}
 	if err != nil && !errors.Is(err, service.ErrTokenDNE) {
 		errorCol.Add(err, zerolog.ErrorLevel, false, map[string]any{"platform": platform}, "failed to retrieve platform tokens for user")
@@ -78,28 +79,26 @@ func (h *PlatformToken) Create(c *gin.Context) {
 		return
 	}
 
-	var token any
-	switch platform {
-	case "resy":
-		var resyToken resy.Tokens
-		if err := c.ShouldBindBodyWithJSON(&resyToken); err != nil {
-			errorCol.Add(nil, zerolog.InfoLevel, true, nil, "incorrect token format for resy token")
-			util.RespondBadRequest(c, "incorrect token format")
-			return
-		}
-		if resyToken.ApiKey == "" {
-			resyToken.ApiKey = resy.DefaultApiKey
-		}
-		token = resyToken
-
-	case "opentable":
-		// TODO: Complete opentable section
-	default:
+	adapter, err := reservation.GetPlatform(platform)
+	if err != nil {
 		errorCol.Add(nil, zerolog.InfoLevel, true, map[string]any{"platform": platform}, "unsupported platform specified")
 		util.RespondBadRequest(c, "unsupported platform specified")
 		return
 	}
 
+	var body json.RawMessage
+	if err := c.ShouldBindBodyWithJSON(&body); err != nil {
+		errorCol.Add(err, zerolog.InfoLevel, true, map[string]any{"platform": platform}, "incorrect token format for platform token")
+		util.RespondBadRequest(c, "incorrect token format")
+		return
+	}
+	token, err := adapter.ParseToken(body)
+	if err != nil {
+		errorCol.Add(err, zerolog.InfoLevel, true, map[string]any{"platform": platform}, "incorrect token format for platform token")
+		util.RespondBadRequest(c, "incorrect token format")
+		return
+	}
+
 	newToken, err := h.ptService.Create(c.Request.Context(), appctx.UserID(c.Request.Context()), platform, token)
 	if err != nil {
 		errorCol.Add(err, zerolog.ErrorLevel, false, map[string]any{"platform": platform}, "error creating platform token")
@@ -3,8 +3,9 @@ package handler
 import (
 	"errors"
 	"net/http"
+	"strings"
 
-	"github.com/daylamtayari/cierge/resy"
+	"github.com/daylamtayari/cierge/reservation"
 	appctx "github.com/daylamtayari/cierge/server/internal/context"
 	"github.com/daylamtayari/cierge/server/internal/service"
 	"github.com/daylamtayari/cierge/server/internal/util"
@@ -13,76 +14,133 @@ import (
 )
 
 type Proxy struct {
-	proxyResyService *service.ProxyResy
-	ptService        *service.PlatformToken
+	proxyService *service.Proxy
+	ptService    *service.PlatformToken
 }
 
-func NewProxy(proxyResyService *service.ProxyResy, ptService *service.PlatformToken) *Proxy {
+func NewProxy(proxyService *service.Proxy, ptService *service.PlatformToken) *Proxy {
 	return &Proxy{
-		proxyResyService: proxyResyService,
-		ptService:        ptService,
+		proxyService: proxyService,
+		ptService:    ptService,
 	}
 }
 
-func (h *Proxy) ResyAuth(c *gin.Context) {
+// POST /proxy/:platform/auth/code - Sends a one-time login code to the
+// email of a user of a platform logging in with codes to then authenticate with
+func (h *Proxy) AuthCode(c *gin.Context) {
 	errorCol := appctx.ErrorCollector(c.Request.Context())
 
-	var resyAuthReq struct {
-		Email    string `json:"email"`
-		Password string `json:"password"`
+	platform, ok := platformParam(c)
+	if !ok {
+		return
+	}
+
+	var codeReq struct {
+		Email string `json:"email"`
+	}
+	if err := c.ShouldBindBodyWithJSON(&codeReq); err != nil || codeReq.Email == "" {
+		errorCol.Add(err, zerolog.InfoLevel, true, map[string]any{"platform": platform}, "proxy login code request has improper format")
+		util.RespondBadRequest(c, "Invalid login code request")
+		return
+	}
+
+	err := h.proxyService.SendLoginCode(c.Request.Context(), platform, codeReq.Email)
+	if errors.Is(err, reservation.ErrLoginMethodUnsupported) {
+		errorCol.Add(err, zerolog.InfoLevel, true, map[string]any{"platform": platform}, "login code requested for a platform not logging in with codes")
+		util.RespondBadRequest(c, "Platform does not login with login codes")
+		return
+	} else if err != nil {
+		errorCol.Add(err, zerolog.ErrorLevel, false, map[string]any{"platform": platform}, "login code request failed")
+		util.RespondFailedDep(c, "Failed to send a login code")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+	c.Set("message", "sent login code for "+platform)
+}
+
+// POST /proxy/:platform/auth - Authenticates to a platform and
+// creates a platform token for the user
+func (h *Proxy) Auth(c *gin.Context) {
+	errorCol := appctx.ErrorCollector(c.Request.Context())
+
+	platform, ok := platformParam(c)
+	if !ok {
+		return
 	}
-	if err := c.ShouldBindBodyWithJSON(&resyAuthReq); err != nil {
-		errorCol.Add(err, zerolog.InfoLevel, true, nil, "resy proxy login request has improper format")
-		util.RespondBadRequest(c, "Invalid Resy login request")
+
+	var credentials reservation.Credentials
+	if err := c.ShouldBindBodyWithJSON(&credentials); err != nil || credentials.Email == "" {
+		errorCol.Add(err, zerolog.InfoLevel, true, map[string]any{"platform": platform}, "proxy login request has improper format")
+		util.RespondBadRequest(c, "Invalid login request")
 		return
 	}
 
-	tokens, err := h.proxyResyService.Auth(c.Request.Context(), resyAuthReq.Email, resyAuthReq.Password)
-	if errors.Is(err, resy.ErrUnauthorized) {
+	tokens, err := h.proxyService.Auth(c.Request.Context(), platform, credentials)
+	if errors.Is(err, reservation.ErrInvalidCredentials) {
 		// Only reason the email is logged on failed logins due to invalid credentials is to monitor abuse
-		errorCol.Add(err, zerolog.InfoLevel, true, map[string]any{"email": resyAuthReq.Email}, "failed to login to resy due to incorrect resy credentials")
+		errorCol.Add(err, zerolog.InfoLevel, true, map[string]any{"email": credentials.Email, "platform": platform}, "failed to login to platform due to incorrect credentials")
 		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
 			"error":      "Forbidden",
-			"message":    "Incorrect Resy credentials",
+			"message":    "Incorrect credentials",
 			"request_id": appctx.RequestID(c.Request.Context()),
 		})
 		return
 	} else if err != nil {
-		errorCol.Add(err, zerolog.ErrorLevel, false, nil, "resy auth request failed")
-		util.RespondFailedDep(c, "Failed to perform authentication to Resy")
+		errorCol.Add(err, zerolog.ErrorLevel, false, map[string]any{"platform": platform}, "platform auth request failed")
+		util.RespondFailedDep(c, "Failed to perform authentication to the platform")
 		return
 	}
 
-	newToken, err := h.ptService.Create(c.Request.Context(), appctx.UserID(c.Request.Context()), "resy", tokens)
+	newToken, err := h.ptService.Create(c.Request.Context(), appctx.UserID(c.Request.Context()), platform, tokens)
 	if err != nil {
-		errorCol.Add(err, zerolog.ErrorLevel, false, map[string]any{"platform": "resy"}, "error creating platform token")
+		errorCol.Add(err, zerolog.ErrorLevel, false, map[string]any{"platform": platform}, "error creating platform token")
 		util.RespondInternalServerError(c)
 		return
 	}
 
 	c.JSON(200, newToken.ToAPI())
-	c.Set("message", "created new platform token for resy")
+	c.Set("message", "created new platform token for "+platform)
 }
 
-func (h *Proxy) ResyRestaurant(c *gin.Context) {
+// POST /proxy/:platform/restaurant - Searches the restaurants of a platform
+func (h *Proxy) Restaurant(c *gin.Context) {
 	errorCol := appctx.ErrorCollector(c.Request.Context())
 
-	var resyResReq struct {
+	platform, ok := platformParam(c)
+	if !ok {
+		return
+	}
+
+	var resReq struct {
 		Query string `json:"query"`
 	}
-	if err := c.ShouldBindBodyWithJSON(&resyResReq); err != nil {
+	if err := c.ShouldBindBodyWithJSON(&resReq); err != nil {
 		errorCol.Add(err, zerolog.InfoLevel, true, nil, "restaurant query has improper format")
 		util.RespondBadRequest(c, "Invalid query format")
 		return
 	}
 
-	venues, err := h.proxyResyService.Restaurant(c.Request.Context(), resyResReq.Query)
+	venues, err := h.proxyService.Restaurant(c.Request.Context(), platform, resReq.Query)
 	if err != nil {
-		errorCol.Add(err, zerolog.ErrorLevel, false, map[string]any{"query": resyResReq.Query}, "failed to proxy restaurant search")
+		errorCol.Add(err, zerolog.ErrorLevel, false, map[string]any{"platform": platform, "query": resReq.Query}, "failed to proxy restaurant search")
 		util.RespondInternalServerError(c)
 		return
 	}
 
 	c.JSON(200, venues)
-	c.Set("message", "proxied search for resy restaurants")
+	c.Set("message", "proxied search for "+platform+" restaurants")
+}
+
+// Returns the platform of the platform path parameter and whether it is supported
+// Responds with a bad request if the platform is not supported
+func platformParam(c *gin.Context) (string, bool) {
+	platform := strings.ToLower(c.Param("platform"))
+	if _, err := reservation.GetPlatform(platform); err != nil {
+		errorCol := appctx.ErrorCollector(c.Request.Context())
+		errorCol.Add(nil, zerolog.InfoLevel, true, map[string]any{"platform": platform}, "unsupported platform specified")
+		util.RespondBadRequest(c, "unsupported platform specified")
+		return "", false
+	}
+	return platform, true
 }
@@ -5,7 +5,7 @@ import (
 	"strconv"
 	"strings"
 
-	"github.com/daylamtayari/cierge/resy"
+	"github.com/daylamtayari/cierge/reservation"
 	appctx "github.com/daylamtayari/cierge/server/internal/context"
 	"github.com/daylamtayari/cierge/server/internal/service"
 	"github.com/daylamtayari/cierge/server/internal/util"
@@ -40,7 +40,7 @@ func (h *Restaurant) Get(c *gin.Context) {
 			Str("platform_id", platformId)
 	})
 
-	if platform != "resy" && platform != "opentable" {
+	if _, err := reservation.GetPlatform(platform); err != nil {
 		errorCol.Add(nil, zerolog.InfoLevel, true, nil, "unsupported platform specified")
 		util.RespondBadRequest(c, "unsupported platform specified")
 		return
@@ -54,7 +54,7 @@ func (h *Restaurant) Get(c *gin.Context) {
 			errorCol.Add(err, zerolog.InfoLevel, true, nil, "platform ID provided could not be converted to its expected type")
 			util.RespondBadRequest(c, "restaurant platform ID contains invalid values")
 			return
-		case errors.Is(err, resy.ErrNotFound):
+		case errors.Is(err, reservation.ErrVenueNotFound):
 			errorCol.Add(err, zerolog.InfoLevel, true, nil, "restaurant with specified ID does not exist on platform")
 			util.RespondNotFound(c, "restaurant platform ID does not match any restaurant on platform")
 			return
@@ -105,3 +105,42 @@ func (h *Restaurant) GetByID(c *gin.Context) {
 	c.JSON(200, restaurant.ToAPI())
 	c.Set("message", "retrieved restaurant")
 }
+
+// GET /api/restaurant/:id/seating-types - Return the known seating types
+// of a restaurant, discovering them from its upcoming available slots
+// if they were not discovered in the last day
+func (h *Restaurant) GetSeatingTypes(c *gin.Context) {
+	errorCol := appctx.ErrorCollector(c.Request.Context())
+	logger := appctx.Logger(c.Request.Context())
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		errorCol.Add(err, zerolog.InfoLevel, true, nil, "invalid restaurant ID")
+		util.RespondBadRequest(c, "Invalid restaurant ID")
+		return
+	}
+
+	logger.UpdateContext(func(c zerolog.Context) zerolog.Context {
+		return c.Str("restaurant_id", id.String())
+	})
+
+	restaurant, err := h.restaurantService.GetByID(c.Request.Context(), id)
+	if err != nil && errors.Is(err, service.ErrRestaurantDNE) {
+		util.RespondNotFound(c, "Restaurant not found")
+		return
+	} else if err != nil {
+		errorCol.Add(err, zerolog.ErrorLevel, false, nil, "failed to retrieve restaurant")
+		util.RespondInternalServerError(c)
+		return
+	}
+
+	seatingTypes, err := h.restaurantService.GetSeatingTypes(c.Request.Context(), restaurant)
+	if err != nil {
+		errorCol.Add(err, zerolog.ErrorLevel, false, nil, "failed to retrieve restaurant seating types")
+		util.RespondInternalServerError(c)
+		return
+	}
+
+	c.JSON(200, seatingTypes)
+	c.Set("message", "retrieved restaurant seating types")
+}
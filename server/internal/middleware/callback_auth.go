@@ -16,7 +16,6 @@ import (
 )
 
 var (
-	ErrAlreadyCallbacked = errors.New("callback request already received")
 	ErrInvalidAuthHeader = errors.New("authorization header value is invalid")
 	ErrInvalidSecret     = errors.New("invalid callback secret")
 	ErrNoJobID           = errors.New("no job ID was specified in request")
@@ -110,18 +109,10 @@ func (m *CallbackAuth) RequireCallbackAuth() gin.HandlerFunc {
 			return c.Str("job_id", job.ID.String())
 		})
 
-		// If a callback request has already been received
-		// for a job, return forbidden
-		// A callback request can only be received a single
-		// time for a job as any subsequent requests can be
-		// assumed to be malicious as the lambda only ever
-		// makes a single callback request
-		if job.Callbacked {
-			errorCol.Add(ErrAlreadyCallbacked, zerolog.InfoLevel, true, nil, "callback request has already been received for this job")
-			util.RespondForbidden(c)
-			return
-		}
-
+		// Callback requests for jobs that were already callbacked are
+		// let through as jobs retry their callback if they could not
+		// confirm it was received, the callback of a job being claimed
+		// atomically by the handler so that it is only handled once
 		c.Set("job", job)
 		c.Next()
 	}
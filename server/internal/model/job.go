@@ -2,6 +2,7 @@ package model
 
 import (
 	"database/sql/driver"
+	"encoding/json"
 	"fmt"
 	"time"
 
@@ -36,11 +37,113 @@ func (d DateString) Value() (driver.Value, error) {
 	return string(d), nil
 }
 
+// TimeWindows are the preferred time windows of a job stored as JSON
+type TimeWindows []api.TimeWindow
+
+func (w *TimeWindows) Scan(value any) error {
+	return scanJSON(value, w)
+}
+
+func (w TimeWindows) Value() (driver.Value, error) {
+	if w == nil {
+		return nil, nil
+	}
+	return valueJSON(w)
+}
+
+// JobAlternatives are the alternative reservation dates and party sizes of a job stored as JSON
+type JobAlternatives []api.JobAlternative
+
+func (a *JobAlternatives) Scan(value any) error {
+	return scanJSON(value, a)
+}
+
+func (a JobAlternatives) Value() (driver.Value, error) {
+	if a == nil {
+		return nil, nil
+	}
+	return valueJSON(a)
+}
+
+// JobPaymentPolicy is the payment policy of a job stored as JSON
+type JobPaymentPolicy api.JobPaymentPolicy
+
+func (p *JobPaymentPolicy) Scan(value any) error {
+	return scanJSON(value, p)
+}
+
+func (p JobPaymentPolicy) Value() (driver.Value, error) {
+	return valueJSON(p)
+}
+
+// JobUpgradeSlot is the booked slot of the reservation upgraded by an upgrade job stored as JSON
+// NOTE: Confirmation is the platform confirmation of the reservation used to cancel it
+type JobUpgradeSlot struct {
+	ReservationDate    string     `json:"reservation_date"` // YYYY-MM-DD
+	PartySize          int16      `json:"party_size"`
+	SlotTime           string     `json:"slot_time"` // HH:mm
+	SeatingType        string     `json:"seating_type,omitempty"`
+	Confirmation       string     `json:"confirmation"`
+	CancellationCutOff *time.Time `json:"cancellation_cut_off,omitempty"`
+}
+
+func (s *JobUpgradeSlot) Scan(value any) error {
+	return scanJSON(value, s)
+}
+
+func (s JobUpgradeSlot) Value() (driver.Value, error) {
+	return valueJSON(s)
+}
+
+// JobStrategy is the booking strategy of a job stored as JSON
+type JobStrategy api.JobStrategy
+
+func (s *JobStrategy) Scan(value any) error {
+	return scanJSON(value, s)
+}
+
+func (s JobStrategy) Value() (driver.Value, error) {
+	return valueJSON(s)
+}
+
+// Scans a JSON column value into a destination
+// A null value leaves the destination unchanged
+func scanJSON(value any, dest any) error {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case string:
+		return json.Unmarshal([]byte(v), dest)
+	case []byte:
+		return json.Unmarshal(v, dest)
+	default:
+		return fmt.Errorf("cannot scan type %T into %T", value, dest)
+	}
+}
+
+// Returns the JSON column value of a source
+func valueJSON(src any) (driver.Value, error) {
+	marshalled, err := json.Marshal(src)
+	if err != nil {
+		return nil, err
+	}
+	return string(marshalled), nil
+}
+
+type JobType string
+
+const (
+	JobTypeDrop    JobType = "drop"
+	JobTypeWatch   JobType = "watch"
+	JobTypeUpgrade JobType = "upgrade"
+)
+
 type JobStatus string
 
 const (
 	JobStatusCreated   JobStatus = "created"
 	JobStatusScheduled JobStatus = "scheduled"
+	JobStatusRunning   JobStatus = "running"
 	JobStatusSuccess   JobStatus = "success"
 	JobStatusFailed    JobStatus = "failed"
 	JobStatusCancelled JobStatus = "cancelled"
@@ -51,10 +154,32 @@ type Job struct {
 	UserID       uuid.UUID `gorm:"type:uuid;not null;index:idx_jobs_user;index:idx_jobs_user_status"`
 	RestaurantID uuid.UUID `gorm:"type:uuid;not null;index:idx_jobs_restaurant"`
 	Platform     string    `gorm:"type:platform;not null;index:idx_jobs_platform"`
+	Type         JobType   `gorm:"type:varchar(16);not null;default:'drop'"`
 
 	ReservationDate DateString     `gorm:"type:date;not null"` // YYYY-MM-DD
 	PartySize       int16          `gorm:"type:smallint;not null"`
 	PreferredTimes  pq.StringArray `gorm:"type:varchar(5)[];not null"` // HH:mm
+	// Matched after the preferred times
+	PreferredWindows TimeWindows `gorm:"type:jsonb"`
+	// Booked if no slot of the reservation date and party size could be booked
+	Alternatives JobAlternatives `gorm:"type:jsonb"`
+	// Only set for watch jobs which poll from the scheduled time until the watch deadline
+	WatchUntil    *time.Time `gorm:"type:timestamptz"`
+	WatchInterval *int32     `gorm:"type:integer"` // Seconds
+	// All seating types are allowed if no allowed seating types are set
+	AllowedSeatingTypes   pq.StringArray `gorm:"type:varchar(255)[]"`
+	PreferredSeatingTypes pq.StringArray `gorm:"type:varchar(255)[]"`
+	// Any deposit is allowed and the default payment method is used if not set
+	PaymentPolicy *JobPaymentPolicy `gorm:"type:jsonb"`
+	// Slots are attempted with a stagger of a second if not set
+	Strategy *JobStrategy `gorm:"type:jsonb"`
+	// Only set for jobs whose booked reservation is upgraded until the upgrade deadline
+	UpgradeUntil    *time.Time `gorm:"type:timestamptz"`
+	UpgradeInterval *int32     `gorm:"type:integer"` // Seconds
+	// Only set for upgrade jobs which poll for slots more preferred than the booked slot
+	// of the reservation until the watch deadline and cancel the reservation
+	UpgradeReservationID *uuid.UUID      `gorm:"type:uuid;index:idx_jobs_upgrade_reservation"`
+	UpgradeSlot          *JobUpgradeSlot `gorm:"type:jsonb"`
 
 	ScheduledAt        time.Time `gorm:"not null;index:idx_jobs_scheduled,where:status = 'scheduled'"`
 	DropConfigID       uuid.UUID `gorm:"type:uuid"`
@@ -66,9 +191,16 @@ type Job struct {
 	CompletedAt *time.Time `gorm:"type:timestamptz"`
 
 	ReservedTime *time.Time `gorm:"type:timestamptz"`
-	Confirmation *string    `gorm:"type:text"`
-	ErrorMessage *string    `gorm:"type:text"`
-	Logs         *string    `gorm:"type:text"`
+	// Reservation date and party size of the booked alternative
+	ReservedDate        *DateString `gorm:"type:date"`
+	ReservedPartySize   *int16      `gorm:"type:smallint"`
+	ReservedSeatingType *string     `gorm:"type:varchar(255)"`
+	ReservedDeposit     *float32    `gorm:"type:real"`
+	// Time until which the booked slot can be cancelled without forfeiting its deposit
+	ReservedCancellationCutOff *time.Time `gorm:"type:timestamptz"`
+	Confirmation               *string    `gorm:"type:text"`
+	ErrorMessage               *string    `gorm:"type:text"`
+	Logs                       *string    `gorm:"type:text"`
 
 	// Relations
 	User       *User       `gorm:"foreignKey:UserID"`
@@ -84,10 +216,21 @@ func (m *Job) ToAPI() *api.Job {
 		UserID:       m.UserID,
 		RestaurantID: m.RestaurantID,
 		Platform:     m.Platform,
+		Type:         api.JobType(m.Type),
 
-		ReservationDate: string(m.ReservationDate),
-		PartySize:       m.PartySize,
-		PreferredTimes:  m.PreferredTimes,
+		ReservationDate:  string(m.ReservationDate),
+		PartySize:        m.PartySize,
+		PreferredTimes:   m.PreferredTimes,
+		PreferredWindows: m.PreferredWindows,
+		Alternatives:     m.Alternatives,
+		Watch:            m.watchToAPI(),
+
+		AllowedSeatingTypes:   m.AllowedSeatingTypes,
+		PreferredSeatingTypes: m.PreferredSeatingTypes,
+		PaymentPolicy:         (*api.JobPaymentPolicy)(m.PaymentPolicy),
+		Strategy:              (*api.JobStrategy)(m.Strategy),
+		Upgrade:               m.upgradeToAPI(),
+		UpgradeReservationID:  m.UpgradeReservationID,
 
 		ScheduledAt:  m.ScheduledAt,
 		DropConfigID: m.DropConfigID,
@@ -97,12 +240,41 @@ func (m *Job) ToAPI() *api.Job {
 		StartedAt:   m.StartedAt,
 		CompletedAt: m.CompletedAt,
 
-		ReservedTime: m.ReservedTime,
-		Confirmation: m.Confirmation,
-		ErrorMessage: m.ErrorMessage,
-		Logs:         m.Logs,
+		ReservedTime:               m.ReservedTime,
+		ReservedDate:               (*string)(m.ReservedDate),
+		ReservedPartySize:          m.ReservedPartySize,
+		ReservedSeatingType:        m.ReservedSeatingType,
+		ReservedDeposit:            m.ReservedDeposit,
+		ReservedCancellationCutOff: m.ReservedCancellationCutOff,
+		Confirmation:               m.Confirmation,
+		ErrorMessage:               m.ErrorMessage,
+		Logs:                       m.Logs,
 
 		CreatedAt: m.CreatedAt.UTC(),
 		UpdatedAt: m.UpdatedAt.UTC(),
 	}
 }
+
+// Returns the watch configuration of a watch job
+func (m *Job) watchToAPI() *api.JobWatch {
+	isWatch := m.Type == JobTypeWatch || m.Type == JobTypeUpgrade
+	if !isWatch || m.WatchUntil == nil || m.WatchInterval == nil {
+		return nil
+	}
+	startAt := m.ScheduledAt
+	return &api.JobWatch{
+		StartAt:  &startAt,
+		Until:    *m.WatchUntil,
+		Interval: int(*m.WatchInterval),
+	}
+}
+
+func (m *Job) upgradeToAPI() *api.JobUpgrade {
+	if m.UpgradeUntil == nil || m.UpgradeInterval == nil {
+		return nil
+	}
+	return &api.JobUpgrade{
+		Until:    *m.UpgradeUntil,
+		Interval: int(*m.UpgradeInterval),
+	}
+}
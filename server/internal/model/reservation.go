@@ -1,16 +1,38 @@
 package model
 
 import (
+	"database/sql/driver"
 	"time"
 
 	"github.com/daylamtayari/cierge/api"
 	"github.com/google/uuid"
 )
 
+type ReservationStatus string
+
+const (
+	ReservationStatusBooked    ReservationStatus = "booked"
+	ReservationStatusCancelled ReservationStatus = "cancelled"
+)
+
+// ReservationAudit is the audit of a reservation stored as JSON
+type ReservationAudit []api.ReservationAuditEntry
+
+func (a *ReservationAudit) Scan(value any) error {
+	return scanJSON(value, a)
+}
+
+func (a ReservationAudit) Value() (driver.Value, error) {
+	if a == nil {
+		return nil, nil
+	}
+	return valueJSON(a)
+}
+
 type Reservation struct {
 	ID           uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
 	UserID       uuid.UUID  `gorm:"type:uuid;not null;index:idx_reservations_user;index:idx_reservations_user_at"`
-	JobID        *uuid.UUID `gorm:"type:uuid"`
+	JobID        *uuid.UUID `gorm:"type:uuid;uniqueIndex:idx_reservations_job"`
 	RestaurantID uuid.UUID  `gorm:"type:uuid;not null;index:idx_reservations_restaurant"`
 
 	Platform      string    `gorm:"type:platform;not null"`
@@ -18,6 +40,10 @@ type Reservation struct {
 	ReservationAt time.Time `gorm:"type:timestamptz;not null;index:idx_reservations_user_at"`
 	PartySize     int16     `gorm:"type:smallint;not null"`
 
+	Status ReservationStatus `gorm:"type:varchar(16);not null;default:'booked'"`
+	// Bookings, upgrades, and cancellations of the reservation in chronological order
+	Audit ReservationAudit `gorm:"type:jsonb"`
+
 	// Relations
 	User       *User       `gorm:"foreignKey:UserID"`
 	Job        *Job        `gorm:"foreignKey:JobID"`
@@ -39,6 +65,9 @@ func (m *Reservation) ToAPI() *api.Reservation {
 		ReservationAt: m.ReservationAt,
 		PartySize:     m.PartySize,
 
+		Status: api.ReservationStatus(m.Status),
+		Audit:  m.Audit,
+
 		CreatedAt: m.CreatedAt.UTC(),
 		UpdatedAt: m.UpdatedAt.UTC(),
 	}
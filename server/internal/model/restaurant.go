@@ -5,6 +5,7 @@ import (
 
 	"github.com/daylamtayari/cierge/api"
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 type Restaurant struct {
@@ -20,6 +21,9 @@ type Restaurant struct {
 
 	Rating *float32 `gorm:"type:real"`
 
+	SeatingTypes             pq.StringArray `gorm:"type:varchar(255)[]"`
+	SeatingTypesDiscoveredAt *time.Time     `gorm:"type:timestamptz"`
+
 	// Relations
 	DropConfigs  []*DropConfig `gorm:"many2many:drop_config_restaurants;"`
 	Favourites   []Favourite   `gorm:"foreignKey:RestaurantID;constraint:OnDelete:CASCADE"`
@@ -36,16 +40,17 @@ func (m *Restaurant) ToAPI() *api.Restaurant {
 		timezone = m.Timezone.String()
 	}
 	return &api.Restaurant{
-		ID:         m.ID,
-		Platform:   m.Platform,
-		PlatformID: m.PlatformID,
-		Name:       m.Name,
-		Address:    m.Address,
-		City:       m.City,
-		State:      m.State,
-		Timezone:   timezone,
-		Rating:     m.Rating,
-		CreatedAt:  m.CreatedAt,
-		UpdatedAt:  m.UpdatedAt,
+		ID:           m.ID,
+		Platform:     m.Platform,
+		PlatformID:   m.PlatformID,
+		Name:         m.Name,
+		Address:      m.Address,
+		City:         m.City,
+		State:        m.State,
+		Timezone:     timezone,
+		Rating:       m.Rating,
+		SeatingTypes: m.SeatingTypes,
+		CreatedAt:    m.CreatedAt,
+		UpdatedAt:    m.UpdatedAt,
 	}
 }
@@ -28,7 +28,7 @@ func (r *DropConfig) GetByID(ctx context.Context, id uuid.UUID) (*model.DropConf
 	defer cancel()
 
 	var dropConfig model.DropConfig
-	if err := r.db.WithContext(ctx).Where("id = ?", id).Take(&dropConfig).Error; err != nil {
+	if err := withContext(ctx, r.db).Where("id = ?", id).Take(&dropConfig).Error; err != nil {
 		return nil, err
 	}
 	return &dropConfig, nil
@@ -40,7 +40,7 @@ func (r *DropConfig) GetByRestaurant(ctx context.Context, restaurantId uuid.UUID
 	defer cancel()
 
 	var dropConfigs []*model.DropConfig
-	err := r.db.WithContext(ctx).
+	err := withContext(ctx, r.db).
 		Select("drop_configs.*, drop_config_restaurants.confidence").
 		Joins("JOIN drop_config_restaurants ON drop_config_restaurants.drop_config_id = drop_configs.id").
 		Where("drop_config_restaurants.restaurant_id = ?", restaurantId).
@@ -55,7 +55,7 @@ func (r *DropConfig) GetByConfig(ctx context.Context, daysInAdvance int16, dropT
 	defer cancel()
 
 	var dropConfig model.DropConfig
-	err := r.db.WithContext(ctx).
+	err := withContext(ctx, r.db).
 		Where("days_in_advance = ? AND drop_time = ?", daysInAdvance, dropTime).
 		Take(&dropConfig).Error
 	if err != nil {
@@ -69,7 +69,7 @@ func (r *DropConfig) Create(ctx context.Context, dropConfig *model.DropConfig) e
 	ctx, cancel := context.WithTimeout(ctx, r.timeout)
 	defer cancel()
 
-	return r.db.WithContext(ctx).Create(dropConfig).Error
+	return withContext(ctx, r.db).Create(dropConfig).Error
 }
 
 // AddRestaurant associates a restaurant with a drop config.
@@ -78,7 +78,7 @@ func (r *DropConfig) AddRestaurant(ctx context.Context, dropConfigId uuid.UUID,
 	ctx, cancel := context.WithTimeout(ctx, r.timeout)
 	defer cancel()
 
-	return r.db.WithContext(ctx).
+	return withContext(ctx, r.db).
 		Clauses(clause.OnConflict{DoNothing: true}).
 		Create(&model.DropConfigRestaurant{
 			DropConfigID: dropConfigId,
@@ -91,7 +91,7 @@ func (r *DropConfig) IncrementConfidence(ctx context.Context, dropConfigId uuid.
 	ctx, cancel := context.WithTimeout(ctx, r.timeout)
 	defer cancel()
 
-	return r.db.WithContext(ctx).Model(&model.DropConfigRestaurant{}).
+	return withContext(ctx, r.db).Model(&model.DropConfigRestaurant{}).
 		Where("drop_config_id = ? AND restaurant_id = ?", dropConfigId, restaurantId).
 		Updates(map[string]any{
 			"confidence": gorm.Expr("confidence + 1"),
@@ -27,7 +27,7 @@ func (r *Job) GetByID(ctx context.Context, id uuid.UUID) (*model.Job, error) {
 	defer cancel()
 
 	var job model.Job
-	if err := r.db.WithContext(ctx).Preload("Restaurant").Where("id = ?", id).Take(&job).Error; err != nil {
+	if err := withContext(ctx, r.db).Preload("Restaurant").Where("id = ?", id).Take(&job).Error; err != nil {
 		return nil, err
 	}
 	return &job, nil
@@ -39,7 +39,7 @@ func (r *Job) GetByUser(ctx context.Context, userId uuid.UUID) ([]*model.Job, er
 	defer cancel()
 
 	var jobs []*model.Job
-	return jobs, r.db.WithContext(ctx).Where("user_id = ?", userId).Find(&jobs).Error
+	return jobs, withContext(ctx, r.db).Where("user_id = ?", userId).Find(&jobs).Error
 }
 
 // Gets all scheduled jobs for a given user and for a given platform
@@ -48,7 +48,31 @@ func (r *Job) GetScheduledByUserAndPlatform(ctx context.Context, userId uuid.UUI
 	defer cancel()
 
 	var jobs []*model.Job
-	return jobs, r.db.WithContext(ctx).Where("status = ?", model.JobStatusScheduled).Where("user_id = ?", userId).Where("platform = ?", platform).Find(&jobs).Error
+	return jobs, withContext(ctx, r.db).Where("status = ?", model.JobStatusScheduled).Where("user_id = ?", userId).Where("platform = ?", platform).Find(&jobs).Error
+}
+
+// Gets all scheduled jobs with their restaurant
+func (r *Job) GetScheduled(ctx context.Context) ([]*model.Job, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	var jobs []*model.Job
+	return jobs, withContext(ctx, r.db).Preload("Restaurant").Where("status = ?", model.JobStatusScheduled).Find(&jobs).Error
+}
+
+// Gets all scheduled or running jobs with their restaurant that have
+// not received a callback and were scheduled before a given time
+// Watch jobs are only stale once their watch deadline is before the given time
+func (r *Job) GetStale(ctx context.Context, scheduledBefore time.Time) ([]*model.Job, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	var jobs []*model.Job
+	return jobs, withContext(ctx, r.db).Preload("Restaurant").
+		Where("status IN ?", []model.JobStatus{model.JobStatusScheduled, model.JobStatusRunning}).
+		Where("callbacked = ?", false).
+		Where("COALESCE(watch_until, scheduled_at) < ?", scheduledBefore).
+		Find(&jobs).Error
 }
 
 // Updates a job
@@ -56,7 +80,7 @@ func (r *Job) Update(ctx context.Context, job *model.Job) error {
 	ctx, cancel := context.WithTimeout(ctx, r.timeout)
 	defer cancel()
 
-	return r.db.WithContext(ctx).Save(job).Error
+	return withContext(ctx, r.db).Save(job).Error
 }
 
 // Sets the callback secret hash for a specified job
@@ -64,7 +88,7 @@ func (r *Job) SetCallbackSecretHash(ctx context.Context, secretHash string, id u
 	ctx, cancel := context.WithTimeout(ctx, r.timeout)
 	defer cancel()
 
-	return r.db.WithContext(ctx).Model(&model.Job{}).
+	return withContext(ctx, r.db).Model(&model.Job{}).
 		Where("id = ?", id).
 		Updates(map[string]any{
 			"callback_secret_hash": secretHash,
@@ -76,20 +100,51 @@ func (r *Job) UpdateStatus(ctx context.Context, status model.JobStatus, id uuid.
 	ctx, cancel := context.WithTimeout(ctx, r.timeout)
 	defer cancel()
 
-	return r.db.WithContext(ctx).Model(&model.Job{}).
+	return withContext(ctx, r.db).Model(&model.Job{}).
 		Where("id = ?", id).
 		Updates(map[string]any{
 			"status": status,
 		}).Error
 }
 
+// Marks a scheduled job as running with the time it started
+// Returns whether the job was scheduled and has been updated
+func (r *Job) MarkRunning(ctx context.Context, id uuid.UUID, startedAt time.Time) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	result := withContext(ctx, r.db).Model(&model.Job{}).
+		Where("id = ?", id).
+		Where("status = ?", model.JobStatusScheduled).
+		Updates(map[string]any{
+			"status":     model.JobStatusRunning,
+			"started_at": startedAt,
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// Marks a job that has not received a callback as callbacked
+// Returns whether the job had not received a callback and has been updated
+func (r *Job) ClaimCallback(ctx context.Context, id uuid.UUID) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	result := withContext(ctx, r.db).Model(&model.Job{}).
+		Where("id = ?", id).
+		Where("callbacked = ?", false).
+		Updates(map[string]any{
+			"callbacked": true,
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
 // Gets all jobs across all users
 func (r *Job) GetAll(ctx context.Context) ([]*model.Job, error) {
 	ctx, cancel := context.WithTimeout(ctx, r.timeout)
 	defer cancel()
 
 	var jobs []*model.Job
-	return jobs, r.db.WithContext(ctx).Find(&jobs).Error
+	return jobs, withContext(ctx, r.db).Find(&jobs).Error
 }
 
 // Create a job
@@ -97,5 +152,5 @@ func (r *Job) Create(ctx context.Context, job *model.Job) error {
 	ctx, cancel := context.WithTimeout(ctx, r.timeout)
 	defer cancel()
 
-	return r.db.WithContext(ctx).Create(job).Error
+	return withContext(ctx, r.db).Create(job).Error
 }
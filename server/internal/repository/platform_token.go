@@ -27,7 +27,7 @@ func (r *PlatformToken) GetByID(ctx context.Context, id uuid.UUID) (*model.Platf
 	defer cancel()
 
 	var platformToken model.PlatformToken
-	if err := r.db.WithContext(ctx).Where("id = ?", id).Take(&platformToken).Error; err != nil {
+	if err := withContext(ctx, r.db).Where("id = ?", id).Take(&platformToken).Error; err != nil {
 		return nil, err
 	}
 	return &platformToken, nil
@@ -39,7 +39,7 @@ func (r *PlatformToken) GetByUser(ctx context.Context, userID uuid.UUID) ([]*mod
 	defer cancel()
 
 	var platformTokens []*model.PlatformToken
-	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).Find(&platformTokens).Error; err != nil {
+	if err := withContext(ctx, r.db).Where("user_id = ?", userID).Find(&platformTokens).Error; err != nil {
 		return nil, err
 	}
 	return platformTokens, nil
@@ -51,7 +51,7 @@ func (r *PlatformToken) GetByUserAndPlatform(ctx context.Context, userID uuid.UU
 	defer cancel()
 
 	var platformToken model.PlatformToken
-	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).Where("platform = ?", platform).Take(&platformToken).Error; err != nil {
+	if err := withContext(ctx, r.db).Where("user_id = ?", userID).Where("platform = ?", platform).Take(&platformToken).Error; err != nil {
 		return nil, err
 	}
 	return &platformToken, nil
@@ -63,7 +63,7 @@ func (r *PlatformToken) GetExpired(ctx context.Context) ([]*model.PlatformToken,
 	defer cancel()
 
 	var platformTokens []*model.PlatformToken
-	if err := r.db.WithContext(ctx).Where("expires_at < ?", time.Now().UTC()).Find(&platformTokens).Error; err != nil {
+	if err := withContext(ctx, r.db).Where("expires_at < ?", time.Now().UTC()).Find(&platformTokens).Error; err != nil {
 		return nil, err
 	}
 	return platformTokens, nil
@@ -75,7 +75,7 @@ func (r *PlatformToken) GetExpiringWithin(ctx context.Context, duration time.Dur
 	defer cancel()
 
 	var platformTokens []*model.PlatformToken
-	if err := r.db.WithContext(ctx).Where("expires_at < ?", (time.Now().UTC()).Add(duration)).Find(&platformTokens).Error; err != nil {
+	if err := withContext(ctx, r.db).Where("expires_at < ?", (time.Now().UTC()).Add(duration)).Find(&platformTokens).Error; err != nil {
 		return nil, err
 	}
 	return platformTokens, nil
@@ -87,18 +87,54 @@ func (r *PlatformToken) GetExpiringWithinWithRefresh(ctx context.Context, durati
 	defer cancel()
 
 	var platformTokens []*model.PlatformToken
-	if err := r.db.WithContext(ctx).Where("expires_at < ?", (time.Now().UTC()).Add(duration)).Where("has_refresh = true").Find(&platformTokens).Error; err != nil {
+	if err := withContext(ctx, r.db).Where("expires_at < ?", (time.Now().UTC()).Add(duration)).Where("has_refresh = true").Find(&platformTokens).Error; err != nil {
 		return nil, err
 	}
 	return platformTokens, nil
 }
 
+// Get a batch of platform tokens ordered by ID with an ID greater than a given ID
+// Used to iterate over all tokens without holding them all in memory
+func (r *PlatformToken) GetBatchAfter(ctx context.Context, afterID uuid.UUID, limit int) ([]*model.PlatformToken, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	var platformTokens []*model.PlatformToken
+	if err := withContext(ctx, r.db).Where("id > ?", afterID).Order("id").Limit(limit).Find(&platformTokens).Error; err != nil {
+		return nil, err
+	}
+	return platformTokens, nil
+}
+
+// Get the total number of platform tokens
+func (r *PlatformToken) Count(ctx context.Context) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	var count int64
+	return count, withContext(ctx, r.db).Model(&model.PlatformToken{}).Count(&count).Error
+}
+
+// Replace the encrypted token value of a platform token only if it still
+// has the expected encrypted value, preventing concurrent updates from being overwritten
+// Returns whether the token was updated
+func (r *PlatformToken) SwapEncryptedToken(ctx context.Context, id uuid.UUID, oldEncryptedToken string, newEncryptedToken string) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	result := withContext(ctx, r.db).Model(&model.PlatformToken{}).
+		Where("id = ?", id).
+		Where("encrypted_token = ?", oldEncryptedToken).
+		Update("encrypted_token", newEncryptedToken)
+	return result.RowsAffected == 1, result.Error
+}
+
 // Create platform token
 func (r *PlatformToken) Create(ctx context.Context, platformToken *model.PlatformToken) error {
 	ctx, cancel := context.WithTimeout(ctx, r.timeout)
 	defer cancel()
 
-	return r.db.WithContext(ctx).Create(platformToken).Error
+	return withContext(ctx, r.db).Create(platformToken).Error
 }
 
 // Create and replace platform token
@@ -107,7 +143,7 @@ func (r *PlatformToken) Replace(ctx context.Context, oldTokenId *uuid.UUID, newT
 	defer cancel()
 
 	if oldTokenId != nil {
-		return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return withContext(ctx, r.db).Transaction(func(tx *gorm.DB) error {
 			// Delete the old token
 			if err := tx.Delete(&model.PlatformToken{}, "id = ?", oldTokenId).Error; err != nil {
 				return err
@@ -130,7 +166,7 @@ func (r *PlatformToken) Delete(ctx context.Context, id uuid.UUID) error {
 	ctx, cancel := context.WithTimeout(ctx, r.timeout)
 	defer cancel()
 
-	return r.db.WithContext(ctx).Delete(&model.PlatformToken{}, "id = ?", id).Error
+	return withContext(ctx, r.db).Delete(&model.PlatformToken{}, "id = ?", id).Error
 }
 
 // Delete tokens for a given user and platform
@@ -138,5 +174,5 @@ func (r *PlatformToken) DeleteByUserAndPlatform(ctx context.Context, userID uuid
 	ctx, cancel := context.WithTimeout(ctx, r.timeout)
 	defer cancel()
 
-	return r.db.WithContext(ctx).Delete(&model.PlatformToken{}, "user_id = ? AND platform = ?", userID, platform).Error
+	return withContext(ctx, r.db).Delete(&model.PlatformToken{}, "user_id = ? AND platform = ?", userID, platform).Error
 }
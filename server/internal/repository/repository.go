@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"time"
 
 	"gorm.io/gorm"
@@ -44,3 +45,22 @@ func (r *Repositories) DB() *gorm.DB {
 func (r *Repositories) Timeout() time.Duration {
 	return r.timeout
 }
+
+type txKey struct{}
+
+// Runs a function within a database transaction that is committed if the function returns no error
+// Repository calls made with the context passed to the function are part of the transaction
+func (r *Repositories) Transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return withContext(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		return fn(context.WithValue(ctx, txKey{}, tx))
+	})
+}
+
+// Returns the database of a repository with a given context, using
+// the transaction of the context if there is one
+func withContext(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if tx, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return tx.WithContext(ctx)
+	}
+	return db.WithContext(ctx)
+}
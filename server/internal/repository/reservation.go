@@ -7,6 +7,7 @@ import (
 	"github.com/daylamtayari/cierge/server/internal/model"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type Reservation struct {
@@ -27,7 +28,19 @@ func (r *Reservation) GetByID(ctx context.Context, id uuid.UUID) (*model.Reserva
 	defer cancel()
 
 	var reservation model.Reservation
-	if err := r.db.WithContext(ctx).Where("id = ?", id).Take(&reservation).Error; err != nil {
+	if err := withContext(ctx, r.db).Where("id = ?", id).Take(&reservation).Error; err != nil {
+		return nil, err
+	}
+	return &reservation, nil
+}
+
+// Gets the reservation created from a given job
+func (r *Reservation) GetByJobID(ctx context.Context, jobID uuid.UUID) (*model.Reservation, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	var reservation model.Reservation
+	if err := withContext(ctx, r.db).Where("job_id = ?", jobID).Take(&reservation).Error; err != nil {
 		return nil, err
 	}
 	return &reservation, nil
@@ -39,7 +52,7 @@ func (r *Reservation) GetByUser(ctx context.Context, userID uuid.UUID) ([]*model
 	defer cancel()
 
 	var reservations []*model.Reservation
-	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).Find(&reservations).Error; err != nil {
+	if err := withContext(ctx, r.db).Where("user_id = ?", userID).Find(&reservations).Error; err != nil {
 		return nil, err
 	}
 	return reservations, nil
@@ -51,7 +64,7 @@ func (r *Reservation) GetByUserUpcoming(ctx context.Context, userID uuid.UUID) (
 	defer cancel()
 
 	var reservations []*model.Reservation
-	if err := r.db.WithContext(ctx).Where("user_id = ? AND reservation_at > ?", userID, time.Now().UTC()).Find(&reservations).Error; err != nil {
+	if err := withContext(ctx, r.db).Where("user_id = ? AND reservation_at > ?", userID, time.Now().UTC()).Find(&reservations).Error; err != nil {
 		return nil, err
 	}
 	return reservations, nil
@@ -62,7 +75,19 @@ func (r *Reservation) Create(ctx context.Context, reservation *model.Reservation
 	ctx, cancel := context.WithTimeout(ctx, r.timeout)
 	defer cancel()
 
-	return r.db.WithContext(ctx).Create(reservation).Error
+	return withContext(ctx, r.db).Create(reservation).Error
+}
+
+// Create a reservation from a job unless a reservation was already created from the job
+// Returns whether the reservation was created
+func (r *Reservation) CreateForJob(ctx context.Context, reservation *model.Reservation) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	result := withContext(ctx, r.db).
+		Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "job_id"}}, DoNothing: true}).
+		Create(reservation)
+	return result.RowsAffected == 1, result.Error
 }
 
 // Update a reservation
@@ -70,7 +95,7 @@ func (r *Reservation) Update(ctx context.Context, reservation *model.Reservation
 	ctx, cancel := context.WithTimeout(ctx, r.timeout)
 	defer cancel()
 
-	return r.db.WithContext(ctx).Save(reservation).Error
+	return withContext(ctx, r.db).Save(reservation).Error
 }
 
 // Delete a reservation
@@ -78,5 +103,5 @@ func (r *Reservation) Delete(ctx context.Context, id uuid.UUID) error {
 	ctx, cancel := context.WithTimeout(ctx, r.timeout)
 	defer cancel()
 
-	return r.db.WithContext(ctx).Delete(&model.Reservation{}, "id = ?", id).Error
+	return withContext(ctx, r.db).Delete(&model.Reservation{}, "id = ?", id).Error
 }
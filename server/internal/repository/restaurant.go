@@ -6,6 +6,7 @@ import (
 
 	"github.com/daylamtayari/cierge/server/internal/model"
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 	"gorm.io/gorm"
 )
 
@@ -27,7 +28,7 @@ func (r *Restaurant) GetByID(ctx context.Context, id uuid.UUID) (*model.Restaura
 	defer cancel()
 
 	var restaurant model.Restaurant
-	if err := r.db.WithContext(ctx).Where("id = ?", id).Take(&restaurant).Error; err != nil {
+	if err := withContext(ctx, r.db).Where("id = ?", id).Take(&restaurant).Error; err != nil {
 		return nil, err
 	}
 	return &restaurant, nil
@@ -39,7 +40,7 @@ func (r *Restaurant) GetByPlatformID(ctx context.Context, platform string, platf
 	defer cancel()
 
 	var restaurant model.Restaurant
-	if err := r.db.WithContext(ctx).Where("platform = ?", platform).Where("platform_id = ?", platformID).Take(&restaurant).Error; err != nil {
+	if err := withContext(ctx, r.db).Where("platform = ?", platform).Where("platform_id = ?", platformID).Take(&restaurant).Error; err != nil {
 		return nil, err
 	}
 	return &restaurant, nil
@@ -50,7 +51,7 @@ func (r *Restaurant) Create(ctx context.Context, restaurant *model.Restaurant) e
 	ctx, cancel := context.WithTimeout(ctx, r.timeout)
 	defer cancel()
 
-	return r.db.WithContext(ctx).Create(restaurant).Error
+	return withContext(ctx, r.db).Create(restaurant).Error
 }
 
 // Update restuarant
@@ -58,7 +59,20 @@ func (r *Restaurant) Update(ctx context.Context, restaurant *model.Restaurant) e
 	ctx, cancel := context.WithTimeout(ctx, r.timeout)
 	defer cancel()
 
-	return r.db.WithContext(ctx).Save(restaurant).Error
+	return withContext(ctx, r.db).Save(restaurant).Error
+}
+
+// Update the seating types of a restaurant and the time they were discovered at
+func (r *Restaurant) UpdateSeatingTypes(ctx context.Context, id uuid.UUID, seatingTypes []string, discoveredAt time.Time) error {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	return withContext(ctx, r.db).Model(&model.Restaurant{}).
+		Where("id = ?", id).
+		Updates(map[string]any{
+			"seating_types":               pq.StringArray(seatingTypes),
+			"seating_types_discovered_at": discoveredAt,
+		}).Error
 }
 
 // Delete restaurant
@@ -66,5 +80,5 @@ func (r *Restaurant) Delete(ctx context.Context, id uuid.UUID) error {
 	ctx, cancel := context.WithTimeout(ctx, r.timeout)
 	defer cancel()
 
-	return r.db.WithContext(ctx).Delete(&model.Restaurant{}, "id = ?", id).Error
+	return withContext(ctx, r.db).Delete(&model.Restaurant{}, "id = ?", id).Error
 }
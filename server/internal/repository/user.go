@@ -27,7 +27,7 @@ func (r *User) GetByID(ctx context.Context, id uuid.UUID) (*model.User, error) {
 	defer cancel()
 
 	var user model.User
-	if err := r.db.WithContext(ctx).Where("id = ?", id).Take(&user).Error; err != nil {
+	if err := withContext(ctx, r.db).Where("id = ?", id).Take(&user).Error; err != nil {
 		return nil, err
 	}
 	return &user, nil
@@ -39,7 +39,7 @@ func (r *User) GetByEmail(ctx context.Context, email string) (*model.User, error
 	defer cancel()
 
 	var user model.User
-	if err := r.db.WithContext(ctx).Where("email = ?", email).Take(&user).Error; err != nil {
+	if err := withContext(ctx, r.db).Where("email = ?", email).Take(&user).Error; err != nil {
 		return nil, err
 	}
 	return &user, nil
@@ -51,7 +51,7 @@ func (r *User) GetByApiKey(ctx context.Context, apiKey string) (*model.User, err
 	defer cancel()
 
 	var user model.User
-	if err := r.db.WithContext(ctx).Where("api_key = ?", apiKey).Take(&user).Error; err != nil {
+	if err := withContext(ctx, r.db).Where("api_key = ?", apiKey).Take(&user).Error; err != nil {
 		return nil, err
 	}
 	return &user, nil
@@ -63,7 +63,7 @@ func (r *User) ExistsByEmail(ctx context.Context, email string) (bool, error) {
 	defer cancel()
 
 	var count int64
-	err := r.db.WithContext(ctx).Model(&model.User{}).Where("email = ?", email).Count(&count).Error
+	err := withContext(ctx, r.db).Model(&model.User{}).Where("email = ?", email).Count(&count).Error
 	return count > 0, err
 }
 
@@ -73,7 +73,7 @@ func (r *User) ExistsByApiKey(ctx context.Context, apiKey string) (bool, error)
 	defer cancel()
 
 	var count int64
-	err := r.db.WithContext(ctx).Model(&model.User{}).Where("api_key = ?", apiKey).Count(&count).Error
+	err := withContext(ctx, r.db).Model(&model.User{}).Where("api_key = ?", apiKey).Count(&count).Error
 	return count > 0, err
 }
 
@@ -83,7 +83,7 @@ func (r *User) List(ctx context.Context) ([]model.User, error) {
 	defer cancel()
 
 	var users []model.User
-	err := r.db.WithContext(ctx).Find(&users).Error
+	err := withContext(ctx, r.db).Find(&users).Error
 	return users, err
 }
 
@@ -93,7 +93,7 @@ func (r *User) GetAdmins(ctx context.Context) ([]model.User, error) {
 	defer cancel()
 
 	var users []model.User
-	err := r.db.WithContext(ctx).Where("is_admin = true").Find(&users).Error
+	err := withContext(ctx, r.db).Where("is_admin = true").Find(&users).Error
 	return users, err
 }
 
@@ -102,7 +102,7 @@ func (r *User) Create(ctx context.Context, user *model.User) error {
 	ctx, cancel := context.WithTimeout(ctx, r.timeout)
 	defer cancel()
 
-	return r.db.WithContext(ctx).Create(user).Error
+	return withContext(ctx, r.db).Create(user).Error
 }
 
 // Update user
@@ -110,7 +110,7 @@ func (r *User) Update(ctx context.Context, user *model.User) error {
 	ctx, cancel := context.WithTimeout(ctx, r.timeout)
 	defer cancel()
 
-	return r.db.WithContext(ctx).Save(user).Error
+	return withContext(ctx, r.db).Save(user).Error
 }
 
 // Update user password, including the password changed timestamp
@@ -118,7 +118,7 @@ func (r *User) UpdatePassword(ctx context.Context, id uuid.UUID, passwordHash st
 	ctx, cancel := context.WithTimeout(ctx, r.timeout)
 	defer cancel()
 
-	return r.db.WithContext(ctx).Model(&model.User{}).
+	return withContext(ctx, r.db).Model(&model.User{}).
 		Where("id = ?", id).
 		Updates(map[string]any{
 			"password_hash":       passwordHash,
@@ -131,7 +131,7 @@ func (r *User) UpdateEmail(ctx context.Context, id uuid.UUID, email string) erro
 	ctx, cancel := context.WithTimeout(ctx, r.timeout)
 	defer cancel()
 
-	return r.db.WithContext(ctx).Model(&model.User{}).Where("id = ?", id).Update("email", email).Error
+	return withContext(ctx, r.db).Model(&model.User{}).Where("id = ?", id).Update("email", email).Error
 }
 
 // Update an API key value and timestamp
@@ -139,7 +139,7 @@ func (r *User) UpdateAPIKey(ctx context.Context, id uuid.UUID, apiKey string) er
 	ctx, cancel := context.WithTimeout(ctx, r.timeout)
 	defer cancel()
 
-	return r.db.WithContext(ctx).Model(&model.User{}).
+	return withContext(ctx, r.db).Model(&model.User{}).
 		Where("id = ?", id).
 		Updates(map[string]any{
 			"api_key":              apiKey,
@@ -162,14 +162,14 @@ func (r *User) RecordFailedLogin(ctx context.Context, id uuid.UUID, lockUntil *t
 		updates["locked_until"] = lockUntil
 	}
 
-	return r.db.WithContext(ctx).Model(&model.User{}).Where("id = ?", id).Updates(updates).Error
+	return withContext(ctx, r.db).Model(&model.User{}).Where("id = ?", id).Updates(updates).Error
 }
 
 func (r *User) RecordSuccessfulLogin(ctx context.Context, id uuid.UUID) error {
 	ctx, cancel := context.WithTimeout(ctx, r.timeout)
 	defer cancel()
 
-	return r.db.WithContext(ctx).Model(&model.User{}).
+	return withContext(ctx, r.db).Model(&model.User{}).
 		Where("id = ?", id).
 		Updates(map[string]any{
 			"last_login_at":         time.Now().UTC(),
@@ -182,7 +182,7 @@ func (r *User) UpdateAdminStatus(ctx context.Context, id uuid.UUID, isAdmin bool
 	ctx, cancel := context.WithTimeout(ctx, r.timeout)
 	defer cancel()
 
-	return r.db.WithContext(ctx).Model(&model.User{}).Where("id = ?", id).Update("is_admin", isAdmin).Error
+	return withContext(ctx, r.db).Model(&model.User{}).Where("id = ?", id).Update("is_admin", isAdmin).Error
 }
 
 // Delete user
@@ -190,5 +190,5 @@ func (r *User) Delete(ctx context.Context, id uuid.UUID) error {
 	ctx, cancel := context.WithTimeout(ctx, r.timeout)
 	defer cancel()
 
-	return r.db.WithContext(ctx).Delete(&model.User{}, "id = ?", id).Error
+	return withContext(ctx, r.db).Delete(&model.User{}, "id = ?", id).Error
 }
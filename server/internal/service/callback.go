@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+
+	"github.com/daylamtayari/cierge/reservation"
+	appctx "github.com/daylamtayari/cierge/server/internal/context"
+	"github.com/daylamtayari/cierge/server/internal/model"
+	"github.com/daylamtayari/cierge/server/internal/repository"
+)
+
+// Handles the outputs of jobs, either received in their callback or recovered
+// by the sweeper, exactly once per job
+type Callback struct {
+	repos              *repository.Repositories
+	jobService         *Job
+	reservationService *Reservation
+	upgradeService     *Upgrade
+}
+
+func NewCallback(repos *repository.Repositories, jobService *Job, reservationService *Reservation, upgradeService *Upgrade) *Callback {
+	return &Callback{
+		repos:              repos,
+		jobService:         jobService,
+		reservationService: reservationService,
+		upgradeService:     upgradeService,
+	}
+}
+
+// Handles the output of a job by claiming its callback, updating the job, creating
+// its reservation if successful and handling its upgrade within a single transaction
+// Outputs of jobs whose callback was already claimed are not handled and a failure
+// to update the job or create its reservation leaves the callback unclaimed
+// Returns whether the output was handled
+func (s *Callback) Handle(ctx context.Context, job *model.Job, output reservation.Output) (bool, error) {
+	handled := false
+	err := s.repos.Transaction(ctx, func(ctx context.Context) error {
+		claimed, err := s.jobService.ClaimCallback(ctx, job.ID)
+		if err != nil || !claimed {
+			return err
+		}
+
+		updatedJob, err := s.jobService.UpdateFromCallback(ctx, job, output)
+		if err != nil {
+			return err
+		}
+
+		var res *model.Reservation
+		if updatedJob.Status == model.JobStatusSuccess {
+			res, err = s.reservationService.CreateFromJob(ctx, updatedJob)
+			if err != nil {
+				return err
+			}
+		}
+
+		// The reservation is kept if its upgrade could not be handled
+		if err := s.upgradeService.HandleJob(ctx, updatedJob, res, output); err != nil {
+			appctx.Logger(ctx).Error().Err(err).Stringer("job_id", job.ID).Msg("failed to handle upgrade of job")
+		}
+
+		handled = true
+		return nil
+	})
+	return handled, err
+}
@@ -93,14 +93,18 @@ func (s *DropConfig) IncrementConfidence(ctx context.Context, dropConfigId uuid.
 	return s.dcRepo.IncrementConfidence(ctx, dropConfigId, restaurantId)
 }
 
-// Returns true if the scheduled job time would be in the past
-func (s *DropConfig) IsScheduledAtPast(dropConfig *model.DropConfig, reservationDate time.Time, restaurantTimezone *time.Location) bool {
+// Returns the time a job for a reservation date is scheduled at by a drop config
+func (s *DropConfig) ScheduledAt(dropConfig *model.DropConfig, reservationDate time.Time, restaurantTimezone *time.Location) time.Time {
 	scheduledAtDate := reservationDate.Add(-time.Duration(dropConfig.DaysInAdvance) * 24 * time.Hour)
 	scheduledAtTime, _ := time.Parse("15:04", dropConfig.DropTime)
 	scheduledAtLoc := time.UTC
 	if restaurantTimezone != nil {
 		scheduledAtLoc = restaurantTimezone
 	}
-	scheduledAt := time.Date(scheduledAtDate.Year(), scheduledAtDate.Month(), scheduledAtDate.Day(), scheduledAtTime.Hour(), scheduledAtTime.Minute(), 0, 0, scheduledAtLoc)
-	return time.Now().After(scheduledAt)
+	return time.Date(scheduledAtDate.Year(), scheduledAtDate.Month(), scheduledAtDate.Day(), scheduledAtTime.Hour(), scheduledAtTime.Minute(), 0, 0, scheduledAtLoc)
+}
+
+// Returns true if the scheduled job time would be in the past
+func (s *DropConfig) IsScheduledAtPast(dropConfig *model.DropConfig, reservationDate time.Time, restaurantTimezone *time.Location) bool {
+	return time.Now().After(s.ScheduledAt(dropConfig, reservationDate, restaurantTimezone))
 }
@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"time"
@@ -9,15 +10,26 @@ import (
 	"github.com/daylamtayari/cierge/api"
 	"github.com/daylamtayari/cierge/reservation"
 	"github.com/daylamtayari/cierge/server/cloud"
+	"github.com/daylamtayari/cierge/server/internal/config"
 	appctx "github.com/daylamtayari/cierge/server/internal/context"
 	"github.com/daylamtayari/cierge/server/internal/model"
 	"github.com/daylamtayari/cierge/server/internal/repository"
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 	"gorm.io/gorm"
 )
 
 var (
-	ErrJobDNE = errors.New("job does not exist")
+	ErrJobDNE           = errors.New("job does not exist")
+	ErrJobNotUpgradable = errors.New("job has no upgrade or booked slot")
+)
+
+const (
+	// Delay before a watch job without a start time starts so
+	// that it can be scheduled with the cloud provider
+	watchStartDelay = time.Minute
+	// Maximum duration of a dry run of a job
+	dryRunTimeout = 2 * time.Minute
 )
 
 type Job struct {
@@ -26,15 +38,22 @@ type Job struct {
 	tokenService      *Token
 	cloudProvider     cloud.Provider
 	serverExternalURL string
+	warmUp            reservation.WarmUp
 }
 
-func NewJob(jobRepo *repository.Job, ptService *PlatformToken, tokenService *Token, cloudProvider cloud.Provider, serverExternalURL string) *Job {
+func NewJob(jobRepo *repository.Job, ptService *PlatformToken, tokenService *Token, cloudProvider cloud.Provider, serverExternalURL string, warmUpCfg config.WarmUp) *Job {
 	return &Job{
 		jobRepo:           jobRepo,
 		ptService:         ptService,
 		tokenService:      tokenService,
 		cloudProvider:     cloudProvider,
 		serverExternalURL: serverExternalURL,
+		warmUp: reservation.WarmUp{
+			Lead:              warmUpCfg.Lead.Duration(),
+			Connections:       warmUpCfg.Connections,
+			EarlyPolls:        warmUpCfg.EarlyPolls,
+			EarlyPollInterval: warmUpCfg.EarlyPollInterval.Duration(),
+		},
 	}
 }
 
@@ -76,6 +95,27 @@ func (s *Job) GetScheduledByUserAndPlatform(ctx context.Context, userID uuid.UUI
 
 }
 
+// Retrieves all scheduled jobs across all users
+func (s *Job) GetScheduled(ctx context.Context) ([]*model.Job, error) {
+	return s.jobRepo.GetScheduled(ctx)
+}
+
+// Retrieves all scheduled or running jobs that have not
+// received a callback and were scheduled before a given time
+func (s *Job) GetStale(ctx context.Context, scheduledBefore time.Time) ([]*model.Job, error) {
+	return s.jobRepo.GetStale(ctx, scheduledBefore)
+}
+
+// Marks a job as failed with a given error message
+// The job is not marked as callbacked so that a late callback is still accepted
+func (s *Job) MarkFailed(ctx context.Context, job *model.Job, errorMessage string) error {
+	completedAt := time.Now().UTC()
+	job.Status = model.JobStatusFailed
+	job.CompletedAt = &completedAt
+	job.ErrorMessage = &errorMessage
+	return s.jobRepo.Update(ctx, job)
+}
+
 // Updates a job record from a callback request. Updates the various fields of the Job object
 // and if successful, stores the success output and creates a reservation.
 func (s *Job) UpdateFromCallback(ctx context.Context, job *model.Job, callback reservation.Output) (*model.Job, error) {
@@ -99,6 +139,20 @@ func (s *Job) UpdateFromCallback(ctx context.Context, job *model.Job, callback r
 	if callback.Success {
 		job.Status = model.JobStatusSuccess
 		job.ReservedTime = &callback.ReservationTime
+		if callback.ReservationDate != "" {
+			reservedDate := model.DateString(callback.ReservationDate)
+			job.ReservedDate = &reservedDate
+		}
+		if callback.PartySize > 0 {
+			job.ReservedPartySize = &callback.PartySize
+		}
+		if callback.SeatingType != "" {
+			job.ReservedSeatingType = &callback.SeatingType
+		}
+		if callback.Deposit != nil {
+			job.ReservedDeposit = &callback.Deposit.Fee
+			job.ReservedCancellationCutOff = parseCancellationCutOff(callback.Deposit.CancellationCutOff)
+		}
 
 		platformConfirmation, err := json.Marshal(callback.PlatformConfirmation)
 		if err != nil {
@@ -117,6 +171,26 @@ func (s *Job) UpdateFromCallback(ctx context.Context, job *model.Job, callback r
 	return job, s.jobRepo.Update(ctx, job)
 }
 
+// Marks a scheduled job as running from a started callback request
+// Jobs that are no longer scheduled are left unchanged
+func (s *Job) MarkRunning(ctx context.Context, job *model.Job, started reservation.Started) error {
+	updated, err := s.jobRepo.MarkRunning(ctx, job.ID, started.StartTime)
+	if err != nil {
+		return err
+	}
+	if updated {
+		job.Status = model.JobStatusRunning
+		job.StartedAt = &started.StartTime
+	}
+	return nil
+}
+
+// Marks a job that has not received a callback as callbacked
+// Returns whether the callback of the job was claimed by this call
+func (s *Job) ClaimCallback(ctx context.Context, id uuid.UUID) (bool, error) {
+	return s.jobRepo.ClaimCallback(ctx, id)
+}
+
 // Update the status of a job
 func (s *Job) UpdateStatus(ctx context.Context, status model.JobStatus, id uuid.UUID) error {
 	return s.jobRepo.UpdateStatus(ctx, status, id)
@@ -134,20 +208,137 @@ func (s *Job) Create(ctx context.Context, jobCreationRequest *api.JobCreationReq
 	scheduledAt := time.Date(scheduledAtDate.Year(), scheduledAtDate.Month(), scheduledAtDate.Day(), scheduledAtTime.Hour(), scheduledAtTime.Minute(), 0, 0, scheduledAtLoc)
 
 	job := model.Job{
-		UserID:          appctx.UserID(ctx),
-		RestaurantID:    restaurant.ID,
-		Platform:        restaurant.Platform,
-		ReservationDate: model.DateString(jobCreationRequest.ReservationDate),
-		PartySize:       jobCreationRequest.PartySize,
-		PreferredTimes:  jobCreationRequest.PreferredTimes,
-		ScheduledAt:     scheduledAt,
-		DropConfigID:    jobCreationRequest.DropConfigID,
-		Status:          model.JobStatusCreated,
+		UserID:                appctx.UserID(ctx),
+		RestaurantID:          restaurant.ID,
+		Platform:              restaurant.Platform,
+		ReservationDate:       model.DateString(jobCreationRequest.ReservationDate),
+		PartySize:             jobCreationRequest.PartySize,
+		PreferredTimes:        jobCreationRequest.PreferredTimes,
+		PreferredWindows:      jobCreationRequest.PreferredWindows,
+		Alternatives:          jobCreationRequest.Alternatives,
+		AllowedSeatingTypes:   jobCreationRequest.AllowedSeatingTypes,
+		PreferredSeatingTypes: jobCreationRequest.PreferredSeatingTypes,
+		PaymentPolicy:         (*model.JobPaymentPolicy)(jobCreationRequest.PaymentPolicy),
+		Strategy:              (*model.JobStrategy)(jobCreationRequest.Strategy),
+		ScheduledAt:           scheduledAt,
+		DropConfigID:          jobCreationRequest.DropConfigID,
+		Status:                model.JobStatusCreated,
+	}
+	// Preferred times cannot be null but are empty if only windows are preferred
+	if job.PreferredTimes == nil {
+		job.PreferredTimes = pq.StringArray{}
+	}
+	setUpgrade(&job, jobCreationRequest.Upgrade)
+
+	return &job, s.jobRepo.Create(ctx, &job)
+}
+
+// Returns the maximum duration of a watch job, bounded by the execution time
+// limit of the cloud provider, and whether the duration of watch jobs is bounded
+func (s *Job) MaxWatchDuration() (time.Duration, bool) {
+	limiter, ok := s.cloudProvider.(cloud.RunTimeLimiter)
+	if !ok {
+		return 0, false
+	}
+	return limiter.MaxRunTime(), true
+}
+
+// Create a new watch job starting at the start of its watch or shortly after creation if
+// not set, and returns the job and an error that is nil if successful
+func (s *Job) CreateWatch(ctx context.Context, jobCreationRequest *api.JobCreationRequest, restaurant *model.Restaurant) (*model.Job, error) {
+	scheduledAt := time.Now().UTC().Add(watchStartDelay)
+	if jobCreationRequest.Watch.StartAt != nil && jobCreationRequest.Watch.StartAt.After(scheduledAt) {
+		scheduledAt = *jobCreationRequest.Watch.StartAt
+	}
+	watchUntil := jobCreationRequest.Watch.Until
+	watchInterval := int32(jobCreationRequest.Watch.Interval)
+
+	job := model.Job{
+		UserID:                appctx.UserID(ctx),
+		RestaurantID:          restaurant.ID,
+		Platform:              restaurant.Platform,
+		Type:                  model.JobTypeWatch,
+		ReservationDate:       model.DateString(jobCreationRequest.ReservationDate),
+		PartySize:             jobCreationRequest.PartySize,
+		PreferredTimes:        jobCreationRequest.PreferredTimes,
+		PreferredWindows:      jobCreationRequest.PreferredWindows,
+		Alternatives:          jobCreationRequest.Alternatives,
+		AllowedSeatingTypes:   jobCreationRequest.AllowedSeatingTypes,
+		PreferredSeatingTypes: jobCreationRequest.PreferredSeatingTypes,
+		PaymentPolicy:         (*model.JobPaymentPolicy)(jobCreationRequest.PaymentPolicy),
+		Strategy:              (*model.JobStrategy)(jobCreationRequest.Strategy),
+		WatchUntil:            &watchUntil,
+		WatchInterval:         &watchInterval,
+		ScheduledAt:           scheduledAt,
+		Status:                model.JobStatusCreated,
+	}
+	// Preferred times cannot be null but are empty if only windows are preferred
+	if job.PreferredTimes == nil {
+		job.PreferredTimes = pq.StringArray{}
 	}
+	setUpgrade(&job, jobCreationRequest.Upgrade)
 
 	return &job, s.jobRepo.Create(ctx, &job)
 }
 
+// Create a new upgrade job watching for slots more preferred than the booked slot of a
+// reservation booked by a job until a given time, and returns the job and an error that
+// is nil if successful
+// The upgrade job has the preferences and upgrade of the job, so that the reservation it books
+// can itself be upgraded, and starts shortly after creation
+func (s *Job) CreateUpgrade(ctx context.Context, job *model.Job, res *model.Reservation, until time.Time) (*model.Job, error) {
+	if job.UpgradeInterval == nil || job.ReservedTime == nil {
+		return nil, ErrJobNotUpgradable
+	}
+	confirmation := ""
+	if job.Confirmation != nil {
+		confirmation = *job.Confirmation
+	}
+	seatingType := ""
+	if job.ReservedSeatingType != nil {
+		seatingType = *job.ReservedSeatingType
+	}
+	// The booked alternative takes precedence over the reservation date
+	reservationDate := job.ReservationDate
+	if job.ReservedDate != nil {
+		reservationDate = *job.ReservedDate
+	}
+	watchInterval := *job.UpgradeInterval
+
+	upgradeJob := model.Job{
+		UserID:                job.UserID,
+		RestaurantID:          job.RestaurantID,
+		Platform:              job.Platform,
+		Type:                  model.JobTypeUpgrade,
+		ReservationDate:       job.ReservationDate,
+		PartySize:             job.PartySize,
+		PreferredTimes:        job.PreferredTimes,
+		PreferredWindows:      job.PreferredWindows,
+		Alternatives:          job.Alternatives,
+		AllowedSeatingTypes:   job.AllowedSeatingTypes,
+		PreferredSeatingTypes: job.PreferredSeatingTypes,
+		PaymentPolicy:         job.PaymentPolicy,
+		Strategy:              job.Strategy,
+		WatchUntil:            &until,
+		WatchInterval:         &watchInterval,
+		UpgradeUntil:          job.UpgradeUntil,
+		UpgradeInterval:       job.UpgradeInterval,
+		UpgradeReservationID:  &res.ID,
+		UpgradeSlot: &model.JobUpgradeSlot{
+			ReservationDate:    string(reservationDate),
+			PartySize:          res.PartySize,
+			SlotTime:           job.ReservedTime.UTC().Format("15:04"),
+			SeatingType:        seatingType,
+			Confirmation:       confirmation,
+			CancellationCutOff: job.ReservedCancellationCutOff,
+		},
+		ScheduledAt: time.Now().UTC().Add(watchStartDelay),
+		Status:      model.JobStatusCreated,
+	}
+
+	return &upgradeJob, s.jobRepo.Create(ctx, &upgradeJob)
+}
+
 // Schedule a job and return an error if unsuccessful
 // Includes getting the platform token and generating and
 // encrypting the callback secret and scheduling the job
@@ -164,19 +355,11 @@ func (s *Job) Schedule(ctx context.Context, job *model.Job, restaurant *model.Re
 	if err != nil {
 		return err
 	}
-	event := reservation.Event{
-		JobID:                   job.ID,
-		Platform:                job.Platform,
-		PlatformVenueId:         restaurant.PlatformID,
-		EncryptedToken:          platformToken.EncryptedToken,
-		EncryptedCallbackSecret: encryptedCallbackSecret,
-		ReservationDate:         string(job.ReservationDate),
-		PartySize:               job.PartySize,
-		PreferredTimes:          job.PreferredTimes,
-		DropTime:                job.ScheduledAt,
-		ServerEndpoint:          s.serverExternalURL,
-		Callback:                true,
-	}
+	event := newEvent(job, restaurant, platformToken.EncryptedToken)
+	event.EncryptedCallbackSecret = encryptedCallbackSecret
+	event.ServerEndpoint = s.serverExternalURL
+	event.Callback = true
+	event.WarmUp = &s.warmUp
 	err = s.cloudProvider.ScheduleJob(ctx, event)
 	if err != nil {
 		return err
@@ -184,6 +367,171 @@ func (s *Job) Schedule(ctx context.Context, job *model.Job, restaurant *model.Re
 	return nil
 }
 
+// Performs a dry run of a job, running every step of the job in the server
+// without booking, and returns its output
+// The slots of the reservation date are retrieved, unless a different reservation
+// date is specified, and the slot that would have been booked is returned
+// NOTE: Dry runs do not notify the server and do not update the job
+func (s *Job) DryRun(ctx context.Context, job *model.Job, restaurant *model.Restaurant, reservationDate string) (reservation.Output, error) {
+	platformToken, err := s.ptService.GetByUserAndPlatform(ctx, job.UserID, job.Platform)
+	if err != nil {
+		return reservation.Output{}, err
+	}
+
+	event := newEvent(job, restaurant, platformToken.EncryptedToken)
+	event.DryRun = true
+	event.DropTime = time.Now().UTC()
+	if reservationDate != "" {
+		event.ReservationDate = reservationDate
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, dryRunTimeout)
+	defer cancel()
+	return reservation.Handle(ctx, event, providerDecrypter{cloudProvider: s.cloudProvider}), nil
+}
+
+// Returns the reservation event of a job without its callback configuration
+func newEvent(job *model.Job, restaurant *model.Restaurant, encryptedToken string) reservation.Event {
+	event := reservation.Event{
+		JobID:                 job.ID,
+		Platform:              job.Platform,
+		PlatformVenueId:       restaurant.PlatformID,
+		EncryptedToken:        encryptedToken,
+		ReservationDate:       string(job.ReservationDate),
+		PartySize:             job.PartySize,
+		PreferredTimes:        job.PreferredTimes,
+		PreferredWindows:      reservationWindows(job.PreferredWindows),
+		Alternatives:          reservationAlternatives(job.Alternatives),
+		AllowedSeatingTypes:   job.AllowedSeatingTypes,
+		PreferredSeatingTypes: job.PreferredSeatingTypes,
+		PaymentPolicy:         reservationPaymentPolicy(job.PaymentPolicy),
+		Strategy:              reservationStrategy(job.Strategy),
+		DropTime:              job.ScheduledAt,
+	}
+	isWatch := job.Type == model.JobTypeWatch || job.Type == model.JobTypeUpgrade
+	if isWatch && job.WatchUntil != nil && job.WatchInterval != nil {
+		event.Watch = &reservation.Watch{
+			Until:    *job.WatchUntil,
+			Interval: time.Duration(*job.WatchInterval) * time.Second,
+		}
+	}
+	if job.Type == model.JobTypeUpgrade {
+		event.Upgrade = reservationUpgrade(job.UpgradeSlot)
+	}
+	return event
+}
+
+// Sets the upgrade of a job from the upgrade of a job creation request
+func setUpgrade(job *model.Job, upgrade *api.JobUpgrade) {
+	if upgrade == nil {
+		return
+	}
+	upgradeUntil := upgrade.Until
+	upgradeInterval := int32(upgrade.Interval)
+	job.UpgradeUntil = &upgradeUntil
+	job.UpgradeInterval = &upgradeInterval
+}
+
+// Parses the cancellation cut off of a deposit, returning nil if it is not set or invalid
+// Platforms return cut offs either in RFC 3339 or as a UTC date time
+func parseCancellationCutOff(cutOff string) *time.Time {
+	if cutOff == "" {
+		return nil
+	}
+	parsed, err := time.Parse(time.RFC3339, cutOff)
+	if err != nil {
+		parsed, err = time.Parse("2006-01-02 15:04:05", cutOff)
+		if err != nil {
+			return nil
+		}
+	}
+	parsed = parsed.UTC()
+	return &parsed
+}
+
+// Decrypts the encrypted values of reservation events using the cloud provider
+type providerDecrypter struct {
+	cloudProvider cloud.Provider
+}
+
+func (d providerDecrypter) Decrypt(ctx context.Context, encrypted []byte) (string, error) {
+	return d.cloudProvider.DecryptData(ctx, base64.StdEncoding.EncodeToString(encrypted))
+}
+
+// Converts the preferred time windows of a job to those of a reservation event
+func reservationWindows(windows model.TimeWindows) []reservation.TimeWindow {
+	if len(windows) == 0 {
+		return nil
+	}
+	converted := make([]reservation.TimeWindow, 0, len(windows))
+	for _, window := range windows {
+		converted = append(converted, reservation.TimeWindow{
+			Start:     window.Start,
+			End:       window.End,
+			Ideal:     window.Ideal,
+			Tolerance: window.Tolerance,
+		})
+	}
+	return converted
+}
+
+// Converts the alternatives of a job to those of a reservation event
+func reservationAlternatives(alternatives model.JobAlternatives) []reservation.Alternative {
+	if len(alternatives) == 0 {
+		return nil
+	}
+	converted := make([]reservation.Alternative, 0, len(alternatives))
+	for _, alternative := range alternatives {
+		converted = append(converted, reservation.Alternative{
+			ReservationDate: alternative.ReservationDate,
+			PartySize:       alternative.PartySize,
+		})
+	}
+	return converted
+}
+
+// Converts the payment policy of a job to the payment policy of an event
+func reservationPaymentPolicy(policy *model.JobPaymentPolicy) *reservation.PaymentPolicy {
+	if policy == nil {
+		return nil
+	}
+	return &reservation.PaymentPolicy{
+		MaxDeposit:      policy.MaxDeposit,
+		PaymentMethodId: policy.PaymentMethodID,
+	}
+}
+
+// Converts the booking strategy of a job to the booking strategy of an event
+func reservationStrategy(strategy *model.JobStrategy) *reservation.Strategy {
+	if strategy == nil {
+		return nil
+	}
+	return &reservation.Strategy{
+		Name:     string(strategy.Name),
+		Stagger:  time.Duration(strategy.Stagger) * time.Millisecond,
+		RaceSize: strategy.RaceSize,
+	}
+}
+
+// Converts the booked slot of an upgrade job to the upgrade of an event
+// The platform confirmation is left empty if the stored confirmation is invalid,
+// failing the cancellation of the booked slot rather than the upgrade job
+func reservationUpgrade(slot *model.JobUpgradeSlot) *reservation.Upgrade {
+	if slot == nil {
+		return nil
+	}
+	var platformConfirmation map[string]any
+	_ = json.Unmarshal([]byte(slot.Confirmation), &platformConfirmation)
+	return &reservation.Upgrade{
+		ReservationDate:      slot.ReservationDate,
+		PartySize:            slot.PartySize,
+		SlotTime:             slot.SlotTime,
+		SeatingType:          slot.SeatingType,
+		PlatformConfirmation: platformConfirmation,
+		CancellationCutOff:   slot.CancellationCutOff,
+	}
+}
+
 // Cancels a job
 func (s *Job) Cancel(ctx context.Context, jobId uuid.UUID) error {
 	err := s.cloudProvider.CancelJob(ctx, jobId)
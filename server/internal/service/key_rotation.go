@@ -0,0 +1,257 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/daylamtayari/cierge/api"
+	"github.com/daylamtayari/cierge/server/cloud"
+	appctx "github.com/daylamtayari/cierge/server/internal/context"
+	"github.com/daylamtayari/cierge/server/internal/model"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+var (
+	ErrKeyRotationInProgress = errors.New("a key rotation is already in progress")
+	ErrKeyRotationDNE        = errors.New("no key rotation has been started")
+	ErrInvalidBatchSize      = errors.New("batch size must be between 1 and 1000")
+)
+
+const (
+	defaultKeyRotationBatchSize = 100
+	maxKeyRotationBatchSize     = 1000
+
+	// Maximum number of failures that are retained in the progress report
+	// Failure counters remain accurate beyond this limit
+	maxKeyRotationFailures = 500
+)
+
+// Platform tokens re-encrypted by a key rotation
+// Implemented by the PlatformToken service
+type rotationTokens interface {
+	Count(ctx context.Context) (int64, error)
+	GetBatchAfter(ctx context.Context, afterID uuid.UUID, limit int) ([]*model.PlatformToken, error)
+	ReEncrypt(ctx context.Context, token *model.PlatformToken) (*model.PlatformToken, error)
+}
+
+// Scheduled jobs updated by a key rotation
+// Implemented by the Job service
+type rotationJobs interface {
+	GetScheduledByUserAndPlatform(ctx context.Context, userID uuid.UUID, platform string) ([]*model.Job, error)
+}
+
+// Re-encrypts every stored platform token under the cloud provider's current
+// encryption key and updates the credentials of all scheduled jobs, the
+// provider re-encrypting their callback secrets under the current key
+// NOTE: Only one rotation can run at a time and the progress of
+// the most recent rotation is kept in memory
+type KeyRotation struct {
+	ptService     rotationTokens
+	jobService    rotationJobs
+	cloudProvider cloud.Provider
+
+	mu       sync.Mutex
+	progress *api.KeyRotation
+}
+
+func NewKeyRotation(ptService *PlatformToken, jobService *Job, cloudProvider cloud.Provider) *KeyRotation {
+	return &KeyRotation{
+		ptService:     ptService,
+		jobService:    jobService,
+		cloudProvider: cloudProvider,
+	}
+}
+
+// Starts a key rotation in the background and returns its initial progress
+// A batch size of zero uses the default batch size
+func (s *KeyRotation) Start(ctx context.Context, batchSize int) (api.KeyRotation, error) {
+	if batchSize == 0 {
+		batchSize = defaultKeyRotationBatchSize
+	} else if batchSize < 0 || batchSize > maxKeyRotationBatchSize {
+		return api.KeyRotation{}, ErrInvalidBatchSize
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.progress != nil && s.progress.Status == api.KeyRotationStatusRunning {
+		return api.KeyRotation{}, ErrKeyRotationInProgress
+	}
+
+	totalTokens, err := s.ptService.Count(ctx)
+	if err != nil {
+		return api.KeyRotation{}, err
+	}
+
+	s.progress = &api.KeyRotation{
+		Status:      api.KeyRotationStatusRunning,
+		BatchSize:   batchSize,
+		TotalTokens: totalTokens,
+		Failures:    make([]api.KeyRotationFailure, 0),
+		StartedAt:   time.Now().UTC(),
+	}
+
+	// The rotation must outlive the request that started it
+	logger := appctx.Logger(ctx).With().Str("component", "key_rotation").Logger()
+	go s.run(context.WithoutCancel(ctx), logger, batchSize)
+
+	return s.snapshot(), nil
+}
+
+// Returns the progress of the current or most recent key rotation
+func (s *KeyRotation) Status() (api.KeyRotation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.progress == nil {
+		return api.KeyRotation{}, ErrKeyRotationDNE
+	}
+	return s.snapshot(), nil
+}
+
+// Iterates over all platform tokens in batches, re-encrypting each one
+func (s *KeyRotation) run(ctx context.Context, logger zerolog.Logger, batchSize int) {
+	logger.Info().Int("batch_size", batchSize).Msg("starting key rotation")
+
+	var lastID uuid.UUID
+	for {
+		tokens, err := s.ptService.GetBatchAfter(ctx, lastID, batchSize)
+		if err != nil {
+			logger.Error().Err(err).Stringer("after_token_id", lastID).Msg("failed to retrieve batch of platform tokens")
+			s.finish(err)
+			return
+		}
+		if len(tokens) == 0 {
+			break
+		}
+
+		for _, token := range tokens {
+			s.rotateToken(ctx, logger, token)
+		}
+		lastID = tokens[len(tokens)-1].ID
+
+		progress := s.snapshotLocked()
+		logger.Info().
+			Int64("processed_tokens", progress.ProcessedTokens).
+			Int64("total_tokens", progress.TotalTokens).
+			Int64("failed_tokens", progress.FailedTokens).
+			Int64("failed_jobs", progress.FailedJobs).
+			Msg("completed key rotation batch")
+	}
+
+	s.finish(nil)
+	progress := s.snapshotLocked()
+	logger.Info().
+		Int64("re_encrypted_tokens", progress.ReEncryptedTokens).
+		Int64("skipped_tokens", progress.SkippedTokens).
+		Int64("failed_tokens", progress.FailedTokens).
+		Int64("updated_jobs", progress.UpdatedJobs).
+		Int64("failed_jobs", progress.FailedJobs).
+		Msg("completed key rotation")
+}
+
+// Re-encrypts a single token and updates the credentials of its scheduled jobs
+func (s *KeyRotation) rotateToken(ctx context.Context, logger zerolog.Logger, token *model.PlatformToken) {
+	newToken, err := s.ptService.ReEncrypt(ctx, token)
+	if errors.Is(err, ErrTokenDNE) {
+		// Token was replaced since the batch was retrieved and
+		// is therefore already encrypted with the current key
+		s.record(func(p *api.KeyRotation) {
+			p.ProcessedTokens++
+			p.SkippedTokens++
+		})
+		return
+	} else if err != nil {
+		logger.Error().Err(err).
+			Str("platform", token.Platform).
+			Stringer("token_id", token.ID).
+			Stringer("user_id", token.UserID).
+			Msg("failed to re-encrypt platform token")
+		s.recordFailure(token.ID, nil, err)
+		s.record(func(p *api.KeyRotation) {
+			p.ProcessedTokens++
+			p.FailedTokens++
+		})
+		return
+	}
+	s.record(func(p *api.KeyRotation) {
+		p.ProcessedTokens++
+		p.ReEncryptedTokens++
+	})
+
+	jobs, err := s.jobService.GetScheduledByUserAndPlatform(ctx, token.UserID, token.Platform)
+	if err != nil {
+		logger.Error().Err(err).Stringer("token_id", token.ID).Msg("failed to retrieve scheduled jobs for platform token")
+		s.recordFailure(token.ID, nil, err)
+		s.record(func(p *api.KeyRotation) { p.FailedJobs++ })
+		return
+	}
+
+	for _, job := range jobs {
+		if err := s.cloudProvider.UpdateJobCredentials(ctx, job.ID, newToken.EncryptedToken); err != nil {
+			logger.Error().Err(err).Stringer("job_id", job.ID).Msg("failed to update job credentials")
+			s.recordFailure(token.ID, &job.ID, err)
+			s.record(func(p *api.KeyRotation) { p.FailedJobs++ })
+			continue
+		}
+		s.record(func(p *api.KeyRotation) { p.UpdatedJobs++ })
+	}
+}
+
+// Applies an update to the progress counters
+func (s *KeyRotation) record(update func(p *api.KeyRotation)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	update(s.progress)
+}
+
+// Adds a failure to the progress report
+func (s *KeyRotation) recordFailure(tokenID uuid.UUID, jobID *uuid.UUID, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.progress.Failures) >= maxKeyRotationFailures {
+		return
+	}
+	s.progress.Failures = append(s.progress.Failures, api.KeyRotationFailure{
+		TokenID: tokenID,
+		JobID:   jobID,
+		Error:   err.Error(),
+	})
+}
+
+// Marks the rotation as completed, or failed if an error is provided
+func (s *KeyRotation) finish(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	completedAt := time.Now().UTC()
+	s.progress.CompletedAt = &completedAt
+	if err != nil {
+		errMessage := err.Error()
+		s.progress.Status = api.KeyRotationStatusFailed
+		s.progress.Error = &errMessage
+	} else {
+		s.progress.Status = api.KeyRotationStatusCompleted
+	}
+}
+
+// Returns a copy of the progress while acquiring the mutex
+func (s *KeyRotation) snapshotLocked() api.KeyRotation {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.snapshot()
+}
+
+// Returns a copy of the progress
+// NOTE: Must be called with the mutex held
+func (s *KeyRotation) snapshot() api.KeyRotation {
+	progress := *s.progress
+	progress.Failures = slices.Clone(s.progress.Failures)
+	return progress
+}
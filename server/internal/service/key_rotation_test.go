@@ -0,0 +1,249 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/daylamtayari/cierge/api"
+	"github.com/daylamtayari/cierge/reservation"
+	appctx "github.com/daylamtayari/cierge/server/internal/context"
+	"github.com/daylamtayari/cierge/server/internal/model"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+// Platform tokens of a key rotation, ordered by ID, whose re-encryption
+// fails for tokens marked as failing and is skipped for replaced tokens
+type fakeRotationTokens struct {
+	tokens   []*model.PlatformToken
+	failing  map[uuid.UUID]bool
+	replaced map[uuid.UUID]bool
+
+	mu      sync.Mutex
+	batches []uuid.UUID
+}
+
+func (f *fakeRotationTokens) Count(ctx context.Context) (int64, error) {
+	return int64(len(f.tokens)), nil
+}
+
+func (f *fakeRotationTokens) GetBatchAfter(ctx context.Context, afterID uuid.UUID, limit int) ([]*model.PlatformToken, error) {
+	f.mu.Lock()
+	f.batches = append(f.batches, afterID)
+	f.mu.Unlock()
+
+	start := 0
+	if afterID != uuid.Nil {
+		start = slices.IndexFunc(f.tokens, func(t *model.PlatformToken) bool { return t.ID == afterID }) + 1
+	}
+	return f.tokens[start:min(start+limit, len(f.tokens))], nil
+}
+
+func (f *fakeRotationTokens) ReEncrypt(ctx context.Context, token *model.PlatformToken) (*model.PlatformToken, error) {
+	switch {
+	case f.failing[token.ID]:
+		return nil, errors.New("decryption failed")
+	case f.replaced[token.ID]:
+		return nil, ErrTokenDNE
+	}
+	reEncrypted := *token
+	reEncrypted.EncryptedToken = "new:" + token.EncryptedToken
+	return &reEncrypted, nil
+}
+
+// Scheduled jobs of the users of a key rotation
+type fakeRotationJobs struct {
+	jobs map[uuid.UUID][]*model.Job
+}
+
+func (f *fakeRotationJobs) GetScheduledByUserAndPlatform(ctx context.Context, userID uuid.UUID, platform string) ([]*model.Job, error) {
+	return f.jobs[userID], nil
+}
+
+// Cloud provider recording the credential updates of scheduled jobs
+// Updates of jobs marked as missing fail
+type fakeRotationProvider struct {
+	missing map[uuid.UUID]bool
+
+	mu      sync.Mutex
+	updated map[uuid.UUID]string
+}
+
+func (p *fakeRotationProvider) ScheduleJob(ctx context.Context, event reservation.Event) error {
+	return nil
+}
+
+func (p *fakeRotationProvider) UpdateJobCredentials(ctx context.Context, jobID uuid.UUID, encryptedToken string) error {
+	if p.missing[jobID] {
+		return errors.New("job was not found")
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.updated[jobID] = encryptedToken
+	return nil
+}
+
+func (p *fakeRotationProvider) CancelJob(ctx context.Context, jobID uuid.UUID) error {
+	return nil
+}
+
+func (p *fakeRotationProvider) ListScheduledJobs(ctx context.Context) ([]uuid.UUID, error) {
+	return nil, nil
+}
+
+func (p *fakeRotationProvider) EncryptData(ctx context.Context, plaintext string) (string, error) {
+	return plaintext, nil
+}
+
+func (p *fakeRotationProvider) DecryptData(ctx context.Context, ciphertext string) (string, error) {
+	return ciphertext, nil
+}
+
+// Returns platform tokens with increasing IDs
+func newRotationTokens(n int) []*model.PlatformToken {
+	tokens := make([]*model.PlatformToken, n)
+	for i := range tokens {
+		id := uuid.UUID{}
+		id[14], id[15] = byte((i+1)>>8), byte(i+1)
+		tokens[i] = &model.PlatformToken{
+			ID:             id,
+			UserID:         uuid.New(),
+			Platform:       "resy",
+			EncryptedToken: "token-" + id.String(),
+		}
+	}
+	return tokens
+}
+
+// Waits for the rotation to complete and returns its progress
+func waitForRotation(t *testing.T, rotation *KeyRotation) api.KeyRotation {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		progress, err := rotation.Status()
+		if err != nil {
+			t.Fatalf("Status failed: %v", err)
+		}
+		if progress.Status != api.KeyRotationStatusRunning {
+			return progress
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatal("key rotation did not complete")
+	return api.KeyRotation{}
+}
+
+func TestKeyRotation(t *testing.T) {
+	tokens := newRotationTokens(5)
+	failedJob := &model.Job{ID: uuid.New()}
+	updatedJobs := []*model.Job{{ID: uuid.New()}, {ID: uuid.New()}}
+
+	ptService := &fakeRotationTokens{
+		tokens:   tokens,
+		failing:  map[uuid.UUID]bool{tokens[1].ID: true},
+		replaced: map[uuid.UUID]bool{tokens[3].ID: true},
+	}
+	jobService := &fakeRotationJobs{jobs: map[uuid.UUID][]*model.Job{
+		tokens[0].UserID: updatedJobs,
+		// Jobs of tokens that were not re-encrypted are not updated
+		tokens[1].UserID: {{ID: uuid.New()}},
+		tokens[4].UserID: {failedJob},
+	}}
+	provider := &fakeRotationProvider{
+		missing: map[uuid.UUID]bool{failedJob.ID: true},
+		updated: make(map[uuid.UUID]string),
+	}
+	rotation := &KeyRotation{ptService: ptService, jobService: jobService, cloudProvider: provider}
+
+	logger := zerolog.Nop()
+	ctx := appctx.WithLogger(context.Background(), &logger)
+	started, err := rotation.Start(ctx, 2)
+	if err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	if started.TotalTokens != 5 || started.BatchSize != 2 {
+		t.Errorf("started with %d tokens in batches of %d, want 5 in batches of 2", started.TotalTokens, started.BatchSize)
+	}
+
+	progress := waitForRotation(t, rotation)
+	if progress.Status != api.KeyRotationStatusCompleted || progress.CompletedAt == nil {
+		t.Fatalf("got status %q, want %q", progress.Status, api.KeyRotationStatusCompleted)
+	}
+
+	// Batches are retrieved after the last token of the previous batch
+	wantBatches := []uuid.UUID{uuid.Nil, tokens[1].ID, tokens[3].ID, tokens[4].ID}
+	if !slices.Equal(ptService.batches, wantBatches) {
+		t.Errorf("batches after %v, want %v", ptService.batches, wantBatches)
+	}
+
+	want := api.KeyRotation{
+		ProcessedTokens:   5,
+		ReEncryptedTokens: 3,
+		SkippedTokens:     1,
+		FailedTokens:      1,
+		UpdatedJobs:       2,
+		FailedJobs:        1,
+	}
+	if progress.ProcessedTokens != want.ProcessedTokens || progress.ReEncryptedTokens != want.ReEncryptedTokens ||
+		progress.SkippedTokens != want.SkippedTokens || progress.FailedTokens != want.FailedTokens ||
+		progress.UpdatedJobs != want.UpdatedJobs || progress.FailedJobs != want.FailedJobs {
+		t.Errorf("got counters %+v, want %+v", progress, want)
+	}
+
+	for _, job := range updatedJobs {
+		if got := provider.updated[job.ID]; got != "new:"+tokens[0].EncryptedToken {
+			t.Errorf("job %s updated with %q, want the re-encrypted token", job.ID, got)
+		}
+	}
+
+	if len(progress.Failures) != 2 {
+		t.Fatalf("got %d failures, want 2", len(progress.Failures))
+	}
+	tokenFailure, jobFailure := progress.Failures[0], progress.Failures[1]
+	if tokenFailure.TokenID != tokens[1].ID || tokenFailure.JobID != nil || tokenFailure.Error == "" {
+		t.Errorf("unexpected token failure %+v", tokenFailure)
+	}
+	if jobFailure.TokenID != tokens[4].ID || jobFailure.JobID == nil || *jobFailure.JobID != failedJob.ID {
+		t.Errorf("unexpected job failure %+v", jobFailure)
+	}
+}
+
+func TestKeyRotation_InvalidBatchSize(t *testing.T) {
+	rotation := &KeyRotation{ptService: &fakeRotationTokens{}, jobService: &fakeRotationJobs{}}
+
+	for _, batchSize := range []int{-1, maxKeyRotationBatchSize + 1} {
+		if _, err := rotation.Start(context.Background(), batchSize); !errors.Is(err, ErrInvalidBatchSize) {
+			t.Errorf("batch size %d: got %v, want %v", batchSize, err, ErrInvalidBatchSize)
+		}
+	}
+	if _, err := rotation.Status(); !errors.Is(err, ErrKeyRotationDNE) {
+		t.Errorf("status without rotation: got %v, want %v", err, ErrKeyRotationDNE)
+	}
+}
+
+func TestKeyRotation_FailureLimit(t *testing.T) {
+	tokens := newRotationTokens(maxKeyRotationFailures + 10)
+	failing := make(map[uuid.UUID]bool, len(tokens))
+	for _, token := range tokens {
+		failing[token.ID] = true
+	}
+	rotation := &KeyRotation{
+		ptService:  &fakeRotationTokens{tokens: tokens, failing: failing},
+		jobService: &fakeRotationJobs{},
+	}
+
+	logger := zerolog.Nop()
+	if _, err := rotation.Start(appctx.WithLogger(context.Background(), &logger), maxKeyRotationBatchSize); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	progress := waitForRotation(t, rotation)
+
+	// Failure counters remain accurate beyond the retained failures
+	if progress.FailedTokens != int64(len(tokens)) || len(progress.Failures) != maxKeyRotationFailures {
+		t.Errorf("got %d failed tokens and %d failures, want %d and %d", progress.FailedTokens, len(progress.Failures), len(tokens), maxKeyRotationFailures)
+	}
+}
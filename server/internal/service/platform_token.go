@@ -6,7 +6,7 @@ import (
 	"errors"
 	"time"
 
-	"github.com/daylamtayari/cierge/resy"
+	"github.com/daylamtayari/cierge/reservation"
 	"github.com/daylamtayari/cierge/server/cloud"
 	"github.com/daylamtayari/cierge/server/internal/model"
 	"github.com/daylamtayari/cierge/server/internal/repository"
@@ -70,31 +70,67 @@ func (s *PlatformToken) GetExpiringWithRefresh(ctx context.Context, duration tim
 	return s.ptRepo.GetExpiringWithinWithRefresh(ctx, duration)
 }
 
-// Returns the decrypted refresh token for a given platform token
-func (s *PlatformToken) getDecryptedRefreshToken(ctx context.Context, token *model.PlatformToken) (string, error) {
+// Gets a batch of platform tokens with an ID greater than a given ID
+func (s *PlatformToken) GetBatchAfter(ctx context.Context, afterID uuid.UUID, limit int) ([]*model.PlatformToken, error) {
+	return s.ptRepo.GetBatchAfter(ctx, afterID, limit)
+}
+
+// Gets the total number of platform tokens
+func (s *PlatformToken) Count(ctx context.Context) (int64, error) {
+	return s.ptRepo.Count(ctx)
+}
+
+// Re-encrypts a platform token using the cloud provider's current encryption key
+// Returns ErrTokenDNE if the token was replaced or deleted while being re-encrypted
+func (s *PlatformToken) ReEncrypt(ctx context.Context, token *model.PlatformToken) (*model.PlatformToken, error) {
+	decryptedToken, err := s.cloudProvider.DecryptData(ctx, token.EncryptedToken)
+	if err != nil {
+		return nil, err
+	}
+	encryptedToken, err := s.cloudProvider.EncryptData(ctx, decryptedToken)
+	if err != nil {
+		return nil, err
+	}
+
+	updated, err := s.ptRepo.SwapEncryptedToken(ctx, token.ID, token.EncryptedToken, encryptedToken)
+	if err != nil {
+		return nil, err
+	} else if !updated {
+		return nil, ErrTokenDNE
+	}
+
+	token.EncryptedToken = encryptedToken
+	return token, nil
+}
+
+// Returns the decrypted tokens of a given platform token
+func (s *PlatformToken) getDecryptedToken(ctx context.Context, token *model.PlatformToken) (any, error) {
+	platform, err := reservation.GetPlatform(token.Platform)
+	if err != nil {
+		return nil, ErrUnsupportedPlatform
+	}
+
 	decryptedToken, err := s.cloudProvider.DecryptData(ctx, token.EncryptedToken)
 	if err != nil {
-		return "", err
-	}
-
-	switch token.Platform {
-	case "resy":
-		var resyTokens resy.Tokens
-		if err := json.Unmarshal([]byte(decryptedToken), &resyTokens); err != nil {
-			return "", err
-		}
-		return resyTokens.Refresh, nil
-	case "opentable":
-		//TODO: Implement opentable
-		return "", ErrUnsupportedPlatform
-	default:
-		return "", ErrUnsupportedPlatform
+		return nil, err
 	}
+	return platform.ParseToken([]byte(decryptedToken))
 }
 
 // Creates a new token, replacing any existing one
 // Encrypts the token string and adds expiry and refresh values
-func (s *PlatformToken) Create(ctx context.Context, userID uuid.UUID, platform string, token any) (*model.PlatformToken, error) {
+func (s *PlatformToken) Create(ctx context.Context, userID uuid.UUID, platformName string, token any) (*model.PlatformToken, error) {
+	platform, err := reservation.GetPlatform(platformName)
+	if err != nil {
+		return nil, ErrUnsupportedPlatform
+	}
+	expiry, err := platform.TokenExpiry(token)
+	if errors.Is(err, reservation.ErrIncorrectToken) {
+		return nil, ErrIncorrectPlatform
+	} else if err != nil {
+		return nil, err
+	}
+
 	tokenString, err := json.Marshal(token)
 	if err != nil {
 		return nil, err
@@ -105,7 +141,7 @@ func (s *PlatformToken) Create(ctx context.Context, userID uuid.UUID, platform s
 	}
 
 	var existingTokenId *uuid.UUID
-	existingToken, err := s.ptRepo.GetByUserAndPlatform(ctx, userID, platform)
+	existingToken, err := s.ptRepo.GetByUserAndPlatform(ctx, userID, platformName)
 	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, err
 	} else if err == nil {
@@ -113,35 +149,12 @@ func (s *PlatformToken) Create(ctx context.Context, userID uuid.UUID, platform s
 	}
 
 	newToken := &model.PlatformToken{
-		UserID:         userID,
-		Platform:       platform,
-		EncryptedToken: encryptedToken,
-	}
-
-	switch platform {
-	case "resy":
-		resyToken, ok := token.(resy.Tokens)
-		if !ok {
-			return nil, ErrIncorrectPlatform
-		}
-
-		authExpires, err := resy.GetTokenExpiry(resyToken.Token)
-		if err != nil {
-			return nil, err
-		}
-		newToken.ExpiresAt = &authExpires
-
-		refreshExpiresAt, err := resy.GetTokenExpiry(resyToken.Refresh)
-		if err != nil {
-			return nil, err
-		}
-		newToken.HasRefresh = true
-		newToken.RefreshExpiresAt = &refreshExpiresAt
-
-	case "opentable":
-		// TODO: Implement opentable
-	default:
-		return nil, ErrUnsupportedPlatform
+		UserID:           userID,
+		Platform:         platformName,
+		EncryptedToken:   encryptedToken,
+		ExpiresAt:        expiry.ExpiresAt,
+		HasRefresh:       expiry.HasRefresh,
+		RefreshExpiresAt: expiry.RefreshExpiresAt,
 	}
 
 	err = s.ptRepo.Replace(ctx, existingTokenId, newToken)
@@ -166,22 +179,19 @@ func (s *PlatformToken) Delete(ctx context.Context, tokenId uuid.UUID) error {
 // NOTE: This does not handle updating any existing jobs with the new credentials so calling this method
 // outside of the token renewer will lead to jobs containing expired credentials
 func (s *PlatformToken) refreshToken(ctx context.Context, token *model.PlatformToken) (*model.PlatformToken, error) {
-	refreshToken, err := s.getDecryptedRefreshToken(ctx, token)
+	platform, err := reservation.GetPlatform(token.Platform)
+	if err != nil {
+		return nil, ErrUnsupportedPlatform
+	}
+
+	decryptedToken, err := s.getDecryptedToken(ctx, token)
 	if err != nil {
 		return nil, err
 	}
 
-	switch token.Platform {
-	case "resy":
-		resyClient := resy.NewClient(nil, resy.Tokens{}, "")
-		newTokens, err := resyClient.RefreshToken(refreshToken)
-		if err != nil {
-			return nil, err
-		}
-		return s.Create(ctx, token.UserID, token.Platform, newTokens)
-	case "opentable":
-		return nil, ErrUnsupportedPlatform
-	default:
-		return nil, ErrUnsupportedPlatform
+	newTokens, err := platform.RefreshToken(ctx, decryptedToken)
+	if err != nil {
+		return nil, err
 	}
+	return s.Create(ctx, token.UserID, token.Platform, newTokens)
 }
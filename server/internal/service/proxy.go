@@ -0,0 +1,41 @@
+package service
+
+import (
+	"context"
+
+	"github.com/daylamtayari/cierge/reservation"
+)
+
+// Proxies requests to reservation platforms on behalf of users
+type Proxy struct{}
+
+func NewProxy() *Proxy {
+	return &Proxy{}
+}
+
+// Sends a one-time login code to the email of a user of a platform logging in with codes
+func (s *Proxy) SendLoginCode(ctx context.Context, platformName string, email string) error {
+	platform, err := reservation.GetPlatform(platformName)
+	if err != nil {
+		return ErrUnsupportedPlatform
+	}
+	return platform.SendLoginCode(ctx, email)
+}
+
+// Logs in to a platform and returns the tokens of the user
+func (s *Proxy) Auth(ctx context.Context, platformName string, credentials reservation.Credentials) (any, error) {
+	platform, err := reservation.GetPlatform(platformName)
+	if err != nil {
+		return nil, ErrUnsupportedPlatform
+	}
+	return platform.Login(ctx, credentials)
+}
+
+// Searches the venues of a platform matching a query
+func (s *Proxy) Restaurant(ctx context.Context, platformName string, query string) ([]reservation.Venue, error) {
+	platform, err := reservation.GetPlatform(platformName)
+	if err != nil {
+		return nil, ErrUnsupportedPlatform
+	}
+	return platform.SearchVenues(ctx, query)
+}
@@ -0,0 +1,199 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/daylamtayari/cierge/server/cloud"
+	"github.com/daylamtayari/cierge/server/internal/config"
+	appctx "github.com/daylamtayari/cierge/server/internal/context"
+	"github.com/daylamtayari/cierge/server/internal/model"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+// Reconciles the scheduled jobs in the database with the
+// scheduled invocations of the cloud provider
+type Reconciler struct {
+	jobService    *Job
+	cloudProvider cloud.Provider
+	logger        zerolog.Logger
+	interval      time.Duration
+	minLeadTime   time.Duration
+}
+
+// Outcome of a reconciliation
+type ReconciliationReport struct {
+	ScheduledJobs int
+	ProviderJobs  int
+	// Scheduled jobs without an invocation that were scheduled again
+	Rescheduled []uuid.UUID
+	// Scheduled jobs without an invocation whose drop time is too close to be scheduled again
+	Unrecoverable []uuid.UUID
+	// Invocations of jobs that are not scheduled that were deleted
+	Deleted []uuid.UUID
+	Failed  int
+}
+
+func NewReconciler(jobService *Job, cloudProvider cloud.Provider, logger zerolog.Logger, cfg config.Reconciler) *Reconciler {
+	return &Reconciler{
+		jobService:    jobService,
+		cloudProvider: cloudProvider,
+		logger:        logger.With().Str("component", "reconciler").Logger(),
+		interval:      cfg.Interval.Duration(),
+		minLeadTime:   cfg.MinLeadTime.Duration(),
+	}
+}
+
+// Start a reconciler goroutine
+func (r *Reconciler) Start(ctx context.Context) {
+	go r.run(ctx)
+}
+
+// Runs the reconciler ticker
+func (r *Reconciler) run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			report, err := r.Reconcile(ctx)
+			if err != nil {
+				r.logger.Error().Err(err).Msg("failed to reconcile scheduled jobs")
+				continue
+			}
+			r.logReport(report)
+		}
+	}
+}
+
+// Reschedules scheduled jobs that are missing from the cloud provider
+// and deletes scheduled invocations of jobs that are no longer scheduled
+func (r *Reconciler) Reconcile(ctx context.Context) (ReconciliationReport, error) {
+	ctx = appctx.WithLogger(ctx, &r.logger)
+	var report ReconciliationReport
+
+	// Jobs are only marked as scheduled after their invocation is created
+	// so retrieving them first ensures they are present in the provider
+	jobs, err := r.jobService.GetScheduled(ctx)
+	if err != nil {
+		return report, err
+	}
+	providerJobIDs, err := r.cloudProvider.ListScheduledJobs(ctx)
+	if err != nil {
+		return report, err
+	}
+	report.ScheduledJobs = len(jobs)
+	report.ProviderJobs = len(providerJobIDs)
+
+	scheduled := make(map[uuid.UUID]bool, len(jobs))
+	for _, job := range jobs {
+		scheduled[job.ID] = true
+	}
+	invoked := make(map[uuid.UUID]bool, len(providerJobIDs))
+	for _, jobID := range providerJobIDs {
+		invoked[jobID] = true
+	}
+
+	for _, job := range jobs {
+		if invoked[job.ID] {
+			continue
+		}
+		// Jobs that are about to drop or have dropped may have been
+		// invoked already and are left to report their outcome
+		if time.Until(job.ScheduledAt) < r.minLeadTime {
+			report.Unrecoverable = append(report.Unrecoverable, job.ID)
+			continue
+		}
+
+		if err := r.reschedule(ctx, job); err != nil {
+			r.logger.Error().Err(err).Stringer("job_id", job.ID).Msg("failed to reschedule job")
+			report.Failed++
+			continue
+		}
+		r.logger.Info().Stringer("job_id", job.ID).Msg("rescheduled job missing from cloud provider")
+		report.Rescheduled = append(report.Rescheduled, job.ID)
+	}
+
+	for _, jobID := range providerJobIDs {
+		if scheduled[jobID] {
+			continue
+		}
+
+		orphaned, err := r.isOrphaned(ctx, jobID)
+		if err != nil {
+			r.logger.Error().Err(err).Stringer("job_id", jobID).Msg("failed to retrieve job of scheduled invocation")
+			report.Failed++
+			continue
+		} else if !orphaned {
+			continue
+		}
+
+		if err := r.cloudProvider.CancelJob(ctx, jobID); err != nil {
+			r.logger.Error().Err(err).Stringer("job_id", jobID).Msg("failed to delete orphaned scheduled invocation")
+			report.Failed++
+			continue
+		}
+		r.logger.Info().Stringer("job_id", jobID).Msg("deleted orphaned scheduled invocation")
+		report.Deleted = append(report.Deleted, jobID)
+	}
+
+	return report, nil
+}
+
+// Schedules a job again with the cloud provider
+func (r *Reconciler) reschedule(ctx context.Context, job *model.Job) error {
+	if job.Restaurant == nil {
+		return ErrRestaurantDNE
+	}
+	return r.jobService.Schedule(ctx, job, job.Restaurant)
+}
+
+// Returns whether the scheduled invocation of a job is orphaned,
+// that is the job does not exist or has already been resolved
+// Jobs that are being created are not considered orphaned
+func (r *Reconciler) isOrphaned(ctx context.Context, jobID uuid.UUID) (bool, error) {
+	job, err := r.jobService.GetByID(ctx, jobID)
+	if errors.Is(err, ErrJobDNE) {
+		return true, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	switch job.Status {
+	case model.JobStatusSuccess, model.JobStatusFailed, model.JobStatusCancelled:
+		return true, nil
+	default:
+		return false, nil
+	}
+}
+
+// Logs the report of a reconciliation
+func (r *Reconciler) logReport(report ReconciliationReport) {
+	event := r.logger.Info()
+	if report.Failed > 0 || len(report.Unrecoverable) > 0 {
+		event = r.logger.Warn()
+	}
+
+	event.
+		Int("scheduled_jobs", report.ScheduledJobs).
+		Int("provider_jobs", report.ProviderJobs).
+		Int("rescheduled", len(report.Rescheduled)).
+		Int("deleted", len(report.Deleted)).
+		Int("failed", report.Failed).
+		Array("unrecoverable", uuidArray(report.Unrecoverable)).
+		Msg("reconciled scheduled jobs")
+}
+
+// Marshals a slice of UUIDs as a zerolog array
+type uuidArray []uuid.UUID
+
+func (a uuidArray) MarshalZerologArray(arr *zerolog.Array) {
+	for _, id := range a {
+		arr.Str(id.String())
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"time"
 
+	"github.com/daylamtayari/cierge/api"
 	"github.com/daylamtayari/cierge/server/internal/model"
 	"github.com/daylamtayari/cierge/server/internal/repository"
 	"github.com/google/uuid"
@@ -55,7 +56,17 @@ func (s *Reservation) GetByUserUpcoming(ctx context.Context, userID uuid.UUID) (
 }
 
 // Create a reservation from a provided job
+// If a reservation was already created from the job, it is returned instead
+// Reservations are unique per job so that concurrent deliveries of the outcome
+// of a job, such as a retried callback and the sweeper, create a single reservation
 func (s *Reservation) CreateFromJob(ctx context.Context, job *model.Job) (*model.Reservation, error) {
+	existing, err := s.reservationRepo.GetByJobID(ctx, job.ID)
+	if err == nil {
+		return existing, nil
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
 	timezone := time.UTC
 	if job.Restaurant.Timezone != nil {
 		timezone = job.Restaurant.Timezone.Location
@@ -63,7 +74,17 @@ func (s *Reservation) CreateFromJob(ctx context.Context, job *model.Job) (*model
 		// TODO: Attempt to fetch the timezone of the restaurant, store it and use it here (sounds like a function for the restaurant service)
 	}
 
-	parsedDate, _ := time.Parse("2006-01-02", string(job.ReservationDate))
+	// The booked alternative takes precedence over the reservation date and party size
+	reservationDate := job.ReservationDate
+	if job.ReservedDate != nil {
+		reservationDate = *job.ReservedDate
+	}
+	partySize := job.PartySize
+	if job.ReservedPartySize != nil {
+		partySize = *job.ReservedPartySize
+	}
+
+	parsedDate, _ := time.Parse("2006-01-02", string(reservationDate))
 
 	res := model.Reservation{
 		JobID:        &job.ID,
@@ -76,8 +97,40 @@ func (s *Reservation) CreateFromJob(ctx context.Context, job *model.Job) (*model
 			job.ReservedTime.Hour(), job.ReservedTime.Minute(), job.ReservedTime.Second(), job.ReservedTime.Nanosecond(),
 			timezone,
 		),
-		PartySize: job.PartySize,
+		PartySize: partySize,
+		Status:    model.ReservationStatusBooked,
+	}
+
+	booked := api.ReservationAuditEntry{
+		Time:    time.Now().UTC(),
+		Action:  api.ReservationAuditBooked,
+		Message: "reservation booked by job",
+		JobID:   &job.ID,
 	}
+	if job.Type == model.JobTypeUpgrade && job.UpgradeReservationID != nil {
+		booked.Message = "reservation booked by upgrade job as an upgrade of reservation " + job.UpgradeReservationID.String()
+		booked.RelatedReservationID = job.UpgradeReservationID
+	}
+	res.Audit = model.ReservationAudit{booked}
+
+	created, err := s.reservationRepo.CreateForJob(ctx, &res)
+	if err != nil {
+		return nil, err
+	} else if !created {
+		// Created concurrently since the reservation was looked up
+		return s.reservationRepo.GetByJobID(ctx, job.ID)
+	}
+	return &res, nil
+}
+
+// Adds an entry to the audit of a reservation
+func (s *Reservation) AddAudit(ctx context.Context, res *model.Reservation, entry api.ReservationAuditEntry) error {
+	res.Audit = append(res.Audit, entry)
+	return s.reservationRepo.Update(ctx, res)
+}
 
-	return &res, s.reservationRepo.Create(ctx, &res)
+// Marks a reservation as cancelled and adds the cancellation to its audit
+func (s *Reservation) MarkCancelled(ctx context.Context, res *model.Reservation, entry api.ReservationAuditEntry) error {
+	res.Status = model.ReservationStatusCancelled
+	return s.AddAudit(ctx, res, entry)
 }
@@ -3,9 +3,11 @@ package service
 import (
 	"context"
 	"errors"
-	"strconv"
+	"slices"
+	"strings"
+	"time"
 
-	"github.com/daylamtayari/cierge/resy"
+	"github.com/daylamtayari/cierge/reservation"
 	"github.com/daylamtayari/cierge/server/internal/model"
 	"github.com/daylamtayari/cierge/server/internal/repository"
 	"github.com/google/uuid"
@@ -16,15 +18,21 @@ var (
 	ErrRestaurantDNE = errors.New("restaurant does not exist")
 )
 
+const (
+	// Duration for which the discovered seating types of a restaurant are
+	// returned before they are discovered again
+	seatingTypeDiscoveryInterval = 24 * time.Hour
+	// Party size of the slots from which seating types are discovered
+	seatingTypeDiscoveryPartySize = 2
+)
+
 type Restaurant struct {
 	restaurantRepo *repository.Restaurant
-	resyClient     *resy.Client
 }
 
-func NewRestaurant(restaurantRepo *repository.Restaurant, resyClient *resy.Client) *Restaurant {
+func NewRestaurant(restaurantRepo *repository.Restaurant) *Restaurant {
 	return &Restaurant{
 		restaurantRepo: restaurantRepo,
-		resyClient:     resyClient,
 	}
 }
 
@@ -32,7 +40,7 @@ func NewRestaurant(restaurantRepo *repository.Restaurant, resyClient *resy.Clien
 func (s *Restaurant) GetByID(ctx context.Context, restaurantId uuid.UUID) (*model.Restaurant, error) {
 	restaurant, err := s.restaurantRepo.GetByID(ctx, restaurantId)
 	if err != nil && errors.Is(err, gorm.ErrRecordNotFound) {
-		return nil, ErrReservationDNE
+		return nil, ErrRestaurantDNE
 	} else if err != nil {
 		return nil, err
 	}
@@ -50,50 +58,81 @@ func (s *Restaurant) GetByPlatformID(ctx context.Context, platform string, platf
 	return restaurant, nil
 }
 
-// Create a restaurant
-func (s *Restaurant) Create(ctx context.Context, platform string, platformID string) (*model.Restaurant, error) {
+// Create a restaurant from the details of its venue on its platform
+func (s *Restaurant) Create(ctx context.Context, platformName string, platformID string) (*model.Restaurant, error) {
+	platform, err := reservation.GetPlatform(platformName)
+	if err != nil {
+		return nil, ErrUnsupportedPlatform
+	}
+	venue, err := platform.GetVenue(ctx, platformID)
+	if err != nil {
+		return nil, err
+	}
+
 	restaurant := model.Restaurant{
-		Platform:   platform,
+		Platform:   platformName,
 		PlatformID: platformID,
+		Name:       venue.Name,
+		Address:    venue.Address,
+	}
+	if venue.Timezone != nil {
+		restaurant.Timezone = &model.Timezone{Location: venue.Timezone}
+	}
+	city := venue.Neighborhood
+	if city == "" {
+		city = venue.City
+	}
+	if city != "" {
+		restaurant.City = &city
+	}
+	if venue.Region != "" {
+		restaurant.State = &venue.Region
+	}
+
+	if err := s.restaurantRepo.Create(ctx, &restaurant); err != nil {
+		return nil, err
 	}
-	switch platform {
-	case "resy":
-		resyVenueId, err := strconv.Atoi(platformID)
-		if err != nil {
-			return nil, err
-		}
-		venue, err := s.resyClient.GetVenue(resyVenueId)
-		if err != nil {
-			return nil, err
-		}
 
-		restaurant.Name = venue.Name
-		restaurant.Timezone = &model.Timezone{Location: venue.Locale.Timezone.Location}
+	return &restaurant, nil
+}
 
-		var addressStr string
-		if venue.Location.Address1 != nil {
-			addressStr = *venue.Location.Address1
-		}
-		if venue.Location.Address2 != nil {
-			if addressStr != "" {
-				addressStr += " " + *venue.Location.Address2
-			} else {
-				addressStr = *venue.Location.Address2
-			}
-		}
-		if addressStr != "" {
-			restaurant.Address = &addressStr
-		}
-		restaurant.City = &venue.Location.Neighborhood
-		restaurant.State = &venue.Location.Region
+// Retrieves the known seating types of a restaurant
+// Seating types are discovered from the slots of the upcoming available dates of the
+// restaurant, at most once per discovery interval as discovery makes multiple platform
+// requests, and stored so that seating types that are not currently available are
+// still returned
+// Seating types are known per restaurant rather than per party size, so discovery
+// is always done for the discovery party size
+func (s *Restaurant) GetSeatingTypes(ctx context.Context, restaurant *model.Restaurant) ([]string, error) {
+	seatingTypes := append([]string{}, restaurant.SeatingTypes...)
+	if restaurant.SeatingTypesDiscoveredAt != nil && time.Since(*restaurant.SeatingTypesDiscoveredAt) < seatingTypeDiscoveryInterval {
+		return seatingTypes, nil
+	}
 
-	case "opentable":
-		// TODO: Implement opentable
+	platform, err := reservation.GetPlatform(restaurant.Platform)
+	if err != nil {
+		return nil, ErrUnsupportedPlatform
+	}
+	discovered, err := platform.GetSeatingTypes(ctx, restaurant.PlatformID, seatingTypeDiscoveryPartySize)
+	if err != nil {
+		return nil, err
 	}
 
-	if err := s.restaurantRepo.Create(ctx, &restaurant); err != nil {
+	for _, seatingType := range discovered {
+		if !slices.ContainsFunc(seatingTypes, func(known string) bool {
+			return strings.EqualFold(known, seatingType)
+		}) {
+			seatingTypes = append(seatingTypes, seatingType)
+		}
+	}
+	slices.Sort(seatingTypes)
+
+	discoveredAt := time.Now().UTC()
+	if err := s.restaurantRepo.UpdateSeatingTypes(ctx, restaurant.ID, seatingTypes, discoveredAt); err != nil {
 		return nil, err
 	}
+	restaurant.SeatingTypes = seatingTypes
+	restaurant.SeatingTypesDiscoveredAt = &discoveredAt
 
-	return &restaurant, nil
+	return seatingTypes, nil
 }
@@ -3,7 +3,6 @@ package service
 import (
 	"errors"
 
-	"github.com/daylamtayari/cierge/resy"
 	"github.com/daylamtayari/cierge/server/cloud"
 	"github.com/daylamtayari/cierge/server/internal/config"
 	"github.com/daylamtayari/cierge/server/internal/repository"
@@ -21,28 +20,36 @@ type Services struct {
 	Auth          *Auth
 	Job           *Job
 	Reservation   *Reservation
+	Upgrade       *Upgrade
+	Callback      *Callback
 	Restaurant    *Restaurant
 	PlatformToken *PlatformToken
 	DropConfig    *DropConfig
-	ProxyResy     *ProxyResy
+	Proxy         *Proxy
+	KeyRotation   *KeyRotation
 }
 
 func New(repos *repository.Repositories, cfg *config.Config, tokenStore *tokenstore.Store, cloudProvider cloud.Provider) *Services {
-	resyClient := resy.NewClient(nil, resy.Tokens{ApiKey: resy.DefaultApiKey}, "")
 	userService := NewUser(repos.User)
 	tokenService := NewToken(userService, repos.Job, cfg.Auth, tokenStore)
 	platformTokenService := NewPlatformToken(repos.PlatformToken, cloudProvider)
+	jobService := NewJob(repos.Job, platformTokenService, tokenService, cloudProvider, cfg.Server.ExternalURL(), cfg.WarmUp)
+	reservationService := NewReservation(repos.Reservation)
+	upgradeService := NewUpgrade(jobService, reservationService)
 
 	return &Services{
 		User:          userService,
 		Token:         tokenService,
 		Health:        NewHealth(repos.DB(), repos.Timeout()),
 		Auth:          NewAuth(userService, tokenService, &cfg.Auth),
-		Job:           NewJob(repos.Job, platformTokenService, tokenService, cloudProvider, cfg.Server.ExternalURL()),
-		Reservation:   NewReservation(repos.Reservation),
-		Restaurant:    NewRestaurant(repos.Restaurant, resyClient),
+		Job:           jobService,
+		Reservation:   reservationService,
+		Upgrade:       upgradeService,
+		Callback:      NewCallback(repos, jobService, reservationService, upgradeService),
+		Restaurant:    NewRestaurant(repos.Restaurant),
 		PlatformToken: platformTokenService,
 		DropConfig:    NewDropConfig(repos.DropConfig, repos.Restaurant),
-		ProxyResy:     NewProxyResy(resyClient),
+		Proxy:         NewProxy(),
+		KeyRotation:   NewKeyRotation(platformTokenService, jobService, cloudProvider),
 	}
 }
@@ -0,0 +1,173 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/daylamtayari/cierge/reservation"
+	"github.com/daylamtayari/cierge/server/cloud"
+	"github.com/daylamtayari/cierge/server/internal/config"
+	appctx "github.com/daylamtayari/cierge/server/internal/context"
+	"github.com/daylamtayari/cierge/server/internal/model"
+	"github.com/rs/zerolog"
+)
+
+const staleJobErrorMessage = "no callback was received from the job and its output could not be retrieved"
+
+// Resolves scheduled jobs that never received a callback, either from
+// the output reported by the cloud provider or by marking them as failed
+// Outputs stored in the outbox by executors that could not notify the server
+// are ingested before resolving stale jobs
+type Sweeper struct {
+	jobService      *Job
+	callbackService *Callback
+	cloudProvider   cloud.Provider
+	outbox          *reservation.FileOutbox
+	logger          zerolog.Logger
+	interval        time.Duration
+	gracePeriod     time.Duration
+}
+
+func NewSweeper(jobService *Job, callbackService *Callback, cloudProvider cloud.Provider, logger zerolog.Logger, cfg config.Sweeper) (*Sweeper, error) {
+	sweeper := &Sweeper{
+		jobService:      jobService,
+		callbackService: callbackService,
+		cloudProvider:   cloudProvider,
+		logger:          logger.With().Str("component", "sweeper").Logger(),
+		interval:        cfg.Interval.Duration(),
+		gracePeriod:     cfg.GracePeriod.Duration(),
+	}
+
+	if cfg.OutboxPath != "" {
+		outbox, err := reservation.NewFileOutbox(cfg.OutboxPath)
+		if err != nil {
+			return nil, err
+		}
+		sweeper.outbox = outbox
+	}
+	return sweeper, nil
+}
+
+// Start a sweeper goroutine
+func (s *Sweeper) Start(ctx context.Context) {
+	go s.run(ctx)
+}
+
+// Runs the sweeper ticker
+func (s *Sweeper) run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.sweep(ctx)
+		}
+	}
+}
+
+// Resolves all jobs whose scheduled time is past the grace period
+// and that have not received a callback
+func (s *Sweeper) sweep(ctx context.Context) {
+	ctx = appctx.WithLogger(ctx, &s.logger)
+
+	if s.outbox != nil {
+		s.ingestOutbox(ctx)
+	}
+
+	jobs, err := s.jobService.GetStale(ctx, time.Now().UTC().Add(-s.gracePeriod))
+	if err != nil {
+		s.logger.Error().Err(err).Msg("failed to retrieve stale jobs")
+		return
+	}
+
+	for _, job := range jobs {
+		recovered, err := s.resolve(ctx, job)
+		if err != nil {
+			s.logger.Error().Err(err).Stringer("job_id", job.ID).Msg("failed to resolve stale job")
+			continue
+		}
+		if recovered {
+			s.logger.Info().Stringer("job_id", job.ID).Str("status", string(job.Status)).Msg("resolved stale job from its reported output")
+		} else {
+			s.logger.Warn().Stringer("job_id", job.ID).Msg("marked stale job as failed")
+		}
+	}
+}
+
+// Updates the jobs of the outputs stored in the outbox and removes them
+// Outputs of jobs that were already callbacked or no longer exist are discarded
+func (s *Sweeper) ingestOutbox(ctx context.Context) {
+	outputs, err := s.outbox.List()
+	if err != nil {
+		s.logger.Error().Err(err).Msg("failed to list outputs in outbox")
+		return
+	}
+
+	for _, output := range outputs {
+		job, err := s.jobService.GetByID(ctx, output.JobId)
+		if err != nil && !errors.Is(err, ErrJobDNE) {
+			s.logger.Error().Err(err).Stringer("job_id", output.JobId).Msg("failed to retrieve job of outbox output")
+			continue
+		}
+
+		if job != nil && !job.Callbacked {
+			if err := s.applyOutput(ctx, job, output); err != nil {
+				s.logger.Error().Err(err).Stringer("job_id", job.ID).Msg("failed to update job from outbox output")
+				continue
+			}
+			s.logger.Info().Stringer("job_id", job.ID).Str("status", string(job.Status)).Msg("ingested job output from outbox")
+		}
+
+		if err := s.outbox.Remove(output.JobId); err != nil {
+			s.logger.Error().Err(err).Stringer("job_id", output.JobId).Msg("failed to remove output from outbox")
+		}
+	}
+}
+
+// Resolves a stale job from the output reported by the cloud provider
+// if available, otherwise the job is marked as failed
+// Jobs whose output could not be retrieved are also marked as failed, with the
+// error of the provider, as they are past the grace period and would otherwise
+// remain scheduled if the provider keeps failing
+// Returns whether the output of the job was recovered
+func (s *Sweeper) resolve(ctx context.Context, job *model.Job) (bool, error) {
+	output, err := s.jobOutput(ctx, job)
+	if errors.Is(err, cloud.ErrOutputNotFound) {
+		return false, s.jobService.MarkFailed(ctx, job, staleJobErrorMessage)
+	} else if err != nil {
+		s.logger.Warn().Err(err).Stringer("job_id", job.ID).Msg("failed to retrieve output of stale job")
+		return false, s.jobService.MarkFailed(ctx, job, staleJobErrorMessage+": "+err.Error())
+	}
+
+	return true, s.applyOutput(ctx, job, output)
+}
+
+// Handles the output of a job as its callback
+// Outputs of jobs that received their callback in the meantime are discarded
+func (s *Sweeper) applyOutput(ctx context.Context, job *model.Job, output reservation.Output) error {
+	_, err := s.callbackService.Handle(ctx, job, output)
+	return err
+}
+
+// Retrieves the output of a job from the cloud provider
+// Returned error wraps cloud.ErrOutputNotFound if the provider
+// cannot report outputs or has no output for the job
+func (s *Sweeper) jobOutput(ctx context.Context, job *model.Job) (reservation.Output, error) {
+	reporter, ok := s.cloudProvider.(cloud.OutputReporter)
+	if !ok {
+		return reservation.Output{}, cloud.ErrOutputNotFound
+	}
+
+	output, err := reporter.GetJobOutput(ctx, job.ID, job.ScheduledAt)
+	if err != nil {
+		return output, err
+	}
+	if output.JobId != job.ID {
+		return output, cloud.ErrOutputNotFound
+	}
+	return output, nil
+}
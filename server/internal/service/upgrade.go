@@ -0,0 +1,165 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/daylamtayari/cierge/api"
+	"github.com/daylamtayari/cierge/reservation"
+	"github.com/daylamtayari/cierge/server/internal/model"
+)
+
+// Minimum duration of an upgrade job, shorter upgrades are skipped
+const minUpgradeDuration = 5 * time.Minute
+
+// Upgrades the reservations booked by jobs with an upgrade to more preferred slots
+// An upgrade job watches for slots more preferred than the booked slot, books one
+// and only then cancels the booked slot, which is only done while the booked slot
+// can be cancelled without a fee
+// The outcome of upgrade jobs is recorded in the audit of both reservations
+type Upgrade struct {
+	jobService         *Job
+	reservationService *Reservation
+}
+
+func NewUpgrade(jobService *Job, reservationService *Reservation) *Upgrade {
+	return &Upgrade{
+		jobService:         jobService,
+		reservationService: reservationService,
+	}
+}
+
+// Handles a job updated from its output, recording the outcome of upgrade jobs
+// and scheduling the upgrade of the reservation of successful jobs with an upgrade
+// Reservation is the reservation created from the job and is nil if none was created
+func (s *Upgrade) HandleJob(ctx context.Context, job *model.Job, res *model.Reservation, output reservation.Output) error {
+	if output.DryRun {
+		return nil
+	}
+	if job.Type == model.JobTypeUpgrade {
+		if err := s.recordOutcome(ctx, job, res, output); err != nil {
+			return err
+		}
+	}
+	if job.Status != model.JobStatusSuccess || res == nil || job.UpgradeUntil == nil {
+		return nil
+	}
+	return s.schedule(ctx, job, res)
+}
+
+// Schedules an upgrade job for the reservation of a job
+// Upgrades end at the upgrade deadline of the job, the cancellation cut off of the
+// booked slot or the reservation, whichever is first, and are skipped if the booked
+// slot cannot be cancelled without a fee or is the most preferred slot
+func (s *Upgrade) schedule(ctx context.Context, job *model.Job, res *model.Reservation) error {
+	until := *job.UpgradeUntil
+	if job.ReservedDeposit != nil {
+		if job.ReservedCancellationCutOff == nil {
+			return s.skip(ctx, job, res, "booked slot has a deposit and no cancellation cut off")
+		}
+		until = minTime(until, *job.ReservedCancellationCutOff)
+	}
+	until = minTime(until, res.ReservationAt)
+
+	if time.Until(until) < minUpgradeDuration {
+		return s.skip(ctx, job, res, "upgrade would end before it could run")
+	}
+	if isMostPreferred(job) {
+		return s.skip(ctx, job, res, "booked slot is the most preferred slot")
+	}
+
+	upgradeJob, err := s.jobService.CreateUpgrade(ctx, job, res, until)
+	if err != nil {
+		return err
+	}
+	if err := s.jobService.Schedule(ctx, upgradeJob, job.Restaurant); err != nil {
+		return err
+	}
+	if err := s.jobService.UpdateStatus(ctx, model.JobStatusScheduled, upgradeJob.ID); err != nil {
+		return err
+	}
+
+	return s.reservationService.AddAudit(ctx, res, api.ReservationAuditEntry{
+		Time:    time.Now().UTC(),
+		Action:  api.ReservationAuditUpgradeScheduled,
+		Message: "upgrade job scheduled until " + until.UTC().Format(time.RFC3339),
+		JobID:   &upgradeJob.ID,
+	})
+}
+
+// Records why the reservation of a job is not upgraded in its audit
+func (s *Upgrade) skip(ctx context.Context, job *model.Job, res *model.Reservation, reason string) error {
+	return s.reservationService.AddAudit(ctx, res, api.ReservationAuditEntry{
+		Time:    time.Now().UTC(),
+		Action:  api.ReservationAuditUpgradeSkipped,
+		Message: reason,
+		JobID:   &job.ID,
+	})
+}
+
+// Records the outcome of an upgrade job in the audit of the upgraded reservation,
+// marking it as cancelled if a more preferred slot was booked and it was cancelled
+func (s *Upgrade) recordOutcome(ctx context.Context, job *model.Job, res *model.Reservation, output reservation.Output) error {
+	if job.UpgradeReservationID == nil {
+		return nil
+	}
+	upgraded, err := s.reservationService.GetByID(ctx, *job.UpgradeReservationID)
+	if err != nil {
+		return err
+	}
+
+	entry := api.ReservationAuditEntry{
+		Time:  time.Now().UTC(),
+		JobID: &job.ID,
+	}
+	if res != nil {
+		entry.RelatedReservationID = &res.ID
+	}
+
+	switch {
+	case job.Status == model.JobStatusSuccess && output.Upgrade != nil && output.Upgrade.Cancelled:
+		entry.Action = api.ReservationAuditUpgraded
+		entry.Message = "reservation cancelled after a more preferred slot was booked"
+		return s.reservationService.MarkCancelled(ctx, upgraded, entry)
+	case job.Status == model.JobStatusSuccess:
+		entry.Action = api.ReservationAuditCancelFailed
+		entry.Message = "more preferred slot was booked but the reservation could not be cancelled"
+		if output.Upgrade != nil && output.Upgrade.Error != "" {
+			entry.Message += ": " + output.Upgrade.Error
+		}
+	default:
+		entry.Action = api.ReservationAuditUpgradeEnded
+		entry.Message = "upgrade job ended without booking a more preferred slot"
+		if output.Error != "" {
+			entry.Message += ": " + output.Error
+		}
+	}
+	return s.reservationService.AddAudit(ctx, upgraded, entry)
+}
+
+// Returns whether the booked slot of a job is its most preferred slot, being the first
+// preferred time and seating type on the reservation date and party size of the job
+// Jobs with only preferred windows are never considered to have booked their most preferred slot
+func isMostPreferred(job *model.Job) bool {
+	if job.ReservedTime == nil || len(job.PreferredTimes) == 0 {
+		return false
+	}
+	if job.ReservedDate != nil && *job.ReservedDate != job.ReservationDate {
+		return false
+	}
+	if job.ReservedPartySize != nil && *job.ReservedPartySize != job.PartySize {
+		return false
+	}
+	if len(job.PreferredSeatingTypes) > 0 && (job.ReservedSeatingType == nil || *job.ReservedSeatingType != job.PreferredSeatingTypes[0]) {
+		return false
+	}
+	return job.ReservedTime.UTC().Format("15:04") == job.PreferredTimes[0]
+}
+
+// Returns the earliest of two times
+func minTime(a time.Time, b time.Time) time.Time {
+	if b.Before(a) {
+		return b
+	}
+	return a
+}
@@ -13,7 +13,10 @@ import (
 	"github.com/daylamtayari/cierge/querycol"
 	"github.com/daylamtayari/cierge/server/cloud"
 	"github.com/daylamtayari/cierge/server/cloud/aws"
+	"github.com/daylamtayari/cierge/server/cloud/gcp"
+	"github.com/daylamtayari/cierge/server/cloud/k8s"
 	"github.com/daylamtayari/cierge/server/cloud/local"
+	"github.com/daylamtayari/cierge/server/cloud/subprocess"
 	"github.com/daylamtayari/cierge/server/internal/config"
 	"github.com/daylamtayari/cierge/server/internal/database"
 	"github.com/daylamtayari/cierge/server/internal/repository"
@@ -32,7 +35,7 @@ func main() {
 	}
 
 	// Register cloud providers
-	cloudProviders := []string{"aws", "local"}
+	cloudProviders := []string{"aws", "gcp", "kubernetes", "local", "subprocess"}
 	for _, providerName := range cloudProviders {
 		var err error
 		switch providerName {
@@ -40,6 +43,12 @@ func main() {
 			err = cloud.Register("local", local.NewProvider, local.ValidateConfig)
 		case "aws":
 			err = cloud.Register("aws", aws.NewProvider, aws.ValidateConfig)
+		case "gcp":
+			err = cloud.Register("gcp", gcp.NewProvider, gcp.ValidateConfig)
+		case "kubernetes":
+			err = cloud.Register("kubernetes", k8s.NewProvider, k8s.ValidateConfig)
+		case "subprocess":
+			err = cloud.Register("subprocess", subprocess.NewProvider, subprocess.ValidateConfig)
 		}
 
 		if err != nil {
@@ -154,6 +163,17 @@ func main() {
 	tokenRenewer := service.NewTokenRenewer(services.PlatformToken, services.Job, cloudProvider, logger, cfg.PlatformToken)
 	tokenRenewer.Start(ctx)
 
+	// Create and start scheduled job reconciler
+	reconciler := service.NewReconciler(services.Job, cloudProvider, logger, cfg.Reconciler)
+	reconciler.Start(ctx)
+
+	// Create and start stale job sweeper
+	sweeper, err := service.NewSweeper(services.Job, services.Callback, cloudProvider, logger, cfg.Sweeper)
+	if err != nil {
+		logger.Fatal().Err(err).Msg("failed to create sweeper")
+	}
+	sweeper.Start(ctx)
+
 	serverErrors := make(chan error, 1)
 	go func() {
 		logger.Info().Str("address", cfg.Server.Address()).Msg("starting http server")
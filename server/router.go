@@ -63,13 +63,15 @@ func NewRouter(cfg *config.Config, logger zerolog.Logger, services *service.Serv
 	internalRoutes := router.Group("/internal")
 	{
 		internalRoutes.POST("/job/status", callbackAuthMiddleware.RequireCallbackAuth(), handlers.JobCallback.HandleJobCallback)
+		internalRoutes.POST("/job/started", callbackAuthMiddleware.RequireCallbackAuth(), handlers.JobCallback.HandleJobStarted)
 	}
 
 	proxyRoutes := router.Group("/proxy")
 	proxyRoutes.Use(authMiddleware.RequireAuth())
 	{
-		proxyRoutes.POST("/resy/auth", handlers.Proxy.ResyAuth)
-		proxyRoutes.POST("/resy/restaurant", handlers.Proxy.ResyRestaurant)
+		proxyRoutes.POST("/:platform/auth", handlers.Proxy.Auth)
+		proxyRoutes.POST("/:platform/auth/code", handlers.Proxy.AuthCode)
+		proxyRoutes.POST("/:platform/restaurant", handlers.Proxy.Restaurant)
 	}
 
 	api := router.Group("/api")
@@ -92,6 +94,7 @@ func NewRouter(cfg *config.Config, logger zerolog.Logger, services *service.Serv
 			jobs.GET("/list", handlers.Job.List)
 			jobs.GET("/:job", handlers.Job.Get)
 			jobs.POST("/:job/cancel", handlers.Job.Cancel)
+			jobs.POST("/:job/test", handlers.Job.Test)
 		}
 
 		// Reservation routes
@@ -106,6 +109,7 @@ func NewRouter(cfg *config.Config, logger zerolog.Logger, services *service.Serv
 		{
 			restaurants.GET("", handlers.Restaurant.Get)
 			restaurants.GET("/:id", handlers.Restaurant.GetByID)
+			restaurants.GET("/:id/seating-types", handlers.Restaurant.GetSeatingTypes)
 		}
 
 		// Drop config routes
@@ -121,6 +125,8 @@ func NewRouter(cfg *config.Config, logger zerolog.Logger, services *service.Serv
 		{
 			admin.PUT("/user", handlers.User.Create)
 			admin.GET("/job/list", handlers.Job.ListAll)
+			admin.POST("/key-rotation", handlers.KeyRotation.Start)
+			admin.GET("/key-rotation", handlers.KeyRotation.Get)
 		}
 	}
 
@@ -9,6 +9,7 @@ function StatusTag({ status }: { status: JobStatus }) {
   const cls: Record<JobStatus, string> = {
     created:   'tag tag-scheduled',
     scheduled: 'tag tag-scheduled',
+    running:   'tag tag-running',
     success:   'tag tag-confirmed',
     failed:    'tag tag-failed',
     cancelled: 'tag tag-cancelled',
@@ -16,6 +17,7 @@ function StatusTag({ status }: { status: JobStatus }) {
   const label: Record<JobStatus, string> = {
     created:   'Scheduled',
     scheduled: 'Scheduled',
+    running:   'Running',
     success:   'Confirmed',
     failed:    'Failed',
     cancelled: 'Cancelled',
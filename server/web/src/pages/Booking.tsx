@@ -2,13 +2,14 @@ import { useEffect, useState } from 'react'
 import { useParams, useNavigate, useLocation, Link } from 'react-router-dom'
 import Layout from '../components/Layout'
 import { apiFetch } from '../lib/apiFetch'
-import type { Job, JobStatus } from '../types/job'
+import type { Job, JobStatus, TimeWindow } from '../types/job'
 import type { Restaurant } from '../types/restaurant'
 
 function StatusTag({ status }: { status: JobStatus }) {
   const map: Record<JobStatus, string> = {
     created:   'tag tag-scheduled',
     scheduled: 'tag tag-scheduled',
+    running:   'tag tag-running',
     success:   'tag tag-confirmed',
     failed:    'tag tag-failed',
     cancelled: 'tag tag-cancelled',
@@ -16,6 +17,7 @@ function StatusTag({ status }: { status: JobStatus }) {
   const label: Record<JobStatus, string> = {
     created:   'Scheduled',
     scheduled: 'Scheduled',
+    running:   'Running',
     success:   'Confirmed',
     failed:    'Failed',
     cancelled: 'Cancelled',
@@ -43,6 +45,14 @@ function formatReservedTime(dateStr: string): string {
   return `${time} on ${date}`
 }
 
+function formatTimeWindow(window: TimeWindow): string {
+  let value = window.start
+  if (window.end && window.end !== window.start) value += `–${window.end}`
+  if (window.ideal && window.ideal !== window.start) value += ` (ideally ${window.ideal})`
+  if (window.tolerance) value += ` ±${window.tolerance} min`
+  return value
+}
+
 function formatLogs(logs: string): string {
   try {
     return JSON.stringify(JSON.parse(logs), null, 2)
@@ -149,9 +159,48 @@ export default function Booking() {
             <dt>Preferred times</dt>
             <dd>{job.preferred_times.join(', ')}</dd>
 
+            {job.preferred_windows && job.preferred_windows.length > 0 && (
+              <>
+                <dt>Preferred windows</dt>
+                <dd>{job.preferred_windows.map(formatTimeWindow).join(', ')}</dd>
+              </>
+            )}
+
+            {job.alternatives && job.alternatives.length > 0 && (
+              <>
+                <dt>Alternatives</dt>
+                <dd>
+                  {job.alternatives
+                    .map(alt => `${formatDate(alt.reservation_date)} for ${alt.party_size}`)
+                    .join('; ')}
+                </dd>
+              </>
+            )}
+
+            {job.allowed_seating_types && job.allowed_seating_types.length > 0 && (
+              <>
+                <dt>Allowed seating</dt>
+                <dd>{job.allowed_seating_types.join(', ')}</dd>
+              </>
+            )}
+
+            {job.preferred_seating_types && job.preferred_seating_types.length > 0 && (
+              <>
+                <dt>Preferred seating</dt>
+                <dd>{job.preferred_seating_types.join(', ')}</dd>
+              </>
+            )}
+
             <dt>Platform</dt>
             <dd className="text-capitalize">{job.platform}</dd>
 
+            {job.watch && (
+              <>
+                <dt>Watching until</dt>
+                <dd>{formatDateTime(job.watch.until)}, every {job.watch.interval}s</dd>
+              </>
+            )}
+
             <dt>Runs at</dt>
             <dd>{formatDateTime(job.scheduled_at)}</dd>
 
@@ -162,6 +211,20 @@ export default function Booking() {
               </>
             )}
 
+            {job.reserved_party_size !== undefined && job.reserved_party_size !== job.party_size && (
+              <>
+                <dt>Reserved party size</dt>
+                <dd>{job.reserved_party_size}</dd>
+              </>
+            )}
+
+            {job.reserved_deposit !== undefined && (
+              <>
+                <dt>Deposit</dt>
+                <dd>{job.reserved_deposit.toFixed(2)}</dd>
+              </>
+            )}
+
             {job.confirmation && (() => {
               const { label, value } = parseConfirmation(job.platform, job.confirmation)
               return (
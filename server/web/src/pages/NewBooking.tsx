@@ -182,7 +182,7 @@ export default function NewBooking() {
         setSearching(true)
         setSearchError('')
         try {
-            const res = await apiFetch('/proxy/resy/restaurant', {
+            const res = await apiFetch(`/proxy/${platform}/restaurant`, {
                 method: 'POST',
                 headers: { 'Content-Type': 'application/json' },
                 body: JSON.stringify({ query: q }),
@@ -199,7 +199,7 @@ export default function NewBooking() {
         } finally {
             if (seq === seqRef.current) setSearching(false)
         }
-    }, [])
+    }, [platform])
 
     useEffect(() => {
         const interval = setInterval(() => {
@@ -219,7 +219,7 @@ export default function NewBooking() {
         setRestaurantError('')
         setLoadingRestaurant(true)
         try {
-            const res = await apiFetch(`/api/restaurant?platform=${platform}&platform-id=${v.id.resy}`)
+            const res = await apiFetch(`/api/restaurant?platform=${platform}&platform-id=${v.id}`)
             if (res.ok) {
                 setRestaurant(await res.json())
             } else {
@@ -453,13 +453,13 @@ export default function NewBooking() {
                                         <div className="option-list">
                                             {venues.map(v => (
                                                 <button
-                                                    key={v.id.resy}
+                                                    key={v.id}
                                                     className="option"
                                                     onClick={() => selectVenue(v)}
                                                 >
                                                     <span className="option-label">{v.name}</span>
                                                     <span className="option-meta">
-                                                        {locationLabel([v.neighborhood || v.locality, v.region])}
+                                                        {locationLabel([v.neighborhood || v.city, v.region])}
                                                     </span>
                                                 </button>
                                             ))}
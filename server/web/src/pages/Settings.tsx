@@ -15,10 +15,15 @@ export default function Settings() {
   const [user, setUser] = useState<User | null>(null)
   const [tokens, setTokens] = useState<PlatformToken[]>([])
 
-  // Resy connect form
+  // Platform connect forms
   const [connecting, setConnecting] = useState<string | null>(null)
   const [resyEmail, setResyEmail] = useState('')
   const [resyPassword, setResyPassword] = useState('')
+  const [openTableEmail, setOpenTableEmail] = useState('')
+  const [openTableCode, setOpenTableCode] = useState('')
+  const [openTableCodeSent, setOpenTableCodeSent] = useState(false)
+  const [tockEmail, setTockEmail] = useState('')
+  const [tockPassword, setTockPassword] = useState('')
   const [connectError, setConnectError] = useState('')
   const [connectLoading, setConnectLoading] = useState(false)
 
@@ -48,6 +53,11 @@ export default function Settings() {
     setConnectError('')
     setResyEmail('')
     setResyPassword('')
+    setOpenTableEmail('')
+    setOpenTableCode('')
+    setOpenTableCodeSent(false)
+    setTockEmail('')
+    setTockPassword('')
   }
 
   async function handleConnectResy(e: FormEvent) {
@@ -73,6 +83,73 @@ export default function Settings() {
     }
   }
 
+  async function handleSendOpenTableCode(e: FormEvent) {
+    e.preventDefault()
+    setConnectError('')
+    setConnectLoading(true)
+    try {
+      const res = await apiFetch('/proxy/opentable/auth/code', {
+        method: 'POST',
+        headers: { 'Content-Type': 'application/json' },
+        body: JSON.stringify({ email: openTableEmail }),
+      })
+      if (!res.ok) {
+        const data = await res.json().catch(() => ({}))
+        setConnectError(data.message || 'Failed to send a login code. Check your email.')
+        return
+      }
+      setOpenTableCodeSent(true)
+    } finally {
+      setConnectLoading(false)
+    }
+  }
+
+  async function handleConnectOpenTable(e: FormEvent) {
+    e.preventDefault()
+    setConnectError('')
+    setConnectLoading(true)
+    try {
+      const res = await apiFetch('/proxy/opentable/auth', {
+        method: 'POST',
+        headers: { 'Content-Type': 'application/json' },
+        body: JSON.stringify({ email: openTableEmail, code: openTableCode.trim() }),
+      })
+      if (!res.ok) {
+        const data = await res.json().catch(() => ({}))
+        setConnectError(data.message || 'Failed to connect. Check your login code.')
+        return
+      }
+      const saved: PlatformToken = await res.json()
+      setTokens(prev => [...prev.filter(t => t.platform !== 'opentable'), saved])
+      setConnecting(null)
+    } finally {
+      setConnectLoading(false)
+    }
+  }
+
+  async function handleConnectTock(e: FormEvent) {
+    e.preventDefault()
+    setConnectError('')
+    setConnectLoading(true)
+    try {
+      const res = await apiFetch('/proxy/tock/auth', {
+        method: 'POST',
+        headers: { 'Content-Type': 'application/json' },
+        body: JSON.stringify({ email: tockEmail, password: tockPassword }),
+      })
+      if (!res.ok) {
+        const data = await res.json().catch(() => ({}))
+        setConnectError(data.message || 'Failed to connect. Check your email and password.')
+        return
+      }
+      const saved: PlatformToken = await res.json()
+      setTokens(prev => [...prev.filter(t => t.platform !== 'tock'), saved])
+      setConnecting(null)
+    } finally {
+      setConnectLoading(false)
+    }
+  }
+
   async function handleGenerateApiKey() {
     setApiKeyLoading(true)
     setNewApiKey(null)
@@ -121,6 +198,8 @@ export default function Settings() {
   }
 
   const resyToken = tokenFor('resy')
+  const openTableToken = tokenFor('opentable')
+  const tockToken = tokenFor('tock')
 
   return (
     <Layout>
@@ -194,9 +273,123 @@ export default function Settings() {
             <div className="platform-row">
               <div>
                 <div className="platform-name">OpenTable</div>
-                <span className="platform-status">Coming soon</span>
+                <PlatformStatus token={openTableToken} />
+              </div>
+              <div className="platform-actions">
+                {connecting === 'opentable' ? (
+                  <button className="btn btn-sm btn-subtle" onClick={() => setConnecting(null)}>
+                    Cancel
+                  </button>
+                ) : (
+                  <button className="btn btn-sm btn-secondary" onClick={() => openConnect('opentable')}>
+                    {openTableToken ? 'Reconnect' : 'Connect'}
+                  </button>
+                )}
               </div>
             </div>
+
+            {connecting === 'opentable' && (
+              <form
+                className="platform-form"
+                onSubmit={openTableCodeSent ? handleConnectOpenTable : handleSendOpenTableCode}
+              >
+                <div className="notice-warn" role="note">
+                  <span className="notice-warn-icon" aria-hidden="true">⚠</span>
+                  <span>By connecting your credentials, you assume trust in the server owner.</span>
+                </div>
+                <div className="field">
+                  <label className="field-label" htmlFor="opentable-email">Email</label>
+                  <input
+                    className="field-input"
+                    id="opentable-email"
+                    type="email"
+                    autoComplete="off"
+                    value={openTableEmail}
+                    onChange={e => setOpenTableEmail(e.target.value)}
+                    disabled={openTableCodeSent}
+                    required
+                  />
+                </div>
+                {openTableCodeSent && (
+                  <div className="field">
+                    <label className="field-label" htmlFor="opentable-code">Login code</label>
+                    <input
+                      className="field-input"
+                      id="opentable-code"
+                      type="text"
+                      inputMode="numeric"
+                      autoComplete="one-time-code"
+                      value={openTableCode}
+                      onChange={e => setOpenTableCode(e.target.value)}
+                      required
+                    />
+                  </div>
+                )}
+                {connectError && <p className="feedback-err">{connectError}</p>}
+                <button className="btn btn-primary btn-sm" type="submit" disabled={connectLoading}>
+                  {openTableCodeSent
+                    ? (connectLoading ? 'Connecting…' : 'Connect OpenTable')
+                    : (connectLoading ? 'Sending…' : 'Send login code')}
+                </button>
+              </form>
+            )}
+          </div>
+
+          <div className="platform-block">
+            <div className="platform-row">
+              <div>
+                <div className="platform-name">Tock</div>
+                <PlatformStatus token={tockToken} />
+              </div>
+              <div className="platform-actions">
+                {connecting === 'tock' ? (
+                  <button className="btn btn-sm btn-subtle" onClick={() => setConnecting(null)}>
+                    Cancel
+                  </button>
+                ) : (
+                  <button className="btn btn-sm btn-secondary" onClick={() => openConnect('tock')}>
+                    {tockToken ? 'Reconnect' : 'Connect'}
+                  </button>
+                )}
+              </div>
+            </div>
+
+            {connecting === 'tock' && (
+              <form className="platform-form" onSubmit={handleConnectTock}>
+                <div className="notice-warn" role="note">
+                  <span className="notice-warn-icon" aria-hidden="true">⚠</span>
+                  <span>By connecting your credentials, you assume trust in the server owner.</span>
+                </div>
+                <div className="field">
+                  <label className="field-label" htmlFor="tock-email">Email</label>
+                  <input
+                    className="field-input"
+                    id="tock-email"
+                    type="email"
+                    autoComplete="off"
+                    value={tockEmail}
+                    onChange={e => setTockEmail(e.target.value)}
+                    required
+                  />
+                </div>
+                <div className="field">
+                  <label className="field-label" htmlFor="tock-password">Password</label>
+                  <input
+                    className="field-input"
+                    id="tock-password"
+                    type="password"
+                    autoComplete="off"
+                    value={tockPassword}
+                    onChange={e => setTockPassword(e.target.value)}
+                    required
+                  />
+                </div>
+                {connectError && <p className="feedback-err">{connectError}</p>}
+                <button className="btn btn-primary btn-sm" type="submit" disabled={connectLoading}>
+                  {connectLoading ? 'Connecting…' : 'Connect Tock'}
+                </button>
+              </form>
+            )}
           </div>
         </section>
 
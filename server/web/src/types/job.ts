@@ -1,13 +1,61 @@
-export type JobStatus = 'created' | 'scheduled' | 'success' | 'failed' | 'cancelled'
+export type JobStatus = 'created' | 'scheduled' | 'running' | 'success' | 'failed' | 'cancelled'
+
+export interface TimeWindow {
+  start: string
+  end?: string
+  ideal?: string
+  tolerance?: number
+}
+
+export interface JobAlternative {
+  reservation_date: string
+  party_size: number
+}
+
+export type JobType = 'drop' | 'watch' | 'upgrade'
+
+export interface JobWatch {
+  start_at?: string
+  until: string
+  interval: number
+}
+
+export interface JobUpgrade {
+  until: string
+  interval: number
+}
+
+export interface JobPaymentPolicy {
+  max_deposit?: number
+  payment_method_id?: number
+}
+
+export type JobStrategyName = 'sequential' | 'stagger' | 'parallel' | 'race' | 'upgrade'
+
+export interface JobStrategy {
+  name: JobStrategyName
+  stagger?: number
+  race_size?: number
+}
 
 export interface Job {
   id: string
   user_id: string
   restaurant_id: string
   platform: string
+  type: JobType
   reservation_date: string
   party_size: number
   preferred_times: string[]
+  preferred_windows?: TimeWindow[]
+  alternatives?: JobAlternative[]
+  watch?: JobWatch
+  allowed_seating_types?: string[]
+  preferred_seating_types?: string[]
+  payment_policy?: JobPaymentPolicy
+  strategy?: JobStrategy
+  upgrade?: JobUpgrade
+  upgrade_reservation_id?: string
   scheduled_at: string
   drop_config_id: string
   callbacked: boolean
@@ -15,6 +63,11 @@ export interface Job {
   started_at?: string
   completed_at?: string
   reserved_time?: string
+  reserved_date?: string
+  reserved_party_size?: number
+  reserved_seating_type?: string
+  reserved_deposit?: number
+  reserved_cancellation_cut_off?: string
   confirmation?: string
   error_message?: string
   logs?: string
@@ -8,4 +8,5 @@ export interface Restaurant {
   state?: string
   timezone?: string
   rating?: number
+  seating_types?: string[]
 }
@@ -1,9 +1,10 @@
-// Search result from POST /proxy/resy/restaurant. The Resy API returns many
-// more fields, but the booking flow only needs these. See resy/venue.go.
+// Search result from POST /proxy/:platform/restaurant, the venue of a platform
+// returned by its search. See reservation/platform.go.
 export interface Venue {
-  id: { resy: number; google: string }
+  id: string
   name: string
-  locality: string
-  region: string
-  neighborhood: string
+  address?: string
+  neighborhood?: string
+  city?: string
+  region?: string
 }
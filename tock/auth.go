@@ -0,0 +1,53 @@
+package tock
+
+import (
+	"errors"
+	"net/http"
+	"time"
+)
+
+var (
+	ErrNoAuthToken = errors.New("no auth token was included in the response")
+)
+
+type Tokens struct {
+	// Session token of the user used to authenticate requests
+	Token string
+	// Expiration time of the session token
+	Expiry time.Time
+}
+
+// Performs authentication using the email and password of a user,
+// returning the user's tokens and an error that is nil if successful
+// Returns an ErrUnauthorized if the credentials are incorrect
+// NOTE: Tock does not issue refresh tokens, users login again once their session expires
+func (c *Client) Login(email string, password string) (Tokens, error) {
+	reqUrl := Host + "/api/consumer/auth/login"
+
+	req, err := c.NewJsonRequest(http.MethodPost, reqUrl, map[string]string{
+		"email":    email,
+		"password": password,
+	})
+	if err != nil {
+		return Tokens{}, err
+	}
+
+	type loginResponse struct {
+		SessionToken string    `json:"sessionToken"`
+		ExpiresAt    time.Time `json:"expiresAt"`
+	}
+	var loginRes loginResponse
+	err = c.Do(req, &loginRes)
+	if err != nil {
+		return Tokens{}, err
+	}
+
+	if loginRes.SessionToken == "" {
+		return Tokens{}, ErrNoAuthToken
+	}
+
+	return Tokens{
+		Token:  loginRes.SessionToken,
+		Expiry: loginRes.ExpiresAt,
+	}, nil
+}
@@ -0,0 +1,40 @@
+package tock
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// Password of the user of the fake Tock API
+const testPassword = "password"
+
+func TestLogin(t *testing.T) {
+	fixtures := fixtureHandler(t, map[string]string{
+		"POST /api/consumer/auth/login": "login.json",
+	})
+	client := newFakeClient(t, "", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if req["email"] != "diner@example.com" || req["password"] != testPassword {
+			http.Error(w, `{"message":"invalid email or password"}`, http.StatusUnauthorized)
+			return
+		}
+		fixtures.ServeHTTP(w, r)
+	}))
+
+	tokens, err := client.Login("diner@example.com", testPassword)
+	requireNoError(t, err, "Login failed")
+	if tokens.Token != testToken || !tokens.Expiry.Equal(time.Date(2026, 12, 20, 19, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected tokens %+v", tokens)
+	}
+
+	if _, err := client.Login("diner@example.com", "incorrect"); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("incorrect password: got %v, want %v", err, ErrUnauthorized)
+	}
+}
@@ -0,0 +1,74 @@
+package tock
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Date and time formats of the Tock API
+// Dates and times are in the local time of the business
+const (
+	DateFormat = "2006-01-02"
+	TimeFormat = "15:04"
+)
+
+// Represents an available slot of an experience of a business
+// Experiences are the offerings of a business, such as its dining room,
+// bar, or tasting menu, each with their own slots and prices
+// The price is per person and in the major unit of the currency of the business
+// Prepaid slots are paid in full when checked out, otherwise the price is
+// only indicative or a deposit is held depending on the experience
+type Slot struct {
+	ExperienceId     int     `json:"experienceId"`
+	ExperienceName   string  `json:"experienceName"`
+	ExperienceType   string  `json:"experienceType"`
+	Date             string  `json:"date"`
+	Time             string  `json:"time"`
+	AvailableTickets int     `json:"availableTickets"`
+	MinPartySize     int     `json:"minPartySize"`
+	MaxPartySize     int     `json:"maxPartySize"`
+	Price            float32 `json:"price"`
+	IsPrepaid        bool    `json:"isPrepaid"`
+}
+
+type availabilityResponse struct {
+	Slots []Slot `json:"slots"`
+}
+
+// Returns the date time of a slot
+// NOTE: The timezone value of the date time is UTC but
+// the time is in the local time of the business
+// e.g. 19:00 local time -> 19:00:00 +0000 UTC
+func (s Slot) DateTime() (time.Time, error) {
+	return time.Parse(DateFormat+" "+TimeFormat, s.Date+" "+s.Time)
+}
+
+// Retrieves the available slots of all experiences of a business for a date and party size
+// Slots without available tickets are not included
+func (c *Client) GetAvailability(slug string, date time.Time, partySize int) ([]Slot, error) {
+	reqUrl := Host + "/api/consumer/business/" + url.PathEscape(slug) + "/availability?" + url.Values{
+		"date": []string{date.Format(DateFormat)},
+		"size": []string{strconv.Itoa(partySize)},
+	}.Encode()
+
+	req, err := http.NewRequest(http.MethodGet, reqUrl, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var res availabilityResponse
+	err = c.Do(req, &res)
+	if err != nil {
+		return nil, err
+	}
+
+	slots := make([]Slot, 0, len(res.Slots))
+	for _, slot := range res.Slots {
+		if slot.AvailableTickets > 0 {
+			slots = append(slots, slot)
+		}
+	}
+	return slots, nil
+}
@@ -0,0 +1,44 @@
+package tock
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestGetAvailability(t *testing.T) {
+	var query map[string][]string
+	fixtures := fixtureHandler(t, map[string]string{
+		"GET /api/consumer/business/" + testSlug + "/availability": "availability.json",
+	})
+	client := newFakeClient(t, "", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		fixtures.ServeHTTP(w, r)
+	}))
+
+	slots, err := client.GetAvailability(testSlug, time.Date(2026, 11, 20, 0, 0, 0, 0, time.UTC), 2)
+	requireNoError(t, err, "GetAvailability failed")
+
+	if query["date"][0] != "2026-11-20" || query["size"][0] != "2" {
+		t.Errorf("unexpected query %v", query)
+	}
+	// Slots without available tickets are not included
+	if len(slots) != 3 {
+		t.Fatalf("slots: got %d, want 3", len(slots))
+	}
+
+	wantExperiences := []string{"Dining Room", "Tasting Menu", "Bar"}
+	for i, slot := range slots {
+		if slot.ExperienceName != wantExperiences[i] {
+			t.Errorf("slot %d: got experience %q, want %q", i, slot.ExperienceName, wantExperiences[i])
+		}
+	}
+	dateTime, err := slots[1].DateTime()
+	requireNoError(t, err, "DateTime failed")
+	if !dateTime.Equal(time.Date(2026, 11, 20, 19, 30, 0, 0, time.UTC)) {
+		t.Errorf("date time: got %s, want 2026-11-20 19:30", dateTime)
+	}
+	if !slots[1].IsPrepaid || slots[1].Price != 125 {
+		t.Errorf("unexpected prepaid slot %+v", slots[1])
+	}
+}
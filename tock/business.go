@@ -0,0 +1,48 @@
+package tock
+
+import (
+	"net/http"
+	"net/url"
+)
+
+// Represents a business, such as a restaurant or a winery
+// The slug identifies the business in the URLs of Tock
+// NOTE: Not all fields are populated by every endpoint
+type Business struct {
+	Id           int    `json:"id"`
+	Slug         string `json:"slug"`
+	Name         string `json:"name"`
+	Description  string `json:"description"`
+	Neighborhood string `json:"neighborhood"`
+	Address      struct {
+		Line1    string `json:"line1"`
+		Line2    string `json:"line2"`
+		City     string `json:"city"`
+		State    string `json:"state"`
+		PostCode string `json:"postalCode"`
+		Country  string `json:"country"`
+	} `json:"address"`
+	// IANA name of the timezone of the business
+	TimeZone     string `json:"timeZone"`
+	CurrencyCode string `json:"currencyCode"`
+	Website      string `json:"website"`
+}
+
+// Retrieves a business from its slug
+// If the business does not exist, an ErrNotFound is returned
+func (c *Client) GetBusiness(slug string) (*Business, error) {
+	reqUrl := Host + "/api/consumer/business/" + url.PathEscape(slug)
+
+	req, err := http.NewRequest(http.MethodGet, reqUrl, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var business Business
+	err = c.Do(req, &business)
+	if err != nil {
+		return nil, err
+	}
+
+	return &business, nil
+}
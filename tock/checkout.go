@@ -0,0 +1,105 @@
+package tock
+
+import (
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Represents the hold of a slot, which reserves its tickets for the user
+// until it is checked out or the hold expires
+// Payment is required to check out the hold if RequiresPayment is true
+// The total is the amount charged or held when checking out, in the
+// major unit of the currency, and the cancellation cut off is the RFC 3339
+// time until which the reservation can be cancelled for a refund, if any
+type Hold struct {
+	Id                 string    `json:"id"`
+	ExpiresAt          time.Time `json:"expiresAt"`
+	RequiresPayment    bool      `json:"requiresPayment"`
+	Total              float32   `json:"total"`
+	CurrencyCode       string    `json:"currencyCode"`
+	CancellationCutOff string    `json:"cancellationCutoff"`
+}
+
+// Represents a checked out reservation
+// The confirmation code is the one shown to the user and the business
+type Confirmation struct {
+	PurchaseId       int     `json:"purchaseId"`
+	ConfirmationCode string  `json:"confirmationCode"`
+	BusinessSlug     string  `json:"businessSlug"`
+	Date             string  `json:"date"`
+	Time             string  `json:"time"`
+	PartySize        int     `json:"partySize"`
+	Total            float32 `json:"total"`
+}
+
+type holdRequest struct {
+	ExperienceId int    `json:"experienceId"`
+	Date         string `json:"date"`
+	Time         string `json:"time"`
+	PartySize    int    `json:"partySize"`
+}
+
+type checkoutRequest struct {
+	PaymentMethodId *string `json:"paymentMethodId,omitempty"`
+}
+
+// Holds a slot of a business for a party size
+// If the slot no longer has enough available tickets, an ErrConflict is returned
+func (c *Client) HoldSlot(slug string, slot Slot, partySize int) (*Hold, error) {
+	reqUrl := Host + "/api/consumer/business/" + url.PathEscape(slug) + "/hold"
+
+	req, err := c.NewJsonRequest(http.MethodPost, reqUrl, holdRequest{
+		ExperienceId: slot.ExperienceId,
+		Date:         slot.Date,
+		Time:         slot.Time,
+		PartySize:    partySize,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var hold Hold
+	err = c.Do(req, &hold)
+	if err != nil {
+		return nil, err
+	}
+
+	return &hold, nil
+}
+
+// Checks out a hold, booking its slot
+// The ID of one of the user's payment methods should be passed if the hold
+// requires payment, otherwise a 402 Payment Required will be returned
+// If the hold expired, an ErrHoldExpired is returned
+func (c *Client) CompleteCheckout(hold *Hold, paymentMethodId *string) (*Confirmation, error) {
+	reqUrl := Host + "/api/consumer/checkout/" + url.PathEscape(hold.Id)
+
+	req, err := c.NewJsonRequest(http.MethodPost, reqUrl, checkoutRequest{
+		PaymentMethodId: paymentMethodId,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var confirmation Confirmation
+	err = c.Do(req, &confirmation)
+	if err != nil {
+		return nil, err
+	}
+
+	return &confirmation, nil
+}
+
+// Releases a hold so that its tickets are available to other users
+// and returns an error that is nil if successful
+func (c *Client) ReleaseHold(hold *Hold) error {
+	reqUrl := Host + "/api/consumer/checkout/" + url.PathEscape(hold.Id)
+
+	req, err := http.NewRequest(http.MethodDelete, reqUrl, nil)
+	if err != nil {
+		return err
+	}
+
+	return c.Do(req, nil)
+}
@@ -0,0 +1,82 @@
+package tock
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestHoldAndCheckout(t *testing.T) {
+	var holdReq holdRequest
+	var checkoutReq checkoutRequest
+	released := false
+	fixtures := fixtureHandler(t, map[string]string{
+		"auth POST /api/consumer/business/" + testSlug + "/hold": "hold.json",
+		"auth POST /api/consumer/checkout/hold-7f3a":             "checkout.json",
+	})
+	client := newFakeClient(t, testToken, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodDelete && r.URL.Path == "/api/consumer/checkout/hold-7f3a":
+			released = true
+			w.WriteHeader(http.StatusNoContent)
+			return
+		case r.URL.Path == "/api/consumer/business/"+testSlug+"/hold":
+			if err := json.NewDecoder(r.Body).Decode(&holdReq); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		case r.URL.Path == "/api/consumer/checkout/hold-7f3a":
+			if err := json.NewDecoder(r.Body).Decode(&checkoutReq); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+		fixtures.ServeHTTP(w, r)
+	}))
+
+	slot := Slot{ExperienceId: 12, ExperienceName: "Tasting Menu", Date: "2026-11-20", Time: "19:30", AvailableTickets: 2}
+	hold, err := client.HoldSlot(testSlug, slot, 2)
+	requireNoError(t, err, "HoldSlot failed")
+	if holdReq.ExperienceId != 12 || holdReq.Date != "2026-11-20" || holdReq.Time != "19:30" || holdReq.PartySize != 2 {
+		t.Errorf("unexpected hold request %+v", holdReq)
+	}
+	if hold.Id != "hold-7f3a" || !hold.RequiresPayment || hold.Total != 250 || hold.ExpiresAt.IsZero() {
+		t.Errorf("unexpected hold %+v", hold)
+	}
+
+	paymentMethodId := "302"
+	confirmation, err := client.CompleteCheckout(hold, &paymentMethodId)
+	requireNoError(t, err, "CompleteCheckout failed")
+	if checkoutReq.PaymentMethodId == nil || *checkoutReq.PaymentMethodId != paymentMethodId {
+		t.Errorf("unexpected checkout request %+v", checkoutReq)
+	}
+	if confirmation.PurchaseId != 555001 || confirmation.ConfirmationCode != "TK-8H2Q" {
+		t.Errorf("unexpected confirmation %+v", confirmation)
+	}
+
+	requireNoError(t, client.ReleaseHold(hold), "ReleaseHold failed")
+	if !released {
+		t.Error("hold was not released")
+	}
+}
+
+func TestHoldAndCheckout_Errors(t *testing.T) {
+	client := newFakeClient(t, testToken, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/consumer/business/" + testSlug + "/hold":
+			http.Error(w, `{"message":"not enough tickets available"}`, http.StatusConflict)
+		case "/api/consumer/checkout/expired":
+			http.Error(w, `{"message":"hold has expired"}`, http.StatusGone)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+
+	if _, err := client.HoldSlot(testSlug, Slot{ExperienceId: 11, Date: "2026-11-20", Time: "18:30"}, 2); !errors.Is(err, ErrConflict) {
+		t.Errorf("taken slot: got %v, want %v", err, ErrConflict)
+	}
+	if _, err := client.CompleteCheckout(&Hold{Id: "expired"}, nil); !errors.Is(err, ErrHoldExpired) {
+		t.Errorf("expired hold: got %v, want %v", err, ErrHoldExpired)
+	}
+}
@@ -0,0 +1,94 @@
+package tock
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// Slug of the test business of the fake Tock API
+const testSlug = "test-kitchen"
+
+// Token of the user of the fake Tock API
+const testToken = "test-token"
+
+// Transport sending the requests to the Tock API to a test server
+type rewriteTransport struct {
+	target *url.URL
+}
+
+func (t rewriteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.URL.Scheme = t.target.Scheme
+	req.URL.Host = t.target.Host
+	req.Host = t.target.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// newFakeClient starts a test server with the provided handler and returns
+// a client authenticated with the token whose requests are sent to it
+func newFakeClient(t *testing.T, token string, handler http.Handler) *Client {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	target, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatalf("invalid test server URL: %v", err)
+	}
+	return NewClient(&http.Client{Transport: rewriteTransport{target: target}}, token, "")
+}
+
+// fixtureHandler returns a handler responding to requests matching the method and
+// path of a route, in the form "METHOD /path", with the recorded response of the
+// route's fixture in testdata, and with a 404 to any other request
+// Routes whose method and path are prefixed with "auth " require the test token
+func fixtureHandler(t *testing.T, routes map[string]string) http.Handler {
+	t.Helper()
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route := r.Method + " " + r.URL.Path
+		fixture, ok := routes[route]
+		if !ok {
+			fixture, ok = routes["auth "+route]
+			if ok && !requireAuth(w, r) {
+				return
+			}
+		}
+		if !ok {
+			http.Error(w, `{"message":"not found"}`, http.StatusNotFound)
+			return
+		}
+
+		body, err := os.ReadFile(filepath.Join("testdata", fixture))
+		if err != nil {
+			t.Errorf("failed to read fixture %s: %v", fixture, err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(body) //nolint:errcheck
+	})
+}
+
+// requireAuth responds with a 401 and returns false if the request
+// is not authenticated with the test token
+func requireAuth(w http.ResponseWriter, r *http.Request) bool {
+	if r.Header.Get("Authorization") != "Bearer "+testToken {
+		http.Error(w, `{"message":"unauthorized"}`, http.StatusUnauthorized)
+		return false
+	}
+	return true
+}
+
+// requireNoError fails the test if err is not nil
+func requireNoError(t *testing.T, err error, msg string) {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("%s: %v", msg, err)
+	}
+}
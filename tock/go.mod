@@ -0,0 +1,3 @@
+module github.com/daylamtayari/cierge/tock
+
+go 1.25.5
@@ -0,0 +1,41 @@
+package tock
+
+import (
+	"net/http"
+	"net/url"
+)
+
+// Represents a business matching a search
+type SearchResult struct {
+	Id           int    `json:"id"`
+	Slug         string `json:"slug"`
+	Name         string `json:"name"`
+	Neighborhood string `json:"neighborhood"`
+	City         string `json:"city"`
+	State        string `json:"state"`
+	Country      string `json:"country"`
+}
+
+type searchResponse struct {
+	Results []SearchResult `json:"businesses"`
+}
+
+// Searches for businesses matching a query
+func (c *Client) Search(query string) ([]SearchResult, error) {
+	reqUrl := Host + "/api/consumer/search?" + url.Values{
+		"query": []string{query},
+	}.Encode()
+
+	req, err := http.NewRequest(http.MethodGet, reqUrl, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var res searchResponse
+	err = c.Do(req, &res)
+	if err != nil {
+		return nil, err
+	}
+
+	return res.Results, nil
+}
@@ -0,0 +1,37 @@
+package tock
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSearch(t *testing.T) {
+	client := newFakeClient(t, "", fixtureHandler(t, map[string]string{
+		"GET /api/consumer/search": "search.json",
+	}))
+
+	results, err := client.Search("test kitchen")
+	requireNoError(t, err, "Search failed")
+	if len(results) != 2 {
+		t.Fatalf("results: got %d, want 2", len(results))
+	}
+	if results[0].Slug != testSlug || results[0].Neighborhood != "West Loop" || results[0].City != "Chicago" {
+		t.Errorf("unexpected result %+v", results[0])
+	}
+}
+
+func TestGetBusiness(t *testing.T) {
+	client := newFakeClient(t, "", fixtureHandler(t, map[string]string{
+		"GET /api/consumer/business/" + testSlug: "business.json",
+	}))
+
+	business, err := client.GetBusiness(testSlug)
+	requireNoError(t, err, "GetBusiness failed")
+	if business.Name != "Test Kitchen" || business.Address.Line1 != "1 Randolph St" || business.TimeZone != "America/Chicago" {
+		t.Errorf("unexpected business %+v", business)
+	}
+
+	if _, err := client.GetBusiness("unknown"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("unknown business: got %v, want %v", err, ErrNotFound)
+	}
+}
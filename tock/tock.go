@@ -0,0 +1,6 @@
+// Go library for the Tock (exploretock.com) API
+//
+// The library uses the consumer API of the Tock website, which authenticates
+// users with a session token. Only the fields used to search businesses,
+// retrieve their availability, and hold and check out slots are handled.
+package tock
@@ -0,0 +1,57 @@
+package tock
+
+import (
+	"net/http"
+)
+
+// Represents the authenticated user
+type User struct {
+	Id             int             `json:"id"`
+	FirstName      string          `json:"firstName"`
+	LastName       string          `json:"lastName"`
+	Email          string          `json:"email"`
+	PhoneNumber    string          `json:"phoneNumber"`
+	PaymentMethods []PaymentMethod `json:"paymentMethods"`
+}
+
+// Represents a saved payment method of the user used
+// to check out holds that require payment
+type PaymentMethod struct {
+	Id        string `json:"id"`
+	Brand     string `json:"brand"`
+	Last4     string `json:"last4"`
+	IsDefault bool   `json:"isDefault"`
+}
+
+// Retrieves the authenticated user
+// Returns an ErrUnauthorized if the token is invalid or expired
+func (c *Client) GetUser() (*User, error) {
+	reqUrl := Host + "/api/consumer/user"
+
+	req, err := http.NewRequest(http.MethodGet, reqUrl, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var user User
+	err = c.Do(req, &user)
+	if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// Returns the default payment method of a user, or the first
+// payment method if none is the default, and false if the user has none
+func GetDefaultPaymentMethod(user *User) (PaymentMethod, bool) {
+	for _, paymentMethod := range user.PaymentMethods {
+		if paymentMethod.IsDefault {
+			return paymentMethod, true
+		}
+	}
+	if len(user.PaymentMethods) > 0 {
+		return user.PaymentMethods[0], true
+	}
+	return PaymentMethod{}, false
+}
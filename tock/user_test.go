@@ -0,0 +1,38 @@
+package tock
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestGetUser(t *testing.T) {
+	routes := map[string]string{
+		"auth GET /api/consumer/user": "user.json",
+	}
+
+	user, err := newFakeClient(t, testToken, fixtureHandler(t, routes)).GetUser()
+	requireNoError(t, err, "GetUser failed")
+	if user.Email != "diner@example.com" || len(user.PaymentMethods) != 2 {
+		t.Errorf("unexpected user %+v", user)
+	}
+
+	if _, err := newFakeClient(t, "expired", fixtureHandler(t, routes)).GetUser(); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("invalid token: got %v, want %v", err, ErrUnauthorized)
+	}
+}
+
+func TestGetDefaultPaymentMethod(t *testing.T) {
+	user := &User{PaymentMethods: []PaymentMethod{{Id: "301"}, {Id: "302", IsDefault: true}}}
+	if paymentMethod, found := GetDefaultPaymentMethod(user); !found || paymentMethod.Id != "302" {
+		t.Errorf("got %+v, want the default payment method", paymentMethod)
+	}
+
+	user.PaymentMethods[1].IsDefault = false
+	if paymentMethod, found := GetDefaultPaymentMethod(user); !found || paymentMethod.Id != "301" {
+		t.Errorf("got %+v, want the first payment method", paymentMethod)
+	}
+
+	if _, found := GetDefaultPaymentMethod(&User{}); found {
+		t.Error("user without payment methods has a default payment method")
+	}
+}